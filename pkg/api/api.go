@@ -276,6 +276,7 @@ func (hs *HTTPServer) registerRoutes() {
 			datasourceRoute.Delete("/name/:name", authorize(reqOrgAdmin, ac.EvalPermission(ActionDatasourcesDelete, ScopeDatasourceName)), routing.Wrap(hs.DeleteDataSourceByName))
 			datasourceRoute.Get("/:id", authorize(reqOrgAdmin, ac.EvalPermission(ActionDatasourcesRead, ScopeDatasourceID)), routing.Wrap(GetDataSourceById))
 			datasourceRoute.Get("/uid/:uid", authorize(reqOrgAdmin, ac.EvalPermission(ActionDatasourcesRead, ScopeDatasourceUID)), routing.Wrap(GetDataSourceByUID))
+			datasourceRoute.Get("/uid/:uid/dependents", authorize(reqOrgAdmin, ac.EvalPermission(ActionDatasourcesRead, ScopeDatasourceUID)), routing.Wrap(hs.GetDataSourceDependents))
 			datasourceRoute.Get("/name/:name", authorize(reqOrgAdmin, ac.EvalPermission(ActionDatasourcesRead, ScopeDatasourceName)), routing.Wrap(GetDataSourceByName))
 		})
 
@@ -285,6 +286,8 @@ func (hs *HTTPServer) registerRoutes() {
 		apiRoute.Get("/plugins/:pluginId/settings", routing.Wrap(hs.GetPluginSettingByID))
 		apiRoute.Get("/plugins/:pluginId/markdown/:name", routing.Wrap(hs.GetPluginMarkdown))
 		apiRoute.Get("/plugins/:pluginId/health", routing.Wrap(hs.CheckHealth))
+		apiRoute.Get("/plugins/:pluginId/dependents", routing.Wrap(hs.GetPluginDependents))
+		apiRoute.Get("/library-elements/:uid/dependents", routing.Wrap(hs.GetLibraryPanelDependents))
 		apiRoute.Any("/plugins/:pluginId/resources", hs.CallResource)
 		apiRoute.Any("/plugins/:pluginId/resources/*", hs.CallResource)
 		apiRoute.Get("/plugins/errors", routing.Wrap(hs.GetPluginErrorsList))
@@ -317,6 +320,7 @@ func (hs *HTTPServer) registerRoutes() {
 				folderUidRoute.Get("/", routing.Wrap(hs.GetFolderByUID))
 				folderUidRoute.Put("/", bind(models.UpdateFolderCommand{}), routing.Wrap(hs.UpdateFolder))
 				folderUidRoute.Delete("/", routing.Wrap(hs.DeleteFolder))
+				folderUidRoute.Post("/move", bind(models.MoveFolderCommand{}), routing.Wrap(hs.MoveFolder))
 
 				folderUidRoute.Group("/permissions", func(folderPermissionRoute routing.RouteRegister) {
 					folderPermissionRoute.Get("/", routing.Wrap(hs.GetFolderPermissionList))
@@ -325,15 +329,28 @@ func (hs *HTTPServer) registerRoutes() {
 			})
 		})
 
+		// Recycle bin of deleted dashboards and folders
+		apiRoute.Group("/trash", func(trashRoute routing.RouteRegister) {
+			trashRoute.Get("/", routing.Wrap(GetTrashedDashboards))
+			trashRoute.Post("/:id/restore", routing.Wrap(hs.RestoreTrashedDashboard))
+			trashRoute.Delete("/:id", routing.Wrap(DeleteTrashedDashboard))
+		}, reqOrgAdmin)
+
 		// Dashboard
 		apiRoute.Group("/dashboards", func(dashboardRoute routing.RouteRegister) {
 			dashboardRoute.Get("/uid/:uid", routing.Wrap(hs.GetDashboard))
+			dashboardRoute.Get("/uid/:uid/views", routing.Wrap(GetDashboardViewStats))
+			dashboardRoute.Post("/uid/:uid/snapshot", bind(dtos.CaptureDashboardSnapshotCommand{}), routing.Wrap(hs.CaptureDashboardSnapshot))
+			dashboardRoute.Get("/uid/:uid/dependencies", routing.Wrap(hs.GetDashboardDependencies))
+			dashboardRoute.Get("/stale", routing.Wrap(GetStaleDashboards))
+			dashboardRoute.Get("/broken", routing.Wrap(hs.GetBrokenDashboards))
 			dashboardRoute.Delete("/uid/:uid", routing.Wrap(hs.DeleteDashboardByUID))
 
 			dashboardRoute.Post("/calculate-diff", bind(dtos.CalculateDiffOptions{}), routing.Wrap(CalculateDashboardDiff))
 			dashboardRoute.Post("/trim", bind(models.TrimDashboardCommand{}), routing.Wrap(hs.TrimDashboard))
 
 			dashboardRoute.Post("/db", bind(models.SaveDashboardCommand{}), routing.Wrap(hs.PostDashboard))
+			dashboardRoute.Post("/bulk", bind(dtos.BulkDashboardsCommand{}), routing.Wrap(hs.BulkDashboards))
 			dashboardRoute.Get("/home", routing.Wrap(hs.GetHomeDashboard))
 			dashboardRoute.Get("/tags", GetDashboardTags)
 			dashboardRoute.Post("/import", bind(dtos.ImportDashboardCommand{}), routing.Wrap(hs.ImportDashboard))
@@ -432,6 +449,11 @@ func (hs *HTTPServer) registerRoutes() {
 			// Some channels may have info
 			liveRoute.Get("/info/*", routing.Wrap(hs.Live.HandleInfoHTTP))
 
+			// Replay recorded data into managed stream channels.
+			liveRoute.Get("/replays", routing.Wrap(hs.Live.HandleReplayListHTTP), reqOrgAdmin)
+			liveRoute.Post("/replays", routing.Wrap(hs.Live.HandleReplayStartHTTP), reqOrgAdmin)
+			liveRoute.Delete("/replays/:replayId", routing.Wrap(hs.Live.HandleReplayStopHTTP), reqOrgAdmin)
+
 			if hs.Cfg.FeatureToggles["live-pipeline"] {
 				// POST Live data to be processed according to channel rules.
 				liveRoute.Post("/push/:streamId/:path", hs.LivePushGateway.HandlePath)
@@ -442,6 +464,9 @@ func (hs *HTTPServer) registerRoutes() {
 				liveRoute.Delete("/channel-rules", routing.Wrap(hs.Live.HandleChannelRulesDeleteHTTP), reqOrgAdmin)
 				liveRoute.Get("/pipeline-entities", routing.Wrap(hs.Live.HandlePipelineEntitiesListHTTP), reqOrgAdmin)
 				liveRoute.Get("/remote-write-backends", routing.Wrap(hs.Live.HandleRemoteWriteBackendsListHTTP), reqOrgAdmin)
+				liveRoute.Get("/publish-tokens", routing.Wrap(hs.Live.HandlePublishTokensListHTTP), reqOrgAdmin)
+				liveRoute.Post("/publish-tokens", routing.Wrap(hs.Live.HandlePublishTokensPostHTTP), reqOrgAdmin)
+				liveRoute.Delete("/publish-tokens/:tokenId", routing.Wrap(hs.Live.HandlePublishTokensDeleteHTTP), reqOrgAdmin)
 			}
 		})
 
@@ -456,6 +481,7 @@ func (hs *HTTPServer) registerRoutes() {
 		adminRoute.Post("/pause-all-alerts", reqGrafanaAdmin, bind(dtos.PauseAllAlertsCommand{}), routing.Wrap(PauseAllAlerts))
 
 		adminRoute.Post("/provisioning/dashboards/reload", authorize(reqGrafanaAdmin, ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersDashboards)), routing.Wrap(hs.AdminProvisioningReloadDashboards))
+		adminRoute.Get("/provisioning/dashboards/git/status", reqGrafanaAdmin, routing.Wrap(hs.AdminProvisioningGetDashboardsGitStatus))
 		adminRoute.Post("/provisioning/plugins/reload", authorize(reqGrafanaAdmin, ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersPlugins)), routing.Wrap(hs.AdminProvisioningReloadPlugins))
 		adminRoute.Post("/provisioning/datasources/reload", authorize(reqGrafanaAdmin, ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersDatasources)), routing.Wrap(hs.AdminProvisioningReloadDatasources))
 		adminRoute.Post("/provisioning/notifications/reload", authorize(reqGrafanaAdmin, ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersNotifications)), routing.Wrap(hs.AdminProvisioningReloadNotifications))
@@ -501,6 +527,11 @@ func (hs *HTTPServer) registerRoutes() {
 	r.Get("/api/snapshots-delete/:deleteKey", reqSnapshotPublicModeOrSignedIn, routing.Wrap(DeleteDashboardSnapshotByDeleteKey))
 	r.Delete("/api/snapshots/:key", reqEditorRole, routing.Wrap(DeleteDashboardSnapshot))
 
+	// Live push with scoped publish tokens, authenticated by the token itself.
+	if hs.Cfg.FeatureToggles["live-pipeline"] {
+		r.Post("/api/live/token-push/:streamId/:path", hs.LivePushGateway.HandleTokenPath)
+	}
+
 	// Frontend logs
 	sourceMapStore := frontendlogging.NewSourceMapStore(hs.Cfg, hs.PluginManager, frontendlogging.ReadSourceMapFromFS)
 	r.Post("/log", middleware.RateLimit(hs.Cfg.Sentry.EndpointRPS, hs.Cfg.Sentry.EndpointBurst, time.Now),
@@ -61,6 +61,8 @@ func loggedInUserScenarioWithRole(t *testing.T, desc string, method string, url
 		switch method {
 		case "GET":
 			sc.m.Get(routePattern, sc.defaultHandler)
+		case "POST":
+			sc.m.Post(routePattern, sc.defaultHandler)
 		case "DELETE":
 			sc.m.Delete(routePattern, sc.defaultHandler)
 		}
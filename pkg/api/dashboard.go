@@ -184,6 +184,8 @@ func (hs *HTTPServer) GetDashboard(c *models.ReqContext) response.Response {
 		Meta:      meta,
 	}
 
+	hs.recordDashboardView(c, dash)
+
 	c.TimeRequest(metrics.MApiDashboardGet)
 	return response.JSON(200, dto)
 }
@@ -242,14 +244,8 @@ func (hs *HTTPServer) deleteDashboard(c *models.ReqContext) response.Response {
 		return dashboardGuardianResponse(err)
 	}
 
-	// disconnect all library elements for this dashboard
-	err := hs.LibraryElementService.DisconnectElementsFromDashboard(c.Req.Context(), dash.Id)
-	if err != nil {
-		hs.log.Error("Failed to disconnect library elements", "dashboard", dash.Id, "user", c.SignedInUser.UserId, "error", err)
-	}
-
 	svc := dashboards.NewService(hs.SQLStore)
-	err = svc.DeleteDashboard(dash.Id, c.OrgId)
+	err := svc.DeleteDashboardByUser(dash.Id, c.OrgId, c.SignedInUser)
 	if err != nil {
 		var dashboardErr models.DashboardErr
 		if ok := errors.As(err, &dashboardErr); ok {
@@ -261,6 +257,13 @@ func (hs *HTTPServer) deleteDashboard(c *models.ReqContext) response.Response {
 		return response.Error(500, "Failed to delete dashboard", err)
 	}
 
+	// disconnect all library elements for this dashboard, after the delete so that
+	// connections are kept with the dashboard in the recycle bin
+	err = hs.LibraryElementService.DisconnectElementsFromDashboard(c.Req.Context(), dash.Id)
+	if err != nil {
+		hs.log.Error("Failed to disconnect library elements", "dashboard", dash.Id, "user", c.SignedInUser.UserId, "error", err)
+	}
+
 	if hs.Live != nil {
 		err := hs.Live.GrafanaScope.Dashboards.DashboardDeleted(c.OrgId, c.ToUserDisplayDTO(), dash.Uid)
 		if err != nil {
@@ -330,7 +333,7 @@ func (hs *HTTPServer) PostDashboard(c *models.ReqContext, cmd models.SaveDashboa
 	}
 
 	dashSvc := dashboards.NewService(hs.SQLStore)
-	dashboard, err := dashSvc.SaveDashboard(dashItem, allowUiUpdate)
+	dashboard, err := dashSvc.SaveDashboard(c.Req.Context(), dashItem, allowUiUpdate)
 
 	if hs.Live != nil {
 		// Tell everyone listening that the dashboard changed
@@ -360,6 +363,14 @@ func (hs *HTTPServer) PostDashboard(c *models.ReqContext, cmd models.SaveDashboa
 		return hs.dashboardSaveErrorToApiResponse(err)
 	}
 
+	// commit the dashboard to the git repository it was provisioned from
+	if provisioningData != nil {
+		err := hs.ProvisioningService.CommitProvisionedDashboard(ctx, provisioningData, dashboard, c.SignedInUser, cmd.Message)
+		if err != nil {
+			c.Logger.Error("Failed to commit provisioned dashboard", "dashboard", dashboard.Uid, "provisioner", provisioningData.Name, "error", err)
+		}
+	}
+
 	if hs.Cfg.EditorsCanAdmin && newDashboard {
 		inFolder := cmd.FolderId > 0
 		err := dashSvc.MakeUserAdmin(ctx, cmd.OrgId, cmd.UserId, dashboard.Id, !inFolder)
@@ -386,6 +397,11 @@ func (hs *HTTPServer) PostDashboard(c *models.ReqContext, cmd models.SaveDashboa
 }
 
 func (hs *HTTPServer) dashboardSaveErrorToApiResponse(err error) response.Response {
+	var conflictErr models.DashboardMergeConflictError
+	if ok := errors.As(err, &conflictErr); ok {
+		return response.JSON(412, conflictErr.Body())
+	}
+
 	var dashboardErr models.DashboardErr
 	if ok := errors.As(err, &dashboardErr); ok {
 		if body := dashboardErr.Body(); body != nil {
@@ -0,0 +1,453 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/api/apierrors"
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/search"
+)
+
+const (
+	bulkOperationMove       = "move"
+	bulkOperationAddTags    = "addTags"
+	bulkOperationRemoveTags = "removeTags"
+	bulkOperationDelete     = "delete"
+	bulkOperationExport     = "export"
+
+	bulkStatusSuccess   = "success"
+	bulkStatusUnchanged = "unchanged"
+	bulkStatusFailed    = "failed"
+
+	// maxBulkDashboards is the maximum number of dashboards of a bulk operation,
+	// the same as the maximum limit of the search API.
+	maxBulkDashboards = 5000
+
+	bulkExportReportFile = "report.json"
+)
+
+// bulkDashboard is a dashboard of a bulk operation with its result. The dashboards
+// without a status are still to be processed.
+type bulkDashboard struct {
+	dash   *models.Dashboard
+	result *dtos.BulkDashboardResult
+}
+
+func (b *bulkDashboard) fail(message string) {
+	b.result.Status = bulkStatusFailed
+	b.result.Message = message
+}
+
+// pendingBulkDashboards returns the dashboards which didn't fail or succeed yet.
+func pendingBulkDashboards(items []*bulkDashboard) []*bulkDashboard {
+	pending := make([]*bulkDashboard, 0, len(items))
+	for _, item := range items {
+		if item.result.Status == "" {
+			pending = append(pending, item)
+		}
+	}
+	return pending
+}
+
+// POST /api/dashboards/bulk
+func (hs *HTTPServer) BulkDashboards(c *models.ReqContext, cmd dtos.BulkDashboardsCommand) response.Response {
+	if (len(cmd.DashboardUids) == 0) == (cmd.Query == nil) {
+		return response.Error(400, "Either dashboardUids or query is required", nil)
+	}
+
+	folderID := int64(0)
+	folderTitle := "General"
+	switch cmd.Operation {
+	case bulkOperationMove:
+		if cmd.FolderUid != "" {
+			folders := dashboards.NewFolderService(c.OrgId, c.SignedInUser, hs.SQLStore)
+			folder, err := folders.GetFolderByUID(c.Req.Context(), cmd.FolderUid)
+			if err != nil {
+				return apierrors.ToFolderErrorResponse(err)
+			}
+			folderID = folder.Id
+			folderTitle = folder.Title
+		}
+	case bulkOperationAddTags, bulkOperationRemoveTags:
+		if len(cmd.Tags) == 0 {
+			return response.Error(400, "Tags are required", nil)
+		}
+	case bulkOperationDelete, bulkOperationExport:
+	default:
+		return response.Error(400, fmt.Sprintf("Invalid operation %q", cmd.Operation), nil)
+	}
+
+	items, rsp := hs.getBulkDashboards(c, cmd)
+	if rsp != nil {
+		return rsp
+	}
+
+	for _, item := range pendingBulkDashboards(items) {
+		g := guardian.New(c.Req.Context(), item.dash.Id, c.OrgId, c.SignedInUser)
+		var allowed bool
+		var err error
+		if cmd.Operation == bulkOperationExport {
+			allowed, err = g.CanView()
+		} else {
+			allowed, err = g.CanSave()
+		}
+		if err != nil {
+			hs.log.Error("Failed to check dashboard permissions", "dashboard", item.dash.Uid, "error", err)
+			item.fail("Failed to check dashboard permissions")
+		} else if !allowed {
+			item.fail("Access denied to this dashboard")
+		}
+	}
+
+	switch cmd.Operation {
+	case bulkOperationMove:
+		message := cmd.Message
+		if message == "" {
+			message = fmt.Sprintf("Moved to %s", folderTitle)
+		}
+		hs.bulkSaveDashboards(c, items, message, func(dash *models.Dashboard) bool {
+			if dash.FolderId == folderID {
+				return false
+			}
+			dash.FolderId = folderID
+			return true
+		})
+	case bulkOperationAddTags:
+		message := cmd.Message
+		if message == "" {
+			message = fmt.Sprintf("Added tags %s", strings.Join(cmd.Tags, ", "))
+		}
+		hs.bulkSaveDashboards(c, items, message, func(dash *models.Dashboard) bool {
+			tags := dash.GetTags()
+			for _, tag := range cmd.Tags {
+				if !containsTag(tags, tag) {
+					tags = append(tags, tag)
+				}
+			}
+			return setDashboardTags(dash, tags)
+		})
+	case bulkOperationRemoveTags:
+		message := cmd.Message
+		if message == "" {
+			message = fmt.Sprintf("Removed tags %s", strings.Join(cmd.Tags, ", "))
+		}
+		hs.bulkSaveDashboards(c, items, message, func(dash *models.Dashboard) bool {
+			tags := make([]string, 0)
+			for _, tag := range dash.GetTags() {
+				if !containsTag(cmd.Tags, tag) {
+					tags = append(tags, tag)
+				}
+			}
+			return setDashboardTags(dash, tags)
+		})
+	case bulkOperationDelete:
+		hs.bulkDeleteDashboards(c, items)
+	case bulkOperationExport:
+		return hs.bulkExportDashboards(c, cmd.Operation, items)
+	}
+
+	return response.JSON(200, bulkDashboardsReport(cmd.Operation, items))
+}
+
+// getBulkDashboards returns the dashboards with the UIDs of the command, or matching its
+// search query, in order. Missing dashboards and folders are marked as failed.
+func (hs *HTTPServer) getBulkDashboards(c *models.ReqContext, cmd dtos.BulkDashboardsCommand) ([]*bulkDashboard, response.Response) {
+	uids := cmd.DashboardUids
+	if cmd.Query != nil {
+		searchQuery := search.Query{
+			Title:        cmd.Query.Query,
+			Tags:         cmd.Query.Tags,
+			SignedInUser: c.SignedInUser,
+			Limit:        maxBulkDashboards + 1,
+			IsStarred:    cmd.Query.Starred,
+			OrgId:        c.OrgId,
+			Type:         string(search.DashHitDB),
+			FolderIds:    cmd.Query.FolderIds,
+			Permission:   models.PERMISSION_VIEW,
+			Content:      cmd.Query.Content,
+		}
+		if err := bus.DispatchCtx(c.Req.Context(), &searchQuery); err != nil {
+			return nil, response.Error(500, "Search failed", err)
+		}
+
+		uids = make([]string, 0, len(searchQuery.Result))
+		for _, hit := range searchQuery.Result {
+			uids = append(uids, hit.UID)
+		}
+	}
+
+	if len(uids) > maxBulkDashboards {
+		return nil, response.Error(422, fmt.Sprintf("Bulk operations are limited to %d dashboards", maxBulkDashboards), nil)
+	}
+
+	items := make([]*bulkDashboard, 0, len(uids))
+	seen := make(map[string]bool, len(uids))
+	for _, uid := range uids {
+		if seen[uid] {
+			continue
+		}
+		seen[uid] = true
+
+		item := &bulkDashboard{result: &dtos.BulkDashboardResult{Uid: uid}}
+		items = append(items, item)
+
+		query := models.GetDashboardQuery{Uid: uid, OrgId: c.OrgId}
+		if err := bus.DispatchCtx(c.Req.Context(), &query); err != nil {
+			if errors.Is(err, models.ErrDashboardNotFound) {
+				item.fail("Dashboard not found")
+				continue
+			}
+			return nil, response.Error(500, "Failed to get dashboard", err)
+		}
+
+		item.dash = query.Result
+		item.result.Title = item.dash.Title
+		item.result.Url = item.dash.GetUrl()
+		if item.dash.IsFolder {
+			item.fail("Bulk operations don't apply to folders")
+		}
+	}
+
+	return items, nil
+}
+
+// bulkSaveDashboards saves the pending dashboards changed by update in a single transaction.
+func (hs *HTTPServer) bulkSaveDashboards(c *models.ReqContext, items []*bulkDashboard, message string,
+	update func(dash *models.Dashboard) bool) {
+	var saved []*bulkDashboard
+	var saveDTOs []*dashboards.SaveDashboardDTO
+	for _, item := range pendingBulkDashboards(items) {
+		if !update(item.dash) {
+			item.result.Status = bulkStatusUnchanged
+			continue
+		}
+
+		saved = append(saved, item)
+		saveDTOs = append(saveDTOs, &dashboards.SaveDashboardDTO{
+			Dashboard: item.dash,
+			Message:   message,
+			OrgId:     c.OrgId,
+			User:      c.SignedInUser,
+		})
+	}
+
+	if len(saved) == 0 {
+		return
+	}
+
+	// provisioned dashboards can't be changed by bulk operations, even if the provisioner allows UI updates
+	errs := dashboards.NewService(hs.SQLStore).SaveDashboards(c.Req.Context(), saveDTOs, false)
+	for i, item := range saved {
+		if errs[i] != nil {
+			item.fail(hs.bulkDashboardErrorMessage("save", item, errs[i]))
+			continue
+		}
+
+		item.dash = saveDTOs[i].Dashboard
+		item.result.Status = bulkStatusSuccess
+		item.result.Url = item.dash.GetUrl()
+
+		if hs.Live != nil {
+			err := hs.Live.GrafanaScope.Dashboards.DashboardSaved(c.OrgId, c.ToUserDisplayDTO(), message, item.dash, nil)
+			if err != nil {
+				hs.log.Warn("unable to broadcast save event", "uid", item.dash.Uid, "error", err)
+			}
+		}
+	}
+}
+
+// bulkDeleteDashboards deletes the pending dashboards in a single transaction.
+func (hs *HTTPServer) bulkDeleteDashboards(c *models.ReqContext, items []*bulkDashboard) {
+	deleted := pendingBulkDashboards(items)
+	if len(deleted) == 0 {
+		return
+	}
+
+	ids := make([]int64, 0, len(deleted))
+	for _, item := range deleted {
+		ids = append(ids, item.dash.Id)
+	}
+
+	errs := dashboards.NewService(hs.SQLStore).DeleteDashboardsByUser(c.Req.Context(), ids, c.OrgId, c.SignedInUser)
+	for i, item := range deleted {
+		if errs[i] != nil {
+			item.fail(hs.bulkDashboardErrorMessage("delete", item, errs[i]))
+			continue
+		}
+
+		item.result.Status = bulkStatusSuccess
+		item.result.Url = ""
+
+		// disconnect all library elements for this dashboard, after the delete so that
+		// connections are kept with the dashboard in the recycle bin
+		err := hs.LibraryElementService.DisconnectElementsFromDashboard(c.Req.Context(), item.dash.Id)
+		if err != nil {
+			hs.log.Error("Failed to disconnect library elements", "dashboard", item.dash.Id, "user", c.SignedInUser.UserId, "error", err)
+		}
+
+		if hs.Live != nil {
+			err := hs.Live.GrafanaScope.Dashboards.DashboardDeleted(c.OrgId, c.ToUserDisplayDTO(), item.dash.Uid)
+			if err != nil {
+				hs.log.Error("Failed to broadcast delete info", "dashboard", item.dash.Uid, "error", err)
+			}
+		}
+	}
+}
+
+// bulkExportDashboards returns a zip file with the JSON model of each pending dashboard, in
+// directories named after its folders, and the report of the export.
+func (hs *HTTPServer) bulkExportDashboards(c *models.ReqContext, operation string, items []*bulkDashboard) response.Response {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	folderPaths := map[int64]string{0: models.SlugifyTitle("General")}
+	files := map[string]bool{bulkExportReportFile: true}
+	for _, item := range pendingBulkDashboards(items) {
+		folderPath, err := bulkExportFolderPath(c.Req.Context(), c.OrgId, item.dash.FolderId, folderPaths)
+		if err != nil {
+			item.fail(hs.bulkDashboardErrorMessage("export", item, err))
+			continue
+		}
+
+		file := path.Join(folderPath, item.dash.Slug+".json")
+		if files[file] {
+			file = path.Join(folderPath, item.dash.Slug+"-"+item.dash.Uid+".json")
+		}
+		files[file] = true
+
+		item.dash.Data.Del("id")
+		content, err := item.dash.Data.EncodePretty()
+		if err != nil {
+			item.fail(hs.bulkDashboardErrorMessage("export", item, err))
+			continue
+		}
+
+		w, err := zw.Create(file)
+		if err != nil {
+			return response.Error(500, "Failed to export dashboards", err)
+		}
+		if _, err := w.Write(content); err != nil {
+			return response.Error(500, "Failed to export dashboards", err)
+		}
+
+		item.result.Status = bulkStatusSuccess
+		item.result.File = file
+	}
+
+	report, err := json.MarshalIndent(bulkDashboardsReport(operation, items), "", "  ")
+	if err != nil {
+		return response.Error(500, "Failed to export dashboards", err)
+	}
+	w, err := zw.Create(bulkExportReportFile)
+	if err != nil {
+		return response.Error(500, "Failed to export dashboards", err)
+	}
+	if _, err := w.Write(report); err != nil {
+		return response.Error(500, "Failed to export dashboards", err)
+	}
+
+	if err := zw.Close(); err != nil {
+		return response.Error(500, "Failed to export dashboards", err)
+	}
+
+	return response.Respond(200, buf.Bytes()).
+		SetHeader("Content-Type", "application/zip").
+		SetHeader("Content-Disposition", `attachment; filename="dashboards.zip"`)
+}
+
+// bulkExportFolderPath returns the directory of the dashboards in the folder, made of the
+// slugs of the folder and its parent folders. Paths are cached in folderPaths.
+func bulkExportFolderPath(ctx context.Context, orgID int64, folderID int64, folderPaths map[int64]string) (string, error) {
+	if folderPath, ok := folderPaths[folderID]; ok {
+		return folderPath, nil
+	}
+
+	query := models.GetFolderPathQuery{OrgId: orgID, FolderId: folderID}
+	if err := bus.DispatchCtx(ctx, &query); err != nil {
+		return "", err
+	}
+
+	slugs := make([]string, 0, len(query.Result))
+	for _, folder := range query.Result {
+		slugs = append(slugs, models.SlugifyTitle(folder.Title))
+	}
+
+	folderPath := path.Join(slugs...)
+	folderPaths[folderID] = folderPath
+	return folderPath, nil
+}
+
+func (hs *HTTPServer) bulkDashboardErrorMessage(action string, item *bulkDashboard, err error) string {
+	var dashboardErr models.DashboardErr
+	if errors.As(err, &dashboardErr) {
+		return dashboardErr.Error()
+	}
+
+	hs.log.Error("Bulk dashboard operation failed", "action", action, "dashboard", item.dash.Uid, "error", err)
+	return fmt.Sprintf("Failed to %s dashboard", action)
+}
+
+func bulkDashboardsReport(operation string, items []*bulkDashboard) dtos.BulkDashboardsReport {
+	report := dtos.BulkDashboardsReport{
+		Operation: operation,
+		Total:     len(items),
+		Results:   make([]*dtos.BulkDashboardResult, 0, len(items)),
+	}
+	for _, item := range items {
+		switch item.result.Status {
+		case bulkStatusSuccess:
+			report.Succeeded++
+		case bulkStatusUnchanged:
+			report.Unchanged++
+		case bulkStatusFailed:
+			report.Failed++
+		}
+		report.Results = append(report.Results, item.result)
+	}
+	return report
+}
+
+// setDashboardTags sets the tags of the dashboard, returning false if they didn't change.
+func setDashboardTags(dash *models.Dashboard, tags []string) bool {
+	current := dash.GetTags()
+	if len(current) == len(tags) {
+		changed := false
+		for i := range tags {
+			if current[i] != tags[i] {
+				changed = true
+				break
+			}
+		}
+		if !changed {
+			return false
+		}
+	}
+
+	values := make([]interface{}, 0, len(tags))
+	for _, tag := range tags {
+		values = append(values, tag)
+	}
+	dash.Data.Set("tags", values)
+	return true
+}
+
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,210 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/web"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulkDashboardsAPIEndpoint(t *testing.T) {
+	const readOnlyDashboardID = 3
+
+	setUp := func(t *testing.T) (*HTTPServer, *models.ReqContext, *dashboards.FakeDashboardService) {
+		t.Helper()
+
+		dashboardsByUID := map[string]*models.Dashboard{}
+		for _, dash := range []*models.Dashboard{
+			bulkTestDashboard(1, "dash-1", "Dashboard 1", 0, "prod"),
+			bulkTestDashboard(2, "dash-2", "Dashboard 2", 10, "prod", "web"),
+			bulkTestDashboard(readOnlyDashboardID, "dash-3", "Dashboard 3", 0),
+		} {
+			dashboardsByUID[dash.Uid] = dash
+		}
+		folder := models.NewDashboardFolder("Folder")
+		folder.Id = 10
+		folder.Uid = "folder"
+		dashboardsByUID[folder.Uid] = folder
+
+		origNew := guardian.New
+		origNewService := dashboards.NewService
+		t.Cleanup(func() {
+			guardian.New = origNew
+			dashboards.NewService = origNewService
+			bus.ClearBusHandlers()
+		})
+
+		bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetDashboardQuery) error {
+			dash, ok := dashboardsByUID[query.Uid]
+			if !ok {
+				return models.ErrDashboardNotFound
+			}
+			query.Result = dash
+			return nil
+		})
+		bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetFolderPathQuery) error {
+			query.Result = []*models.Dashboard{folder}
+			return nil
+		})
+
+		guardian.New = func(_ context.Context, dashID int64, orgID int64, user *models.SignedInUser) guardian.DashboardGuardian {
+			return &guardian.FakeDashboardGuardian{
+				CanViewValue: true,
+				CanSaveValue: dashID != readOnlyDashboardID,
+			}
+		}
+
+		mock := &dashboards.FakeDashboardService{}
+		dashboards.MockDashboardService(mock)
+
+		httpReq, err := http.NewRequest(http.MethodPost, "/api/dashboards/bulk", nil)
+		require.NoError(t, err)
+		c := &models.ReqContext{
+			Context:      &web.Context{Req: httpReq},
+			SignedInUser: &models.SignedInUser{OrgId: 1, UserId: 1, OrgRole: models.ROLE_EDITOR},
+		}
+		hs := &HTTPServer{
+			log:                   log.New("test"),
+			LibraryElementService: &mockLibraryElementService{},
+		}
+
+		return hs, c, mock
+	}
+
+	t.Run("Should add tags and report the result of each dashboard", func(t *testing.T) {
+		hs, c, mock := setUp(t)
+
+		resp := hs.BulkDashboards(c, dtos.BulkDashboardsCommand{
+			Operation:     bulkOperationAddTags,
+			DashboardUids: []string{"dash-1", "dash-2", "dash-3", "missing", "folder"},
+			Tags:          []string{"web"},
+		})
+		require.Equal(t, 200, resp.Status())
+
+		var report dtos.BulkDashboardsReport
+		require.NoError(t, json.Unmarshal(resp.Body(), &report))
+		require.Equal(t, 5, report.Total)
+		require.Equal(t, 1, report.Succeeded)
+		require.Equal(t, 1, report.Unchanged)
+		require.Equal(t, 3, report.Failed)
+
+		statuses := map[string]string{}
+		for _, result := range report.Results {
+			statuses[result.Uid] = result.Status
+		}
+		require.Equal(t, map[string]string{
+			"dash-1":  bulkStatusSuccess,
+			"dash-2":  bulkStatusUnchanged,
+			"dash-3":  bulkStatusFailed,
+			"missing": bulkStatusFailed,
+			"folder":  bulkStatusFailed,
+		}, statuses)
+
+		require.Len(t, mock.SavedDashboards, 1)
+		require.Equal(t, []string{"prod", "web"}, mock.SavedDashboards[0].Dashboard.GetTags())
+		require.Equal(t, "Added tags web", mock.SavedDashboards[0].Message)
+	})
+
+	t.Run("Should remove tags", func(t *testing.T) {
+		hs, c, mock := setUp(t)
+
+		resp := hs.BulkDashboards(c, dtos.BulkDashboardsCommand{
+			Operation:     bulkOperationRemoveTags,
+			DashboardUids: []string{"dash-1", "dash-2"},
+			Tags:          []string{"prod"},
+		})
+		require.Equal(t, 200, resp.Status())
+
+		require.Len(t, mock.SavedDashboards, 2)
+		require.Empty(t, mock.SavedDashboards[0].Dashboard.GetTags())
+		require.Equal(t, []string{"web"}, mock.SavedDashboards[1].Dashboard.GetTags())
+	})
+
+	t.Run("Should delete the dashboards the user can save", func(t *testing.T) {
+		hs, c, _ := setUp(t)
+
+		resp := hs.BulkDashboards(c, dtos.BulkDashboardsCommand{
+			Operation:     bulkOperationDelete,
+			DashboardUids: []string{"dash-1", "dash-3"},
+		})
+		require.Equal(t, 200, resp.Status())
+
+		var report dtos.BulkDashboardsReport
+		require.NoError(t, json.Unmarshal(resp.Body(), &report))
+		require.Equal(t, bulkStatusSuccess, report.Results[0].Status)
+		require.Equal(t, bulkStatusFailed, report.Results[1].Status)
+		require.Equal(t, "Access denied to this dashboard", report.Results[1].Message)
+	})
+
+	t.Run("Should export the dashboards as a zip file", func(t *testing.T) {
+		hs, c, _ := setUp(t)
+
+		resp := hs.BulkDashboards(c, dtos.BulkDashboardsCommand{
+			Operation:     bulkOperationExport,
+			DashboardUids: []string{"dash-1", "dash-2", "missing"},
+		})
+		require.Equal(t, 200, resp.Status())
+
+		files := readZipFiles(t, resp.Body())
+		require.Len(t, files, 3)
+		require.JSONEq(t, `{"uid": "dash-1", "title": "Dashboard 1", "tags": ["prod"], "version": 0}`, files["general/dashboard-1.json"])
+		require.Contains(t, files, "folder/dashboard-2.json")
+
+		var report dtos.BulkDashboardsReport
+		require.NoError(t, json.Unmarshal([]byte(files[bulkExportReportFile]), &report))
+		require.Equal(t, 2, report.Succeeded)
+		require.Equal(t, 1, report.Failed)
+	})
+
+	t.Run("Should fail without dashboards or with an invalid operation", func(t *testing.T) {
+		hs, c, _ := setUp(t)
+
+		resp := hs.BulkDashboards(c, dtos.BulkDashboardsCommand{Operation: bulkOperationDelete})
+		require.Equal(t, 400, resp.Status())
+
+		resp = hs.BulkDashboards(c, dtos.BulkDashboardsCommand{Operation: "rename", DashboardUids: []string{"dash-1"}})
+		require.Equal(t, 400, resp.Status())
+	})
+}
+
+func bulkTestDashboard(id int64, uid string, title string, folderID int64, tags ...interface{}) *models.Dashboard {
+	dash := models.NewDashboardFromJson(simplejson.NewFromAny(map[string]interface{}{
+		"id":    id,
+		"uid":   uid,
+		"title": title,
+		"tags":  tags,
+	}))
+	dash.FolderId = folderID
+	return dash
+}
+
+func readZipFiles(t *testing.T, content []byte) map[string]string {
+	t.Helper()
+
+	zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	require.NoError(t, err)
+
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, err := ioutil.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		files[f.Name] = string(data)
+	}
+	return files
+}
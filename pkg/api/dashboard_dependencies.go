@@ -0,0 +1,77 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/libraryelements"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// GET /api/dashboards/uid/:uid/dependencies
+func (hs *HTTPServer) GetDashboardDependencies(c *models.ReqContext) response.Response {
+	dash, rsp := getDashboardHelper(c.Req.Context(), c.OrgId, 0, web.Params(c.Req)[":uid"])
+	if rsp != nil {
+		return rsp
+	}
+
+	guardian := guardian.New(c.Req.Context(), dash.Id, c.OrgId, c.SignedInUser)
+	if canView, err := guardian.CanView(); err != nil || !canView {
+		return dashboardGuardianResponse(err)
+	}
+
+	deps, err := hs.DashboardDepsService.GetDashboardDependencies(c.Req.Context(), c.SignedInUser, dash)
+	if err != nil {
+		return response.Error(500, "Failed to get dashboard dependencies", err)
+	}
+	return response.JSON(200, deps)
+}
+
+// GET /api/dashboards/broken
+func (hs *HTTPServer) GetBrokenDashboards(c *models.ReqContext) response.Response {
+	broken, err := hs.DashboardDepsService.GetBrokenDashboards(c.Req.Context(), c.SignedInUser)
+	if err != nil {
+		return response.Error(500, "Failed to get broken dashboards", err)
+	}
+	return response.JSON(200, broken)
+}
+
+// GET /api/datasources/uid/:uid/dependents
+func (hs *HTTPServer) GetDataSourceDependents(c *models.ReqContext) response.Response {
+	ds, err := getRawDataSourceByUID(c.Req.Context(), web.Params(c.Req)[":uid"], c.OrgId)
+	if err != nil {
+		if errors.Is(err, models.ErrDataSourceNotFound) {
+			return response.Error(404, "Data source not found", nil)
+		}
+		return response.Error(500, "Failed to query datasources", err)
+	}
+
+	dependents, err := hs.DashboardDepsService.GetDatasourceDependents(c.Req.Context(), c.SignedInUser, ds)
+	if err != nil {
+		return response.Error(500, "Failed to get data source dependents", err)
+	}
+	return response.JSON(200, dependents)
+}
+
+// GET /api/plugins/:pluginId/dependents
+func (hs *HTTPServer) GetPluginDependents(c *models.ReqContext) response.Response {
+	dependents, err := hs.DashboardDepsService.GetPluginDependents(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":pluginId"])
+	if err != nil {
+		return response.Error(500, "Failed to get plugin dependents", err)
+	}
+	return response.JSON(200, dependents)
+}
+
+// GET /api/library-elements/:uid/dependents
+func (hs *HTTPServer) GetLibraryPanelDependents(c *models.ReqContext) response.Response {
+	dependents, err := hs.DashboardDepsService.GetLibraryPanelDependents(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"])
+	if err != nil {
+		if errors.Is(err, libraryelements.ErrLibraryElementNotFound) {
+			return response.Error(404, "Library element could not be found", err)
+		}
+		return response.Error(500, "Failed to get library element dependents", err)
+	}
+	return response.JSON(200, dependents)
+}
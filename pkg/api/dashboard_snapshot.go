@@ -3,6 +3,7 @@ package api
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -13,6 +14,7 @@ import (
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
 	"github.com/grafana/grafana/pkg/services/guardian"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
@@ -332,3 +334,98 @@ func SearchDashboardSnapshots(c *models.ReqContext) response.Response {
 
 	return response.JSON(200, dtos)
 }
+
+// POST /api/dashboards/uid/:uid/snapshot
+func (hs *HTTPServer) CaptureDashboardSnapshot(c *models.ReqContext, cmd dtos.CaptureDashboardSnapshotCommand) response.Response {
+	dash, rsp := getDashboardHelper(c.Req.Context(), c.OrgId, 0, web.Params(c.Req)[":uid"])
+	if rsp != nil {
+		return rsp
+	}
+
+	guardian := guardian.New(c.Req.Context(), dash.Id, c.OrgId, c.SignedInUser)
+	if canView, err := guardian.CanView(); err != nil || !canView {
+		return dashboardGuardianResponse(err)
+	}
+
+	if err := hs.LibraryPanelService.LoadLibraryPanelsForDashboard(c.Req.Context(), dash); err != nil {
+		return response.Error(500, "Error while loading library panels", err)
+	}
+
+	variables := make(map[string][]string, len(cmd.Variables))
+	for name, value := range cmd.Variables {
+		switch v := value.(type) {
+		case string:
+			variables[name] = []string{v}
+		case []interface{}:
+			values := make([]string, 0, len(v))
+			for _, item := range v {
+				s, ok := item.(string)
+				if !ok {
+					return response.Error(400, fmt.Sprintf("Values of variable %s must be strings", name), nil)
+				}
+				values = append(values, s)
+			}
+			variables[name] = values
+		default:
+			return response.Error(400, fmt.Sprintf("Value of variable %s must be a string or a list of strings", name), nil)
+		}
+	}
+
+	capturer := dashboardsnapshots.Capturer{
+		Cfg:                    hs.Cfg,
+		DataService:            hs.DataService,
+		DataSourceCache:        hs.DataSourceCache,
+		PluginRequestValidator: hs.PluginRequestValidator,
+	}
+	snapshot, panelErrors, err := capturer.Capture(c.Req.Context(), dash.Data, dashboardsnapshots.CaptureOptions{
+		User:      c.SignedInUser,
+		Headers:   queryHeaders(c),
+		SkipCache: c.SkipCache,
+		From:      cmd.From,
+		To:        cmd.To,
+		Variables: variables,
+	})
+	if err != nil {
+		if errors.Is(err, dashboardsnapshots.ErrInvalidSnapshotTimeRange) {
+			return response.Error(400, "Invalid time range", err)
+		}
+		return response.Error(500, "Failed to capture dashboard", err)
+	}
+
+	key, err := util.GetRandomString(32)
+	if err != nil {
+		return response.Error(500, "Could not generate random string", err)
+	}
+	deleteKey, err := util.GetRandomString(32)
+	if err != nil {
+		return response.Error(500, "Could not generate random string", err)
+	}
+
+	name := cmd.Name
+	if name == "" {
+		name = dash.Title
+	}
+	createCmd := models.CreateDashboardSnapshotCommand{
+		Dashboard: snapshot,
+		Name:      name,
+		Expires:   cmd.Expires,
+		Key:       key,
+		DeleteKey: deleteKey,
+		OrgId:     c.OrgId,
+		UserId:    c.UserId,
+	}
+	if err := bus.DispatchCtx(c.Req.Context(), &createCmd); err != nil {
+		return response.Error(500, "Failed to create snapshot", err)
+	}
+
+	metrics.MApiDashboardSnapshotCreate.Inc()
+
+	return response.JSON(200, util.DynMap{
+		"key":         key,
+		"deleteKey":   deleteKey,
+		"url":         setting.ToAbsUrl("dashboard/snapshot/" + key),
+		"deleteUrl":   setting.ToAbsUrl("api/snapshots-delete/" + deleteKey),
+		"id":          createCmd.Result.Id,
+		"panelErrors": panelErrors,
+	})
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -8,9 +9,13 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -249,3 +254,81 @@ func TestDashboardSnapshotAPIEndpoint_singleSnapshot(t *testing.T) {
 			})
 	})
 }
+
+func TestCaptureDashboardSnapshotAPIEndpoint(t *testing.T) {
+	hs := &HTTPServer{Cfg: setting.NewCfg(), LibraryPanelService: &mockLibraryPanelService{}}
+
+	setUpCaptureTest := func(t *testing.T) *models.CreateDashboardSnapshotCommand {
+		t.Helper()
+
+		dashboard, err := simplejson.NewJson([]byte(`{
+			"title": "Overview",
+			"time": {"from": "now-6h", "to": "now"},
+			"panels": [{"id": 1, "type": "text", "options": {"content": "$host"}}],
+			"templating": {"list": [{"name": "host", "type": "query", "query": "hosts",
+				"current": {"text": "web-1", "value": "web-1"}}]}
+		}`))
+		require.NoError(t, err)
+
+		bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetDashboardQuery) error {
+			query.Result = &models.Dashboard{Id: 1, Uid: "abc", OrgId: 1, Title: "Overview", Data: dashboard}
+			return nil
+		})
+
+		origNewGuardian := guardian.New
+		t.Cleanup(func() {
+			guardian.New = origNewGuardian
+		})
+		guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanViewValue: true})
+
+		created := &models.CreateDashboardSnapshotCommand{}
+		bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.CreateDashboardSnapshotCommand) error {
+			*created = *cmd
+			created.Result = &models.DashboardSnapshot{Id: 7}
+			cmd.Result = created.Result
+			return nil
+		})
+		return created
+	}
+
+	loggedInUserScenarioWithRole(t, "Should store a snapshot of the dashboard when calling POST on", "POST",
+		"/api/dashboards/uid/abc/snapshot", "/api/dashboards/uid/:uid/snapshot", models.ROLE_VIEWER, func(sc *scenarioContext) {
+			created := setUpCaptureTest(t)
+
+			sc.handlerFunc = func(c *models.ReqContext) response.Response {
+				return hs.CaptureDashboardSnapshot(c, dtos.CaptureDashboardSnapshotCommand{
+					From:      "now-1h",
+					To:        "now",
+					Variables: map[string]interface{}{"host": "web-2"},
+					Expires:   3600,
+				})
+			}
+			sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+
+			require.Equal(t, 200, sc.resp.Code, sc.resp.Body.String())
+			respJSON, err := simplejson.NewJson(sc.resp.Body.Bytes())
+			require.NoError(t, err)
+			assert.Equal(t, int64(7), respJSON.Get("id").MustInt64())
+			assert.True(t, strings.HasSuffix(respJSON.Get("url").MustString(), "dashboard/snapshot/"+created.Key))
+
+			assert.Equal(t, "Overview", created.Name)
+			assert.Equal(t, int64(3600), created.Expires)
+			assert.Equal(t, "now-1h", created.Dashboard.GetPath("time", "from").MustString())
+			variable := created.Dashboard.GetPath("templating", "list").GetIndex(0)
+			assert.Equal(t, "web-2", variable.GetPath("current", "value").MustString())
+			assert.Equal(t, "", variable.Get("query").MustString())
+			assert.NotNil(t, created.Dashboard.Get("snapshot").Interface())
+		})
+
+	loggedInUserScenarioWithRole(t, "Should return 400 for an invalid time range when calling POST on", "POST",
+		"/api/dashboards/uid/abc/snapshot", "/api/dashboards/uid/:uid/snapshot", models.ROLE_VIEWER, func(sc *scenarioContext) {
+			setUpCaptureTest(t)
+
+			sc.handlerFunc = func(c *models.ReqContext) response.Response {
+				return hs.CaptureDashboardSnapshot(c, dtos.CaptureDashboardSnapshotCommand{From: "now", To: "now-1h"})
+			}
+			sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+
+			assert.Equal(t, 400, sc.resp.Code)
+		})
+}
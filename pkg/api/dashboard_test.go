@@ -739,8 +739,10 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 				{SaveError: models.ErrDashboardWithSameUIDExists, ExpectedStatusCode: 400},
 				{SaveError: models.ErrDashboardWithSameNameInFolderExists, ExpectedStatusCode: 412},
 				{SaveError: models.ErrDashboardVersionMismatch, ExpectedStatusCode: 412},
+				{SaveError: models.DashboardMergeConflictError{Conflicts: []string{"panels[id=1].title"}}, ExpectedStatusCode: 412},
 				{SaveError: models.ErrDashboardTitleEmpty, ExpectedStatusCode: 400},
-				{SaveError: models.ErrDashboardFolderCannotHaveParent, ExpectedStatusCode: 400},
+				{SaveError: models.ErrDashboardFolderMaxDepthExceeded, ExpectedStatusCode: 400},
+				{SaveError: models.ErrDashboardFolderCircularParent, ExpectedStatusCode: 400},
 				{SaveError: alerting.ValidationError{Reason: "Mu"}, ExpectedStatusCode: 422},
 				{SaveError: models.ErrDashboardFailedGenerateUniqueUid, ExpectedStatusCode: 500},
 				{SaveError: models.ErrDashboardTypeMismatch, ExpectedStatusCode: 400},
@@ -1267,6 +1269,11 @@ func (l *mockLibraryElementService) GetElementsForDashboard(c context.Context, d
 	return map[string]libraryelements.LibraryElementDTO{}, nil
 }
 
+// GetConnections gets the dashboards connected to an element.
+func (l *mockLibraryElementService) GetConnections(c context.Context, signedInUser *models.SignedInUser, UID string) ([]libraryelements.LibraryElementConnectionDTO, error) {
+	return []libraryelements.LibraryElementConnectionDTO{}, nil
+}
+
 // ConnectElementsToDashboard connects elements to a specific dashboard.
 func (l *mockLibraryElementService) ConnectElementsToDashboard(c context.Context, signedInUser *models.SignedInUser, elementUIDs []string, dashboardID int64) error {
 	return nil
@@ -1281,3 +1288,8 @@ func (l *mockLibraryElementService) DisconnectElementsFromDashboard(c context.Co
 func (l *mockLibraryElementService) DeleteLibraryElementsInFolder(c context.Context, signedInUser *models.SignedInUser, folderUID string) error {
 	return nil
 }
+
+// DeleteExpiredVersions deletes the oldest versions of all elements.
+func (l *mockLibraryElementService) DeleteExpiredVersions(c context.Context) (int64, error) {
+	return 0, nil
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// GetTrashedDashboards returns deleted dashboards and folders in the recycle bin of the org
+func GetTrashedDashboards(c *models.ReqContext) response.Response {
+	query := models.GetTrashedDashboardsQuery{OrgId: c.OrgId}
+	if err := bus.DispatchCtx(c.Req.Context(), &query); err != nil {
+		return response.Error(500, "Failed to get deleted dashboards", err)
+	}
+	return response.JSON(200, query.Result)
+}
+
+// RestoreTrashedDashboard restores a dashboard or folder from the recycle bin
+func (hs *HTTPServer) RestoreTrashedDashboard(c *models.ReqContext) response.Response {
+	cmd := models.RestoreTrashedDashboardCommand{OrgId: c.OrgId, Id: c.ParamsInt64(":id")}
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
+		var dashboardErr models.DashboardErr
+		if errors.As(err, &dashboardErr) {
+			return response.Error(dashboardErr.StatusCode, dashboardErr.Error(), err)
+		}
+		if errors.Is(err, models.ErrFolderSameNameExists) {
+			return response.Error(409, err.Error(), err)
+		}
+		return response.Error(500, "Failed to restore dashboard", err)
+	}
+
+	dash := cmd.Result
+	return response.JSON(200, util.DynMap{
+		"id":      dash.Id,
+		"uid":     dash.Uid,
+		"title":   dash.Title,
+		"url":     dash.GetUrl(),
+		"message": fmt.Sprintf("%s restored", dash.Title),
+	})
+}
+
+// DeleteTrashedDashboard deletes a dashboard or folder from the recycle bin permanently
+func DeleteTrashedDashboard(c *models.ReqContext) response.Response {
+	cmd := models.DeleteTrashedDashboardCommand{OrgId: c.OrgId, Id: c.ParamsInt64(":id")}
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
+		if errors.Is(err, models.ErrTrashedDashboardNotFound) {
+			return response.Error(404, "Deleted dashboard not found", err)
+		}
+		return response.Error(500, "Failed to delete dashboard permanently", err)
+	}
+	return response.Success("Dashboard deleted permanently")
+}
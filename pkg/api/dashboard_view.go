@@ -0,0 +1,166 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// GetDashboardViewStats returns views, unique viewers and views per day of a dashboard
+func GetDashboardViewStats(c *models.ReqContext) response.Response {
+	dash, rsp := getDashboardHelper(c.Req.Context(), c.OrgId, 0, web.Params(c.Req)[":uid"])
+	if rsp != nil {
+		return rsp
+	}
+
+	guardian := guardian.New(c.Req.Context(), dash.Id, c.OrgId, c.SignedInUser)
+	if canView, err := guardian.CanView(); err != nil || !canView {
+		return dashboardGuardianResponse(err)
+	}
+
+	days := c.QueryInt("days")
+	if days <= 0 {
+		days = 30
+	}
+	if days > 366 {
+		return response.Error(400, "Days cannot be more than 366", nil)
+	}
+
+	query := models.GetDashboardViewStatsQuery{OrgId: c.OrgId, DashboardId: dash.Id, Days: days}
+	if err := bus.DispatchCtx(c.Req.Context(), &query); err != nil {
+		return response.Error(500, "Failed to get dashboard views", err)
+	}
+	return response.JSON(200, query.Result)
+}
+
+// GetStaleDashboards returns dashboards the user can view which haven't been viewed within a number of days
+func GetStaleDashboards(c *models.ReqContext) response.Response {
+	days := c.QueryInt("days")
+	if days <= 0 {
+		days = 90
+	}
+	limit := c.QueryInt("limit")
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > 5000 {
+		return response.Error(422, "Limit is above maximum allowed (5000), use page parameter to access hits beyond limit", nil)
+	}
+	page := c.QueryInt("page")
+	if page <= 0 {
+		page = 1
+	}
+
+	query := models.GetStaleDashboardsQuery{SignedInUser: c.SignedInUser, Days: days, Limit: limit, Page: page}
+	if err := bus.DispatchCtx(c.Req.Context(), &query); err != nil {
+		return response.Error(500, "Failed to get stale dashboards", err)
+	}
+	return response.JSON(200, query.Result)
+}
+
+// dashboardViewFlushInterval is how often counted dashboard views are recorded.
+const dashboardViewFlushInterval = 10 * time.Second
+
+// maxPendingDashboardViews is the number of dashboard, user and day
+// combinations with counted views which triggers recording them before the
+// flush interval.
+const maxPendingDashboardViews = 1000
+
+type dashboardViewKey struct {
+	orgID       int64
+	dashboardID int64
+	userID      int64
+	day         time.Time
+}
+
+// dashboardViewRecorder counts dashboard views in memory and records them in
+// batches, so viewing a dashboard doesn't wait for a database write.
+type dashboardViewRecorder struct {
+	log   log.Logger
+	flush chan struct{}
+
+	mu    sync.Mutex
+	views map[dashboardViewKey]*models.RecordDashboardViewCommand
+}
+
+func newDashboardViewRecorder() *dashboardViewRecorder {
+	return &dashboardViewRecorder{
+		log:   log.New("dashboard.views"),
+		flush: make(chan struct{}, 1),
+		views: map[dashboardViewKey]*models.RecordDashboardViewCommand{},
+	}
+}
+
+// add counts a view of the dashboard by the user.
+func (r *dashboardViewRecorder) add(orgID, dashboardID, userID int64, viewed time.Time) {
+	key := dashboardViewKey{orgID: orgID, dashboardID: dashboardID, userID: userID, day: viewed.UTC().Truncate(24 * time.Hour)}
+
+	r.mu.Lock()
+	cmd, ok := r.views[key]
+	if !ok {
+		cmd = &models.RecordDashboardViewCommand{OrgId: orgID, DashboardId: dashboardID, UserId: userID}
+		r.views[key] = cmd
+	}
+	cmd.Views++
+	cmd.Time = viewed
+	pending := len(r.views)
+	r.mu.Unlock()
+
+	if pending >= maxPendingDashboardViews {
+		select {
+		case r.flush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// record records the counted views.
+func (r *dashboardViewRecorder) record(ctx context.Context) {
+	r.mu.Lock()
+	views := r.views
+	r.views = map[dashboardViewKey]*models.RecordDashboardViewCommand{}
+	r.mu.Unlock()
+
+	for _, cmd := range views {
+		if err := bus.DispatchCtx(ctx, cmd); err != nil {
+			r.log.Warn("Failed to record dashboard views", "dashboardId", cmd.DashboardId, "error", err)
+		}
+	}
+}
+
+// run records the counted views periodically until the context is done, and
+// once more afterwards.
+func (r *dashboardViewRecorder) run(ctx context.Context) {
+	ticker := time.NewTicker(dashboardViewFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.record(ctx)
+		case <-r.flush:
+			r.record(ctx)
+		case <-ctx.Done():
+			r.record(context.Background())
+			return
+		}
+	}
+}
+
+// recordDashboardView counts the view of the dashboard, the views are recorded
+// in the background. Views of anonymous users and API keys aren't recorded as
+// they have no user.
+func (hs *HTTPServer) recordDashboardView(c *models.ReqContext, dash *models.Dashboard) {
+	if dash.IsFolder || c.UserId == 0 || hs.dashboardViews == nil || setting.DashboardViewRetention <= 0 {
+		return
+	}
+	hs.dashboardViews.add(c.OrgId, dash.Id, c.UserId, time.Now())
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDashboardViewRecorder(t *testing.T) {
+	t.Cleanup(bus.ClearBusHandlers)
+
+	var recorded []models.RecordDashboardViewCommand
+	bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.RecordDashboardViewCommand) error {
+		recorded = append(recorded, *cmd)
+		return nil
+	})
+
+	today := time.Date(2021, 10, 12, 10, 0, 0, 0, time.UTC)
+	yesterday := today.AddDate(0, 0, -1)
+
+	recorder := newDashboardViewRecorder()
+	recorder.add(1, 10, 1, yesterday)
+	recorder.add(1, 10, 1, today)
+	recorder.add(1, 10, 1, today.Add(time.Minute))
+	recorder.add(1, 10, 2, today)
+
+	recorder.record(context.Background())
+	require.ElementsMatch(t, []models.RecordDashboardViewCommand{
+		{OrgId: 1, DashboardId: 10, UserId: 1, Views: 1, Time: yesterday},
+		{OrgId: 1, DashboardId: 10, UserId: 1, Views: 2, Time: today.Add(time.Minute)},
+		{OrgId: 1, DashboardId: 10, UserId: 2, Views: 1, Time: today},
+	}, recorded)
+
+	t.Run("Should not record views twice", func(t *testing.T) {
+		recorded = nil
+		recorder.record(context.Background())
+		require.Empty(t, recorded)
+	})
+
+	t.Run("Should record views when the context is done", func(t *testing.T) {
+		recorded = nil
+		recorder.add(1, 10, 1, today)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		recorder.run(ctx)
+		require.Len(t, recorded, 1)
+	})
+}
+
+func TestRecordDashboardView(t *testing.T) {
+	origRetention := setting.DashboardViewRetention
+	t.Cleanup(func() { setting.DashboardViewRetention = origRetention })
+	setting.DashboardViewRetention = 30 * 24 * time.Hour
+
+	hs := &HTTPServer{dashboardViews: newDashboardViewRecorder()}
+	dash := &models.Dashboard{Id: 10, OrgId: 1}
+
+	t.Run("Should not count views without a user", func(t *testing.T) {
+		c := &models.ReqContext{SignedInUser: &models.SignedInUser{OrgId: 1, IsAnonymous: true}}
+		hs.recordDashboardView(c, dash)
+		require.Empty(t, hs.dashboardViews.views)
+	})
+
+	t.Run("Should count views of users", func(t *testing.T) {
+		c := &models.ReqContext{SignedInUser: &models.SignedInUser{OrgId: 1, UserId: 2}}
+		hs.recordDashboardView(c, dash)
+		require.Len(t, hs.dashboardViews.views, 1)
+	})
+}
@@ -62,3 +62,54 @@ type CalculateDiffTarget struct {
 type RestoreDashboardVersionCommand struct {
 	Version int `json:"version" binding:"Required"`
 }
+
+// CaptureDashboardSnapshotCommand creates a snapshot of a dashboard with the
+// results of its queries run server-side. Variable values are either a string
+// or a list of strings.
+type CaptureDashboardSnapshotCommand struct {
+	Name      string                 `json:"name"`
+	Expires   int64                  `json:"expires"`
+	From      string                 `json:"from"`
+	To        string                 `json:"to"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// BulkDashboardsCommand applies an operation to the dashboards with the given
+// UIDs, or to the dashboards matching the search query.
+type BulkDashboardsCommand struct {
+	Operation     string               `json:"operation" binding:"Required"`
+	DashboardUids []string             `json:"dashboardUids"`
+	Query         *BulkDashboardsQuery `json:"query"`
+	FolderUid     string               `json:"folderUid"`
+	Tags          []string             `json:"tags"`
+	Message       string               `json:"message"`
+}
+
+// BulkDashboardsQuery selects the dashboards of a bulk operation like the search API.
+type BulkDashboardsQuery struct {
+	Query     string   `json:"query"`
+	Tags      []string `json:"tags"`
+	FolderIds []int64  `json:"folderIds"`
+	Starred   bool     `json:"starred"`
+	Content   string   `json:"content"`
+}
+
+// BulkDashboardResult is the result of a bulk operation for a dashboard.
+type BulkDashboardResult struct {
+	Uid     string `json:"uid"`
+	Title   string `json:"title"`
+	Url     string `json:"url,omitempty"`
+	File    string `json:"file,omitempty"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// BulkDashboardsReport reports the result of a bulk operation for each dashboard.
+type BulkDashboardsReport struct {
+	Operation string                 `json:"operation"`
+	Total     int                    `json:"total"`
+	Succeeded int                    `json:"succeeded"`
+	Unchanged int                    `json:"unchanged"`
+	Failed    int                    `json:"failed"`
+	Results   []*BulkDashboardResult `json:"results"`
+}
@@ -16,10 +16,15 @@ type Folder struct {
 	UpdatedBy string    `json:"updatedBy"`
 	Updated   time.Time `json:"updated"`
 	Version   int       `json:"version"`
+	// ParentUid is the uid of the parent folder, empty for folders in the General folder.
+	ParentUid string `json:"parentUid,omitempty"`
+	// Parents are the parent folders, root first.
+	Parents []FolderSearchHit `json:"parents"`
 }
 
 type FolderSearchHit struct {
-	Id    int64  `json:"id"`
-	Uid   string `json:"uid"`
-	Title string `json:"title"`
+	Id        int64  `json:"id"`
+	Uid       string `json:"uid"`
+	Title     string `json:"title"`
+	ParentUid string `json:"parentUid,omitempty"`
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/apierrors"
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/guardian"
@@ -28,9 +29,10 @@ func (hs *HTTPServer) GetFolders(c *models.ReqContext) response.Response {
 
 	for _, f := range folders {
 		result = append(result, dtos.FolderSearchHit{
-			Id:    f.Id,
-			Uid:   f.Uid,
-			Title: f.Title,
+			Id:        f.Id,
+			Uid:       f.Uid,
+			Title:     f.Title,
+			ParentUid: f.ParentUid,
 		})
 	}
 
@@ -45,7 +47,7 @@ func (hs *HTTPServer) GetFolderByUID(c *models.ReqContext) response.Response {
 	}
 
 	g := guardian.New(c.Req.Context(), folder.Id, c.OrgId, c.SignedInUser)
-	return response.JSON(200, toFolderDto(c.Req.Context(), g, folder))
+	return response.JSON(200, toFolderDto(c.Req.Context(), c.OrgId, g, folder))
 }
 
 func (hs *HTTPServer) GetFolderByID(c *models.ReqContext) response.Response {
@@ -56,12 +58,12 @@ func (hs *HTTPServer) GetFolderByID(c *models.ReqContext) response.Response {
 	}
 
 	g := guardian.New(c.Req.Context(), folder.Id, c.OrgId, c.SignedInUser)
-	return response.JSON(200, toFolderDto(c.Req.Context(), g, folder))
+	return response.JSON(200, toFolderDto(c.Req.Context(), c.OrgId, g, folder))
 }
 
 func (hs *HTTPServer) CreateFolder(c *models.ReqContext, cmd models.CreateFolderCommand) response.Response {
 	s := dashboards.NewFolderService(c.OrgId, c.SignedInUser, hs.SQLStore)
-	folder, err := s.CreateFolder(c.Req.Context(), cmd.Title, cmd.Uid)
+	folder, err := s.CreateFolder(c.Req.Context(), cmd.Title, cmd.Uid, cmd.ParentUid)
 	if err != nil {
 		return apierrors.ToFolderErrorResponse(err)
 	}
@@ -74,7 +76,7 @@ func (hs *HTTPServer) CreateFolder(c *models.ReqContext, cmd models.CreateFolder
 	}
 
 	g := guardian.New(c.Req.Context(), folder.Id, c.OrgId, c.SignedInUser)
-	return response.JSON(200, toFolderDto(c.Req.Context(), g, folder))
+	return response.JSON(200, toFolderDto(c.Req.Context(), c.OrgId, g, folder))
 }
 
 func (hs *HTTPServer) UpdateFolder(c *models.ReqContext, cmd models.UpdateFolderCommand) response.Response {
@@ -85,19 +87,46 @@ func (hs *HTTPServer) UpdateFolder(c *models.ReqContext, cmd models.UpdateFolder
 	}
 
 	g := guardian.New(c.Req.Context(), cmd.Result.Id, c.OrgId, c.SignedInUser)
-	return response.JSON(200, toFolderDto(c.Req.Context(), g, cmd.Result))
+	return response.JSON(200, toFolderDto(c.Req.Context(), c.OrgId, g, cmd.Result))
+}
+
+func (hs *HTTPServer) MoveFolder(c *models.ReqContext, cmd models.MoveFolderCommand) response.Response {
+	s := dashboards.NewFolderService(c.OrgId, c.SignedInUser, hs.SQLStore)
+	err := s.MoveFolder(c.Req.Context(), web.Params(c.Req)[":uid"], &cmd)
+	if err != nil {
+		return apierrors.ToFolderErrorResponse(err)
+	}
+
+	g := guardian.New(c.Req.Context(), cmd.Result.Id, c.OrgId, c.SignedInUser)
+	return response.JSON(200, toFolderDto(c.Req.Context(), c.OrgId, g, cmd.Result))
 }
 
 func (hs *HTTPServer) DeleteFolder(c *models.ReqContext) response.Response { // temporarily adding this function to HTTPServer, will be removed from HTTPServer when librarypanels featuretoggle is removed
 	s := dashboards.NewFolderService(c.OrgId, c.SignedInUser, hs.SQLStore)
-	err := hs.LibraryElementService.DeleteLibraryElementsInFolder(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"])
+	folder, err := s.GetFolderByUID(c.Req.Context(), web.Params(c.Req)[":uid"])
 	if err != nil {
-		if errors.Is(err, libraryelements.ErrFolderHasConnectedLibraryElements) {
-			return response.Error(403, "Folder could not be deleted because it contains library elements in use", err)
-		}
 		return apierrors.ToFolderErrorResponse(err)
 	}
 
+	// Subfolders are deleted with the folder, so are their library elements.
+	descendants := models.GetFolderDescendantsQuery{OrgId: c.OrgId, FolderId: folder.Id}
+	if err := bus.DispatchCtx(c.Req.Context(), &descendants); err != nil {
+		return apierrors.ToFolderErrorResponse(err)
+	}
+	folderUIDs := []string{folder.Uid}
+	for _, subfolder := range descendants.Result {
+		folderUIDs = append(folderUIDs, subfolder.Uid)
+	}
+	for i := len(folderUIDs) - 1; i >= 0; i-- {
+		err := hs.LibraryElementService.DeleteLibraryElementsInFolder(c.Req.Context(), c.SignedInUser, folderUIDs[i])
+		if err != nil {
+			if errors.Is(err, libraryelements.ErrFolderHasConnectedLibraryElements) {
+				return response.Error(403, "Folder could not be deleted because it contains library elements in use", err)
+			}
+			return apierrors.ToFolderErrorResponse(err)
+		}
+	}
+
 	f, err := s.DeleteFolder(c.Req.Context(), web.Params(c.Req)[":uid"], c.QueryBool("forceDeleteRules"))
 	if err != nil {
 		return apierrors.ToFolderErrorResponse(err)
@@ -110,7 +139,7 @@ func (hs *HTTPServer) DeleteFolder(c *models.ReqContext) response.Response { //
 	})
 }
 
-func toFolderDto(ctx context.Context, g guardian.DashboardGuardian, folder *models.Folder) dtos.Folder {
+func toFolderDto(ctx context.Context, orgID int64, g guardian.DashboardGuardian, folder *models.Folder) dtos.Folder {
 	canEdit, _ := g.CanEdit()
 	canSave, _ := g.CanSave()
 	canAdmin, _ := g.CanAdmin()
@@ -124,6 +153,22 @@ func toFolderDto(ctx context.Context, g guardian.DashboardGuardian, folder *mode
 		updater = getUserLogin(ctx, folder.UpdatedBy)
 	}
 
+	// Finding parent folders of nested folders
+	parents := make([]dtos.FolderSearchHit, 0)
+	if folder.ParentId > 0 {
+		query := models.GetFolderPathQuery{OrgId: orgID, FolderId: folder.ParentId}
+		if err := bus.DispatchCtx(ctx, &query); err == nil {
+			for _, parent := range query.Result {
+				parents = append(parents, dtos.FolderSearchHit{
+					Id:        parent.Id,
+					Uid:       parent.Uid,
+					Title:     parent.Title,
+					ParentUid: parentUid(parents),
+				})
+			}
+		}
+	}
+
 	return dtos.Folder{
 		Id:        folder.Id,
 		Uid:       folder.Uid,
@@ -138,5 +183,15 @@ func toFolderDto(ctx context.Context, g guardian.DashboardGuardian, folder *mode
 		UpdatedBy: updater,
 		Updated:   folder.Updated,
 		Version:   folder.Version,
+		ParentUid: parentUid(parents),
+		Parents:   parents,
+	}
+}
+
+// parentUid returns the uid of the last folder of the path.
+func parentUid(path []dtos.FolderSearchHit) string {
+	if len(path) == 0 {
+		return ""
 	}
+	return path[len(path)-1].Uid
 }
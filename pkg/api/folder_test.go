@@ -130,6 +130,69 @@ func TestFoldersAPIEndpoint(t *testing.T) {
 				})
 		}
 	})
+
+	t.Run("Given a correct request for moving a folder", func(t *testing.T) {
+		cmd := models.MoveFolderCommand{
+			ParentUid: "parent",
+		}
+
+		mock := &fakeFolderService{
+			MoveFolderResult: &models.Folder{Id: 3, Uid: "uid", Title: "Folder", ParentId: 2},
+		}
+
+		moveFolderScenario(t, "When calling POST on", "/api/folders/uid/move", "/api/folders/:uid/move", mock, cmd,
+			func(sc *scenarioContext) {
+				bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetFolderPathQuery) error {
+					query.Result = []*models.Dashboard{
+						{Id: 1, Uid: "root", Title: "Root"},
+						{Id: 2, Uid: "parent", Title: "Parent", FolderId: 1},
+					}
+					return nil
+				})
+
+				callMoveFolder(sc)
+
+				folder := dtos.Folder{}
+				err := json.NewDecoder(sc.resp.Body).Decode(&folder)
+				require.NoError(t, err)
+				assert.Equal(t, int64(3), folder.Id)
+				assert.Equal(t, "parent", folder.ParentUid)
+				require.Len(t, folder.Parents, 2)
+				assert.Equal(t, "Root", folder.Parents[0].Title)
+				assert.Equal(t, "", folder.Parents[0].ParentUid)
+				assert.Equal(t, "Parent", folder.Parents[1].Title)
+				assert.Equal(t, "root", folder.Parents[1].ParentUid)
+			})
+	})
+
+	t.Run("Given incorrect requests for moving a folder", func(t *testing.T) {
+		testCases := []struct {
+			Error              error
+			ExpectedStatusCode int
+		}{
+			{Error: models.ErrDashboardFolderMaxDepthExceeded, ExpectedStatusCode: 400},
+			{Error: models.ErrDashboardFolderCircularParent, ExpectedStatusCode: 400},
+			{Error: models.ErrFolderSameNameExists, ExpectedStatusCode: 409},
+			{Error: models.ErrFolderAccessDenied, ExpectedStatusCode: 403},
+			{Error: models.ErrFolderNotFound, ExpectedStatusCode: 404},
+		}
+
+		cmd := models.MoveFolderCommand{
+			ParentUid: "parent",
+		}
+
+		for _, tc := range testCases {
+			mock := &fakeFolderService{
+				MoveFolderError: tc.Error,
+			}
+
+			moveFolderScenario(t, fmt.Sprintf("Expect '%s' error when calling POST on", tc.Error.Error()),
+				"/api/folders/uid/move", "/api/folders/:uid/move", mock, cmd, func(sc *scenarioContext) {
+					callMoveFolder(sc)
+					assert.Equalf(t, tc.ExpectedStatusCode, sc.resp.Code, "Wrong status code for %s", tc.Error)
+				})
+		}
+	})
 }
 
 func callCreateFolder(sc *scenarioContext) {
@@ -200,6 +263,39 @@ func updateFolderScenario(t *testing.T, desc string, url string, routePattern st
 	})
 }
 
+func callMoveFolder(sc *scenarioContext) {
+	sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+}
+
+func moveFolderScenario(t *testing.T, desc string, url string, routePattern string, mock *fakeFolderService,
+	cmd models.MoveFolderCommand, fn scenarioFunc) {
+	t.Run(fmt.Sprintf("%s %s", desc, url), func(t *testing.T) {
+		t.Cleanup(bus.ClearBusHandlers)
+
+		hs := HTTPServer{
+			Cfg: setting.NewCfg(),
+		}
+
+		sc := setupScenarioContext(t, url)
+		sc.defaultHandler = routing.Wrap(func(c *models.ReqContext) response.Response {
+			sc.context = c
+			sc.context.SignedInUser = &models.SignedInUser{OrgId: testOrgID, UserId: testUserID}
+
+			return hs.MoveFolder(c, cmd)
+		})
+
+		origNewFolderService := dashboards.NewFolderService
+		t.Cleanup(func() {
+			dashboards.NewFolderService = origNewFolderService
+		})
+		mockFolderService(mock)
+
+		sc.m.Post(routePattern, sc.defaultHandler)
+
+		fn(sc)
+	})
+}
+
 type fakeFolderService struct {
 	dashboards.FolderService
 
@@ -213,6 +309,8 @@ type fakeFolderService struct {
 	CreateFolderError    error
 	UpdateFolderResult   *models.Folder
 	UpdateFolderError    error
+	MoveFolderResult     *models.Folder
+	MoveFolderError      error
 	DeleteFolderResult   *models.Folder
 	DeleteFolderError    error
 	DeletedFolderUids    []string
@@ -230,7 +328,7 @@ func (s *fakeFolderService) GetFolderByUID(ctx context.Context, uid string) (*mo
 	return s.GetFolderByUIDResult, s.GetFolderByUIDError
 }
 
-func (s *fakeFolderService) CreateFolder(ctx context.Context, title, uid string) (*models.Folder, error) {
+func (s *fakeFolderService) CreateFolder(ctx context.Context, title, uid, parentUID string) (*models.Folder, error) {
 	return s.CreateFolderResult, s.CreateFolderError
 }
 
@@ -239,6 +337,11 @@ func (s *fakeFolderService) UpdateFolder(ctx context.Context, existingUID string
 	return s.UpdateFolderError
 }
 
+func (s *fakeFolderService) MoveFolder(ctx context.Context, uid string, cmd *models.MoveFolderCommand) error {
+	cmd.Result = s.MoveFolderResult
+	return s.MoveFolderError
+}
+
 func (s *fakeFolderService) DeleteFolder(ctx context.Context, uid string, forceDeleteRules bool) (*models.Folder, error) {
 	s.DeletedFolderUids = append(s.DeletedFolderUids, uid)
 	return s.DeleteFolderResult, s.DeleteFolderError
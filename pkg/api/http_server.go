@@ -35,6 +35,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/alerting"
 	"github.com/grafana/grafana/pkg/services/cleanup"
 	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/services/dashboarddeps"
 	"github.com/grafana/grafana/pkg/services/datasourceproxy"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/encryption"
@@ -101,6 +102,7 @@ type HTTPServer struct {
 	AlertNG                *ngalert.AlertNG
 	LibraryPanelService    librarypanels.Service
 	LibraryElementService  libraryelements.Service
+	DashboardDepsService   *dashboarddeps.Service
 	notificationService    *notifications.NotificationService
 	SocialService          social.Service
 	OAuthTokenService      oauthtoken.OAuthTokenService
@@ -111,6 +113,7 @@ type HTTPServer struct {
 	tracingService         *tracing.TracingService
 	internalMetricsSvc     *metrics.InternalMetricsService
 	searchUsersService     searchusers.Service
+	dashboardViews         *dashboardViewRecorder
 }
 
 type ServerOptions struct {
@@ -135,7 +138,7 @@ func ProvideHTTPServer(opts ServerOptions, cfg *setting.Cfg, routeRegister routi
 	internalMetricsSvc *metrics.InternalMetricsService, quotaService *quota.QuotaService,
 	socialService social.Service, oauthTokenService oauthtoken.OAuthTokenService,
 	encryptionService encryption.Service, searchUsersService searchusers.Service,
-	dataSourcesService *datasources.Service) (*HTTPServer, error) {
+	dataSourcesService *datasources.Service, dashboardDepsService *dashboarddeps.Service) (*HTTPServer, error) {
 	web.Env = cfg.Env
 	m := web.New()
 
@@ -172,6 +175,7 @@ func ProvideHTTPServer(opts ServerOptions, cfg *setting.Cfg, routeRegister routi
 		AlertNG:                alertNG,
 		LibraryPanelService:    libraryPanelService,
 		LibraryElementService:  libraryElementService,
+		DashboardDepsService:   dashboardDepsService,
 		QuotaService:           quotaService,
 		notificationService:    notificationService,
 		tracingService:         tracingService,
@@ -184,6 +188,7 @@ func ProvideHTTPServer(opts ServerOptions, cfg *setting.Cfg, routeRegister routi
 		EncryptionService:      encryptionService,
 		DataSourcesService:     dataSourcesService,
 		searchUsersService:     searchUsersService,
+		dashboardViews:         newDashboardViewRecorder(),
 	}
 	if hs.Listener != nil {
 		hs.log.Debug("Using provided listener")
@@ -205,6 +210,8 @@ func (hs *HTTPServer) Run(ctx context.Context) error {
 
 	hs.applyRoutes()
 
+	go hs.dashboardViews.run(ctx)
+
 	// Remove any square brackets enclosing IPv6 addresses, a format we support for backwards compatibility
 	host := strings.TrimSuffix(strings.TrimPrefix(hs.Cfg.HTTPAddr, "["), "]")
 	hs.httpSrv = &http.Server{
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 
+	"github.com/grafana/grafana/pkg/tsdb"
 	"github.com/grafana/grafana/pkg/tsdb/grafanads"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
@@ -23,10 +24,12 @@ func (hs *HTTPServer) QueryMetricsV2(c *models.ReqContext, reqDTO dtos.MetricReq
 
 	timeRange := plugins.NewDataTimeRange(reqDTO.From, reqDTO.To)
 	request := plugins.DataQuery{
-		TimeRange: &timeRange,
-		Debug:     reqDTO.Debug,
-		User:      c.SignedInUser,
-		Queries:   make([]plugins.DataSubQuery, 0, len(reqDTO.Queries)),
+		TimeRange:     &timeRange,
+		Debug:         reqDTO.Debug,
+		User:          c.SignedInUser,
+		Headers:       queryHeaders(c),
+		Queries:       make([]plugins.DataSubQuery, 0, len(reqDTO.Queries)),
+		UseQueryCache: true,
 	}
 
 	// Loop to see if we have an expression.
@@ -115,6 +118,7 @@ func (hs *HTTPServer) handleExpressions(c *models.ReqContext, reqDTO dtos.Metric
 		TimeRange: &timeRange,
 		Debug:     reqDTO.Debug,
 		User:      c.SignedInUser,
+		Headers:   queryHeaders(c),
 		Queries:   make([]plugins.DataSubQuery, 0, len(reqDTO.Queries)),
 	}
 
@@ -156,6 +160,15 @@ func (hs *HTTPServer) handleExpressions(c *models.ReqContext, reqDTO dtos.Metric
 	return toMacronResponse(qdr)
 }
 
+// queryHeaders returns request headers which are passed to data source queries.
+func queryHeaders(c *models.ReqContext) map[string]string {
+	headers := map[string]string{}
+	if skip := c.Req.Header.Get(tsdb.QueryCacheSkipHeader); skip != "" {
+		headers[tsdb.QueryCacheSkipHeader] = skip
+	}
+	return headers
+}
+
 func (hs *HTTPServer) handleGetDataSourceError(err error, datasourceID int64) *response.NormalResponse {
 	hs.log.Debug("Encountered error getting data source", "err", err, "id", datasourceID)
 	if errors.Is(err, models.ErrDataSourceAccessDenied) {
@@ -191,9 +204,11 @@ func (hs *HTTPServer) QueryMetrics(c *models.ReqContext, reqDto dtos.MetricReque
 
 	timeRange := plugins.NewDataTimeRange(reqDto.From, reqDto.To)
 	request := plugins.DataQuery{
-		TimeRange: &timeRange,
-		Debug:     reqDto.Debug,
-		User:      c.SignedInUser,
+		TimeRange:     &timeRange,
+		Debug:         reqDto.Debug,
+		User:          c.SignedInUser,
+		Headers:       queryHeaders(c),
+		UseQueryCache: true,
 	}
 
 	for _, query := range reqDto.Queries {
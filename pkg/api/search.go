@@ -1,6 +1,7 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/search"
+	"github.com/grafana/grafana/pkg/services/sqlstore/searchstore"
 )
 
 func Search(c *models.ReqContext) response.Response {
@@ -31,6 +33,13 @@ func Search(c *models.ReqContext) response.Response {
 		permission = models.PERMISSION_EDIT
 	}
 
+	contentKinds := c.QueryStrings("contentKind")
+	for _, kind := range contentKinds {
+		if !isContentKind(kind) {
+			return response.Error(400, fmt.Sprintf("Invalid content kind %q", kind), nil)
+		}
+	}
+
 	dbIDs := make([]int64, 0)
 	for _, id := range c.QueryStrings("dashboardIds") {
 		dashboardID, err := strconv.ParseInt(id, 10, 64)
@@ -60,6 +69,8 @@ func Search(c *models.ReqContext) response.Response {
 		FolderIds:    folderIDs,
 		Permission:   permission,
 		Sort:         sort,
+		Content:      c.Query("content"),
+		ContentKinds: contentKinds,
 	}
 
 	err := bus.DispatchCtx(c.Req.Context(), &searchQuery)
@@ -71,6 +82,15 @@ func Search(c *models.ReqContext) response.Response {
 	return response.JSON(200, searchQuery.Result)
 }
 
+func isContentKind(kind string) bool {
+	for _, k := range searchstore.ContentKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
 func (hs *HTTPServer) ListSortOptions(c *models.ReqContext) response.Response {
 	opts := hs.SearchService.SortOptions()
 
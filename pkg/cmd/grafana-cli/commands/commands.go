@@ -124,6 +124,11 @@ var adminCommands = []*cli.Command{
 			},
 		},
 	},
+	{
+		Name:   "rebuild-search-index",
+		Usage:  "Extracts panels, queries and variables of all dashboards again for dashboard content search",
+		Action: runDbCommand(rebuildSearchIndexCommand),
+	},
 	{
 		Name:  "data-migration",
 		Usage: "Runs a script that migrates or cleanups data in your db",
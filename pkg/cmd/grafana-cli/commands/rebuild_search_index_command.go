@@ -0,0 +1,22 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/fatih/color"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+func rebuildSearchIndexCommand(c utils.CommandLine, sqlStore *sqlstore.SQLStore) error {
+	count, err := sqlStore.RebuildDashboardSearchContent(context.Background())
+	if err != nil {
+		return errutil.Wrap("failed to rebuild dashboard search content", err)
+	}
+
+	logger.Infof("\n")
+	logger.Infof("Dashboard search content rebuilt for %d dashboards %s\n", count, color.GreenString("✔"))
+	return nil
+}
@@ -0,0 +1,236 @@
+package dashmerge
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// keyedLists are the lists of the dashboard which are merged item by item,
+// mapped to the field identifying their items. Lists are identified by their
+// path, with [] for the items of a list.
+var keyedLists = map[string]string{
+	"panels":           "id",
+	"panels[].panels":  "id",
+	"templating.list":  "name",
+	"annotations.list": "name",
+}
+
+// ignoredFields are updated on every save, the latest value is kept.
+var ignoredFields = map[string]bool{
+	"version":   true,
+	"iteration": true,
+}
+
+// absentValue marks a field or list item which doesn't exist in a version.
+type absentValue struct{}
+
+var absent interface{} = absentValue{}
+
+// Merge merges the changes between the base version and the incoming dashboard
+// into the latest version. Panels, variables and annotations are merged by
+// their id or name, other values of objects by their keys. Values changed
+// differently in both the latest version and the incoming dashboard are
+// conflicts, in which case no merged dashboard is returned, only the JSON
+// paths of the conflicts.
+func Merge(base, latest, incoming *simplejson.Json) (*simplejson.Json, []string, error) {
+	values := make([]interface{}, 0, 3)
+	for _, j := range []*simplejson.Json{base, latest, incoming} {
+		v, err := decode(j)
+		if err != nil {
+			return nil, nil, err
+		}
+		values = append(values, v)
+	}
+
+	m := &merger{}
+	merged := m.merge("", "", values[0], values[1], values[2])
+	if len(m.conflicts) > 0 {
+		return nil, m.conflicts, nil
+	}
+
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return nil, nil, err
+	}
+	result, err := simplejson.NewJson(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, nil, nil
+}
+
+// decode decodes the JSON to plain maps, lists and values, so versions can be
+// compared regardless of how they were built.
+func decode(j *simplejson.Json) (interface{}, error) {
+	data, err := j.Encode()
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+type merger struct {
+	conflicts []string
+}
+
+// merge merges the value at the path. The schema is the path without the keys
+// of list items, used to look up how lists are merged.
+func (m *merger) merge(path, schema string, base, latest, incoming interface{}) interface{} {
+	switch {
+	case reflect.DeepEqual(latest, incoming):
+		return latest
+	case reflect.DeepEqual(base, latest):
+		return incoming
+	case reflect.DeepEqual(base, incoming):
+		return latest
+	case ignoredFields[schema]:
+		return latest
+	}
+
+	// Changed in both versions, merged at a lower level if possible.
+	if key, ok := keyedLists[schema]; ok {
+		if merged, ok := m.mergeList(path, schema, key, base, latest, incoming); ok {
+			return merged
+		}
+	}
+
+	latestObject, latestOk := latest.(map[string]interface{})
+	incomingObject, incomingOk := incoming.(map[string]interface{})
+	baseObject, baseOk := base.(map[string]interface{})
+	if base == absent {
+		baseObject, baseOk = map[string]interface{}{}, true
+	}
+	if latestOk && incomingOk && baseOk {
+		return m.mergeObject(path, schema, baseObject, latestObject, incomingObject)
+	}
+
+	m.conflicts = append(m.conflicts, path)
+	return latest
+}
+
+func (m *merger) mergeObject(path, schema string, base, latest, incoming map[string]interface{}) interface{} {
+	seen := map[string]bool{}
+	keys := make([]string, 0, len(latest))
+	for _, object := range []map[string]interface{}{base, latest, incoming} {
+		for key := range object {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	// Sorted so conflicts are reported in the same order.
+	sort.Strings(keys)
+
+	merged := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		value := m.merge(join(path, key), join(schema, key), get(base, key), get(latest, key), get(incoming, key))
+		if value != absent {
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+// mergeList merges the items of the lists by the key field. Items keep the
+// order of the incoming list, items only added in the latest version are
+// appended. It returns false if the lists can't be merged by the key.
+func (m *merger) mergeList(path, schema, key string, base, latest, incoming interface{}) (interface{}, bool) {
+	baseItems, ok := indexList(base, key)
+	if !ok {
+		return nil, false
+	}
+	latestItems, ok := indexList(latest, key)
+	if !ok {
+		return nil, false
+	}
+	incomingItems, ok := indexList(incoming, key)
+	if !ok {
+		return nil, false
+	}
+
+	order := make([]string, 0, len(incomingItems.order)+len(latestItems.order))
+	order = append(order, incomingItems.order...)
+	for _, k := range latestItems.order {
+		if _, ok := incomingItems.items[k]; !ok {
+			order = append(order, k)
+		}
+	}
+
+	merged := make([]interface{}, 0, len(order))
+	for _, k := range order {
+		itemPath := fmt.Sprintf("%s[%s=%s]", path, key, k)
+		item := m.merge(itemPath, schema+"[]", baseItems.get(k), latestItems.get(k), incomingItems.get(k))
+		if item != absent {
+			merged = append(merged, item)
+		}
+	}
+	return merged, true
+}
+
+type indexedList struct {
+	order []string
+	items map[string]interface{}
+}
+
+func (l indexedList) get(key string) interface{} {
+	if item, ok := l.items[key]; ok {
+		return item
+	}
+	return absent
+}
+
+// indexList indexes the items of the list by the key field. It returns false
+// if the value isn't a list, or an item doesn't have a unique key.
+func indexList(value interface{}, key string) (indexedList, bool) {
+	l := indexedList{items: map[string]interface{}{}}
+	if value == absent {
+		return l, true
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		return l, false
+	}
+	for _, item := range items {
+		object, ok := item.(map[string]interface{})
+		if !ok {
+			return l, false
+		}
+		id, ok := object[key]
+		if !ok || id == nil {
+			return l, false
+		}
+		k := fmt.Sprint(id)
+		if _, exists := l.items[k]; exists {
+			return l, false
+		}
+		l.order = append(l.order, k)
+		l.items[k] = item
+	}
+	return l, true
+}
+
+func get(object map[string]interface{}, key string) interface{} {
+	if value, ok := object[key]; ok {
+		return value
+	}
+	return absent
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,118 @@
+package dashmerge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestMerge(t *testing.T) {
+	const baseJSON = `{
+		"title": "Dashboard",
+		"version": 3,
+		"refresh": "1m",
+		"panels": [
+			{"id": 1, "title": "CPU", "gridPos": {"x": 0, "y": 0, "w": 12, "h": 8}},
+			{"id": 2, "title": "Memory", "gridPos": {"x": 12, "y": 0, "w": 12, "h": 8}},
+			{"id": 3, "type": "row", "collapsed": true, "panels": [
+				{"id": 4, "title": "Disk"}
+			]}
+		],
+		"templating": {"list": [
+			{"name": "host", "query": "hosts"},
+			{"name": "env", "query": "envs"}
+		]},
+		"annotations": {"list": [
+			{"name": "Annotations & Alerts", "enable": true}
+		]}
+	}`
+
+	parse := func(t *testing.T, s string) *simplejson.Json {
+		t.Helper()
+		j, err := simplejson.NewJson([]byte(s))
+		require.NoError(t, err)
+		return j
+	}
+
+	t.Run("Should merge changes to different panels, variables and annotations", func(t *testing.T) {
+		base := parse(t, baseJSON)
+
+		latest := parse(t, baseJSON)
+		latest.Set("version", 4)
+		latest.Get("panels").GetIndex(0).Set("title", "CPU usage")
+		latest.Get("panels").GetIndex(2).Get("panels").GetIndex(0).Set("title", "Disk usage")
+		latest.Get("templating").Set("list", append(latest.Get("templating").Get("list").MustArray(),
+			map[string]interface{}{"name": "region", "query": "regions"}))
+		latest.Get("annotations").Set("list", []interface{}{
+			map[string]interface{}{"name": "Annotations & Alerts", "enable": true},
+			map[string]interface{}{"name": "Deploys", "enable": true},
+		})
+
+		incoming := parse(t, baseJSON)
+		incoming.Get("panels").GetIndex(1).Set("title", "Memory usage")
+		incoming.Set("panels", append(incoming.Get("panels").MustArray(),
+			map[string]interface{}{"id": 5, "title": "Network"}))
+		incoming.Get("templating").Set("list", []interface{}{
+			map[string]interface{}{"name": "host", "query": "hosts"},
+		})
+		incoming.Set("refresh", "5m")
+
+		merged, conflicts, err := Merge(base, latest, incoming)
+		require.NoError(t, err)
+		require.Empty(t, conflicts)
+
+		require.Equal(t, "5m", merged.Get("refresh").MustString())
+		require.Equal(t, int64(4), merged.Get("version").MustInt64())
+
+		panels := merged.Get("panels")
+		require.Len(t, panels.MustArray(), 4)
+		require.Equal(t, "CPU usage", panels.GetIndex(0).Get("title").MustString())
+		require.Equal(t, "Memory usage", panels.GetIndex(1).Get("title").MustString())
+		require.Equal(t, "Disk usage", panels.GetIndex(2).Get("panels").GetIndex(0).Get("title").MustString())
+		require.Equal(t, "Network", panels.GetIndex(3).Get("title").MustString())
+		require.Equal(t, int64(12), panels.GetIndex(1).Get("gridPos").Get("x").MustInt64())
+
+		variables := merged.Get("templating").Get("list")
+		require.Len(t, variables.MustArray(), 2)
+		require.Equal(t, "host", variables.GetIndex(0).Get("name").MustString())
+		require.Equal(t, "region", variables.GetIndex(1).Get("name").MustString())
+		require.Len(t, merged.Get("annotations").Get("list").MustArray(), 2)
+	})
+
+	t.Run("Should return conflicting changes", func(t *testing.T) {
+		base := parse(t, baseJSON)
+
+		latest := parse(t, baseJSON)
+		latest.Set("version", 4)
+		latest.Get("panels").GetIndex(0).Set("title", "CPU usage")
+		latest.Get("panels").GetIndex(1).Get("gridPos").Set("y", 8)
+		latest.Get("templating").Get("list").GetIndex(0).Set("query", "servers")
+
+		incoming := parse(t, baseJSON)
+		incoming.Get("panels").GetIndex(0).Set("title", "CPU load")
+		incoming.Get("panels").GetIndex(1).Get("gridPos").Set("x", 0)
+		incoming.Get("templating").Set("list", []interface{}{
+			map[string]interface{}{"name": "env", "query": "envs"},
+		})
+
+		merged, conflicts, err := Merge(base, latest, incoming)
+		require.NoError(t, err)
+		require.Nil(t, merged)
+		require.Equal(t, []string{
+			"panels[id=1].title",
+			"templating.list[name=host]",
+		}, conflicts)
+	})
+
+	t.Run("Should merge lists without keys as a whole", func(t *testing.T) {
+		base := parse(t, `{"tags": ["a"], "panels": [{"title": "no id"}]}`)
+		latest := parse(t, `{"tags": ["a", "b"], "panels": [{"title": "no id, latest"}]}`)
+		incoming := parse(t, `{"tags": ["a", "c"], "panels": [{"title": "no id"}]}`)
+
+		_, conflicts, err := Merge(base, latest, incoming)
+		require.NoError(t, err)
+		require.Equal(t, []string{"tags"}, conflicts)
+	})
+}
@@ -36,6 +36,7 @@ func init() {
 func (s *Service) WrapTransformData(ctx context.Context, query plugins.DataQuery) (*backend.QueryDataResponse, error) {
 	req := Request{
 		OrgId:   query.User.OrgId,
+		Headers: query.Headers,
 		Queries: []Query{},
 	}
 
@@ -0,0 +1,47 @@
+package fs
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+)
+
+// ErrPathNotAllowed is returned by ResolveAllowedPath if a path isn't located
+// inside of any of the allowed directories.
+var ErrPathNotAllowed = errors.New("path is outside of allowed directories")
+
+// ResolveAllowedPath returns absolute path to a file making sure it's located
+// inside one of allowed directories. Relative paths are resolved against each
+// allowed directory. Symbolic links are followed before the check.
+func ResolveAllowedPath(allowedDirs []string, path string) (string, error) {
+	for _, allowed := range allowedDirs {
+		allowedDir, err := filepath.Abs(allowed)
+		if err != nil {
+			continue
+		}
+		allowedDir, err = filepath.EvalSymlinks(allowedDir)
+		if err != nil {
+			continue
+		}
+
+		candidate := path
+		if !filepath.IsAbs(candidate) {
+			candidate = filepath.Join(allowedDir, candidate)
+		}
+		candidate, err = filepath.EvalSymlinks(candidate)
+		if err != nil {
+			continue
+		}
+
+		rel, err := filepath.Rel(allowedDir, candidate)
+		if err != nil {
+			continue
+		}
+		if rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		return candidate, nil
+	}
+
+	return "", ErrPathNotAllowed
+}
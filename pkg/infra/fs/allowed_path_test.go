@@ -0,0 +1,56 @@
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveAllowedPath(t *testing.T) {
+	allowedDir := t.TempDir()
+	otherDir := t.TempDir()
+	filePath := filepath.Join(allowedDir, "metrics.db")
+	require.NoError(t, os.WriteFile(filePath, nil, 0600))
+	otherPath := filepath.Join(otherDir, "other.db")
+	require.NoError(t, os.WriteFile(otherPath, nil, 0600))
+	allowedDir, err := filepath.EvalSymlinks(allowedDir)
+	require.NoError(t, err)
+
+	t.Run("no allowed directories", func(t *testing.T) {
+		_, err := ResolveAllowedPath(nil, filePath)
+		require.ErrorIs(t, err, ErrPathNotAllowed)
+	})
+
+	t.Run("relative path", func(t *testing.T) {
+		path, err := ResolveAllowedPath([]string{otherDir, allowedDir}, "metrics.db")
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(allowedDir, "metrics.db"), path)
+	})
+
+	t.Run("absolute path", func(t *testing.T) {
+		path, err := ResolveAllowedPath([]string{allowedDir}, filePath)
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(allowedDir, "metrics.db"), path)
+	})
+
+	t.Run("allowed directory itself", func(t *testing.T) {
+		_, err := ResolveAllowedPath([]string{allowedDir}, "")
+		require.ErrorIs(t, err, ErrPathNotAllowed)
+	})
+
+	t.Run("path outside allowed directory", func(t *testing.T) {
+		_, err := ResolveAllowedPath([]string{allowedDir}, otherPath)
+		require.ErrorIs(t, err, ErrPathNotAllowed)
+		_, err = ResolveAllowedPath([]string{allowedDir}, filepath.Join("..", filepath.Base(otherDir), "other.db"))
+		require.ErrorIs(t, err, ErrPathNotAllowed)
+	})
+
+	t.Run("symlink pointing outside allowed directory", func(t *testing.T) {
+		link := filepath.Join(allowedDir, "link.db")
+		require.NoError(t, os.Symlink(otherPath, link))
+		_, err := ResolveAllowedPath([]string{allowedDir}, "link.db")
+		require.ErrorIs(t, err, ErrPathNotAllowed)
+	})
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"time"
+)
+
+// Typed errors
+var (
+	ErrTrashedDashboardNotFound = DashboardErr{
+		Reason:     "Deleted dashboard not found",
+		StatusCode: 404,
+		Status:     "not-found",
+	}
+	ErrTrashedDashboardFolderDeleted = DashboardErr{
+		Reason:     "The folder of the dashboard is deleted, restore the folder first",
+		StatusCode: 412,
+		Status:     "folder-deleted",
+	}
+)
+
+// TrashedDashboard is a deleted dashboard or folder kept in the recycle bin.
+// Data holds the dashboard and, for folders, dashboards in the folder together
+// with their permissions, versions and library panel connections.
+type TrashedDashboard struct {
+	Id           int64
+	OrgId        int64
+	DashboardId  int64
+	DashboardUid string
+	Title        string
+	IsFolder     bool
+	FolderId     int64
+	DeletedBy    int64
+	Deleted      time.Time
+	Data         string
+}
+
+// TrashedDashboardContent is the content of a recycle bin entry.
+type TrashedDashboardContent struct {
+	Dashboards                []*Dashboard                       `json:"dashboards"`
+	Acl                       []*DashboardAcl                    `json:"acl"`
+	Versions                  []*DashboardVersion                `json:"versions"`
+	LibraryElementConnections []*TrashedLibraryElementConnection `json:"libraryElementConnections"`
+}
+
+// TrashedLibraryElementConnection is a connection of a library element to a
+// deleted dashboard.
+type TrashedLibraryElementConnection struct {
+	ElementId    int64     `json:"elementId"`
+	Kind         int64     `json:"kind"`
+	ConnectionId int64     `json:"connectionId"`
+	Created      time.Time `json:"created"`
+	CreatedBy    int64     `json:"createdBy"`
+}
+
+type TrashedDashboardDTO struct {
+	Id           int64     `json:"id"`
+	DashboardId  int64     `json:"dashboardId"`
+	DashboardUid string    `json:"dashboardUid"`
+	Title        string    `json:"title"`
+	IsFolder     bool      `json:"isFolder"`
+	FolderId     int64     `json:"folderId"`
+	DeletedById  int64     `json:"deletedById"`
+	DeletedBy    string    `json:"deletedBy"`
+	Deleted      time.Time `json:"deleted"`
+}
+
+//
+// COMMANDS
+//
+
+// RestoreTrashedDashboardCommand restores a dashboard or folder from the recycle bin
+type RestoreTrashedDashboardCommand struct {
+	Id    int64
+	OrgId int64
+
+	Result *Dashboard
+}
+
+// DeleteTrashedDashboardCommand permanently deletes a dashboard or folder from the recycle bin
+type DeleteTrashedDashboardCommand struct {
+	Id    int64
+	OrgId int64
+}
+
+// DeleteExpiredTrashCommand permanently deletes recycle bin entries older than the retention
+type DeleteExpiredTrashCommand struct {
+	DeletedRows int64
+}
+
+//
+// QUERIES
+//
+
+type GetTrashedDashboardsQuery struct {
+	OrgId int64
+
+	Result []*TrashedDashboardDTO
+}
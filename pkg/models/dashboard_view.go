@@ -0,0 +1,92 @@
+package models
+
+import (
+	"time"
+)
+
+// DashboardView counts the views of a dashboard by a user on a day. Anonymous
+// views are counted with user id 0. Day and LastViewed are unix timestamps in
+// seconds, Day of the start of the day in UTC.
+type DashboardView struct {
+	Id          int64
+	OrgId       int64
+	DashboardId int64
+	UserId      int64
+	Day         int64
+	Views       int64
+	LastViewed  int64
+}
+
+// DashboardViewStats are the views of a dashboard within a number of days.
+type DashboardViewStats struct {
+	DashboardId   int64                  `json:"dashboardId"`
+	Days          int                    `json:"days"`
+	Views         int64                  `json:"views"`
+	UniqueViewers int64                  `json:"uniqueViewers"`
+	LastViewed    *time.Time             `json:"lastViewed"`
+	Daily         []*DashboardDailyViews `json:"daily"`
+}
+
+// DashboardDailyViews are the views of a dashboard on a day in UTC.
+type DashboardDailyViews struct {
+	Date          string `json:"date"`
+	Views         int64  `json:"views"`
+	UniqueViewers int64  `json:"uniqueViewers"`
+}
+
+// StaleDashboardDTO is a dashboard not viewed within a number of days.
+// LastViewed is nil if there are no recorded views of the dashboard.
+type StaleDashboardDTO struct {
+	Id          int64      `json:"id"`
+	Uid         string     `json:"uid"`
+	Title       string     `json:"title"`
+	Url         string     `json:"url"`
+	FolderId    int64      `json:"folderId"`
+	FolderUid   string     `json:"folderUid,omitempty"`
+	FolderTitle string     `json:"folderTitle,omitempty"`
+	Created     time.Time  `json:"created"`
+	Updated     time.Time  `json:"updated"`
+	LastViewed  *time.Time `json:"lastViewed"`
+}
+
+//
+// COMMANDS
+//
+
+// RecordDashboardViewCommand records views of a dashboard by a user on the
+// day of Time. Views defaults to a single view.
+type RecordDashboardViewCommand struct {
+	OrgId       int64
+	DashboardId int64
+	UserId      int64
+	Views       int64
+	Time        time.Time
+}
+
+// DeleteExpiredDashboardViewsCommand deletes recorded views older than the retention
+type DeleteExpiredDashboardViewsCommand struct {
+	DeletedRows int64
+}
+
+//
+// QUERIES
+//
+
+type GetDashboardViewStatsQuery struct {
+	OrgId       int64
+	DashboardId int64
+	Days        int
+
+	Result *DashboardViewStats
+}
+
+// GetStaleDashboardsQuery returns dashboards the user can view which haven't
+// been viewed within a number of days, least recently viewed first.
+type GetStaleDashboardsQuery struct {
+	SignedInUser *SignedInUser
+	Days         int
+	Limit        int
+	Page         int
+
+	Result []*StaleDashboardDTO
+}
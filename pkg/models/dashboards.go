@@ -47,8 +47,12 @@ var (
 		Reason:     "Dashboard title cannot be empty",
 		StatusCode: 400,
 	}
-	ErrDashboardFolderCannotHaveParent = DashboardErr{
-		Reason:     "A Dashboard Folder cannot be added to another folder",
+	ErrDashboardFolderMaxDepthExceeded = DashboardErr{
+		Reason:     fmt.Sprintf("Folders cannot be nested more than %d levels deep", MaxFolderDepth),
+		StatusCode: 400,
+	}
+	ErrDashboardFolderCircularParent = DashboardErr{
+		Reason:     "A folder cannot be moved into itself or one of its subfolders",
 		StatusCode: 400,
 	}
 	ErrDashboardsWithSameSlugExists = DashboardErr{
@@ -134,6 +138,26 @@ func (e DashboardErr) Body() util.DynMap {
 	return util.DynMap{"status": e.Status, "message": e.Error()}
 }
 
+// DashboardMergeConflictError is returned when changes to an older version of a
+// dashboard can't be merged with the changes saved by someone else since.
+type DashboardMergeConflictError struct {
+	// Conflicts are the JSON paths changed in both.
+	Conflicts []string
+}
+
+func (e DashboardMergeConflictError) Error() string {
+	return fmt.Sprintf("%s, conflicting changes: %s", ErrDashboardVersionMismatch.Reason, strings.Join(e.Conflicts, ", "))
+}
+
+// Body returns the response body, listing the conflicts.
+func (e DashboardMergeConflictError) Body() util.DynMap {
+	return util.DynMap{
+		"status":    ErrDashboardVersionMismatch.Status,
+		"message":   ErrDashboardVersionMismatch.Reason,
+		"conflicts": e.Conflicts,
+	}
+}
+
 type UpdatePluginDashboardError struct {
 	PluginId string
 }
@@ -366,12 +390,37 @@ type DeleteDashboardCommand struct {
 	Id                     int64
 	OrgId                  int64
 	ForceDeleteFolderRules bool
+
+	// Trash moves the dashboard to the recycle bin instead of deleting it permanently
+	Trash     bool
+	DeletedBy int64
 }
 
 type DeleteOrphanedProvisionedDashboardsCommand struct {
 	ReaderNames []string
 }
 
+// SaveDashboardsCommand saves several dashboards in a single transaction.
+// Either all dashboards are saved or none.
+type SaveDashboardsCommand struct {
+	Commands []*SaveDashboardCommand
+}
+
+// DeleteDashboardsCommand deletes several dashboards in a single transaction.
+// Either all dashboards are deleted or none.
+type DeleteDashboardsCommand struct {
+	Commands []*DeleteDashboardCommand
+}
+
+// UpdateDashboardProvisioningCommand updates the checksum and time of the
+// provisioning data of a dashboard, without saving the dashboard.
+type UpdateDashboardProvisioningCommand struct {
+	DashboardId int64
+	Name        string
+	CheckSum    string
+	Updated     int64
+}
+
 //
 // QUERIES
 //
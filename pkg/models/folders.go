@@ -6,6 +6,10 @@ import (
 	"time"
 )
 
+// MaxFolderDepth is the maximum number of levels of nested folders. Folders in
+// the General folder are on the first level.
+const MaxFolderDepth = 4
+
 // Typed errors
 var (
 	ErrFolderNotFound                = errors.New("folder not found")
@@ -31,6 +35,12 @@ type Folder struct {
 	UpdatedBy int64
 	CreatedBy int64
 	HasAcl    bool
+
+	// ParentId is the id of the parent folder, 0 for folders in the General folder
+	ParentId  int64
+	ParentUid string
+	// ParentTitles are titles of the parent folders, root first
+	ParentTitles []string
 }
 
 // UpdateDashboardModel updates an existing model from command into model for update
@@ -59,8 +69,9 @@ func (cmd *UpdateFolderCommand) UpdateDashboardModel(dashFolder *Dashboard, orgI
 //
 
 type CreateFolderCommand struct {
-	Uid   string `json:"uid"`
-	Title string `json:"title"`
+	Uid       string `json:"uid"`
+	Title     string `json:"title"`
+	ParentUid string `json:"parentUid"`
 
 	Result *Folder
 }
@@ -74,10 +85,33 @@ type UpdateFolderCommand struct {
 	Result *Folder
 }
 
+// MoveFolderCommand moves a folder with its subfolders and dashboards to
+// another parent folder. An empty ParentUid moves the folder to the General folder.
+type MoveFolderCommand struct {
+	ParentUid string `json:"parentUid"`
+
+	Result *Folder
+}
+
 //
 // QUERIES
 //
 
+// GetFolderPathQuery returns the folder and its parent folders, root first.
+type GetFolderPathQuery struct {
+	OrgId    int64
+	FolderId int64
+	Result   []*Dashboard
+}
+
+// GetFolderDescendantsQuery returns subfolders of the folder at any depth,
+// parents before their subfolders.
+type GetFolderDescendantsQuery struct {
+	OrgId    int64
+	FolderId int64
+	Result   []*Dashboard
+}
+
 type HasEditPermissionInFoldersQuery struct {
 	SignedInUser *SignedInUser
 	Result       bool
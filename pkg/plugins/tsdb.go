@@ -33,6 +33,10 @@ type DataQuery struct {
 	Headers   map[string]string
 	Debug     bool
 	User      *models.SignedInUser
+	// UseQueryCache allows the response to be served from the query cache of
+	// the data source. It's only set for queries of the query API, so alerting
+	// always queries the data source.
+	UseQueryCache bool
 }
 
 type DataTimeRange struct {
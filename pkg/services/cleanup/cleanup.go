@@ -15,25 +15,28 @@ import (
 	"github.com/grafana/grafana/pkg/infra/serverlock"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/libraryelements"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
 func ProvideService(cfg *setting.Cfg, serverLockService *serverlock.ServerLockService,
-	shortURLService shorturls.Service) *CleanUpService {
+	shortURLService shorturls.Service, libraryElementService libraryelements.Service) *CleanUpService {
 	s := &CleanUpService{
-		Cfg:               cfg,
-		ServerLockService: serverLockService,
-		ShortURLService:   shortURLService,
-		log:               log.New("cleanup"),
+		Cfg:                   cfg,
+		ServerLockService:     serverLockService,
+		ShortURLService:       shortURLService,
+		LibraryElementService: libraryElementService,
+		log:                   log.New("cleanup"),
 	}
 	return s
 }
 
 type CleanUpService struct {
-	log               log.Logger
-	Cfg               *setting.Cfg
-	ServerLockService *serverlock.ServerLockService
-	ShortURLService   shorturls.Service
+	log                   log.Logger
+	Cfg                   *setting.Cfg
+	ServerLockService     *serverlock.ServerLockService
+	ShortURLService       shorturls.Service
+	LibraryElementService libraryelements.Service
 }
 
 func (srv *CleanUpService) Run(ctx context.Context) error {
@@ -49,6 +52,9 @@ func (srv *CleanUpService) Run(ctx context.Context) error {
 			srv.cleanUpTmpFiles()
 			srv.deleteExpiredSnapshots()
 			srv.deleteExpiredDashboardVersions()
+			srv.deleteExpiredLibraryElementVersions(ctxWithTimeout)
+			srv.deleteExpiredTrash()
+			srv.deleteExpiredDashboardViews()
 			srv.cleanUpOldAnnotations(ctxWithTimeout)
 			srv.expireOldUserInvites()
 			srv.deleteStaleShortURLs()
@@ -143,6 +149,33 @@ func (srv *CleanUpService) deleteExpiredDashboardVersions() {
 	}
 }
 
+func (srv *CleanUpService) deleteExpiredLibraryElementVersions(ctx context.Context) {
+	deleted, err := srv.LibraryElementService.DeleteExpiredVersions(ctx)
+	if err != nil {
+		srv.log.Error("Failed to delete expired library element versions", "error", err.Error())
+	} else {
+		srv.log.Debug("Deleted old/expired library element versions", "rows affected", deleted)
+	}
+}
+
+func (srv *CleanUpService) deleteExpiredTrash() {
+	cmd := models.DeleteExpiredTrashCommand{}
+	if err := bus.Dispatch(&cmd); err != nil {
+		srv.log.Error("Failed to delete expired dashboards from recycle bin", "error", err.Error())
+	} else {
+		srv.log.Debug("Deleted expired dashboards from recycle bin", "rows affected", cmd.DeletedRows)
+	}
+}
+
+func (srv *CleanUpService) deleteExpiredDashboardViews() {
+	cmd := models.DeleteExpiredDashboardViewsCommand{}
+	if err := bus.Dispatch(&cmd); err != nil {
+		srv.log.Error("Failed to delete expired dashboard views", "error", err.Error())
+	} else {
+		srv.log.Debug("Deleted expired dashboard views", "rows affected", cmd.DeletedRows)
+	}
+}
+
 func (srv *CleanUpService) deleteOldLoginAttempts() {
 	if srv.Cfg.DisableBruteForceLoginProtection {
 		return
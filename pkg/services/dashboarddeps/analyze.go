@@ -0,0 +1,185 @@
+package dashboarddeps
+
+import (
+	"regexp"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/tsdb/grafanads"
+)
+
+const (
+	mixedDatasourceName     = "-- Mixed --"
+	dashboardDatasourceName = "-- Dashboard --"
+	defaultDatasourceName   = "default"
+)
+
+// variableRegex matches a reference to a variable, e.g. $ds or ${ds}
+var variableRegex = regexp.MustCompile(`^\$\{?(\w+)\}?$`)
+
+// references are the data sources, panel plugins and library panels
+// referenced by the JSON of a dashboard.
+type references struct {
+	datasources   map[string]*datasourceRef
+	panels        map[string][]int64
+	libraryPanels map[string]*libraryPanelRef
+
+	// values of the data source variables of the dashboard
+	datasourceVariables map[string]string
+}
+
+// datasourceRef is a reference to a data source by uid or name. Both are
+// empty for the default data source.
+type datasourceRef struct {
+	uid         string
+	name        string
+	panelIDs    []int64
+	variables   []string
+	annotations []string
+}
+
+type libraryPanelRef struct {
+	name     string
+	panelIDs []int64
+}
+
+// analyze returns the references of the dashboard. Placeholders of library
+// panels are analyzed with the model of the library panel, if it exists.
+func analyze(dash *simplejson.Json, libraryPanels map[string]*simplejson.Json) *references {
+	refs := &references{
+		datasources:         make(map[string]*datasourceRef),
+		panels:              make(map[string][]int64),
+		libraryPanels:       make(map[string]*libraryPanelRef),
+		datasourceVariables: make(map[string]string),
+	}
+
+	variables := dash.GetPath("templating", "list").MustArray()
+	for _, v := range variables {
+		variable := simplejson.NewFromAny(v)
+		if variable.Get("type").MustString() == "datasource" {
+			refs.datasourceVariables[variable.Get("name").MustString()] = variable.GetPath("current", "value").MustString()
+		}
+	}
+
+	for _, p := range dash.Get("panels").MustArray() {
+		panel := simplejson.NewFromAny(p)
+		refs.addPanel(panel, libraryPanels)
+		for _, nested := range panel.Get("panels").MustArray() {
+			refs.addPanel(simplejson.NewFromAny(nested), libraryPanels)
+		}
+	}
+
+	for _, v := range variables {
+		variable := simplejson.NewFromAny(v)
+		if ds, ok := variable.CheckGet("datasource"); ok && variable.Get("type").MustString() == "query" {
+			if ref := refs.addDatasource(ds); ref != nil {
+				ref.variables = append(ref.variables, variable.Get("name").MustString())
+			}
+		}
+	}
+
+	for _, a := range dash.GetPath("annotations", "list").MustArray() {
+		annotation := simplejson.NewFromAny(a)
+		if ds, ok := annotation.CheckGet("datasource"); ok {
+			if ref := refs.addDatasource(ds); ref != nil {
+				ref.annotations = append(ref.annotations, annotation.Get("name").MustString())
+			}
+		}
+	}
+	return refs
+}
+
+func (r *references) addPanel(panel *simplejson.Json, libraryPanels map[string]*simplejson.Json) {
+	id := panel.Get("id").MustInt64()
+
+	if uid := panel.GetPath("libraryPanel", "uid").MustString(); uid != "" {
+		ref, ok := r.libraryPanels[uid]
+		if !ok {
+			ref = &libraryPanelRef{name: panel.GetPath("libraryPanel", "name").MustString()}
+			r.libraryPanels[uid] = ref
+		}
+		ref.panelIDs = append(ref.panelIDs, id)
+
+		model, ok := libraryPanels[uid]
+		if !ok {
+			return
+		}
+		panel = model
+	}
+
+	panelType := panel.Get("type").MustString()
+	if panelType == "row" {
+		return
+	}
+	if panelType != "" {
+		r.panels[panelType] = append(r.panels[panelType], id)
+	}
+
+	targets := panel.Get("targets").MustArray()
+	panelDs := panel.Get("datasource")
+	mixed := panelDs.MustString() == mixedDatasourceName
+	if !mixed && (len(targets) > 0 || panelDs.Interface() != nil) {
+		if ref := r.addDatasource(panelDs); ref != nil {
+			ref.panelIDs = appendID(ref.panelIDs, id)
+		}
+	}
+	for _, t := range targets {
+		targetDs := simplejson.NewFromAny(t).Get("datasource")
+		if !mixed && targetDs.Interface() == nil {
+			continue
+		}
+		if ref := r.addDatasource(targetDs); ref != nil {
+			ref.panelIDs = appendID(ref.panelIDs, id)
+		}
+	}
+}
+
+// addDatasource adds the data source of a reference, which is either a name
+// or an object with the uid of the data source, and returns it. Built-in data
+// sources and variables without a value aren't added.
+func (r *references) addDatasource(ds *simplejson.Json) *datasourceRef {
+	uid, name := "", ""
+	if s, err := ds.String(); err == nil {
+		name = s
+	} else if ds.Interface() != nil {
+		uid = ds.Get("uid").MustString()
+	}
+
+	for _, ref := range []*string{&uid, &name} {
+		if m := variableRegex.FindStringSubmatch(*ref); m != nil {
+			value, ok := r.datasourceVariables[m[1]]
+			if !ok || value == "" {
+				return nil
+			}
+			uid, name = "", value
+		}
+	}
+
+	switch {
+	case name == grafanads.DatasourceName, name == dashboardDatasourceName, name == mixedDatasourceName, name == expr.DatasourceName,
+		uid == grafanads.DatasourceUID, uid == expr.DatasourceUID:
+		return nil
+	case name == defaultDatasourceName:
+		name = ""
+	}
+
+	key := "uid:" + uid
+	if uid == "" {
+		key = "name:" + name
+	}
+	ref, ok := r.datasources[key]
+	if !ok {
+		ref = &datasourceRef{uid: uid, name: name}
+		r.datasources[key] = ref
+	}
+	return ref
+}
+
+func appendID(ids []int64, id int64) []int64 {
+	for _, existing := range ids {
+		if existing == id {
+			return ids
+		}
+	}
+	return append(ids, id)
+}
@@ -0,0 +1,63 @@
+package dashboarddeps
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyze(t *testing.T) {
+	dash, err := simplejson.NewJson([]byte(`{
+		"panels": [
+			{"id": 1, "type": "timeseries", "datasource": "Prometheus", "targets": [{"refId": "A"}]},
+			{"id": 2, "type": "text"},
+			{"id": 3, "type": "stat", "datasource": "-- Mixed --", "targets": [
+				{"refId": "A", "datasource": {"uid": "loki-uid", "type": "loki"}},
+				{"refId": "B", "datasource": "$ds"},
+				{"refId": "C", "datasource": "__expr__"}
+			]},
+			{"id": 4, "type": "row", "collapsed": true, "panels": [
+				{"id": 5, "type": "graph", "targets": [{"refId": "A"}]},
+				{"id": 6, "gridPos": {}, "libraryPanel": {"uid": "lib-1", "name": "Shared"}},
+				{"id": 7, "libraryPanel": {"uid": "deleted", "name": "Deleted"}}
+			]}
+		],
+		"templating": {"list": [
+			{"name": "ds", "type": "datasource", "query": "prometheus", "current": {"text": "Thanos", "value": "Thanos"}},
+			{"name": "host", "type": "query", "datasource": "${ds}"},
+			{"name": "other", "type": "datasource", "current": {}},
+			{"name": "job", "type": "query", "datasource": "$other"}
+		]},
+		"annotations": {"list": [
+			{"name": "Annotations & Alerts", "datasource": "-- Grafana --"},
+			{"name": "Deploys", "datasource": "Loki"}
+		]}
+	}`))
+	require.NoError(t, err)
+
+	libraryPanel, err := simplejson.NewJson([]byte(`{"type": "piechart", "datasource": "Prometheus", "targets": [{"refId": "A"}]}`))
+	require.NoError(t, err)
+
+	refs := analyze(dash, map[string]*simplejson.Json{"lib-1": libraryPanel})
+
+	require.Len(t, refs.datasources, 5)
+	require.Equal(t, []int64{1, 6}, refs.datasources["name:Prometheus"].panelIDs)
+	require.Equal(t, []int64{3}, refs.datasources["uid:loki-uid"].panelIDs)
+	require.Equal(t, []int64{3}, refs.datasources["name:Thanos"].panelIDs)
+	require.Equal(t, []string{"host"}, refs.datasources["name:Thanos"].variables)
+	require.Equal(t, []int64{5}, refs.datasources["name:"].panelIDs)
+	require.Equal(t, []string{"Deploys"}, refs.datasources["name:Loki"].annotations)
+
+	require.Equal(t, map[string][]int64{
+		"timeseries": {1},
+		"text":       {2},
+		"stat":       {3},
+		"graph":      {5},
+		"piechart":   {6},
+	}, refs.panels)
+
+	require.Len(t, refs.libraryPanels, 2)
+	require.Equal(t, []int64{6}, refs.libraryPanels["lib-1"].panelIDs)
+	require.Equal(t, "Deleted", refs.libraryPanels["deleted"].name)
+}
@@ -0,0 +1,435 @@
+package dashboarddeps
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/libraryelements"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// Service analyzes which data sources, panel plugins, library panels and
+// alert rules dashboards depend on.
+type Service struct {
+	SQLStore              *sqlstore.SQLStore
+	PluginManager         plugins.Manager
+	LibraryElementService libraryelements.Service
+}
+
+func ProvideService(sqlStore *sqlstore.SQLStore, pluginManager plugins.Manager,
+	libraryElementService libraryelements.Service) *Service {
+	return &Service{
+		SQLStore:              sqlStore,
+		PluginManager:         pluginManager,
+		LibraryElementService: libraryElementService,
+	}
+}
+
+// orgResources are the data sources and library panels of an org that
+// dashboard references are resolved with.
+type orgResources struct {
+	datasourcesByUID  map[string]*models.DataSource
+	datasourcesByName map[string]*models.DataSource
+	defaultDatasource *models.DataSource
+	libraryPanels     map[string]*simplejson.Json
+}
+
+// GetDashboardDependencies returns the dependencies of the dashboard. Alert
+// rules are limited to the rules in folders the user can view.
+func (s *Service) GetDashboardDependencies(ctx context.Context, user *models.SignedInUser, dash *models.Dashboard) (*DashboardDependencies, error) {
+	resources, err := s.getOrgResources(ctx, user.OrgId)
+	if err != nil {
+		return nil, err
+	}
+	deps := s.dependencies(dash, resources)
+
+	// library panels connected to the dashboard but no longer in its JSON
+	connected, err := s.LibraryElementService.GetElementsForDashboard(ctx, dash.Id)
+	if err != nil {
+		return nil, err
+	}
+	for uid, element := range connected {
+		found := false
+		for _, lp := range deps.LibraryPanels {
+			found = found || lp.Uid == uid
+		}
+		if !found {
+			deps.LibraryPanels = append(deps.LibraryPanels, &LibraryPanelDependency{Uid: uid, Name: element.Name, PanelIds: []int64{}})
+		}
+	}
+	sort.Slice(deps.LibraryPanels, func(i, j int) bool {
+		return deps.LibraryPanels[i].Name < deps.LibraryPanels[j].Name
+	})
+
+	rules, err := s.getAlertRules(ctx, user, dash.Uid)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		deps.AlertRules = append(deps.AlertRules, toAlertRuleDependency(rule))
+	}
+	return deps, nil
+}
+
+// GetDatasourceDependents returns the dashboards the user can view using the
+// data source and the alert rules querying it.
+func (s *Service) GetDatasourceDependents(ctx context.Context, user *models.SignedInUser, ds *models.DataSource) (*Dependents, error) {
+	dependents, err := s.getDependents(ctx, user, nil, func(deps *DashboardDependencies) *DependentDashboard {
+		for _, d := range deps.Datasources {
+			if d.Id == ds.Id {
+				return &DependentDashboard{PanelIds: d.PanelIds, Variables: d.Variables, Annotations: d.Annotations}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := s.getAlertRules(ctx, user, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		for _, query := range rule.Data {
+			if query.DatasourceUID == ds.Uid {
+				dependents.AlertRules = append(dependents.AlertRules, toAlertRuleDependency(rule))
+				break
+			}
+		}
+	}
+	return dependents, nil
+}
+
+// GetPluginDependents returns the dashboards the user can view with panels of the panel plugin.
+func (s *Service) GetPluginDependents(ctx context.Context, user *models.SignedInUser, pluginID string) (*Dependents, error) {
+	return s.getDependents(ctx, user, nil, func(deps *DashboardDependencies) *DependentDashboard {
+		for _, p := range deps.Plugins {
+			if p.Id == pluginID {
+				return &DependentDashboard{PanelIds: p.PanelIds}
+			}
+		}
+		return nil
+	})
+}
+
+// GetLibraryPanelDependents returns the dashboards the user can view which are
+// connected to the library panel.
+func (s *Service) GetLibraryPanelDependents(ctx context.Context, user *models.SignedInUser, uid string) (*Dependents, error) {
+	connections, err := s.LibraryElementService.GetConnections(ctx, user, uid)
+	if err != nil {
+		return nil, err
+	}
+	dashboardIDs := make([]int64, 0, len(connections))
+	for _, connection := range connections {
+		dashboardIDs = append(dashboardIDs, connection.ConnectionID)
+	}
+	if len(dashboardIDs) == 0 {
+		return &Dependents{Dashboards: []*DependentDashboard{}, AlertRules: []*AlertRuleDependency{}}, nil
+	}
+
+	return s.getDependents(ctx, user, dashboardIDs, func(deps *DashboardDependencies) *DependentDashboard {
+		for _, lp := range deps.LibraryPanels {
+			if lp.Uid == uid {
+				return &DependentDashboard{PanelIds: lp.PanelIds}
+			}
+		}
+		// connected, but no longer in the JSON of the dashboard
+		return &DependentDashboard{PanelIds: []int64{}}
+	})
+}
+
+// GetBrokenDashboards returns the dashboards the user can view which
+// reference missing data sources, panel plugins or library panels.
+func (s *Service) GetBrokenDashboards(ctx context.Context, user *models.SignedInUser) ([]*BrokenDashboard, error) {
+	resources, err := s.getOrgResources(ctx, user.OrgId)
+	if err != nil {
+		return nil, err
+	}
+	dashboards, err := s.getDashboards(ctx, user, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	broken := make([]*BrokenDashboard, 0)
+	for _, dash := range dashboards {
+		deps := s.dependencies(dash, resources)
+		b := &BrokenDashboard{
+			Uid:                  dash.Uid,
+			Title:                dash.Title,
+			Url:                  dash.GetUrl(),
+			FolderId:             dash.FolderId,
+			MissingDatasources:   make([]*DatasourceDependency, 0),
+			MissingPlugins:       make([]*PluginDependency, 0),
+			MissingLibraryPanels: make([]*LibraryPanelDependency, 0),
+		}
+		for _, d := range deps.Datasources {
+			if d.Missing {
+				b.MissingDatasources = append(b.MissingDatasources, d)
+			}
+		}
+		for _, p := range deps.Plugins {
+			if p.Missing {
+				b.MissingPlugins = append(b.MissingPlugins, p)
+			}
+		}
+		for _, lp := range deps.LibraryPanels {
+			if lp.Missing {
+				b.MissingLibraryPanels = append(b.MissingLibraryPanels, lp)
+			}
+		}
+		if len(b.MissingDatasources)+len(b.MissingPlugins)+len(b.MissingLibraryPanels) > 0 {
+			broken = append(broken, b)
+		}
+	}
+	return broken, nil
+}
+
+// getDependents returns the dashboards the user can view, optionally limited
+// to the ids, for which match returns how they depend on something.
+func (s *Service) getDependents(ctx context.Context, user *models.SignedInUser, dashboardIDs []int64,
+	match func(*DashboardDependencies) *DependentDashboard) (*Dependents, error) {
+	resources, err := s.getOrgResources(ctx, user.OrgId)
+	if err != nil {
+		return nil, err
+	}
+	dashboards, err := s.getDashboards(ctx, user, dashboardIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	dependents := &Dependents{
+		Dashboards: make([]*DependentDashboard, 0),
+		AlertRules: make([]*AlertRuleDependency, 0),
+	}
+	for _, dash := range dashboards {
+		dependent := match(s.dependencies(dash, resources))
+		if dependent == nil {
+			continue
+		}
+		dependent.Uid = dash.Uid
+		dependent.Title = dash.Title
+		dependent.Url = dash.GetUrl()
+		dependent.FolderId = dash.FolderId
+		if dependent.Variables == nil {
+			dependent.Variables = []string{}
+		}
+		if dependent.Annotations == nil {
+			dependent.Annotations = []string{}
+		}
+		dependents.Dashboards = append(dependents.Dashboards, dependent)
+	}
+	return dependents, nil
+}
+
+// dependencies resolves the references of the dashboard with the resources
+// of the org and the installed plugins.
+func (s *Service) dependencies(dash *models.Dashboard, resources *orgResources) *DashboardDependencies {
+	refs := analyze(dash.Data, resources.libraryPanels)
+	deps := &DashboardDependencies{
+		Uid:           dash.Uid,
+		Title:         dash.Title,
+		Datasources:   make([]*DatasourceDependency, 0, len(refs.datasources)),
+		Plugins:       make([]*PluginDependency, 0, len(refs.panels)),
+		LibraryPanels: make([]*LibraryPanelDependency, 0, len(refs.libraryPanels)),
+		AlertRules:    make([]*AlertRuleDependency, 0),
+	}
+
+	byID := make(map[int64]*DatasourceDependency)
+	for _, ref := range refs.datasources {
+		var ds *models.DataSource
+		switch {
+		case ref.uid != "":
+			ds = resources.datasourcesByUID[ref.uid]
+		case ref.name != "":
+			ds = resources.datasourcesByName[ref.name]
+		default:
+			ds = resources.defaultDatasource
+		}
+
+		dep := &DatasourceDependency{Uid: ref.uid, Name: ref.name, Missing: true}
+		if ds != nil {
+			// references by name and by uid of the same data source are merged
+			if existing, ok := byID[ds.Id]; ok {
+				dep = existing
+			} else {
+				dep = &DatasourceDependency{Id: ds.Id, Uid: ds.Uid, Name: ds.Name, Type: ds.Type}
+				byID[ds.Id] = dep
+				deps.Datasources = append(deps.Datasources, dep)
+			}
+		} else {
+			if dep.Name == "" && dep.Uid == "" {
+				dep.Name = defaultDatasourceName
+			}
+			deps.Datasources = append(deps.Datasources, dep)
+		}
+		for _, id := range ref.panelIDs {
+			dep.PanelIds = appendID(dep.PanelIds, id)
+		}
+		dep.Variables = append(dep.Variables, ref.variables...)
+		dep.Annotations = append(dep.Annotations, ref.annotations...)
+	}
+	for _, dep := range deps.Datasources {
+		if dep.PanelIds == nil {
+			dep.PanelIds = []int64{}
+		}
+		if dep.Variables == nil {
+			dep.Variables = []string{}
+		}
+		if dep.Annotations == nil {
+			dep.Annotations = []string{}
+		}
+	}
+	sort.Slice(deps.Datasources, func(i, j int) bool {
+		return deps.Datasources[i].Name < deps.Datasources[j].Name
+	})
+
+	for id, panelIDs := range refs.panels {
+		dep := &PluginDependency{Id: id, PanelIds: panelIDs}
+		if plugin := s.PluginManager.GetPlugin(id); plugin != nil && plugin.Type == "panel" {
+			dep.Name = plugin.Name
+		} else {
+			dep.Missing = true
+		}
+		deps.Plugins = append(deps.Plugins, dep)
+	}
+	sort.Slice(deps.Plugins, func(i, j int) bool {
+		return deps.Plugins[i].Id < deps.Plugins[j].Id
+	})
+
+	for uid, ref := range refs.libraryPanels {
+		_, exists := resources.libraryPanels[uid]
+		deps.LibraryPanels = append(deps.LibraryPanels, &LibraryPanelDependency{
+			Uid:      uid,
+			Name:     ref.name,
+			Missing:  !exists,
+			PanelIds: ref.panelIDs,
+		})
+	}
+	sort.Slice(deps.LibraryPanels, func(i, j int) bool {
+		return deps.LibraryPanels[i].Name < deps.LibraryPanels[j].Name
+	})
+	return deps
+}
+
+func (s *Service) getOrgResources(ctx context.Context, orgID int64) (*orgResources, error) {
+	resources := &orgResources{
+		datasourcesByUID:  make(map[string]*models.DataSource),
+		datasourcesByName: make(map[string]*models.DataSource),
+		libraryPanels:     make(map[string]*simplejson.Json),
+	}
+
+	query := models.GetDataSourcesQuery{OrgId: orgID}
+	if err := s.SQLStore.GetDataSources(&query); err != nil {
+		return nil, err
+	}
+	for _, ds := range query.Result {
+		resources.datasourcesByUID[ds.Uid] = ds
+		resources.datasourcesByName[ds.Name] = ds
+		if ds.IsDefault {
+			resources.defaultDatasource = ds
+		}
+	}
+
+	err := s.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var elements []struct {
+			UID   string `xorm:"uid"`
+			Model json.RawMessage
+		}
+		if err := sess.Table("library_element").Cols("uid", "model").
+			Where("org_id = ? AND kind = ?", orgID, models.PanelElement).Find(&elements); err != nil {
+			return err
+		}
+		for _, element := range elements {
+			model, err := simplejson.NewJson(element.Model)
+			if err != nil {
+				model = simplejson.New()
+			}
+			resources.libraryPanels[element.UID] = model
+		}
+		return nil
+	})
+	return resources, err
+}
+
+// getDashboards returns the dashboards the user can view, optionally limited to the ids.
+func (s *Service) getDashboards(ctx context.Context, user *models.SignedInUser, ids []int64) ([]*models.Dashboard, error) {
+	dashboards := make([]*models.Dashboard, 0)
+	err := s.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		builder := sqlstore.SQLBuilder{}
+		builder.Write("SELECT dashboard.* FROM dashboard WHERE dashboard.org_id = ? AND dashboard.is_folder = ?",
+			user.OrgId, s.SQLStore.Dialect.BooleanStr(false))
+		if len(ids) > 0 {
+			params := make([]interface{}, 0, len(ids))
+			for _, id := range ids {
+				params = append(params, id)
+			}
+			builder.Write(" AND dashboard.id IN (?"+strings.Repeat(",?", len(ids)-1)+")", params...)
+		}
+		builder.WriteDashboardPermissionFilter(user, models.PERMISSION_VIEW)
+		builder.Write(" ORDER BY dashboard.title ASC")
+		return sess.SQL(builder.GetSQLString(), builder.GetParams()...).Find(&dashboards)
+	})
+	return dashboards, err
+}
+
+// getAlertRules returns the alert rules in folders the user can view,
+// optionally limited to the rules linked to the dashboard.
+func (s *Service) getAlertRules(ctx context.Context, user *models.SignedInUser, dashboardUID string) ([]*ngmodels.AlertRule, error) {
+	rules := make([]*ngmodels.AlertRule, 0)
+	err := s.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		q := sess.Table("alert_rule").Where("org_id = ?", user.OrgId)
+		if dashboardUID != "" {
+			q = q.And("dashboard_uid = ?", dashboardUID)
+		}
+		var all []*ngmodels.AlertRule
+		if err := q.Asc("title").Find(&all); err != nil {
+			return err
+		}
+		if user.OrgRole == models.ROLE_ADMIN {
+			rules = all
+			return nil
+		}
+
+		builder := sqlstore.SQLBuilder{}
+		builder.Write("SELECT dashboard.uid FROM dashboard WHERE dashboard.org_id = ? AND dashboard.is_folder = ?",
+			user.OrgId, s.SQLStore.Dialect.BooleanStr(true))
+		builder.WriteDashboardPermissionFilter(user, models.PERMISSION_VIEW)
+		var folderUIDs []string
+		if err := sess.SQL(builder.GetSQLString(), builder.GetParams()...).Find(&folderUIDs); err != nil {
+			return err
+		}
+		viewable := make(map[string]bool, len(folderUIDs))
+		for _, uid := range folderUIDs {
+			viewable[uid] = true
+		}
+		for _, rule := range all {
+			if viewable[rule.NamespaceUID] {
+				rules = append(rules, rule)
+			}
+		}
+		return nil
+	})
+	return rules, err
+}
+
+func toAlertRuleDependency(rule *ngmodels.AlertRule) *AlertRuleDependency {
+	dep := &AlertRuleDependency{
+		Uid:          rule.UID,
+		Title:        rule.Title,
+		NamespaceUid: rule.NamespaceUID,
+	}
+	if rule.DashboardUID != nil {
+		dep.DashboardUid = *rule.DashboardUID
+	}
+	if rule.PanelID != nil {
+		dep.PanelId = *rule.PanelID
+	}
+	return dep
+}
@@ -0,0 +1,152 @@
+package dashboarddeps
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/libraryelements"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePluginManager struct {
+	plugins.Manager
+	panels map[string]bool
+}
+
+func (m *fakePluginManager) GetPlugin(id string) *plugins.PluginBase {
+	if !m.panels[id] {
+		return nil
+	}
+	return &plugins.PluginBase{Id: id, Type: "panel", Name: id}
+}
+
+func TestDashboardDepsService(t *testing.T) {
+	ctx := context.Background()
+	user := &models.SignedInUser{UserId: 1, OrgId: 1, OrgRole: models.ROLE_ADMIN}
+
+	sqlStore := sqlstore.InitTestDB(t)
+	elementService := libraryelements.ProvideService(setting.NewCfg(), sqlStore, routing.NewRouteRegister())
+	s := ProvideService(sqlStore, &fakePluginManager{panels: map[string]bool{"timeseries": true, "text": true}}, elementService)
+
+	addDatasource := func(name string, isDefault bool) *models.DataSource {
+		cmd := &models.AddDataSourceCommand{OrgId: 1, Name: name, Uid: name + "-uid", Type: "prometheus", Access: models.DS_ACCESS_PROXY, IsDefault: isDefault}
+		require.NoError(t, sqlStore.AddDataSource(ctx, cmd))
+		return cmd.Result
+	}
+	prometheus := addDatasource("Prometheus", true)
+	loki := addDatasource("Loki", false)
+
+	element, err := elementService.CreateElement(ctx, user, libraryelements.CreateLibraryElementCommand{
+		Name:  "Shared",
+		Kind:  int64(models.PanelElement),
+		Model: json.RawMessage(`{"type": "timeseries", "datasource": "Loki", "targets": [{"refId": "A"}]}`),
+	})
+	require.NoError(t, err)
+
+	saveDashboard := func(title string, data string) *models.Dashboard {
+		dashboard, err := simplejson.NewJson([]byte(data))
+		require.NoError(t, err)
+		dashboard.Set("title", title)
+		dash, err := sqlStore.SaveDashboard(models.SaveDashboardCommand{OrgId: 1, Dashboard: dashboard})
+		require.NoError(t, err)
+		return dash
+	}
+	healthy := saveDashboard("Healthy", `{"panels": [
+		{"id": 1, "type": "timeseries", "targets": [{"refId": "A"}]},
+		{"id": 2, "libraryPanel": {"uid": "`+element.UID+`", "name": "Shared"}}
+	]}`)
+	require.NoError(t, elementService.ConnectElementsToDashboard(ctx, user, []string{element.UID}, healthy.Id))
+	err = sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		panelID := int64(1)
+		_, err := sess.Insert(&ngmodels.AlertRule{
+			OrgID:        1,
+			UID:          "rule-uid",
+			Title:        "High load",
+			Condition:    "A",
+			Data:         []ngmodels.AlertQuery{{RefID: "A", DatasourceUID: prometheus.Uid, Model: json.RawMessage(`{}`)}},
+			NamespaceUID: "folder-uid",
+			DashboardUID: &healthy.Uid,
+			PanelID:      &panelID,
+			RuleGroup:    "group",
+			Updated:      time.Now(),
+		})
+		return err
+	})
+	require.NoError(t, err)
+	broken := saveDashboard("Broken", `{"panels": [
+		{"id": 1, "type": "worldmap", "datasource": "Graphite", "targets": [{"refId": "A"}]},
+		{"id": 2, "type": "text", "datasource": "Prometheus", "targets": [{"refId": "A"}]},
+		{"id": 3, "libraryPanel": {"uid": "deleted", "name": "Deleted"}}
+	]}`)
+
+	t.Run("Should return the dependencies of a dashboard", func(t *testing.T) {
+		deps, err := s.GetDashboardDependencies(ctx, user, healthy)
+		require.NoError(t, err)
+
+		require.Len(t, deps.Datasources, 2)
+		require.Equal(t, loki.Uid, deps.Datasources[0].Uid)
+		require.Equal(t, []int64{2}, deps.Datasources[0].PanelIds)
+		require.Equal(t, prometheus.Uid, deps.Datasources[1].Uid)
+		require.Equal(t, []int64{1}, deps.Datasources[1].PanelIds)
+
+		require.Len(t, deps.Plugins, 1)
+		require.Equal(t, []int64{1, 2}, deps.Plugins[0].PanelIds)
+		require.False(t, deps.Plugins[0].Missing)
+
+		require.Len(t, deps.LibraryPanels, 1)
+		require.Equal(t, element.UID, deps.LibraryPanels[0].Uid)
+		require.False(t, deps.LibraryPanels[0].Missing)
+
+		require.Len(t, deps.AlertRules, 1)
+		require.Equal(t, "rule-uid", deps.AlertRules[0].Uid)
+		require.Equal(t, int64(1), deps.AlertRules[0].PanelId)
+	})
+
+	t.Run("Should return the dashboards using a data source", func(t *testing.T) {
+		dependents, err := s.GetDatasourceDependents(ctx, user, prometheus)
+		require.NoError(t, err)
+		require.Len(t, dependents.Dashboards, 2)
+		require.Equal(t, "Broken", dependents.Dashboards[0].Title)
+		require.Equal(t, []int64{2}, dependents.Dashboards[0].PanelIds)
+		require.Equal(t, "Healthy", dependents.Dashboards[1].Title)
+		require.Len(t, dependents.AlertRules, 1)
+		require.Equal(t, "rule-uid", dependents.AlertRules[0].Uid)
+	})
+
+	t.Run("Should return the dashboards using a panel plugin", func(t *testing.T) {
+		dependents, err := s.GetPluginDependents(ctx, user, "worldmap")
+		require.NoError(t, err)
+		require.Len(t, dependents.Dashboards, 1)
+		require.Equal(t, broken.Uid, dependents.Dashboards[0].Uid)
+	})
+
+	t.Run("Should return the dashboards connected to a library panel", func(t *testing.T) {
+		dependents, err := s.GetLibraryPanelDependents(ctx, user, element.UID)
+		require.NoError(t, err)
+		require.Len(t, dependents.Dashboards, 1)
+		require.Equal(t, healthy.Uid, dependents.Dashboards[0].Uid)
+		require.Equal(t, []int64{2}, dependents.Dashboards[0].PanelIds)
+	})
+
+	t.Run("Should return dashboards with missing references", func(t *testing.T) {
+		result, err := s.GetBrokenDashboards(ctx, user)
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		require.Equal(t, broken.Uid, result[0].Uid)
+		require.Len(t, result[0].MissingDatasources, 1)
+		require.Equal(t, "Graphite", result[0].MissingDatasources[0].Name)
+		require.Len(t, result[0].MissingPlugins, 1)
+		require.Equal(t, "worldmap", result[0].MissingPlugins[0].Id)
+		require.Len(t, result[0].MissingLibraryPanels, 1)
+		require.Equal(t, "deleted", result[0].MissingLibraryPanels[0].Uid)
+	})
+}
@@ -0,0 +1,86 @@
+package dashboarddeps
+
+// DashboardDependencies are the data sources, panel plugins, library panels
+// and alert rules a dashboard depends on.
+type DashboardDependencies struct {
+	Uid           string                    `json:"uid"`
+	Title         string                    `json:"title"`
+	Datasources   []*DatasourceDependency   `json:"datasources"`
+	Plugins       []*PluginDependency       `json:"plugins"`
+	LibraryPanels []*LibraryPanelDependency `json:"libraryPanels"`
+	AlertRules    []*AlertRuleDependency    `json:"alertRules"`
+}
+
+// DatasourceDependency is a data source used by panels, variables or
+// annotations of a dashboard. Missing data sources are referenced by the uid
+// or name in the dashboard, or have the name "default" if the org doesn't
+// have a default data source.
+type DatasourceDependency struct {
+	Id          int64    `json:"id,omitempty"`
+	Uid         string   `json:"uid,omitempty"`
+	Name        string   `json:"name"`
+	Type        string   `json:"type,omitempty"`
+	Missing     bool     `json:"missing"`
+	PanelIds    []int64  `json:"panelIds"`
+	Variables   []string `json:"variables"`
+	Annotations []string `json:"annotations"`
+}
+
+// PluginDependency is a panel plugin used by panels of a dashboard. It is
+// missing if the plugin isn't installed.
+type PluginDependency struct {
+	Id       string  `json:"id"`
+	Name     string  `json:"name,omitempty"`
+	Missing  bool    `json:"missing"`
+	PanelIds []int64 `json:"panelIds"`
+}
+
+// LibraryPanelDependency is a library panel used by a dashboard. It is
+// missing if the library panel was deleted.
+type LibraryPanelDependency struct {
+	Uid      string  `json:"uid"`
+	Name     string  `json:"name"`
+	Missing  bool    `json:"missing"`
+	PanelIds []int64 `json:"panelIds"`
+}
+
+// AlertRuleDependency is an alert rule linked to a dashboard or querying a
+// data source.
+type AlertRuleDependency struct {
+	Uid          string `json:"uid"`
+	Title        string `json:"title"`
+	NamespaceUid string `json:"namespaceUid"`
+	DashboardUid string `json:"dashboardUid,omitempty"`
+	PanelId      int64  `json:"panelId,omitempty"`
+}
+
+// Dependents are the dashboards and alert rules which depend on a data
+// source, panel plugin or library panel.
+type Dependents struct {
+	Dashboards []*DependentDashboard  `json:"dashboards"`
+	AlertRules []*AlertRuleDependency `json:"alertRules"`
+}
+
+// DependentDashboard is a dashboard depending on a data source, panel plugin
+// or library panel, with the panels, variables and annotations using it.
+type DependentDashboard struct {
+	Uid         string   `json:"uid"`
+	Title       string   `json:"title"`
+	Url         string   `json:"url"`
+	FolderId    int64    `json:"folderId"`
+	PanelIds    []int64  `json:"panelIds"`
+	Variables   []string `json:"variables"`
+	Annotations []string `json:"annotations"`
+}
+
+// BrokenDashboard is a dashboard referencing data sources, panel plugins or
+// library panels which don't exist.
+type BrokenDashboard struct {
+	Uid                  string                    `json:"uid"`
+	Title                string                    `json:"title"`
+	Url                  string                    `json:"url"`
+	FolderId             int64                     `json:"folderId"`
+	MissingDatasources   []*DatasourceDependency   `json:"missingDatasources"`
+	MissingPlugins       []*PluginDependency       `json:"missingPlugins"`
+	MissingLibraryPanels []*LibraryPanelDependency `json:"missingLibraryPanels"`
+}
@@ -0,0 +1,99 @@
+package dashboards
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+// SaveDashboards validates each dashboard like SaveDashboard and saves the valid ones in a
+// single transaction. The returned errors are indexed like dtos and are nil for the saved
+// dashboards, which replace the dashboards of their DTO.
+func (dr *dashboardServiceImpl) SaveDashboards(ctx context.Context, dtos []*SaveDashboardDTO, allowUiUpdate bool) []error {
+	errs := make([]error, len(dtos))
+	bulkCmd := &models.SaveDashboardsCommand{}
+	var saved []int
+
+	for i, dto := range dtos {
+		if err := validateDashboardRefreshInterval(dto.Dashboard); err != nil {
+			dr.log.Warn("Changing refresh interval for saved dashboard to minimum refresh interval",
+				"dashboardUid", dto.Dashboard.Uid, "dashboardTitle", dto.Dashboard.Title, "minRefreshInterval",
+				setting.MinRefreshInterval)
+			dto.Dashboard.Data.Set("refresh", setting.MinRefreshInterval)
+		}
+
+		cmd, err := dr.buildSaveDashboardCommand(dto, true, !allowUiUpdate)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		bulkCmd.Commands = append(bulkCmd.Commands, cmd)
+		saved = append(saved, i)
+	}
+
+	if len(bulkCmd.Commands) == 0 {
+		return errs
+	}
+
+	if err := bus.DispatchCtx(ctx, bulkCmd); err != nil {
+		err = errutil.Wrap("saving dashboards failed", err)
+		for _, i := range saved {
+			errs[i] = err
+		}
+		return errs
+	}
+
+	for j, i := range saved {
+		dtos[i].Dashboard = bulkCmd.Commands[j].Result
+		if err := UpdateAlerting(dr.dashboardStore, dtos[i].OrgId, dtos[i].Dashboard, dtos[i].User); err != nil {
+			errs[i] = err
+		}
+	}
+
+	return errs
+}
+
+// DeleteDashboardsByUser is like DeleteDashboardByUser for several dashboards. The dashboards
+// which aren't provisioned are deleted in a single transaction. The returned errors are
+// indexed like dashboardIds and are nil for the deleted dashboards.
+func (dr *dashboardServiceImpl) DeleteDashboardsByUser(ctx context.Context, dashboardIds []int64, orgId int64,
+	user *models.SignedInUser) []error {
+	errs := make([]error, len(dashboardIds))
+	bulkCmd := &models.DeleteDashboardsCommand{}
+	var deleted []int
+
+	for i, dashboardId := range dashboardIds {
+		provisionedData, err := dr.GetProvisionedDashboardDataByDashboardID(dashboardId)
+		if err != nil {
+			errs[i] = errutil.Wrap("failed to check if dashboard is provisioned", err)
+			continue
+		}
+		if provisionedData != nil {
+			errs[i] = models.ErrDashboardCannotDeleteProvisionedDashboard
+			continue
+		}
+
+		cmd := &models.DeleteDashboardCommand{OrgId: orgId, Id: dashboardId}
+		if setting.DashboardTrashRetention > 0 {
+			cmd.Trash = true
+			cmd.DeletedBy = user.UserId
+		}
+		bulkCmd.Commands = append(bulkCmd.Commands, cmd)
+		deleted = append(deleted, i)
+	}
+
+	if len(bulkCmd.Commands) == 0 {
+		return errs
+	}
+
+	if err := bus.DispatchCtx(ctx, bulkCmd); err != nil {
+		for _, i := range deleted {
+			errs[i] = err
+		}
+	}
+
+	return errs
+}
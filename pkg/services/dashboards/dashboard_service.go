@@ -2,11 +2,13 @@ package dashboards
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana/pkg/components/dashmerge"
 	"github.com/grafana/grafana/pkg/dashboards"
 	"github.com/grafana/grafana/pkg/services/alerting"
 	"github.com/grafana/grafana/pkg/setting"
@@ -21,9 +23,12 @@ import (
 
 // DashboardService is a service for operating on dashboards.
 type DashboardService interface {
-	SaveDashboard(dto *SaveDashboardDTO, allowUiUpdate bool) (*models.Dashboard, error)
+	SaveDashboard(ctx context.Context, dto *SaveDashboardDTO, allowUiUpdate bool) (*models.Dashboard, error)
 	ImportDashboard(dto *SaveDashboardDTO) (*models.Dashboard, error)
 	DeleteDashboard(dashboardId int64, orgId int64) error
+	DeleteDashboardByUser(dashboardId int64, orgId int64, user *models.SignedInUser) error
+	SaveDashboards(ctx context.Context, dtos []*SaveDashboardDTO, allowUiUpdate bool) []error
+	DeleteDashboardsByUser(ctx context.Context, dashboardIds []int64, orgId int64, user *models.SignedInUser) []error
 	MakeUserAdmin(ctx context.Context, orgID int64, userID, dashboardID int64, setViewAndEditPermissions bool) error
 }
 
@@ -94,10 +99,6 @@ func (dr *dashboardServiceImpl) buildSaveDashboardCommand(dto *SaveDashboardDTO,
 		return nil, models.ErrDashboardTitleEmpty
 	}
 
-	if dash.IsFolder && dash.FolderId > 0 {
-		return nil, models.ErrDashboardFolderCannotHaveParent
-	}
-
 	if dash.IsFolder && strings.EqualFold(dash.Title, models.RootFolderName) {
 		return nil, models.ErrDashboardFolderNameExists
 	}
@@ -270,7 +271,7 @@ func (dr *dashboardServiceImpl) SaveFolderForProvisionedDashboards(dto *SaveDash
 	return dash, nil
 }
 
-func (dr *dashboardServiceImpl) SaveDashboard(dto *SaveDashboardDTO,
+func (dr *dashboardServiceImpl) SaveDashboard(ctx context.Context, dto *SaveDashboardDTO,
 	allowUiUpdate bool) (*models.Dashboard, error) {
 	if err := validateDashboardRefreshInterval(dto.Dashboard); err != nil {
 		dr.log.Warn("Changing refresh interval for imported dashboard to minimum refresh interval",
@@ -280,6 +281,12 @@ func (dr *dashboardServiceImpl) SaveDashboard(dto *SaveDashboardDTO,
 	}
 
 	cmd, err := dr.buildSaveDashboardCommand(dto, true, !allowUiUpdate)
+	if errors.Is(err, models.ErrDashboardVersionMismatch) && !dto.Overwrite {
+		if err := dr.mergeDashboard(ctx, dto); err != nil {
+			return nil, err
+		}
+		cmd, err = dr.buildSaveDashboardCommand(dto, true, !allowUiUpdate)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -296,18 +303,68 @@ func (dr *dashboardServiceImpl) SaveDashboard(dto *SaveDashboardDTO,
 	return dash, nil
 }
 
+// mergeDashboard merges the changes of the dashboard, made to the version it is
+// based on, with the changes saved by someone else since. The dashboard of the
+// DTO is replaced by the merged dashboard, based on the latest version.
+func (dr *dashboardServiceImpl) mergeDashboard(ctx context.Context, dto *SaveDashboardDTO) error {
+	dash := dto.Dashboard
+
+	latestQuery := models.GetDashboardQuery{Id: dash.Id, Uid: dash.Uid, OrgId: dto.OrgId}
+	if err := bus.DispatchCtx(ctx, &latestQuery); err != nil {
+		return err
+	}
+	latest := latestQuery.Result
+
+	baseQuery := models.GetDashboardVersionQuery{DashboardId: latest.Id, Version: dash.Version, OrgId: dto.OrgId}
+	if err := bus.DispatchCtx(ctx, &baseQuery); err != nil {
+		if errors.Is(err, models.ErrDashboardVersionNotFound) {
+			// without the base version there's nothing to merge with
+			return models.ErrDashboardVersionMismatch
+		}
+		return err
+	}
+
+	merged, conflicts, err := dashmerge.Merge(baseQuery.Result.Data, latest.Data, dash.Data)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return models.DashboardMergeConflictError{Conflicts: conflicts}
+	}
+
+	dr.log.Debug("Merged dashboard with changes saved since its version", "dashboardUid", latest.Uid,
+		"version", dash.Version, "latestVersion", latest.Version)
+
+	merged.Set("id", latest.Id)
+	merged.Set("uid", latest.Uid)
+	merged.Set("version", latest.Version)
+	mergedDash := models.NewDashboardFromJson(merged)
+	mergedDash.FolderId = dash.FolderId
+	mergedDash.IsFolder = dash.IsFolder
+	mergedDash.PluginId = dash.PluginId
+	dto.Dashboard = mergedDash
+	return nil
+}
+
 // DeleteDashboard removes dashboard from the DB. Errors out if the dashboard was provisioned. Should be used for
 // operations by the user where we want to make sure user does not delete provisioned dashboard.
 func (dr *dashboardServiceImpl) DeleteDashboard(dashboardId int64, orgId int64) error {
-	return dr.deleteDashboard(dashboardId, orgId, true)
+	return dr.deleteDashboard(dashboardId, orgId, true, nil)
+}
+
+// DeleteDashboardByUser is like DeleteDashboard, but moves the dashboard to the recycle bin when it's enabled,
+// recording the user as the one deleting it.
+func (dr *dashboardServiceImpl) DeleteDashboardByUser(dashboardId int64, orgId int64, user *models.SignedInUser) error {
+	return dr.deleteDashboard(dashboardId, orgId, true, user)
 }
 
 // DeleteProvisionedDashboard removes dashboard from the DB even if it is provisioned.
 func (dr *dashboardServiceImpl) DeleteProvisionedDashboard(dashboardId int64, orgId int64) error {
-	return dr.deleteDashboard(dashboardId, orgId, false)
+	return dr.deleteDashboard(dashboardId, orgId, false, nil)
 }
 
-func (dr *dashboardServiceImpl) deleteDashboard(dashboardId int64, orgId int64, validateProvisionedDashboard bool) error {
+func (dr *dashboardServiceImpl) deleteDashboard(dashboardId int64, orgId int64, validateProvisionedDashboard bool,
+	user *models.SignedInUser) error {
 	if validateProvisionedDashboard {
 		provisionedData, err := dr.GetProvisionedDashboardDataByDashboardID(dashboardId)
 		if err != nil {
@@ -319,6 +376,10 @@ func (dr *dashboardServiceImpl) deleteDashboard(dashboardId int64, orgId int64,
 		}
 	}
 	cmd := &models.DeleteDashboardCommand{OrgId: orgId, Id: dashboardId}
+	if user != nil && setting.DashboardTrashRetention > 0 {
+		cmd.Trash = true
+		cmd.DeletedBy = user.UserId
+	}
 	return bus.Dispatch(cmd)
 }
 
@@ -360,7 +421,7 @@ type FakeDashboardService struct {
 	ProvisionedDashData *models.DashboardProvisioning
 }
 
-func (s *FakeDashboardService) SaveDashboard(dto *SaveDashboardDTO, allowUiUpdate bool) (*models.Dashboard, error) {
+func (s *FakeDashboardService) SaveDashboard(ctx context.Context, dto *SaveDashboardDTO, allowUiUpdate bool) (*models.Dashboard, error) {
 	s.SavedDashboards = append(s.SavedDashboards, dto)
 
 	if s.SaveDashboardResult == nil && s.SaveDashboardError == nil {
@@ -371,7 +432,7 @@ func (s *FakeDashboardService) SaveDashboard(dto *SaveDashboardDTO, allowUiUpdat
 }
 
 func (s *FakeDashboardService) ImportDashboard(dto *SaveDashboardDTO) (*models.Dashboard, error) {
-	return s.SaveDashboard(dto, true)
+	return s.SaveDashboard(context.Background(), dto, true)
 }
 
 func (s *FakeDashboardService) DeleteDashboard(dashboardId int64, orgId int64) error {
@@ -384,6 +445,27 @@ func (s *FakeDashboardService) DeleteDashboard(dashboardId int64, orgId int64) e
 	return nil
 }
 
+func (s *FakeDashboardService) DeleteDashboardByUser(dashboardId int64, orgId int64, user *models.SignedInUser) error {
+	return s.DeleteDashboard(dashboardId, orgId)
+}
+
+func (s *FakeDashboardService) SaveDashboards(ctx context.Context, dtos []*SaveDashboardDTO, allowUiUpdate bool) []error {
+	errs := make([]error, len(dtos))
+	for i, dto := range dtos {
+		_, errs[i] = s.SaveDashboard(ctx, dto, allowUiUpdate)
+	}
+	return errs
+}
+
+func (s *FakeDashboardService) DeleteDashboardsByUser(ctx context.Context, dashboardIds []int64, orgId int64,
+	user *models.SignedInUser) []error {
+	errs := make([]error, len(dashboardIds))
+	for i, dashboardId := range dashboardIds {
+		errs[i] = s.DeleteDashboardByUser(dashboardId, orgId, user)
+	}
+	return errs
+}
+
 func (s *FakeDashboardService) GetProvisionedDashboardDataByDashboardID(id int64) (*models.DashboardProvisioning, error) {
 	return s.ProvisionedDashData, nil
 }
@@ -1,6 +1,7 @@
 package dashboards
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/grafana/grafana/pkg/setting"
 
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/guardian"
 
@@ -37,21 +39,14 @@ func TestDashboardService(t *testing.T) {
 
 				for _, title := range titles {
 					dto.Dashboard = models.NewDashboard(title)
-					_, err := service.SaveDashboard(dto, false)
+					_, err := service.SaveDashboard(context.Background(), dto, false)
 					require.Equal(t, err, models.ErrDashboardTitleEmpty)
 				}
 			})
 
-			t.Run("Should return validation error if it's a folder and have a folder id", func(t *testing.T) {
-				dto.Dashboard = models.NewDashboardFolder("Folder")
-				dto.Dashboard.FolderId = 1
-				_, err := service.SaveDashboard(dto, false)
-				require.Equal(t, err, models.ErrDashboardFolderCannotHaveParent)
-			})
-
 			t.Run("Should return validation error if folder is named General", func(t *testing.T) {
 				dto.Dashboard = models.NewDashboardFolder("General")
-				_, err := service.SaveDashboard(dto, false)
+				_, err := service.SaveDashboard(context.Background(), dto, false)
 				require.Equal(t, err, models.ErrDashboardFolderNameExists)
 			})
 
@@ -104,7 +99,7 @@ func TestDashboardService(t *testing.T) {
 				dto.Dashboard = models.NewDashboard("Dash")
 				dto.Dashboard.SetId(3)
 				dto.User = &models.SignedInUser{UserId: 1}
-				_, err := service.SaveDashboard(dto, false)
+				_, err := service.SaveDashboard(context.Background(), dto, false)
 				require.Equal(t, err, models.ErrDashboardCannotSaveProvisionedDashboard)
 			})
 
@@ -120,7 +115,7 @@ func TestDashboardService(t *testing.T) {
 				dto.Dashboard = models.NewDashboard("Dash")
 				dto.Dashboard.SetId(3)
 				dto.User = &models.SignedInUser{UserId: 1}
-				_, err := service.SaveDashboard(dto, true)
+				_, err := service.SaveDashboard(context.Background(), dto, true)
 				require.NoError(t, err)
 			})
 
@@ -134,11 +129,81 @@ func TestDashboardService(t *testing.T) {
 				}
 
 				dto.Dashboard = models.NewDashboard("Dash")
-				_, err := service.SaveDashboard(dto, false)
+				_, err := service.SaveDashboard(context.Background(), dto, false)
 				require.Equal(t, err.Error(), "alert validation error")
 			})
 		})
 
+		t.Run("Save dashboard based on an older version", func(t *testing.T) {
+			origValidateAlerts := validateAlerts
+			origUpdateAlerting := UpdateAlerting
+			t.Cleanup(func() {
+				validateAlerts = origValidateAlerts
+				UpdateAlerting = origUpdateAlerting
+				fakeStore.latestVersion = 0
+				bus.ClearBusHandlers()
+			})
+			validateAlerts = func(dash *models.Dashboard, user *models.SignedInUser) error {
+				return nil
+			}
+			UpdateAlerting = func(store dashboards.Store, orgID int64, dashboard *models.Dashboard,
+				user *models.SignedInUser) error {
+				return nil
+			}
+			fakeStore.latestVersion = 2
+
+			newDashboard := func(version int, panelTitles ...string) *models.Dashboard {
+				panels := make([]interface{}, 0, len(panelTitles))
+				for i, title := range panelTitles {
+					panels = append(panels, map[string]interface{}{"id": i + 1, "title": title})
+				}
+				dash := models.NewDashboardFromJson(simplejson.NewFromAny(map[string]interface{}{
+					"id":      3,
+					"uid":     "dash",
+					"title":   "Dash",
+					"version": version,
+					"panels":  panels,
+				}))
+				dash.OrgId = 1
+				return dash
+			}
+
+			bus.AddHandler("test", func(query *models.GetDashboardQuery) error {
+				query.Result = newDashboard(2, "A latest", "B")
+				return nil
+			})
+			bus.AddHandler("test", func(query *models.GetDashboardVersionQuery) error {
+				require.Equal(t, 1, query.Version)
+				query.Result = &models.DashboardVersion{DashboardId: 3, Version: 1, Data: newDashboard(1, "A", "B").Data}
+				return nil
+			})
+
+			t.Run("Should merge changes that don't conflict", func(t *testing.T) {
+				dto := &SaveDashboardDTO{
+					OrgId:     1,
+					User:      &models.SignedInUser{UserId: 1},
+					Dashboard: newDashboard(1, "A", "B incoming"),
+				}
+				dash, err := service.SaveDashboard(context.Background(), dto, true)
+				require.NoError(t, err)
+				require.Equal(t, 2, dash.Version)
+
+				panels := dash.Data.Get("panels")
+				require.Equal(t, "A latest", panels.GetIndex(0).Get("title").MustString())
+				require.Equal(t, "B incoming", panels.GetIndex(1).Get("title").MustString())
+			})
+
+			t.Run("Should return conflicting changes", func(t *testing.T) {
+				dto := &SaveDashboardDTO{
+					OrgId:     1,
+					User:      &models.SignedInUser{UserId: 1},
+					Dashboard: newDashboard(1, "A incoming", "B"),
+				}
+				_, err := service.SaveDashboard(context.Background(), dto, true)
+				require.Equal(t, models.DashboardMergeConflictError{Conflicts: []string{"panels[id=1].title"}}, err)
+			})
+		})
+
 		t.Run("Save provisioned dashboard validation", func(t *testing.T) {
 			dto := &SaveDashboardDTO{}
 
@@ -297,10 +362,15 @@ type fakeDashboardStore struct {
 
 	validationError error
 	provisionedData *models.DashboardProvisioning
+	// latestVersion, if set, is the version saved dashboards must be based on
+	latestVersion int
 }
 
 func (s *fakeDashboardStore) ValidateDashboardBeforeSave(dashboard *models.Dashboard, overwrite bool) (
 	bool, error) {
+	if s.latestVersion > 0 && dashboard.Version != s.latestVersion && !overwrite {
+		return false, models.ErrDashboardVersionMismatch
+	}
 	return false, s.validationError
 }
 
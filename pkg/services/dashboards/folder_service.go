@@ -10,6 +10,7 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/guardian"
 	"github.com/grafana/grafana/pkg/services/search"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 // FolderService is a service for operating on folders.
@@ -18,8 +19,9 @@ type FolderService interface {
 	GetFolderByID(ctx context.Context, id int64) (*models.Folder, error)
 	GetFolderByUID(ctx context.Context, uid string) (*models.Folder, error)
 	GetFolderByTitle(ctx context.Context, title string) (*models.Folder, error)
-	CreateFolder(ctx context.Context, title, uid string) (*models.Folder, error)
+	CreateFolder(ctx context.Context, title, uid, parentUID string) (*models.Folder, error)
 	UpdateFolder(ctx context.Context, uid string, cmd *models.UpdateFolderCommand) error
+	MoveFolder(ctx context.Context, uid string, cmd *models.MoveFolderCommand) error
 	DeleteFolder(ctx context.Context, uid string, forceDeleteRules bool) (*models.Folder, error)
 	MakeUserAdmin(ctx context.Context, orgID int64, userID, folderID int64, setViewAndEditPermissions bool) error
 }
@@ -53,9 +55,12 @@ func (dr *dashboardServiceImpl) GetFolders(ctx context.Context, limit int64, pag
 
 	for _, hit := range searchQuery.Result {
 		folders = append(folders, &models.Folder{
-			Id:    hit.ID,
-			Uid:   hit.UID,
-			Title: hit.Title,
+			Id:           hit.ID,
+			Uid:          hit.UID,
+			Title:        hit.Title,
+			ParentId:     hit.FolderID,
+			ParentUid:    hit.FolderUID,
+			ParentTitles: hit.FolderPath,
 		})
 	}
 
@@ -119,10 +124,19 @@ func (dr *dashboardServiceImpl) GetFolderByTitle(ctx context.Context, title stri
 	return dashToFolder(dashFolder), nil
 }
 
-func (dr *dashboardServiceImpl) CreateFolder(ctx context.Context, title, uid string) (*models.Folder, error) {
+// CreateFolder creates a folder in the parent folder, or in the General folder
+// if parentUID is empty.
+func (dr *dashboardServiceImpl) CreateFolder(ctx context.Context, title, uid, parentUID string) (*models.Folder, error) {
 	dashFolder := models.NewDashboardFolder(title)
 	dashFolder.OrgId = dr.orgId
 	dashFolder.SetUid(strings.TrimSpace(uid))
+	if parentUID != "" {
+		parent, err := getFolder(ctx, models.GetDashboardQuery{OrgId: dr.orgId, Uid: parentUID})
+		if err != nil {
+			return nil, toFolderError(err)
+		}
+		dashFolder.FolderId = parent.Id
+	}
 	userID := dr.user.UserId
 	if userID == 0 {
 		userID = -1
@@ -193,6 +207,59 @@ func (dr *dashboardServiceImpl) UpdateFolder(ctx context.Context, existingUid st
 	return nil
 }
 
+// MoveFolder moves the folder with its subfolders and dashboards to another
+// parent folder. The user needs to be able to save the folder and to save in
+// the parent folder.
+func (dr *dashboardServiceImpl) MoveFolder(ctx context.Context, uid string, cmd *models.MoveFolderCommand) error {
+	dashFolder, err := getFolder(ctx, models.GetDashboardQuery{OrgId: dr.orgId, Uid: uid})
+	if err != nil {
+		return toFolderError(err)
+	}
+
+	g := guardian.New(ctx, dashFolder.Id, dr.orgId, dr.user)
+	if canSave, err := g.CanSave(); err != nil || !canSave {
+		if err != nil {
+			return toFolderError(err)
+		}
+		return models.ErrFolderAccessDenied
+	}
+
+	var parentID int64
+	if cmd.ParentUid != "" {
+		parent, err := getFolder(ctx, models.GetDashboardQuery{OrgId: dr.orgId, Uid: cmd.ParentUid})
+		if err != nil {
+			return toFolderError(err)
+		}
+		parentID = parent.Id
+	}
+	dashFolder.FolderId = parentID
+
+	// The parent folder is validated and checked for permissions as for
+	// dashboards moved to another folder.
+	dto := &SaveDashboardDTO{
+		Dashboard: dashFolder,
+		OrgId:     dr.orgId,
+		User:      dr.user,
+	}
+	saveDashboardCmd, err := dr.buildSaveDashboardCommand(dto, false, false)
+	if err != nil {
+		return toFolderError(err)
+	}
+
+	dash, err := dr.dashboardStore.SaveDashboard(*saveDashboardCmd)
+	if err != nil {
+		return toFolderError(err)
+	}
+
+	dashFolder, err = getFolder(ctx, models.GetDashboardQuery{OrgId: dr.orgId, Id: dash.Id})
+	if err != nil {
+		return toFolderError(err)
+	}
+
+	cmd.Result = dashToFolder(dashFolder)
+	return nil
+}
+
 func (dr *dashboardServiceImpl) DeleteFolder(ctx context.Context, uid string, forceDeleteRules bool) (*models.Folder, error) {
 	query := models.GetDashboardQuery{OrgId: dr.orgId, Uid: uid}
 	dashFolder, err := getFolder(ctx, query)
@@ -209,6 +276,10 @@ func (dr *dashboardServiceImpl) DeleteFolder(ctx context.Context, uid string, fo
 	}
 
 	deleteCmd := models.DeleteDashboardCommand{OrgId: dr.orgId, Id: dashFolder.Id, ForceDeleteFolderRules: forceDeleteRules}
+	if setting.DashboardTrashRetention > 0 {
+		deleteCmd.Trash = true
+		deleteCmd.DeletedBy = dr.user.UserId
+	}
 	if err := bus.Dispatch(&deleteCmd); err != nil {
 		return nil, toFolderError(err)
 	}
@@ -240,6 +311,7 @@ func dashToFolder(dash *models.Dashboard) *models.Folder {
 		CreatedBy: dash.CreatedBy,
 		Updated:   dash.Updated,
 		UpdatedBy: dash.UpdatedBy,
+		ParentId:  dash.FolderId,
 	}
 }
 
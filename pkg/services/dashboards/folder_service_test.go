@@ -55,7 +55,7 @@ func TestFolderService(t *testing.T) {
 			})
 
 			t.Run("When creating folder should return access denied error", func(t *testing.T) {
-				_, err := service.CreateFolder(context.Background(), "Folder", "")
+				_, err := service.CreateFolder(context.Background(), "Folder", "", "")
 				require.Equal(t, err, models.ErrFolderAccessDenied)
 			})
 
@@ -67,6 +67,11 @@ func TestFolderService(t *testing.T) {
 				require.Equal(t, err, models.ErrFolderAccessDenied)
 			})
 
+			t.Run("When moving folder should return access denied error", func(t *testing.T) {
+				err := service.MoveFolder(context.Background(), "uid", &models.MoveFolderCommand{ParentUid: "parent"})
+				require.Equal(t, err, models.ErrFolderAccessDenied)
+			})
+
 			t.Run("When deleting folder by uid should return access denied error", func(t *testing.T) {
 				_, err := service.DeleteFolder(context.Background(), "uid", false)
 				require.Error(t, err)
@@ -109,7 +114,7 @@ func TestFolderService(t *testing.T) {
 			})
 
 			t.Run("When creating folder should not return access denied error", func(t *testing.T) {
-				_, err := service.CreateFolder(context.Background(), "Folder", "")
+				_, err := service.CreateFolder(context.Background(), "Folder", "", "")
 				require.NoError(t, err)
 			})
 
@@ -121,6 +126,11 @@ func TestFolderService(t *testing.T) {
 				require.NoError(t, err)
 			})
 
+			t.Run("When moving folder should not return access denied error", func(t *testing.T) {
+				err := service.MoveFolder(context.Background(), "uid", &models.MoveFolderCommand{ParentUid: "parent"})
+				require.NoError(t, err)
+			})
+
 			t.Run("When deleting folder by uid should not return access denied error", func(t *testing.T) {
 				_, err := service.DeleteFolder(context.Background(), "uid", false)
 				require.NoError(t, err)
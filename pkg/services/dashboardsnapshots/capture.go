@@ -0,0 +1,640 @@
+package dashboardsnapshots
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/grafana/grafana/pkg/tsdb/grafanads"
+	"github.com/grafana/grafana/pkg/tsdb/intervalv2"
+)
+
+const (
+	mixedDatasourceName     = "-- Mixed --"
+	dashboardDatasourceName = "-- Dashboard --"
+	defaultMaxDataPoints    = 1000
+	allValue                = "$__all"
+)
+
+var (
+	ErrInvalidSnapshotTimeRange = errors.New("invalid snapshot time range")
+
+	// variableRegex matches $var, [[var]], [[var:format]], ${var} and ${var:format}
+	variableRegex = regexp.MustCompile(`\$(\w+)|\[\[(\w+?)(?::(\w+))?\]\]|\$\{(\w+)(?::([^}]+))?\}`)
+)
+
+// Capturer runs the queries of the panels of a dashboard server-side and
+// returns a snapshot model of the dashboard with the results embedded in
+// the panels, like the snapshots shared from the browser.
+type Capturer struct {
+	Cfg                    *setting.Cfg
+	DataService            *tsdb.Service
+	DataSourceCache        datasources.CacheService
+	PluginRequestValidator models.PluginRequestValidator
+}
+
+// CaptureOptions are the time range and variable values to capture a
+// dashboard with. Variables without a value use their current value in the
+// dashboard, and an empty time range the time range of the dashboard.
+type CaptureOptions struct {
+	User      *models.SignedInUser
+	Headers   map[string]string
+	SkipCache bool
+	From      string
+	To        string
+	Variables map[string][]string
+}
+
+// PanelError is the error of running the queries of a panel. The panel is
+// kept in the snapshot without data.
+type PanelError struct {
+	PanelId int64  `json:"panelId"`
+	Error   string `json:"error"`
+}
+
+type captureContext struct {
+	opts      CaptureOptions
+	timeRange plugins.DataTimeRange
+	variables map[string][]string
+}
+
+// Capture returns the snapshot model of the dashboard and the errors of the
+// panels whose queries failed.
+func (c *Capturer) Capture(ctx context.Context, dashboard *simplejson.Json, opts CaptureOptions) (*simplejson.Json, []PanelError, error) {
+	raw, err := dashboard.Encode()
+	if err != nil {
+		return nil, nil, err
+	}
+	dash, err := simplejson.NewJson(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if opts.From == "" {
+		opts.From = dash.GetPath("time", "from").MustString("now-6h")
+	}
+	if opts.To == "" {
+		opts.To = dash.GetPath("time", "to").MustString("now")
+	}
+	timeRange := plugins.NewDataTimeRange(opts.From, opts.To)
+	from, err := timeRange.ParseFrom()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrInvalidSnapshotTimeRange, err)
+	}
+	to, err := timeRange.ParseTo()
+	if err != nil || !from.Before(to) {
+		return nil, nil, ErrInvalidSnapshotTimeRange
+	}
+
+	cc := &captureContext{
+		opts:      opts,
+		timeRange: timeRange,
+		variables: resolveVariables(dash, opts.Variables),
+	}
+	cc.variables["__from"] = []string{fmt.Sprint(timeRange.GetFromAsMsEpoch())}
+	cc.variables["__to"] = []string{fmt.Sprint(timeRange.GetToAsMsEpoch())}
+	cc.variables["__range_ms"] = []string{fmt.Sprint(to.Sub(from).Milliseconds())}
+	cc.variables["__range_s"] = []string{fmt.Sprint(int64(to.Sub(from).Seconds()))}
+	cc.variables["__range"] = []string{fmt.Sprintf("%ds", int64(to.Sub(from).Seconds()))}
+	cc.variables["__dashboard"] = []string{dash.Get("title").MustString()}
+
+	panels := flattenPanels(dash)
+	snapshotData := make(map[int64][]interface{}, len(panels))
+	panelErrors := make([]PanelError, 0)
+	for _, panel := range panels {
+		if panelDatasourceName(panel) == dashboardDatasourceName || len(panel.Get("targets").MustArray()) == 0 {
+			continue
+		}
+		frames, err := c.capturePanel(ctx, cc, panel)
+		if err != nil {
+			panelErrors = append(panelErrors, PanelError{PanelId: panel.Get("id").MustInt64(), Error: err.Error()})
+			continue
+		}
+		snapshotData[panel.Get("id").MustInt64()] = frames
+	}
+
+	for _, panel := range panels {
+		frames, ok := snapshotData[panel.Get("id").MustInt64()]
+		if panelDatasourceName(panel) == dashboardDatasourceName {
+			// panels using the results of another panel
+			frames, ok = snapshotData[panel.Get("targets").GetIndex(0).Get("panelId").MustInt64()]
+		}
+		if ok {
+			panel.Set("snapshotData", frames)
+		}
+	}
+
+	scrubDashboard(dash, cc)
+	dash.Set("snapshot", map[string]interface{}{
+		"timestamp": time.Now().UTC(),
+	})
+	return dash, panelErrors, nil
+}
+
+// capturePanel runs the queries of the panel and returns the frames of the
+// results in the snapshot data format of the frontend.
+func (c *Capturer) capturePanel(ctx context.Context, cc *captureContext, panel *simplejson.Json) ([]interface{}, error) {
+	panelDsName := interpolate(panelDatasourceName(panel), cc.variables, "")
+
+	type panelQuery struct {
+		model *simplejson.Json
+		ds    *models.DataSource
+	}
+	queries := make([]panelQuery, 0)
+	hidden := make(map[string]bool)
+	hasExpression := false
+	for _, target := range panel.Get("targets").MustArray() {
+		dsRef := simplejson.NewFromAny(interpolateValue(simplejson.NewFromAny(target).Get("datasource").Interface(), cc.variables, ""))
+		dsName := panelDsName
+		if panelDsName == mixedDatasourceName || dsRef.Interface() != nil {
+			dsName = datasourceName(dsRef)
+		}
+
+		var ds *models.DataSource
+		dsType := ""
+		if dsName != expr.DatasourceName {
+			var err error
+			ds, err = c.getDataSource(ctx, cc, dsName, dsRef, panel.Get("datasource"))
+			if err != nil {
+				return nil, err
+			}
+			dsType = ds.Type
+		}
+
+		// variables are formatted for the data source of the query
+		model := simplejson.NewFromAny(interpolateValue(target, cc.variables, dsType))
+		refID := model.Get("refId").MustString("A")
+		if model.Get("hide").MustBool() {
+			hidden[refID] = true
+		}
+
+		if ds == nil {
+			hasExpression = true
+			model.Set("datasource", expr.DatasourceName)
+			model.Set("datasourceId", expr.DatasourceID)
+			queries = append(queries, panelQuery{model: model})
+			continue
+		}
+
+		model.Set("datasource", ds.Name)
+		model.Set("datasourceId", ds.Id)
+		queries = append(queries, panelQuery{model: model, ds: ds})
+	}
+
+	from, to := cc.timeRange.GetFromAsTimeUTC(), cc.timeRange.GetToAsTimeUTC()
+	maxDataPoints := panel.Get("maxDataPoints").MustInt64(defaultMaxDataPoints)
+	request := func(queries []panelQuery) (plugins.DataQuery, error) {
+		timeRange := cc.timeRange
+		req := plugins.DataQuery{
+			TimeRange: &timeRange,
+			User:      cc.opts.User,
+			Headers:   cc.opts.Headers,
+			Queries:   make([]plugins.DataSubQuery, 0, len(queries)),
+		}
+		for _, q := range queries {
+			dsInterval := ""
+			if q.ds != nil && q.ds.JsonData != nil {
+				dsInterval = q.ds.JsonData.Get("timeInterval").MustString()
+			}
+			minInterval, err := intervalv2.GetIntervalFrom(dsInterval, interpolate(panel.Get("interval").MustString(), cc.variables, ""), 0, time.Millisecond)
+			if err != nil {
+				return req, err
+			}
+			interval := intervalv2.NewCalculator().Calculate(backend.TimeRange{From: from, To: to}, minInterval, maxDataPoints)
+			intervalVars := map[string][]string{
+				"__interval":    {interval.Text},
+				"__interval_ms": {fmt.Sprint(interval.Milliseconds())},
+			}
+			model := simplejson.NewFromAny(interpolateValue(q.model.Interface(), intervalVars, ""))
+			model.Set("intervalMs", interval.Milliseconds())
+			model.Set("maxDataPoints", maxDataPoints)
+
+			req.Queries = append(req.Queries, plugins.DataSubQuery{
+				RefID:         model.Get("refId").MustString("A"),
+				MaxDataPoints: maxDataPoints,
+				IntervalMS:    interval.Milliseconds(),
+				QueryType:     model.Get("queryType").MustString(""),
+				Model:         model,
+				DataSource:    q.ds,
+			})
+		}
+		return req, nil
+	}
+
+	responses := make(map[string]backend.DataResponse)
+	if hasExpression {
+		// expressions need the queries of all data sources in one request
+		req, err := request(queries)
+		if err != nil {
+			return nil, err
+		}
+		qdr, err := (&expr.Service{Cfg: c.Cfg, DataService: c.DataService}).WrapTransformData(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		for refID, res := range qdr.Responses {
+			responses[refID] = res
+		}
+	} else {
+		byDatasource := make(map[int64][]panelQuery)
+		order := make([]int64, 0)
+		for _, q := range queries {
+			if hidden[q.model.Get("refId").MustString("A")] {
+				continue
+			}
+			if _, ok := byDatasource[q.ds.Id]; !ok {
+				order = append(order, q.ds.Id)
+			}
+			byDatasource[q.ds.Id] = append(byDatasource[q.ds.Id], q)
+		}
+		for _, dsID := range order {
+			dsQueries := byDatasource[dsID]
+			req, err := request(dsQueries)
+			if err != nil {
+				return nil, err
+			}
+			resp, err := c.DataService.HandleRequest(ctx, dsQueries[0].ds, req)
+			if err != nil {
+				return nil, err
+			}
+			qdr, err := resp.ToBackendDataResponse()
+			if err != nil {
+				return nil, err
+			}
+			for refID, res := range qdr.Responses {
+				responses[refID] = res
+			}
+		}
+	}
+
+	snapshotData := make([]interface{}, 0)
+	for _, q := range queries {
+		refID := q.model.Get("refId").MustString("A")
+		res, ok := responses[refID]
+		if !ok || hidden[refID] {
+			continue
+		}
+		if res.Error != nil {
+			return nil, fmt.Errorf("query %s: %w", refID, res.Error)
+		}
+		for _, frame := range res.Frames {
+			snapshotData = append(snapshotData, frameToSnapshotData(refID, frame))
+		}
+	}
+	return snapshotData, nil
+}
+
+// getDataSource returns the data source of the name, or the data source
+// referenced by uid if the name is empty, if the user can query it.
+func (c *Capturer) getDataSource(ctx context.Context, cc *captureContext, name string, refs ...*simplejson.Json) (*models.DataSource, error) {
+	orgID := cc.opts.User.OrgId
+	if name == grafanads.DatasourceName {
+		return grafanads.DataSourceModel(orgID), nil
+	}
+
+	var ds *models.DataSource
+	var err error
+	uid := ""
+	for _, ref := range refs {
+		if uid = ref.Get("uid").MustString(); uid != "" {
+			break
+		}
+	}
+	switch {
+	case name != "" && name != "default":
+		query := models.GetDataSourceQuery{Name: name, OrgId: orgID}
+		if err := bus.DispatchCtx(ctx, &query); err != nil {
+			return nil, fmt.Errorf("data source %q: %w", name, err)
+		}
+		ds, err = c.DataSourceCache.GetDatasource(query.Result.Id, cc.opts.User, cc.opts.SkipCache)
+	case uid != "":
+		ds, err = c.DataSourceCache.GetDatasourceByUID(uid, cc.opts.User, cc.opts.SkipCache)
+	default:
+		query := models.GetDefaultDataSourceQuery{OrgId: orgID, User: cc.opts.User}
+		if err := bus.Dispatch(&query); err != nil {
+			return nil, fmt.Errorf("default data source: %w", err)
+		}
+		ds, err = c.DataSourceCache.GetDatasource(query.Result.Id, cc.opts.User, cc.opts.SkipCache)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("data source %q: %w", name, err)
+	}
+
+	if err := c.PluginRequestValidator.Validate(ds.Url, nil); err != nil {
+		return nil, fmt.Errorf("data source %q: %w", ds.Name, models.ErrDataSourceAccessDenied)
+	}
+	return ds, nil
+}
+
+// resolveVariables returns the values of the dashboard variables, the given
+// values overriding the current ones, and sets them as the current values.
+func resolveVariables(dash *simplejson.Json, values map[string][]string) map[string][]string {
+	resolved := make(map[string][]string)
+	for _, v := range dash.GetPath("templating", "list").MustArray() {
+		variable := simplejson.NewFromAny(v)
+		name := variable.Get("name").MustString()
+		if name == "" || variable.Get("type").MustString() == "adhoc" {
+			continue
+		}
+
+		current, ok := values[name]
+		if ok {
+			text := strings.Join(current, " + ")
+			var value interface{} = current
+			if len(current) == 1 && !variable.Get("multi").MustBool() {
+				value = current[0]
+			}
+			if len(current) == 1 && current[0] == allValue {
+				text = "All"
+			}
+			variable.Set("current", map[string]interface{}{"text": text, "value": value})
+		} else {
+			current = stringValues(variable.GetPath("current", "value").Interface())
+		}
+
+		if len(current) == 1 && current[0] == allValue {
+			current = allValues(variable)
+		}
+		resolved[name] = current
+	}
+	return resolved
+}
+
+// allValues returns the values of the "All" option of the variable.
+func allValues(variable *simplejson.Json) []string {
+	if custom := variable.Get("allValue").MustString(); custom != "" {
+		return []string{custom}
+	}
+	values := make([]string, 0)
+	for _, o := range variable.Get("options").MustArray() {
+		for _, value := range stringValues(simplejson.NewFromAny(o).Get("value").Interface()) {
+			if value != allValue {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+func stringValues(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			values = append(values, fmt.Sprint(item))
+		}
+		return values
+	case nil:
+		return []string{}
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+// interpolateValue replaces the variables in the strings of a JSON value.
+func interpolateValue(value interface{}, variables map[string][]string, dsType string) interface{} {
+	switch v := value.(type) {
+	case string:
+		return interpolate(v, variables, dsType)
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			res[key] = interpolateValue(item, variables, dsType)
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(v))
+		for i, item := range v {
+			res[i] = interpolateValue(item, variables, dsType)
+		}
+		return res
+	default:
+		return v
+	}
+}
+
+// interpolate replaces the variables in the string. Several values of a
+// variable without a format are formatted for the data source type, a single
+// value is kept as is. Unknown variables are kept.
+func interpolate(s string, variables map[string][]string, dsType string) string {
+	return variableRegex.ReplaceAllStringFunc(s, func(match string) string {
+		groups := variableRegex.FindStringSubmatch(match)
+		name, format := groups[1], ""
+		switch {
+		case groups[2] != "":
+			name, format = groups[2], groups[3]
+		case groups[4] != "":
+			name, format = groups[4], groups[5]
+		}
+		values, ok := variables[name]
+		if !ok {
+			return match
+		}
+		if format == "" && len(values) > 1 {
+			return formatDefault(values, dsType)
+		}
+		return formatValues(values, format)
+	})
+}
+
+// formatDefault formats several values of a variable like the frontend does
+// in queries of the data source type.
+func formatDefault(values []string, dsType string) string {
+	switch dsType {
+	case "prometheus", "loki":
+		// the regex is in a string literal of the query, so backslashes are escaped
+		return strings.ReplaceAll(formatValues(values, "regex"), `\`, `\\`)
+	case "mysql", "postgres", "mssql":
+		return formatValues(values, "sqlstring")
+	default:
+		return formatValues(values, "glob")
+	}
+}
+
+// formatValues formats the values of a variable like the frontend does,
+// glob being the default format.
+func formatValues(values []string, format string) string {
+	switch format {
+	case "csv", "raw":
+		return strings.Join(values, ",")
+	case "pipe":
+		return strings.Join(values, "|")
+	case "regex":
+		quoted := make([]string, 0, len(values))
+		for _, value := range values {
+			quoted = append(quoted, regexp.QuoteMeta(value))
+		}
+		if len(quoted) == 1 {
+			return quoted[0]
+		}
+		return "(" + strings.Join(quoted, "|") + ")"
+	case "sqlstring":
+		quoted := make([]string, 0, len(values))
+		for _, value := range values {
+			quoted = append(quoted, "'"+strings.ReplaceAll(value, "'", "''")+"'")
+		}
+		return strings.Join(quoted, ",")
+	case "json":
+		b, _ := json.Marshal(values)
+		return string(b)
+	default:
+		if len(values) == 1 {
+			return values[0]
+		}
+		return "{" + strings.Join(values, ",") + "}"
+	}
+}
+
+// flattenPanels returns the panels of the dashboard including the panels of
+// collapsed rows.
+func flattenPanels(dash *simplejson.Json) []*simplejson.Json {
+	panels := make([]*simplejson.Json, 0)
+	for _, p := range dash.Get("panels").MustArray() {
+		panel := simplejson.NewFromAny(p)
+		panels = append(panels, panel)
+		for _, nested := range panel.Get("panels").MustArray() {
+			panels = append(panels, simplejson.NewFromAny(nested))
+		}
+	}
+	return panels
+}
+
+func panelDatasourceName(panel *simplejson.Json) string {
+	return datasourceName(panel.Get("datasource"))
+}
+
+// datasourceName returns the name of a data source reference, which is either
+// a name or an object with the uid of the data source.
+func datasourceName(ref *simplejson.Json) string {
+	if name, err := ref.String(); err == nil {
+		return name
+	}
+	if uid := ref.Get("uid").MustString(); uid == expr.DatasourceUID {
+		return expr.DatasourceName
+	} else if uid == grafanads.DatasourceUID {
+		return grafanads.DatasourceName
+	}
+	return ""
+}
+
+// scrubDashboard removes the queries from the snapshot like the frontend does
+// for snapshots shared from the browser.
+func scrubDashboard(dash *simplejson.Json, cc *captureContext) {
+	dash.Set("time", map[string]interface{}{"from": cc.opts.From, "to": cc.opts.To})
+
+	for _, panel := range flattenPanels(dash) {
+		if panel.Get("type").MustString() == "row" {
+			continue
+		}
+		panel.Set("targets", []interface{}{})
+		panel.Set("links", []interface{}{})
+		panel.Set("datasource", nil)
+	}
+
+	for _, v := range dash.GetPath("templating", "list").MustArray() {
+		variable := simplejson.NewFromAny(v)
+		variable.Set("query", "")
+		variable.Set("refresh", 0)
+		if current, ok := variable.CheckGet("current"); ok {
+			variable.Set("options", []interface{}{current.Interface()})
+		} else {
+			variable.Set("options", []interface{}{})
+		}
+	}
+
+	// annotations aren't captured
+	if annotations, ok := dash.CheckGet("annotations"); ok {
+		annotations.Set("list", []interface{}{})
+	}
+}
+
+// frameToSnapshotData converts the frame to the data frame format of panel
+// snapshot data in the frontend.
+func frameToSnapshotData(refID string, frame *data.Frame) map[string]interface{} {
+	fields := make([]interface{}, 0, len(frame.Fields))
+	for _, f := range frame.Fields {
+		values := make([]interface{}, f.Len())
+		for i := range values {
+			v, ok := f.ConcreteAt(i)
+			if !ok {
+				continue
+			}
+			switch value := v.(type) {
+			case time.Time:
+				v = value.UnixNano() / int64(time.Millisecond)
+			case float64:
+				if math.IsNaN(value) || math.IsInf(value, 0) {
+					v = nil
+				}
+			case float32:
+				if math.IsNaN(float64(value)) || math.IsInf(float64(value), 0) {
+					v = nil
+				}
+			}
+			values[i] = v
+		}
+
+		field := map[string]interface{}{
+			"name":   f.Name,
+			"type":   fieldType(f.Type()),
+			"config": map[string]interface{}{},
+			"values": values,
+		}
+		if f.Config != nil {
+			field["config"] = f.Config
+		}
+		if len(f.Labels) > 0 {
+			field["labels"] = f.Labels
+		}
+		fields = append(fields, field)
+	}
+
+	if frame.RefID != "" {
+		refID = frame.RefID
+	}
+	res := map[string]interface{}{
+		"name":   frame.Name,
+		"refId":  refID,
+		"fields": fields,
+	}
+	if frame.Meta != nil {
+		// executed queries can reveal details of data sources
+		meta := *frame.Meta
+		meta.ExecutedQueryString = ""
+		res["meta"] = &meta
+	}
+	return res
+}
+
+// fieldType returns the frontend field type of the field type.
+func fieldType(t data.FieldType) string {
+	switch {
+	case t.Time():
+		return "time"
+	case t.Numeric():
+		return "number"
+	case t == data.FieldTypeString || t == data.FieldTypeNullableString:
+		return "string"
+	case t == data.FieldTypeBool || t == data.FieldTypeNullableBool:
+		return "boolean"
+	default:
+		return "other"
+	}
+}
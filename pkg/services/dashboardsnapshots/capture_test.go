@@ -0,0 +1,166 @@
+package dashboardsnapshots
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterpolate(t *testing.T) {
+	variables := map[string][]string{
+		"host":   {"web-1"},
+		"region": {"eu", "us.east"},
+	}
+
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{query: "cpu{host=\"$host\"}", expected: "cpu{host=\"web-1\"}"},
+		{query: "[[host]] ${host}", expected: "web-1 web-1"},
+		{query: "$region", expected: "{eu,us.east}"},
+		{query: "${region:csv}", expected: "eu,us.east"},
+		{query: "${region:pipe}", expected: "eu|us.east"},
+		{query: "${region:regex}", expected: "(eu|us\\.east)"},
+		{query: "[[region:json]]", expected: `["eu","us.east"]`},
+		{query: "$unknown $__timeFilter(time)", expected: "$unknown $__timeFilter(time)"},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.expected, interpolate(tt.query, variables, ""), tt.query)
+	}
+}
+
+func TestInterpolateTargets(t *testing.T) {
+	variables := map[string][]string{
+		"host":   {"web-1"},
+		"region": {"eu", "us.east", "o'neil"},
+	}
+
+	t.Run("Prometheus target", func(t *testing.T) {
+		target := map[string]interface{}{
+			"refId": "A",
+			"expr":  `up{host="$host", region=~"$region"}`,
+		}
+		model := simplejson.NewFromAny(interpolateValue(target, variables, "prometheus"))
+		require.Equal(t, `up{host="web-1", region=~"(eu|us\\.east|o'neil)"}`, model.Get("expr").MustString())
+	})
+
+	t.Run("Loki target", func(t *testing.T) {
+		target := map[string]interface{}{"expr": `{region=~"$region"}`}
+		model := simplejson.NewFromAny(interpolateValue(target, variables, "loki"))
+		require.Equal(t, `{region=~"(eu|us\\.east|o'neil)"}`, model.Get("expr").MustString())
+	})
+
+	t.Run("SQL target", func(t *testing.T) {
+		target := map[string]interface{}{
+			"refId":  "A",
+			"rawSql": "SELECT * FROM hosts WHERE host = '$host' AND region IN ($region) AND name ~ '${region:regex}'",
+		}
+		for _, dsType := range []string{"mysql", "postgres", "mssql"} {
+			model := simplejson.NewFromAny(interpolateValue(target, variables, dsType))
+			require.Equal(t, `SELECT * FROM hosts WHERE host = 'web-1' AND region IN ('eu','us.east','o''neil') AND name ~ '(eu|us\.east|o'neil)'`,
+				model.Get("rawSql").MustString(), dsType)
+		}
+	})
+}
+
+func TestResolveVariables(t *testing.T) {
+	dash, err := simplejson.NewJson([]byte(`{
+		"templating": {"list": [
+			{"name": "host", "type": "query", "current": {"text": "web-1", "value": "web-1"}},
+			{"name": "env", "type": "custom", "multi": true, "current": {"text": "All", "value": ["$__all"]},
+				"options": [{"value": "$__all"}, {"value": "dev"}, {"value": "prod"}]},
+			{"name": "region", "type": "custom", "allValue": ".*", "current": {"text": "eu", "value": "eu"}},
+			{"name": "filters", "type": "adhoc"}
+		]}
+	}`))
+	require.NoError(t, err)
+
+	variables := resolveVariables(dash, map[string][]string{
+		"host":   {"web-2", "web-3"},
+		"region": {"$__all"},
+	})
+	require.Equal(t, map[string][]string{
+		"host":   {"web-2", "web-3"},
+		"env":    {"dev", "prod"},
+		"region": {".*"},
+	}, variables)
+
+	current := dash.GetPath("templating", "list").GetIndex(0).Get("current")
+	require.Equal(t, "web-2 + web-3", current.Get("text").MustString())
+	require.Equal(t, []string{"web-2", "web-3"}, current.Get("value").Interface())
+	require.Equal(t, "All", dash.GetPath("templating", "list").GetIndex(2).GetPath("current", "text").MustString())
+}
+
+func TestFrameToSnapshotData(t *testing.T) {
+	ts := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	value := 1.5
+	frame := data.NewFrame("cpu",
+		data.NewField("time", nil, []time.Time{ts, ts.Add(time.Minute)}),
+		data.NewField("value", data.Labels{"host": "web-1"}, []*float64{&value, nil}),
+		data.NewField("ratio", nil, []float64{math.NaN(), 2}),
+	)
+
+	res := frameToSnapshotData("A", frame)
+	require.Equal(t, "cpu", res["name"])
+	require.Equal(t, "A", res["refId"])
+
+	fields := res["fields"].([]interface{})
+	require.Len(t, fields, 3)
+	timeField := fields[0].(map[string]interface{})
+	require.Equal(t, "time", timeField["type"])
+	require.Equal(t, []interface{}{ts.UnixNano() / int64(time.Millisecond), ts.Add(time.Minute).UnixNano() / int64(time.Millisecond)}, timeField["values"])
+
+	valueField := fields[1].(map[string]interface{})
+	require.Equal(t, "number", valueField["type"])
+	require.Equal(t, data.Labels{"host": "web-1"}, valueField["labels"])
+	require.Equal(t, []interface{}{1.5, nil}, valueField["values"])
+
+	require.Equal(t, []interface{}{nil, 2.0}, fields[2].(map[string]interface{})["values"])
+	require.Nil(t, res["meta"])
+
+	frame.Meta = &data.FrameMeta{
+		ExecutedQueryString: "SELECT value FROM cpu WHERE host = 'web-1'",
+		Notices:             []data.Notice{{Text: "limited"}},
+	}
+	res = frameToSnapshotData("A", frame)
+	meta := res["meta"].(*data.FrameMeta)
+	require.Empty(t, meta.ExecutedQueryString)
+	require.Equal(t, frame.Meta.Notices, meta.Notices)
+	require.NotEmpty(t, frame.Meta.ExecutedQueryString)
+}
+
+func TestScrubDashboard(t *testing.T) {
+	dash, err := simplejson.NewJson([]byte(`{
+		"panels": [
+			{"id": 1, "datasource": "prometheus", "targets": [{"expr": "up"}], "links": [{"url": "/"}]},
+			{"id": 2, "type": "row", "collapsed": true, "panels": [
+				{"id": 3, "datasource": "prometheus", "targets": [{"expr": "up"}]}
+			]}
+		],
+		"templating": {"list": [{"name": "host", "query": "label_values(host)", "refresh": 1,
+			"current": {"text": "web-1", "value": "web-1"}, "options": [{"value": "web-1"}, {"value": "web-2"}]}]},
+		"annotations": {"list": [{"name": "Deploys", "enable": true}]}
+	}`))
+	require.NoError(t, err)
+
+	scrubDashboard(dash, &captureContext{opts: CaptureOptions{From: "now-1h", To: "now"}})
+
+	require.Equal(t, "now-1h", dash.GetPath("time", "from").MustString())
+	for _, panel := range flattenPanels(dash) {
+		if panel.Get("type").MustString() == "row" {
+			continue
+		}
+		require.Empty(t, panel.Get("targets").MustArray())
+		require.Nil(t, panel.Get("datasource").Interface())
+	}
+
+	variable := dash.GetPath("templating", "list").GetIndex(0)
+	require.Equal(t, "", variable.Get("query").MustString())
+	require.Len(t, variable.Get("options").MustArray(), 1)
+	require.Empty(t, dash.GetPath("annotations", "list").MustArray())
+}
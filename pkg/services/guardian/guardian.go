@@ -197,7 +197,8 @@ func (g *dashboardGuardianImpl) CheckPermissionBeforeUpdate(permission models.Pe
 	return g.checkAcl(permission, existingPermissions)
 }
 
-// GetAcl returns dashboard acl
+// GetAcl returns dashboard acl, including permissions inherited from the
+// nearest parent folder with permissions set
 func (g *dashboardGuardianImpl) GetAcl() ([]*models.DashboardAclInfoDTO, error) {
 	if g.acl != nil {
 		return g.acl, nil
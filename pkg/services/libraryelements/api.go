@@ -6,6 +6,7 @@ import (
 	"github.com/go-macaron/binding"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
 	"github.com/grafana/grafana/pkg/middleware"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/web"
@@ -20,6 +21,10 @@ func (l *LibraryElementService) registerAPIEndpoints() {
 		entities.Get("/:uid/connections/", middleware.ReqSignedIn, routing.Wrap(l.getConnectionsHandler))
 		entities.Get("/name/:name", middleware.ReqSignedIn, routing.Wrap(l.getByNameHandler))
 		entities.Patch("/:uid", middleware.ReqSignedIn, binding.Bind(patchLibraryElementCommand{}), routing.Wrap(l.patchHandler))
+		entities.Get("/:uid/versions", middleware.ReqSignedIn, routing.Wrap(l.getVersionsHandler))
+		entities.Get("/:uid/versions/:version", middleware.ReqSignedIn, routing.Wrap(l.getVersionHandler))
+		entities.Post("/:uid/calculate-diff", middleware.ReqSignedIn, binding.Bind(calculateLibraryElementDiffCommand{}), routing.Wrap(l.calculateDiffHandler))
+		entities.Post("/:uid/restore", middleware.ReqSignedIn, binding.Bind(restoreLibraryElementVersionCommand{}), routing.Wrap(l.restoreHandler))
 	})
 }
 
@@ -80,7 +85,63 @@ func (l *LibraryElementService) patchHandler(c *models.ReqContext, cmd patchLibr
 		return toLibraryElementError(err, "Failed to update library element")
 	}
 
-	return response.JSON(200, LibraryElementResponse{Result: element})
+	return l.patchResponse(c, element)
+}
+
+// getVersionsHandler handles GET /api/library-elements/:uid/versions.
+func (l *LibraryElementService) getVersionsHandler(c *models.ReqContext) response.Response {
+	versions, err := l.getLibraryElementVersions(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"],
+		c.QueryInt("limit"), c.QueryInt("start"))
+	if err != nil {
+		return toLibraryElementError(err, "Failed to get library element versions")
+	}
+
+	return response.JSON(200, LibraryElementVersionsResponse{Result: versions})
+}
+
+// getVersionHandler handles GET /api/library-elements/:uid/versions/:version.
+func (l *LibraryElementService) getVersionHandler(c *models.ReqContext) response.Response {
+	version, err := l.getLibraryElementVersionByNumber(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"],
+		c.ParamsInt64(":version"))
+	if err != nil {
+		return toLibraryElementError(err, "Failed to get library element version")
+	}
+
+	return response.JSON(200, LibraryElementVersionResponse{Result: version})
+}
+
+// calculateDiffHandler handles POST /api/library-elements/:uid/calculate-diff.
+func (l *LibraryElementService) calculateDiffHandler(c *models.ReqContext, cmd calculateLibraryElementDiffCommand) response.Response {
+	result, err := l.calculateLibraryElementDiff(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"], cmd)
+	if err != nil {
+		return toLibraryElementError(err, "Unable to compute diff")
+	}
+
+	if dashdiffs.ParseDiffType(cmd.DiffType) == dashdiffs.DiffDelta {
+		return response.Respond(200, result.Delta).SetHeader("Content-Type", "application/json")
+	}
+
+	return response.Respond(200, result.Delta).SetHeader("Content-Type", "text/html")
+}
+
+// restoreHandler handles POST /api/library-elements/:uid/restore.
+func (l *LibraryElementService) restoreHandler(c *models.ReqContext, cmd restoreLibraryElementVersionCommand) response.Response {
+	element, err := l.restoreLibraryElementVersion(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"], cmd)
+	if err != nil {
+		return toLibraryElementError(err, "Failed to restore library element version")
+	}
+
+	return l.patchResponse(c, element)
+}
+
+// patchResponse returns the changed library element with the connected dashboards affected by the change.
+func (l *LibraryElementService) patchResponse(c *models.ReqContext, element LibraryElementDTO) response.Response {
+	dashboards, err := l.getConnectedDashboards(c.Req.Context(), c.SignedInUser, element.ID)
+	if err != nil {
+		return toLibraryElementError(err, "Failed to get connected dashboards")
+	}
+
+	return response.JSON(200, LibraryElementPatchResponse{Result: element, ConnectedDashboards: dashboards})
 }
 
 // getConnectionsHandler handles GET /api/library-panels/:uid/connections/.
@@ -131,5 +192,11 @@ func toLibraryElementError(err error, message string) response.Response {
 	if errors.Is(err, errLibraryElementUIDTooLong) {
 		return response.Error(400, errLibraryElementUIDTooLong.Error(), err)
 	}
+	if errors.Is(err, errLibraryElementVersionNotFound) {
+		return response.Error(404, errLibraryElementVersionNotFound.Error(), err)
+	}
+	if errors.Is(err, errLibraryElementVersionsIdentical) {
+		return response.Error(400, errLibraryElementVersionsIdentical.Error(), err)
+	}
 	return response.Error(500, message, err)
 }
@@ -26,9 +26,11 @@ type Service interface {
 	CreateElement(c context.Context, signedInUser *models.SignedInUser, cmd CreateLibraryElementCommand) (LibraryElementDTO, error)
 	GetElement(c context.Context, signedInUser *models.SignedInUser, UID string) (LibraryElementDTO, error)
 	GetElementsForDashboard(c context.Context, dashboardID int64) (map[string]LibraryElementDTO, error)
+	GetConnections(c context.Context, signedInUser *models.SignedInUser, UID string) ([]LibraryElementConnectionDTO, error)
 	ConnectElementsToDashboard(c context.Context, signedInUser *models.SignedInUser, elementUIDs []string, dashboardID int64) error
 	DisconnectElementsFromDashboard(c context.Context, dashboardID int64) error
 	DeleteLibraryElementsInFolder(c context.Context, signedInUser *models.SignedInUser, folderUID string) error
+	DeleteExpiredVersions(c context.Context) (int64, error)
 }
 
 // LibraryElementService is the service for the Library Element feature.
@@ -54,6 +56,11 @@ func (l *LibraryElementService) GetElementsForDashboard(c context.Context, dashb
 	return l.getElementsForDashboardID(c, dashboardID)
 }
 
+// GetConnections gets the dashboards connected to an element that the user can view.
+func (l *LibraryElementService) GetConnections(c context.Context, signedInUser *models.SignedInUser, UID string) ([]LibraryElementConnectionDTO, error) {
+	return l.getConnections(c, signedInUser, UID)
+}
+
 // ConnectElementsToDashboard connects elements to a specific dashboard.
 func (l *LibraryElementService) ConnectElementsToDashboard(c context.Context, signedInUser *models.SignedInUser, elementUIDs []string, dashboardID int64) error {
 	return l.connectElementsToDashboardID(c, signedInUser, elementUIDs, dashboardID)
@@ -68,3 +75,8 @@ func (l *LibraryElementService) DisconnectElementsFromDashboard(c context.Contex
 func (l *LibraryElementService) DeleteLibraryElementsInFolder(c context.Context, signedInUser *models.SignedInUser, folderUID string) error {
 	return l.deleteLibraryElementsInFolderUID(c, signedInUser, folderUID)
 }
+
+// DeleteExpiredVersions deletes the oldest versions of all elements, keeping the configured number of versions.
+func (l *LibraryElementService) DeleteExpiredVersions(c context.Context) (int64, error) {
+	return l.deleteExpiredLibraryElementVersions(c)
+}
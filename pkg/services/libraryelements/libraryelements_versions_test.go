@@ -0,0 +1,150 @@
+package libraryelements
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLibraryElementVersions(t *testing.T) {
+	scenarioWithPanel(t, "When an admin creates a library panel, it should store the initial version",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			result := validateAndUnMarshalVersionsResponse(t, sc.service.getVersionsHandler(sc.reqContext))
+			require.Len(t, result.Result, 1)
+			require.Equal(t, int64(1), result.Result[0].Version)
+			require.Equal(t, "Initial save", result.Result[0].Message)
+			require.Equal(t, "Text - Library Panel", result.Result[0].Name)
+			require.Empty(t, result.Result[0].Model)
+		})
+
+	scenarioWithPanel(t, "When an admin patches a library panel, it should store a new version",
+		func(t *testing.T, sc scenarioContext) {
+			patchPanelModel(t, sc, 1, `{"title": "Patched", "type": "graph"}`, "Change type")
+
+			result := validateAndUnMarshalVersionsResponse(t, sc.service.getVersionsHandler(sc.reqContext))
+			require.Len(t, result.Result, 2)
+			require.Equal(t, int64(2), result.Result[0].Version)
+			require.Equal(t, int64(1), result.Result[0].ParentVersion)
+			require.Equal(t, "Change type", result.Result[0].Message)
+			require.Equal(t, int64(1), result.Result[1].Version)
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID, ":version": "2"})
+			resp := sc.service.getVersionHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+			var version LibraryElementVersionResponse
+			require.NoError(t, json.Unmarshal(resp.Body(), &version))
+			require.Equal(t, int64(2), version.Result.Version)
+			require.JSONEq(t, `{"description": "A description", "title": "Patched", "type": "graph"}`, string(version.Result.Model))
+		})
+
+	scenarioWithPanel(t, "When an admin gets a library panel version that does not exist, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID, ":version": "5"})
+			resp := sc.service.getVersionHandler(sc.reqContext)
+			require.Equal(t, 404, resp.Status())
+		})
+
+	scenarioWithPanel(t, "When an admin calculates the diff of two library panel versions, it should return the delta",
+		func(t *testing.T, sc scenarioContext) {
+			patchPanelModel(t, sc, 1, `{"title": "Patched", "type": "graph"}`, "")
+
+			cmd := calculateLibraryElementDiffCommand{Base: 1, New: 2, DiffType: "delta"}
+			resp := sc.service.calculateDiffHandler(sc.reqContext, cmd)
+			require.Equal(t, 200, resp.Status())
+			require.Contains(t, string(resp.Body()), "Patched")
+
+			cmd = calculateLibraryElementDiffCommand{Base: 2, New: 2, DiffType: "delta"}
+			resp = sc.service.calculateDiffHandler(sc.reqContext, cmd)
+			require.Equal(t, 400, resp.Status())
+		})
+
+	scenarioWithPanel(t, "When an admin restores a library panel version, it should store a new version with the restored model",
+		func(t *testing.T, sc scenarioContext) {
+			patchPanelModel(t, sc, 1, `{"title": "Patched", "type": "graph"}`, "")
+
+			resp := sc.service.restoreHandler(sc.reqContext, restoreLibraryElementVersionCommand{Version: 1})
+			result := validateAndUnMarshalResponse(t, resp)
+			require.Equal(t, int64(3), result.Result.Version)
+			require.Equal(t, "text", result.Result.Type)
+			require.Equal(t, "Text - Library Panel", result.Result.Model["title"])
+
+			versions := validateAndUnMarshalVersionsResponse(t, sc.service.getVersionsHandler(sc.reqContext))
+			require.Len(t, versions.Result, 3)
+			require.Equal(t, int64(1), versions.Result[0].RestoredFrom)
+			require.Equal(t, "Restored from version 1", versions.Result[0].Message)
+		})
+
+	scenarioWithPanel(t, "When an admin patches a connected library panel, it should return the connected dashboards",
+		func(t *testing.T, sc scenarioContext) {
+			dash := models.Dashboard{
+				Title: "Connected dashboard",
+				Data:  simplejson.NewFromAny(map[string]interface{}{}),
+			}
+			dashInDB := createDashboard(t, sc.sqlStore, sc.user, &dash, sc.folder.Id)
+			err := sc.service.ConnectElementsToDashboard(sc.reqContext.Req.Context(), sc.reqContext.SignedInUser,
+				[]string{sc.initialResult.Result.UID}, dashInDB.Id)
+			require.NoError(t, err)
+
+			result := patchPanelModel(t, sc, 1, `{"title": "Patched", "type": "graph"}`, "")
+			require.Len(t, result.ConnectedDashboards, 1)
+			require.Equal(t, dashInDB.Uid, result.ConnectedDashboards[0].UID)
+			require.Equal(t, "Connected dashboard", result.ConnectedDashboards[0].Title)
+		})
+
+	scenarioWithPanel(t, "When expired versions are deleted, it should keep the configured number of versions",
+		func(t *testing.T, sc scenarioContext) {
+			origVersionsToKeep := setting.LibraryPanelVersionsToKeep
+			t.Cleanup(func() {
+				setting.LibraryPanelVersionsToKeep = origVersionsToKeep
+			})
+			setting.LibraryPanelVersionsToKeep = 2
+
+			patchPanelModel(t, sc, 1, `{"title": "Patched", "type": "graph"}`, "")
+			patchPanelModel(t, sc, 2, `{"title": "Patched again", "type": "graph"}`, "")
+
+			deleted, err := sc.service.DeleteExpiredVersions(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, int64(1), deleted)
+
+			versions := validateAndUnMarshalVersionsResponse(t, sc.service.getVersionsHandler(sc.reqContext))
+			require.Len(t, versions.Result, 2)
+			require.Equal(t, int64(3), versions.Result[0].Version)
+			require.Equal(t, int64(2), versions.Result[1].Version)
+		})
+}
+
+func patchPanelModel(t *testing.T, sc scenarioContext, version int64, model string, message string) LibraryElementPatchResponse {
+	t.Helper()
+
+	cmd := patchLibraryElementCommand{
+		FolderID: -1,
+		Model:    []byte(model),
+		Kind:     int64(models.PanelElement),
+		Version:  version,
+		Message:  message,
+	}
+	sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+	resp := sc.service.patchHandler(sc.reqContext, cmd)
+	require.Equal(t, 200, resp.Status())
+
+	var result LibraryElementPatchResponse
+	require.NoError(t, json.Unmarshal(resp.Body(), &result))
+	return result
+}
+
+func validateAndUnMarshalVersionsResponse(t *testing.T, resp response.Response) LibraryElementVersionsResponse {
+	t.Helper()
+
+	require.Equal(t, 200, resp.Status())
+	var result LibraryElementVersionsResponse
+	require.NoError(t, json.Unmarshal(resp.Body(), &result))
+	return result
+}
@@ -134,6 +134,59 @@ type LibraryElementConnectionDTO struct {
 	CreatedBy    LibraryElementDTOMetaUser `json:"createdBy"`
 }
 
+// libraryElementVersion is the model for versions of library elements.
+type libraryElementVersion struct {
+	ID            int64 `xorm:"pk autoincr 'id'"`
+	ElementID     int64 `xorm:"element_id"`
+	ParentVersion int64
+	RestoredFrom  int64
+	Version       int64
+	Name          string
+	Model         json.RawMessage
+	Message       string
+	Created       time.Time
+	CreatedBy     int64
+}
+
+// libraryElementVersionWithMeta is the model for versions of library elements with meta.
+type libraryElementVersionWithMeta struct {
+	ID             int64 `xorm:"pk autoincr 'id'"`
+	ElementID      int64 `xorm:"element_id"`
+	ParentVersion  int64
+	RestoredFrom   int64
+	Version        int64
+	Name           string
+	Model          json.RawMessage
+	Message        string
+	Created        time.Time
+	CreatedBy      int64
+	CreatedByName  string
+	CreatedByEmail string
+}
+
+// LibraryElementVersionDTO is the frontend DTO for versions of library elements.
+type LibraryElementVersionDTO struct {
+	ID            int64                     `json:"id"`
+	ElementID     int64                     `json:"elementId"`
+	ParentVersion int64                     `json:"parentVersion"`
+	RestoredFrom  int64                     `json:"restoredFrom"`
+	Version       int64                     `json:"version"`
+	Name          string                    `json:"name"`
+	Model         json.RawMessage           `json:"model,omitempty"`
+	Message       string                    `json:"message"`
+	Created       time.Time                 `json:"created"`
+	CreatedBy     LibraryElementDTOMetaUser `json:"createdBy"`
+}
+
+// LibraryElementConnectedDashboardDTO is the frontend DTO for dashboards connected to a library element.
+type LibraryElementConnectedDashboardDTO struct {
+	ID       int64  `json:"id"`
+	UID      string `json:"uid"`
+	Title    string `json:"title"`
+	URL      string `json:"url"`
+	FolderID int64  `json:"folderId"`
+}
+
 var (
 	// errLibraryElementAlreadyExists is an error for when the user tries to add a library element that already exists.
 	errLibraryElementAlreadyExists = errors.New("library element with that name or UID already exists")
@@ -153,6 +206,10 @@ var (
 	errLibraryElementInvalidUID = errors.New("uid contains illegal characters")
 	// errLibraryElementUIDTooLong is an error for when the uid of a library element is invalid
 	errLibraryElementUIDTooLong = errors.New("uid too long, max 40 characters")
+	// errLibraryElementVersionNotFound is an error for when a version of a library element can't be found.
+	errLibraryElementVersionNotFound = errors.New("library element version could not be found")
+	// errLibraryElementVersionsIdentical is an error for when the diff of two identical versions is requested.
+	errLibraryElementVersionsIdentical = errors.New("the library element versions are identical")
 )
 
 // Commands
@@ -174,6 +231,21 @@ type patchLibraryElementCommand struct {
 	Kind     int64           `json:"kind" binding:"Required"`
 	Version  int64           `json:"version" binding:"Required"`
 	UID      string          `json:"uid"`
+	Message  string          `json:"message"`
+
+	restoredFrom int64
+}
+
+// restoreLibraryElementVersionCommand is the command for restoring a version of a LibraryElement
+type restoreLibraryElementVersionCommand struct {
+	Version int64 `json:"version" binding:"Required"`
+}
+
+// calculateLibraryElementDiffCommand is the command for the diff of two versions of a LibraryElement
+type calculateLibraryElementDiffCommand struct {
+	Base     int64  `json:"base" binding:"Required"`
+	New      int64  `json:"new" binding:"Required"`
+	DiffType string `json:"diffType"`
 }
 
 // searchLibraryElementsQuery is the query used for searching for Elements
@@ -193,6 +265,13 @@ type LibraryElementResponse struct {
 	Result LibraryElementDTO `json:"result"`
 }
 
+// LibraryElementPatchResponse is a response struct for a patched LibraryElementDTO, with the dashboards
+// affected by the change.
+type LibraryElementPatchResponse struct {
+	Result              LibraryElementDTO                     `json:"result"`
+	ConnectedDashboards []LibraryElementConnectedDashboardDTO `json:"connectedDashboards"`
+}
+
 // LibraryElementSearchResponse is a response struct for LibraryElementSearchResult.
 type LibraryElementSearchResponse struct {
 	Result LibraryElementSearchResult `json:"result"`
@@ -207,3 +286,13 @@ type LibraryElementArrayResponse struct {
 type LibraryElementConnectionsResponse struct {
 	Result []LibraryElementConnectionDTO `json:"result"`
 }
+
+// LibraryElementVersionsResponse is a response struct for an array of LibraryElementVersionDTO.
+type LibraryElementVersionsResponse struct {
+	Result []LibraryElementVersionDTO `json:"result"`
+}
+
+// LibraryElementVersionResponse is a response struct for LibraryElementVersionDTO.
+type LibraryElementVersionResponse struct {
+	Result LibraryElementVersionDTO `json:"result"`
+}
@@ -0,0 +1,330 @@
+package libraryelements
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	maxLibraryElementVersionsToDeletePerBatch = 100
+	maxLibraryElementVersionDeletionBatches   = 50
+)
+
+// insertLibraryElementVersion stores the name and model of a library element as a version.
+func insertLibraryElementVersion(session *sqlstore.DBSession, element *LibraryElement, parentVersion int64,
+	restoredFrom int64, message string) error {
+	version := libraryElementVersion{
+		ElementID:     element.ID,
+		ParentVersion: parentVersion,
+		RestoredFrom:  restoredFrom,
+		Version:       element.Version,
+		Name:          element.Name,
+		Model:         element.Model,
+		Message:       message,
+		Created:       element.Updated,
+		CreatedBy:     element.UpdatedBy,
+	}
+	_, err := session.Insert(&version)
+	return err
+}
+
+// insertMissingLibraryElementVersion stores the version of a library element which was changed last
+// before versions were stored, so that later versions can be compared with it and it can be restored.
+func insertMissingLibraryElementVersion(session *sqlstore.DBSession, element LibraryElementWithMeta) error {
+	exists, err := session.Table(models.LibraryElementVersionTableName).
+		Where("element_id=? AND version=?", element.ID, element.Version).
+		Exist()
+	if err != nil || exists {
+		return err
+	}
+
+	return insertLibraryElementVersion(session, &LibraryElement{
+		ID:        element.ID,
+		Version:   element.Version,
+		Name:      element.Name,
+		Model:     element.Model,
+		Updated:   element.Updated,
+		UpdatedBy: element.UpdatedBy,
+	}, 0, 0, "")
+}
+
+// getLibraryElementForVersions gets a Library Element if the user can change it.
+func (l *LibraryElementService) getLibraryElementForVersions(c context.Context, session *sqlstore.DBSession,
+	signedInUser *models.SignedInUser, uid string) (LibraryElementWithMeta, error) {
+	element, err := getLibraryElement(l.SQLStore.Dialect, session, uid, signedInUser.OrgId)
+	if err != nil {
+		return LibraryElementWithMeta{}, err
+	}
+	if err := l.requirePermissionsOnFolder(c, signedInUser, element.FolderID); err != nil {
+		return LibraryElementWithMeta{}, err
+	}
+	return element, nil
+}
+
+// getLibraryElementVersion gets a version of a Library Element.
+func getLibraryElementVersion(dialect migrator.Dialect, session *sqlstore.DBSession, elementID int64, version int64) (libraryElementVersionWithMeta, error) {
+	versions := make([]libraryElementVersionWithMeta, 0)
+	err := session.Table(models.LibraryElementVersionTableName).Alias("lev").
+		Select("lev.*, u.login AS created_by_name, u.email AS created_by_email").
+		Join("LEFT", dialect.Quote("user")+" AS u", "lev.created_by = u.id").
+		Where("lev.element_id=? AND lev.version=?", elementID, version).
+		Find(&versions)
+	if err != nil {
+		return libraryElementVersionWithMeta{}, err
+	}
+	if len(versions) == 0 {
+		return libraryElementVersionWithMeta{}, errLibraryElementVersionNotFound
+	}
+
+	return versions[0], nil
+}
+
+// getLibraryElementVersions gets the versions of a Library Element, latest version first.
+func (l *LibraryElementService) getLibraryElementVersions(c context.Context, signedInUser *models.SignedInUser, uid string,
+	limit int, start int) ([]LibraryElementVersionDTO, error) {
+	if limit == 0 {
+		limit = 1000
+	}
+
+	result := make([]LibraryElementVersionDTO, 0)
+	err := l.SQLStore.WithDbSession(c, func(session *sqlstore.DBSession) error {
+		element, err := l.getLibraryElementForVersions(c, session, signedInUser, uid)
+		if err != nil {
+			return err
+		}
+
+		versions := make([]libraryElementVersionWithMeta, 0)
+		err = session.Table(models.LibraryElementVersionTableName).Alias("lev").
+			Select(`lev.id, lev.element_id, lev.parent_version, lev.restored_from, lev.version, lev.name, lev.message,
+				lev.created, lev.created_by, u.login AS created_by_name, u.email AS created_by_email`).
+			Join("LEFT", l.SQLStore.Dialect.Quote("user")+" AS u", "lev.created_by = u.id").
+			Where("lev.element_id=?", element.ID).
+			OrderBy("lev.version DESC").
+			Limit(limit, start).
+			Find(&versions)
+		if err != nil {
+			return err
+		}
+
+		for _, version := range versions {
+			dto := toLibraryElementVersionDTO(version)
+			dto.Model = nil
+			if dto.Message == "" && dto.Version == 1 {
+				dto.Message = "Initial save"
+			}
+			result = append(result, dto)
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// getLibraryElementVersionByNumber gets a version of a Library Element.
+func (l *LibraryElementService) getLibraryElementVersionByNumber(c context.Context, signedInUser *models.SignedInUser,
+	uid string, version int64) (LibraryElementVersionDTO, error) {
+	var dto LibraryElementVersionDTO
+	err := l.SQLStore.WithDbSession(c, func(session *sqlstore.DBSession) error {
+		element, err := l.getLibraryElementForVersions(c, session, signedInUser, uid)
+		if err != nil {
+			return err
+		}
+
+		elementVersion, err := getLibraryElementVersion(l.SQLStore.Dialect, session, element.ID, version)
+		if err != nil {
+			return err
+		}
+
+		dto = toLibraryElementVersionDTO(elementVersion)
+		return nil
+	})
+
+	return dto, err
+}
+
+// calculateLibraryElementDiff computes the diff of the models of two versions of a Library Element.
+func (l *LibraryElementService) calculateLibraryElementDiff(c context.Context, signedInUser *models.SignedInUser,
+	uid string, cmd calculateLibraryElementDiffCommand) (*dashdiffs.Result, error) {
+	var baseModel, newModel *simplejson.Json
+	err := l.SQLStore.WithDbSession(c, func(session *sqlstore.DBSession) error {
+		element, err := l.getLibraryElementForVersions(c, session, signedInUser, uid)
+		if err != nil {
+			return err
+		}
+
+		baseVersion, err := getLibraryElementVersion(l.SQLStore.Dialect, session, element.ID, cmd.Base)
+		if err != nil {
+			return err
+		}
+		newVersion, err := getLibraryElementVersion(l.SQLStore.Dialect, session, element.ID, cmd.New)
+		if err != nil {
+			return err
+		}
+
+		if baseModel, err = simplejson.NewJson(baseVersion.Model); err != nil {
+			return err
+		}
+		newModel, err = simplejson.NewJson(newVersion.Model)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := dashdiffs.CalculateDataDiff(baseModel, newModel, dashdiffs.ParseDiffType(cmd.DiffType))
+	if errors.Is(err, dashdiffs.ErrNilDiff) {
+		return nil, errLibraryElementVersionsIdentical
+	}
+	return result, err
+}
+
+// restoreLibraryElementVersion restores the name and model of a Library Element to a version.
+func (l *LibraryElementService) restoreLibraryElementVersion(c context.Context, signedInUser *models.SignedInUser,
+	uid string, cmd restoreLibraryElementVersionCommand) (LibraryElementDTO, error) {
+	var patchCmd patchLibraryElementCommand
+	err := l.SQLStore.WithDbSession(c, func(session *sqlstore.DBSession) error {
+		element, err := l.getLibraryElementForVersions(c, session, signedInUser, uid)
+		if err != nil {
+			return err
+		}
+
+		version, err := getLibraryElementVersion(l.SQLStore.Dialect, session, element.ID, cmd.Version)
+		if err != nil {
+			return err
+		}
+
+		patchCmd = patchLibraryElementCommand{
+			FolderID:     -1,
+			Name:         version.Name,
+			Model:        version.Model,
+			Kind:         element.Kind,
+			Version:      element.Version,
+			Message:      fmt.Sprintf("Restored from version %d", version.Version),
+			restoredFrom: version.Version,
+		}
+		return nil
+	})
+	if err != nil {
+		return LibraryElementDTO{}, err
+	}
+
+	return l.patchLibraryElement(c, signedInUser, patchCmd, uid)
+}
+
+// getConnectedDashboards gets the dashboards connected to a Library Element that the user can view.
+func (l *LibraryElementService) getConnectedDashboards(c context.Context, signedInUser *models.SignedInUser,
+	elementID int64) ([]LibraryElementConnectedDashboardDTO, error) {
+	result := make([]LibraryElementConnectedDashboardDTO, 0)
+	err := l.SQLStore.WithDbSession(c, func(session *sqlstore.DBSession) error {
+		var dashboards []*models.Dashboard
+		builder := sqlstore.SQLBuilder{}
+		builder.Write("SELECT dashboard.id, dashboard.uid, dashboard.title, dashboard.slug, dashboard.folder_id")
+		builder.Write(" FROM " + models.LibraryElementConnectionTableName + " AS lec")
+		builder.Write(" INNER JOIN dashboard AS dashboard on lec.connection_id = dashboard.id")
+		builder.Write(" WHERE lec.element_id=? AND lec.kind=?", elementID, int64(Dashboard))
+		if signedInUser.OrgRole != models.ROLE_ADMIN {
+			builder.WriteDashboardPermissionFilter(signedInUser, models.PERMISSION_VIEW)
+		}
+		builder.Write(" ORDER BY dashboard.title ASC")
+		if err := session.SQL(builder.GetSQLString(), builder.GetParams()...).Find(&dashboards); err != nil {
+			return err
+		}
+
+		for _, dashboard := range dashboards {
+			result = append(result, LibraryElementConnectedDashboardDTO{
+				ID:       dashboard.Id,
+				UID:      dashboard.Uid,
+				Title:    dashboard.Title,
+				URL:      models.GetDashboardUrl(dashboard.Uid, dashboard.Slug),
+				FolderID: dashboard.FolderId,
+			})
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// deleteExpiredLibraryElementVersions deletes the oldest versions of Library Elements, keeping at least
+// setting.LibraryPanelVersionsToKeep versions of each Library Element.
+func (l *LibraryElementService) deleteExpiredLibraryElementVersions(c context.Context) (int64, error) {
+	versionsToKeep := setting.LibraryPanelVersionsToKeep
+	if versionsToKeep < 1 {
+		versionsToKeep = 1
+	}
+
+	var deletedRows int64
+	for batch := 0; batch < maxLibraryElementVersionDeletionBatches; batch++ {
+		deleted := int64(0)
+
+		err := l.SQLStore.WithTransactionalDbSession(c, func(session *sqlstore.DBSession) error {
+			versionIdsToDeleteQuery := `SELECT id
+				FROM ` + models.LibraryElementVersionTableName + `, (
+					SELECT element_id, count(version) as count, min(version) as min
+					FROM ` + models.LibraryElementVersionTableName + `
+					GROUP BY element_id
+				) AS vtd
+				WHERE ` + models.LibraryElementVersionTableName + `.element_id=vtd.element_id
+				AND version < vtd.min + vtd.count - ?
+				LIMIT ?`
+
+			var versionIdsToDelete []interface{}
+			err := session.SQL(versionIdsToDeleteQuery, versionsToKeep, maxLibraryElementVersionsToDeletePerBatch).Find(&versionIdsToDelete)
+			if err != nil {
+				return err
+			}
+
+			if len(versionIdsToDelete) < 1 {
+				return nil
+			}
+
+			deleteExpiredSQL := `DELETE FROM ` + models.LibraryElementVersionTableName + ` WHERE id IN (?` + strings.Repeat(",?", len(versionIdsToDelete)-1) + `)`
+			expiredResponse, err := session.Exec(append([]interface{}{deleteExpiredSQL}, versionIdsToDelete...)...)
+			if err != nil {
+				return err
+			}
+
+			deleted, err = expiredResponse.RowsAffected()
+			return err
+		})
+		if err != nil {
+			return deletedRows, err
+		}
+
+		deletedRows += deleted
+		if deleted < maxLibraryElementVersionsToDeletePerBatch {
+			break
+		}
+	}
+
+	return deletedRows, nil
+}
+
+func toLibraryElementVersionDTO(version libraryElementVersionWithMeta) LibraryElementVersionDTO {
+	return LibraryElementVersionDTO{
+		ID:            version.ID,
+		ElementID:     version.ElementID,
+		ParentVersion: version.ParentVersion,
+		RestoredFrom:  version.RestoredFrom,
+		Version:       version.Version,
+		Name:          version.Name,
+		Model:         version.Model,
+		Message:       version.Message,
+		Created:       version.Created,
+		CreatedBy: LibraryElementDTOMetaUser{
+			ID:        version.CreatedBy,
+			Name:      version.CreatedByName,
+			AvatarURL: dtos.GetGravatarUrl(version.CreatedByEmail),
+		},
+	}
+}
@@ -0,0 +1,82 @@
+package live
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/centrifugal/centrifuge"
+	"github.com/go-redis/redis/v8"
+	"github.com/grafana/grafana/pkg/services/live/managedstream"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// haPrefix is a prefix for all Live keys and channels in Redis.
+const haPrefix = "gf_live"
+
+// setupRedisHA configures HA with Redis. In this case Centrifuge nodes will be
+// connected over Redis PUB/SUB, so publications made on one Grafana instance reach
+// subscribers connected to all other instances. Presence will work globally since
+// kept inside Redis. Returned frame cache is shared between instances too, so
+// managed stream subscribers get actual frame schema on any instance.
+func setupRedisHA(node *centrifuge.Node, cfg *setting.Cfg) (managedstream.FrameCache, error) {
+	redisShardConfigs := []centrifuge.RedisShardConfig{
+		{
+			Address:  cfg.LiveHAEngineAddress,
+			Password: cfg.LiveHAEnginePassword,
+			DB:       cfg.LiveHAEngineDB,
+		},
+	}
+	var redisShards []*centrifuge.RedisShard
+	for _, redisConf := range redisShardConfigs {
+		redisShard, err := centrifuge.NewRedisShard(node, redisConf)
+		if err != nil {
+			return nil, fmt.Errorf("error connecting to Live Redis: %v", err)
+		}
+		redisShards = append(redisShards, redisShard)
+	}
+
+	broker, err := centrifuge.NewRedisBroker(node, centrifuge.RedisBrokerConfig{
+		Prefix: haPrefix,
+
+		// Use reasonably large expiration interval for stream meta key,
+		// much bigger than maximum HistoryLifetime value in Node config.
+		// This way stream meta data will expire, in some cases you may want
+		// to prevent its expiration setting this to zero value.
+		HistoryMetaTTL: 7 * 24 * time.Hour,
+
+		// And configure a couple of shards to use.
+		Shards: redisShards,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating Live Redis broker: %v", err)
+	}
+
+	presenceManager, err := centrifuge.NewRedisPresenceManager(node, centrifuge.RedisPresenceManagerConfig{
+		Prefix: haPrefix,
+		Shards: redisShards,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating Live Redis presence manager: %v", err)
+	}
+
+	setupHA(node, broker, presenceManager)
+
+	redisClient := redis.NewClient(&redis.Options{
+		Addr:     cfg.LiveHAEngineAddress,
+		Password: cfg.LiveHAEnginePassword,
+		DB:       cfg.LiveHAEngineDB,
+	})
+	cmd := redisClient.Ping(context.TODO())
+	if _, err := cmd.Result(); err != nil {
+		return nil, fmt.Errorf("error pinging Redis: %v", err)
+	}
+	return managedstream.NewRedisFrameCache(redisClient), nil
+}
+
+// setupHA makes node use a shared broker and presence manager. All publications
+// made through GrafanaLive.Publish and the pipeline go through the broker.
+func setupHA(node *centrifuge.Node, broker centrifuge.Broker, presenceManager centrifuge.PresenceManager) {
+	node.SetBroker(broker)
+	node.SetPresenceManager(presenceManager)
+}
@@ -0,0 +1,289 @@
+package live
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/centrifugal/centrifuge"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/services/live/liveplugin"
+	"github.com/grafana/grafana/pkg/services/live/managedstream"
+	"github.com/grafana/grafana/pkg/services/live/orgchannel"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryHABroker is an in-memory stand-in for Redis broker and presence manager
+// shared between several in-process Centrifuge nodes.
+type memoryHABroker struct {
+	mu       sync.Mutex
+	handlers map[*memoryHANode]centrifuge.BrokerEventHandler
+	subs     map[*memoryHANode]map[string]struct{}
+	presence map[string]map[string]*centrifuge.ClientInfo
+}
+
+func newMemoryHABroker() *memoryHABroker {
+	return &memoryHABroker{
+		handlers: map[*memoryHANode]centrifuge.BrokerEventHandler{},
+		subs:     map[*memoryHANode]map[string]struct{}{},
+		presence: map[string]map[string]*centrifuge.ClientInfo{},
+	}
+}
+
+// forNode returns broker and presence manager to use by a single node.
+func (b *memoryHABroker) forNode() *memoryHANode {
+	n := &memoryHANode{broker: b}
+	b.mu.Lock()
+	b.subs[n] = map[string]struct{}{}
+	b.mu.Unlock()
+	return n
+}
+
+type memoryHANode struct {
+	broker *memoryHABroker
+}
+
+func (n *memoryHANode) Run(h centrifuge.BrokerEventHandler) error {
+	n.broker.mu.Lock()
+	defer n.broker.mu.Unlock()
+	n.broker.handlers[n] = h
+	return nil
+}
+
+func (n *memoryHANode) Subscribe(ch string) error {
+	n.broker.mu.Lock()
+	defer n.broker.mu.Unlock()
+	n.broker.subs[n][ch] = struct{}{}
+	return nil
+}
+
+func (n *memoryHANode) Unsubscribe(ch string) error {
+	n.broker.mu.Lock()
+	defer n.broker.mu.Unlock()
+	delete(n.broker.subs[n], ch)
+	return nil
+}
+
+func (n *memoryHANode) subscribedHandlers(ch string) []centrifuge.BrokerEventHandler {
+	n.broker.mu.Lock()
+	defer n.broker.mu.Unlock()
+	var handlers []centrifuge.BrokerEventHandler
+	for node, h := range n.broker.handlers {
+		if _, ok := n.broker.subs[node][ch]; ok {
+			handlers = append(handlers, h)
+		}
+	}
+	return handlers
+}
+
+func (n *memoryHANode) Publish(ch string, data []byte, opts centrifuge.PublishOptions) (centrifuge.StreamPosition, error) {
+	for _, h := range n.subscribedHandlers(ch) {
+		if err := h.HandlePublication(ch, &centrifuge.Publication{Data: data, Info: opts.ClientInfo}, centrifuge.StreamPosition{}); err != nil {
+			return centrifuge.StreamPosition{}, err
+		}
+	}
+	return centrifuge.StreamPosition{}, nil
+}
+
+func (n *memoryHANode) PublishJoin(ch string, info *centrifuge.ClientInfo) error {
+	for _, h := range n.subscribedHandlers(ch) {
+		if err := h.HandleJoin(ch, info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *memoryHANode) PublishLeave(ch string, info *centrifuge.ClientInfo) error {
+	for _, h := range n.subscribedHandlers(ch) {
+		if err := h.HandleLeave(ch, info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *memoryHANode) PublishControl(data []byte, _, _ string) error {
+	n.broker.mu.Lock()
+	var handlers []centrifuge.BrokerEventHandler
+	for _, h := range n.broker.handlers {
+		handlers = append(handlers, h)
+	}
+	n.broker.mu.Unlock()
+	for _, h := range handlers {
+		if err := h.HandleControl(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *memoryHANode) History(_ string, _ centrifuge.HistoryFilter) ([]*centrifuge.Publication, centrifuge.StreamPosition, error) {
+	return nil, centrifuge.StreamPosition{}, nil
+}
+
+func (n *memoryHANode) RemoveHistory(_ string) error {
+	return nil
+}
+
+func (n *memoryHANode) Presence(ch string) (map[string]*centrifuge.ClientInfo, error) {
+	n.broker.mu.Lock()
+	defer n.broker.mu.Unlock()
+	result := map[string]*centrifuge.ClientInfo{}
+	for k, v := range n.broker.presence[ch] {
+		result[k] = v
+	}
+	return result, nil
+}
+
+func (n *memoryHANode) PresenceStats(ch string) (centrifuge.PresenceStats, error) {
+	p, _ := n.Presence(ch)
+	return centrifuge.PresenceStats{NumClients: len(p), NumUsers: len(p)}, nil
+}
+
+func (n *memoryHANode) AddPresence(ch string, clientID string, info *centrifuge.ClientInfo) error {
+	n.broker.mu.Lock()
+	defer n.broker.mu.Unlock()
+	if _, ok := n.broker.presence[ch]; !ok {
+		n.broker.presence[ch] = map[string]*centrifuge.ClientInfo{}
+	}
+	n.broker.presence[ch][clientID] = info
+	return nil
+}
+
+func (n *memoryHANode) RemovePresence(ch string, clientID string) error {
+	n.broker.mu.Lock()
+	defer n.broker.mu.Unlock()
+	delete(n.broker.presence[ch], clientID)
+	return nil
+}
+
+// testTransport is a unidirectional Centrifuge transport which collects pushes.
+type testTransport struct {
+	mu     sync.Mutex
+	pushes []string
+	ch     chan struct{}
+}
+
+func newTestTransport() *testTransport {
+	return &testTransport{ch: make(chan struct{}, 128)}
+}
+
+func (t *testTransport) Name() string                         { return "test" }
+func (t *testTransport) Protocol() centrifuge.ProtocolType    { return centrifuge.ProtocolTypeJSON }
+func (t *testTransport) Unidirectional() bool                 { return true }
+func (t *testTransport) DisabledPushFlags() uint64            { return 0 }
+func (t *testTransport) Close(_ *centrifuge.Disconnect) error { return nil }
+
+func (t *testTransport) Write(data []byte) error {
+	return t.WriteMany(data)
+}
+
+func (t *testTransport) WriteMany(data ...[]byte) error {
+	t.mu.Lock()
+	for _, d := range data {
+		t.pushes = append(t.pushes, string(d))
+	}
+	t.mu.Unlock()
+	t.ch <- struct{}{}
+	return nil
+}
+
+func (t *testTransport) waitPush(tb testing.TB, substr string) {
+	tb.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		t.mu.Lock()
+		for _, p := range t.pushes {
+			if strings.Contains(p, substr) {
+				t.mu.Unlock()
+				return
+			}
+		}
+		t.mu.Unlock()
+		select {
+		case <-t.ch:
+		case <-timeout:
+			tb.Fatalf("timeout waiting for push containing %q", substr)
+		}
+	}
+}
+
+func newHATestNode(t *testing.T, broker *memoryHABroker, subscribeTo string) *centrifuge.Node {
+	t.Helper()
+	node, err := centrifuge.New(centrifuge.DefaultConfig)
+	require.NoError(t, err)
+	haNode := broker.forNode()
+	setupHA(node, haNode, haNode)
+	node.OnConnecting(func(_ context.Context, _ centrifuge.ConnectEvent) (centrifuge.ConnectReply, error) {
+		reply := centrifuge.ConnectReply{}
+		if subscribeTo != "" {
+			reply.Subscriptions = map[string]centrifuge.SubscribeOptions{subscribeTo: {Presence: true}}
+		}
+		return reply, nil
+	})
+	node.OnConnect(func(_ *centrifuge.Client) {})
+	require.NoError(t, node.Run())
+	t.Cleanup(func() {
+		_ = node.Shutdown(context.Background())
+	})
+	return node
+}
+
+func connectTestClient(t *testing.T, node *centrifuge.Node) *testTransport {
+	t.Helper()
+	transport := newTestTransport()
+	ctx := centrifuge.SetCredentials(context.Background(), &centrifuge.Credentials{UserID: "1"})
+	client, closeFn, err := centrifuge.NewClient(ctx, node, transport)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = closeFn() })
+	client.Connect(centrifuge.ConnectRequest{})
+	return transport
+}
+
+func TestHA_PublishReachesSubscribersOnAllNodes(t *testing.T) {
+	broker := newMemoryHABroker()
+	channel := orgchannel.PrependOrgID(1, "stream/test/ha")
+
+	nodeA := newHATestNode(t, broker, "")
+	nodeB := newHATestNode(t, broker, channel)
+
+	transportB := connectTestClient(t, nodeB)
+
+	gA := &GrafanaLive{node: nodeA}
+	require.NoError(t, gA.Publish(1, "stream/test/ha", []byte(`{"value":"from-a"}`)))
+	transportB.waitPush(t, "from-a")
+
+	// Presence is shared between nodes.
+	gB := &GrafanaLive{node: nodeB}
+	count, err := gB.ClientCount(1, "stream/test/ha")
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+	count, err = gA.ClientCount(1, "stream/test/ha")
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+}
+
+func TestHA_ManagedStreamPushReachesSubscribersOnAllNodes(t *testing.T) {
+	broker := newMemoryHABroker()
+	channel := orgchannel.PrependOrgID(1, "stream/test/ha")
+
+	nodeA := newHATestNode(t, broker, "")
+	nodeB := newHATestNode(t, broker, channel)
+
+	transportB := connectTestClient(t, nodeB)
+
+	gA := &GrafanaLive{node: nodeA}
+	runnerA := managedstream.NewRunner(gA.Publish, liveplugin.NewChannelLocalPublisher(nodeA, nil), managedstream.NewMemoryFrameCache())
+	stream, err := runnerA.GetOrCreateStream(1, "stream", "test")
+	require.NoError(t, err)
+
+	frame := data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{time.Unix(1, 0)}),
+		data.NewField("value", nil, []string{"frame-from-a"}),
+	)
+	require.NoError(t, stream.Push("ha", frame))
+	transportB.waitPush(t, "frame-from-a")
+}
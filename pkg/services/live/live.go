@@ -14,9 +14,9 @@ import (
 	"time"
 
 	"github.com/centrifugal/centrifuge"
-	"github.com/go-redis/redis/v8"
 	"github.com/gobwas/glob"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana-plugin-sdk-go/live"
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
@@ -37,6 +37,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/live/orgchannel"
 	"github.com/grafana/grafana/pkg/services/live/pipeline"
 	"github.com/grafana/grafana/pkg/services/live/pushws"
+	"github.com/grafana/grafana/pkg/services/live/ratelimit"
 	"github.com/grafana/grafana/pkg/services/live/runstream"
 	"github.com/grafana/grafana/pkg/services/live/survey"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
@@ -80,6 +81,22 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		usageStatsService: usageStatsService,
 	}
 
+	limits := cfg.LivePublishLimits
+	g.PublishLimiter = ratelimit.NewLimiter(ratelimit.Config{
+		Org: ratelimit.Limits{
+			MessagesPerSecond: limits.OrgMessagesPerSecond,
+			BytesPerSecond:    limits.OrgBytesPerSecond,
+		},
+		Channel: ratelimit.Limits{
+			MessagesPerSecond: limits.ChannelMessagesPerSecond,
+			BytesPerSecond:    limits.ChannelBytesPerSecond,
+		},
+		Connection: ratelimit.Limits{
+			MessagesPerSecond: limits.ConnectionMessagesPerSecond,
+			BytesPerSecond:    limits.ConnectionBytesPerSecond,
+		},
+	})
+
 	logger.Debug("GrafanaLive initialization", "ha", g.IsHA())
 
 	// We use default config here as starting point. Default config contains
@@ -100,73 +117,23 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 	}
 	g.node = node
 
+	var frameCache managedstream.FrameCache
 	if g.IsHA() {
-		// Configure HA with Redis. In this case Centrifuge nodes
-		// will be connected over Redis PUB/SUB. Presence will work
-		// globally since kept inside Redis.
-		redisAddress := g.Cfg.LiveHAEngineAddress
-		redisShardConfigs := []centrifuge.RedisShardConfig{
-			{Address: redisAddress},
-		}
-		var redisShards []*centrifuge.RedisShard
-		for _, redisConf := range redisShardConfigs {
-			redisShard, err := centrifuge.NewRedisShard(node, redisConf)
-			if err != nil {
-				return nil, fmt.Errorf("error connecting to Live Redis: %v", err)
-			}
-			redisShards = append(redisShards, redisShard)
-		}
-
-		broker, err := centrifuge.NewRedisBroker(node, centrifuge.RedisBrokerConfig{
-			Prefix: "gf_live",
-
-			// Use reasonably large expiration interval for stream meta key,
-			// much bigger than maximum HistoryLifetime value in Node config.
-			// This way stream meta data will expire, in some cases you may want
-			// to prevent its expiration setting this to zero value.
-			HistoryMetaTTL: 7 * 24 * time.Hour,
-
-			// And configure a couple of shards to use.
-			Shards: redisShards,
-		})
+		frameCache, err = setupRedisHA(node, g.Cfg)
 		if err != nil {
-			return nil, fmt.Errorf("error creating Live Redis broker: %v", err)
-		}
-		node.SetBroker(broker)
-
-		presenceManager, err := centrifuge.NewRedisPresenceManager(node, centrifuge.RedisPresenceManagerConfig{
-			Prefix: "gf_live",
-			Shards: redisShards,
-		})
-		if err != nil {
-			return nil, fmt.Errorf("error creating Live Redis presence manager: %v", err)
+			return nil, err
 		}
-		node.SetPresenceManager(presenceManager)
+	} else {
+		frameCache = managedstream.NewMemoryFrameCache()
 	}
 
 	channelLocalPublisher := liveplugin.NewChannelLocalPublisher(node, nil)
 
-	var managedStreamRunner *managedstream.Runner
-	if g.IsHA() {
-		redisClient := redis.NewClient(&redis.Options{
-			Addr: g.Cfg.LiveHAEngineAddress,
-		})
-		cmd := redisClient.Ping(context.TODO())
-		if _, err := cmd.Result(); err != nil {
-			return nil, fmt.Errorf("error pinging Redis: %v", err)
-		}
-		managedStreamRunner = managedstream.NewRunner(
-			g.Publish,
-			channelLocalPublisher,
-			managedstream.NewRedisFrameCache(redisClient),
-		)
-	} else {
-		managedStreamRunner = managedstream.NewRunner(
-			g.Publish,
-			channelLocalPublisher,
-			managedstream.NewMemoryFrameCache(),
-		)
-	}
+	managedStreamRunner := managedstream.NewRunner(
+		g.Publish,
+		channelLocalPublisher,
+		frameCache,
+	)
 
 	g.ManagedStreamRunner = managedStreamRunner
 	if enabled := g.Cfg.FeatureToggles["live-pipeline"]; enabled {
@@ -183,12 +150,14 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 				DataPath: cfg.DataPath,
 			}
 			g.channelRuleStorage = storage
+			g.publishTokenStorage = storage
 			builder = &pipeline.StorageRuleBuilder{
 				Node:                 node,
 				ManagedStream:        g.ManagedStreamRunner,
 				FrameStorage:         pipeline.NewFrameStorage(),
 				RuleStorage:          storage,
 				ChannelHandlerGetter: g,
+				DataPath:             cfg.DataPath,
 			}
 		}
 		channelRuleGetter := pipeline.NewCacheSegmentedTree(builder)
@@ -218,6 +187,7 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 	pipelinedChannelLocalPublisher := liveplugin.NewChannelLocalPublisher(node, g.Pipeline)
 	numLocalSubscribersGetter := liveplugin.NewNumLocalSubscribersGetter(node)
 	g.runStreamManager = runstream.NewManager(pipelinedChannelLocalPublisher, numLocalSubscribersGetter, g.contextGetter)
+	g.replayRunner = runstream.NewReplayRunner(g)
 
 	// Initialize the main features
 	dash := &features.DashboardHandler{
@@ -279,6 +249,7 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		})
 
 		client.OnDisconnect(func(e centrifuge.DisconnectEvent) {
+			g.PublishLimiter.Forget(client.ID())
 			reason := "normal"
 			if e.Disconnect != nil {
 				reason = e.Disconnect.Reason
@@ -315,6 +286,11 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		ReadBufferSize:  1024,
 		WriteBufferSize: 1024,
 		CheckOrigin:     checkOrigin,
+		PublishLimiter:  g.PublishLimiter,
+		CanPublishWithToken: func(ctx context.Context, token pipeline.PublishToken, channel string) (bool, error) {
+			_, ok, err := g.PublishTokenContext(ctx, token, channel)
+			return ok, err
+		},
 	})
 
 	g.websocketHandler = func(ctx *models.ReqContext) {
@@ -338,6 +314,37 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		pushWSHandler.ServeHTTP(ctx.Resp, r)
 	}
 
+	g.tokenPushWebsocketHandler = func(ctx *models.ReqContext) {
+		if g.publishTokenStorage == nil {
+			ctx.Resp.WriteHeader(http.StatusNotFound)
+			return
+		}
+		tokenSecret := ctx.Req.Header.Get(pipeline.PublishTokenHeader)
+		if tokenSecret == "" {
+			ctx.Resp.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		token, ok, err := g.publishTokenStorage.GetPublishTokenByHash(ctx.Req.Context(), pipeline.HashPublishToken(tokenSecret))
+		if err != nil {
+			logger.Error("Error getting publish token", "error", err)
+			ctx.Resp.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			ctx.Resp.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if token.Expired(time.Now()) {
+			ctx.Resp.WriteHeader(http.StatusForbidden)
+			return
+		}
+		newCtx := livecontext.SetContextSignedUser(ctx.Req.Context(), token.SignedInUser())
+		newCtx = livecontext.SetContextStreamID(newCtx, web.Params(ctx.Req)[":streamId"])
+		newCtx = pipeline.SetContextPublishToken(newCtx, token)
+		r := ctx.Req.WithContext(newCtx)
+		pushWSHandler.ServeHTTP(ctx.Resp, r)
+	}
+
 	g.RouteRegister.Group("/api/live", func(group routing.RouteRegister) {
 		group.Get("/ws", g.websocketHandler)
 	}, middleware.ReqSignedIn)
@@ -346,6 +353,11 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		group.Get("/push/:streamId", g.pushWebsocketHandler)
 	}, middleware.ReqOrgAdmin)
 
+	if g.publishTokenStorage != nil {
+		// Authenticated by publish token itself.
+		g.RouteRegister.Get("/api/live/token-push/:streamId", g.tokenPushWebsocketHandler)
+	}
+
 	g.registerUsageMetrics()
 
 	return g, nil
@@ -370,8 +382,9 @@ type GrafanaLive struct {
 	surveyCaller *survey.Caller
 
 	// Websocket handlers
-	websocketHandler     interface{}
-	pushWebsocketHandler interface{}
+	websocketHandler          interface{}
+	pushWebsocketHandler      interface{}
+	tokenPushWebsocketHandler interface{}
 
 	// Full channel handler
 	channels   map[string]models.ChannelHandler
@@ -383,9 +396,14 @@ type GrafanaLive struct {
 	ManagedStreamRunner *managedstream.Runner
 	Pipeline            *pipeline.Pipeline
 	channelRuleStorage  pipeline.RuleStorage
+	publishTokenStorage pipeline.PublishTokenStorage
+
+	// PublishLimiter enforces publish rate limits for all incoming data.
+	PublishLimiter *ratelimit.Limiter
 
 	contextGetter    *liveplugin.ContextGetter
 	runStreamManager *runstream.Manager
+	replayRunner     *runstream.ReplayRunner
 	storage          *database.Storage
 
 	usageStatsService usagestats.Service
@@ -421,6 +439,12 @@ func (g *GrafanaLive) Run(ctx context.Context) error {
 		}
 	})
 
+	if g.PublishLimiter.Enabled() {
+		eGroup.Go(func() error {
+			return g.PublishLimiter.Run(eCtx)
+		})
+	}
+
 	if g.runStreamManager != nil {
 		// Only run stream manager if GrafanaLive properly initialized.
 		eGroup.Go(func() error {
@@ -428,6 +452,12 @@ func (g *GrafanaLive) Run(ctx context.Context) error {
 		})
 	}
 
+	if g.replayRunner != nil {
+		eGroup.Go(func() error {
+			return g.replayRunner.Run(eCtx)
+		})
+	}
+
 	return eGroup.Wait()
 }
 
@@ -435,6 +465,41 @@ func (g *GrafanaLive) ChannelRuleStorage() pipeline.RuleStorage {
 	return g.channelRuleStorage
 }
 
+func (g *GrafanaLive) PublishTokenStorage() pipeline.PublishTokenStorage {
+	return g.publishTokenStorage
+}
+
+// PublishTokenContext checks that a publish token can be used to publish into
+// a channel and returns a context to process the publication with. Token must
+// not be expired, must match the channel and a channel rule must accept
+// publish tokens.
+func (g *GrafanaLive) PublishTokenContext(ctx context.Context, token pipeline.PublishToken, channel string) (context.Context, bool, error) {
+	allowed, err := token.Allows(channel, time.Now())
+	if err != nil || !allowed {
+		return nil, false, err
+	}
+	if g.Pipeline == nil {
+		return nil, false, nil
+	}
+	rule, ok, err := g.Pipeline.Get(token.OrgId, channel)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	if _, ok := rule.PublishAuth.(*pipeline.TokenPublishAuthChecker); !ok {
+		// Publishing with tokens must be explicitly allowed by a channel rule.
+		return nil, false, nil
+	}
+	user := token.SignedInUser()
+	ctx = livecontext.SetContextSignedUser(ctx, user)
+	ctx = livecontext.SetContextChannelID(ctx, channel)
+	ctx = pipeline.SetContextPublishToken(ctx, token)
+	allowed, err = rule.PublishAuth.CanPublish(ctx, user)
+	if err != nil || !allowed {
+		return nil, false, err
+	}
+	return ctx, true, nil
+}
+
 func getCheckOriginFunc(appURL *url.URL, originPatterns []string, originGlobs []glob.Glob) func(r *http.Request) bool {
 	return func(r *http.Request) bool {
 		origin := r.Header.Get("Origin")
@@ -650,6 +715,12 @@ func (g *GrafanaLive) handleOnPublish(client *centrifuge.Client, e centrifuge.Pu
 		return centrifuge.PublishReply{}, centrifuge.ErrorPermissionDenied
 	}
 
+	if ok, scope := g.PublishLimiter.Allow(orgID, channel, client.ID(), len(e.Data)); !ok {
+		logger.Debug("Publish rate limit exceeded", "user", client.UserID(), "client", client.ID(), "channel", e.Channel, "scope", scope)
+		// using HTTP error codes for WS errors too.
+		return centrifuge.PublishReply{}, &centrifuge.Error{Code: uint32(http.StatusTooManyRequests), Message: http.StatusText(http.StatusTooManyRequests)}
+	}
+
 	if g.Pipeline != nil {
 		rule, ok, err := g.Pipeline.Get(user.OrgId, channel)
 		if err != nil {
@@ -868,6 +939,19 @@ func (g *GrafanaLive) Publish(orgID int64, channel string, data []byte) error {
 	return err
 }
 
+// PushFrame pushes frame into a managed stream channel.
+func (g *GrafanaLive) PushFrame(orgID int64, channel string, frame *data.Frame) error {
+	addr, err := live.ParseChannel(channel)
+	if err != nil {
+		return err
+	}
+	stream, err := g.ManagedStreamRunner.GetOrCreateStream(orgID, addr.Scope, addr.Namespace)
+	if err != nil {
+		return err
+	}
+	return stream.Push(addr.Path, frame)
+}
+
 // ClientCount returns the number of clients.
 func (g *GrafanaLive) ClientCount(orgID int64, channel string) (int, error) {
 	p, err := g.node.Presence(orgchannel.PrependOrgID(orgID, channel))
@@ -887,6 +971,11 @@ func (g *GrafanaLive) HandleHTTPPublish(ctx *models.ReqContext, cmd dtos.LivePub
 	user := ctx.SignedInUser
 	channel := cmd.Channel
 
+	if ok, scope := g.PublishLimiter.Allow(user.OrgId, channel, "", len(cmd.Data)); !ok {
+		logger.Debug("Publish rate limit exceeded", "user", user.UserId, "channel", channel, "scope", scope)
+		return response.Error(http.StatusTooManyRequests, http.StatusText(http.StatusTooManyRequests), nil)
+	}
+
 	if g.Pipeline != nil {
 		rule, ok, err := g.Pipeline.Get(user.OrgId, channel)
 		if err != nil {
@@ -1155,6 +1244,90 @@ func (g *GrafanaLive) HandleRemoteWriteBackendsListHTTP(c *models.ReqContext) re
 	})
 }
 
+// HandlePublishTokensListHTTP ...
+func (g *GrafanaLive) HandlePublishTokensListHTTP(c *models.ReqContext) response.Response {
+	result, err := g.publishTokenStorage.ListPublishTokens(c.Req.Context(), c.OrgId)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get publish tokens", err)
+	}
+	return response.JSON(http.StatusOK, util.DynMap{
+		"tokens": result,
+	})
+}
+
+// HandlePublishTokensPostHTTP ...
+func (g *GrafanaLive) HandlePublishTokensPostHTTP(c *models.ReqContext) response.Response {
+	body, err := ioutil.ReadAll(c.Req.Body)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Error reading body", err)
+	}
+	var cmd pipeline.CreatePublishTokenCmd
+	err = json.Unmarshal(body, &cmd)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Error decoding publish token", err)
+	}
+	token, secret, err := g.publishTokenStorage.CreatePublishToken(c.Req.Context(), c.OrgId, cmd)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Failed to create publish token", err)
+	}
+	// Token secret is only returned once, on creation.
+	return response.JSON(http.StatusOK, util.DynMap{
+		"token":  token,
+		"secret": secret,
+	})
+}
+
+// HandlePublishTokensDeleteHTTP ...
+func (g *GrafanaLive) HandlePublishTokensDeleteHTTP(c *models.ReqContext) response.Response {
+	err := g.publishTokenStorage.DeletePublishToken(c.Req.Context(), c.OrgId, web.Params(c.Req)[":tokenId"])
+	if err != nil {
+		if errors.Is(err, pipeline.ErrPublishTokenNotFound) {
+			return response.Error(http.StatusNotFound, "Publish token not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to delete publish token", err)
+	}
+	return response.JSON(http.StatusOK, util.DynMap{})
+}
+
+// HandleReplayListHTTP ...
+func (g *GrafanaLive) HandleReplayListHTTP(c *models.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, util.DynMap{
+		"replays": g.replayRunner.List(c.OrgId),
+	})
+}
+
+// HandleReplayStartHTTP ...
+func (g *GrafanaLive) HandleReplayStartHTTP(c *models.ReqContext) response.Response {
+	body, err := ioutil.ReadAll(c.Req.Body)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Error reading body", err)
+	}
+	var req runstream.ReplayRequest
+	err = json.Unmarshal(body, &req)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Error decoding replay request", err)
+	}
+	info, err := g.replayRunner.Start(c.OrgId, req)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Failed to start replay", err)
+	}
+	return response.JSON(http.StatusOK, util.DynMap{
+		"replay": info,
+	})
+}
+
+// HandleReplayStopHTTP ...
+func (g *GrafanaLive) HandleReplayStopHTTP(c *models.ReqContext) response.Response {
+	err := g.replayRunner.Stop(c.OrgId, web.Params(c.Req)[":replayId"])
+	if err != nil {
+		if errors.Is(err, runstream.ErrReplayNotFound) {
+			return response.Error(http.StatusNotFound, "Replay not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to stop replay", err)
+	}
+	return response.JSON(http.StatusOK, util.DynMap{})
+}
+
 // Write to the standard log15 logger
 func handleLog(msg centrifuge.LogEntry) {
 	arr := make([]interface{}, 0)
@@ -7,6 +7,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
 	"github.com/grafana/grafana/pkg/setting"
 
 	"github.com/stretchr/testify/require"
@@ -157,3 +159,49 @@ func TestCheckOrigin(t *testing.T) {
 		})
 	}
 }
+
+type testRuleGetter map[string]*pipeline.LiveChannelRule
+
+func (t testRuleGetter) Get(_ int64, channel string) (*pipeline.LiveChannelRule, bool, error) {
+	rule, ok := t[channel]
+	return rule, ok, nil
+}
+
+func TestPublishTokenContext(t *testing.T) {
+	p, err := pipeline.New(testRuleGetter{
+		"stream/sensors/tokens": {
+			PublishAuth: pipeline.NewTokenPublishAuthChecker(pipeline.NewRoleCheckAuthorizer(models.ROLE_ADMIN)),
+		},
+		"stream/sensors/viewers": {
+			PublishAuth: pipeline.NewRoleCheckAuthorizer(models.ROLE_VIEWER),
+		},
+		"stream/sensors/default": {},
+	})
+	require.NoError(t, err)
+	g := &GrafanaLive{Pipeline: p}
+
+	canPublish := func(token pipeline.PublishToken, channel string) bool {
+		ctx, ok, err := g.PublishTokenContext(context.Background(), token, channel)
+		require.NoError(t, err)
+		if ok {
+			gotToken, found := pipeline.GetContextPublishToken(ctx)
+			require.True(t, found)
+			require.Equal(t, token.ID, gotToken.ID)
+		}
+		return ok
+	}
+
+	token := pipeline.PublishToken{ID: "1", OrgId: 1, Pattern: "stream/sensors/*"}
+	require.True(t, canPublish(token, "stream/sensors/tokens"))
+	// Channel rules must accept tokens explicitly.
+	require.False(t, canPublish(token, "stream/sensors/viewers"))
+	require.False(t, canPublish(token, "stream/sensors/default"))
+	require.False(t, canPublish(token, "stream/sensors/missing"))
+
+	scoped := pipeline.PublishToken{ID: "2", OrgId: 1, Pattern: "stream/sensors/other"}
+	require.False(t, canPublish(scoped, "stream/sensors/tokens"))
+
+	expiredAt := time.Now().Add(-time.Minute)
+	expired := pipeline.PublishToken{ID: "3", OrgId: 1, Pattern: "stream/sensors/*", Expires: &expiredAt}
+	require.False(t, canPublish(expired, "stream/sensors/tokens"))
+}
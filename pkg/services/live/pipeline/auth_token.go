@@ -0,0 +1,132 @@
+package pipeline
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/live/livecontext"
+
+	"github.com/gobwas/glob"
+)
+
+// PublishTokenHeader is a header to pass publish token in.
+const PublishTokenHeader = "X-Grafana-Live-Token"
+
+// publishTokenPrefix helps to distinguish publish tokens from other secrets.
+const publishTokenPrefix = "glive_"
+
+// ErrPublishTokenNotFound returned when publish token does not exist.
+var ErrPublishTokenNotFound = errors.New("publish token not found")
+
+// PublishToken allows publishing into channels matching Pattern without
+// Grafana user credentials. Only a hash of token secret is stored.
+type PublishToken struct {
+	OrgId     int64     `json:"orgId"`
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Pattern   string    `json:"pattern"`
+	TokenHash string    `json:"tokenHash,omitempty"`
+	Created   time.Time `json:"created"`
+	// Expires is an optional token expiration time, nil means token never expires.
+	Expires *time.Time `json:"expires,omitempty"`
+}
+
+// Expired returns true if token expired at the moment.
+func (t PublishToken) Expired(now time.Time) bool {
+	return t.Expires != nil && now.After(*t.Expires)
+}
+
+// Matches checks that channel matches token Pattern. Pattern supports
+// glob syntax where * matches a single channel path segment.
+func (t PublishToken) Matches(channel string) (bool, error) {
+	g, err := glob.Compile(t.Pattern, '/')
+	if err != nil {
+		return false, err
+	}
+	return g.Match(channel), nil
+}
+
+// Allows checks that token is not expired and matches channel.
+func (t PublishToken) Allows(channel string, now time.Time) (bool, error) {
+	if t.Expired(now) {
+		return false, nil
+	}
+	return t.Matches(channel)
+}
+
+// SignedInUser returns a user to publish with the token on behalf of.
+func (t PublishToken) SignedInUser() *models.SignedInUser {
+	return &models.SignedInUser{
+		OrgId:   t.OrgId,
+		Login:   "publish-token:" + t.Name,
+		OrgRole: models.ROLE_VIEWER,
+	}
+}
+
+// HashPublishToken returns a hash of a token secret to store and lookup tokens.
+func HashPublishToken(token string) string {
+	h := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(h[:])
+}
+
+type CreatePublishTokenCmd struct {
+	Name    string     `json:"name"`
+	Pattern string     `json:"pattern"`
+	Expires *time.Time `json:"expires,omitempty"`
+}
+
+type PublishTokenStorage interface {
+	ListPublishTokens(_ context.Context, orgID int64) ([]PublishToken, error)
+	GetPublishTokenByHash(_ context.Context, tokenHash string) (PublishToken, bool, error)
+	CreatePublishToken(_ context.Context, orgID int64, cmd CreatePublishTokenCmd) (PublishToken, string, error)
+	DeletePublishToken(_ context.Context, orgID int64, id string) error
+}
+
+type publishTokenContextKey struct{}
+
+// SetContextPublishToken puts a publish token used by request into context.
+func SetContextPublishToken(ctx context.Context, token PublishToken) context.Context {
+	return context.WithValue(ctx, publishTokenContextKey{}, token)
+}
+
+// GetContextPublishToken returns a publish token used by request.
+func GetContextPublishToken(ctx context.Context) (PublishToken, bool) {
+	if val := ctx.Value(publishTokenContextKey{}); val != nil {
+		token, ok := val.(PublishToken)
+		return token, ok
+	}
+	return PublishToken{}, false
+}
+
+// TokenPublishAuthChecker allows publishing with scoped publish tokens. Requests
+// without a token are checked by a fallback checker, if no fallback
+// provided then only admins can publish.
+type TokenPublishAuthChecker struct {
+	fallback PublishAuthChecker
+}
+
+func NewTokenPublishAuthChecker(fallback PublishAuthChecker) *TokenPublishAuthChecker {
+	return &TokenPublishAuthChecker{fallback: fallback}
+}
+
+func (s *TokenPublishAuthChecker) CanPublish(ctx context.Context, u *models.SignedInUser) (bool, error) {
+	token, ok := GetContextPublishToken(ctx)
+	if !ok {
+		if s.fallback != nil {
+			return s.fallback.CanPublish(ctx, u)
+		}
+		return u.HasRole(models.ROLE_ADMIN), nil
+	}
+	channel, ok := livecontext.GetContextChannelID(ctx)
+	if !ok {
+		return false, errors.New("channel not found in context")
+	}
+	if token.OrgId != u.OrgId {
+		return false, nil
+	}
+	return token.Allows(channel, time.Now())
+}
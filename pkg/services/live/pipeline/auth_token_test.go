@@ -0,0 +1,96 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/live/livecontext"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenPublishAuthChecker(t *testing.T) {
+	checker := NewTokenPublishAuthChecker(nil)
+	user := &models.SignedInUser{OrgId: 1, OrgRole: models.ROLE_VIEWER}
+
+	canPublish := func(token *PublishToken, channel string) bool {
+		ctx := livecontext.SetContextChannelID(context.Background(), channel)
+		if token != nil {
+			ctx = SetContextPublishToken(ctx, *token)
+		}
+		ok, err := checker.CanPublish(ctx, user)
+		require.NoError(t, err)
+		return ok
+	}
+
+	token := PublishToken{OrgId: 1, Pattern: "stream/sensors/*"}
+	require.True(t, canPublish(&token, "stream/sensors/a"))
+	require.False(t, canPublish(&token, "stream/sensors/a/b"))
+	require.False(t, canPublish(&token, "stream/other/a"))
+
+	expiredAt := time.Now().Add(-time.Minute)
+	expired := PublishToken{OrgId: 1, Pattern: "stream/sensors/*", Expires: &expiredAt}
+	require.False(t, canPublish(&expired, "stream/sensors/a"))
+
+	otherOrg := PublishToken{OrgId: 2, Pattern: "stream/sensors/*"}
+	require.False(t, canPublish(&otherOrg, "stream/sensors/a"))
+
+	// Without token only admins allowed by default.
+	require.False(t, canPublish(nil, "stream/sensors/a"))
+	user.OrgRole = models.ROLE_ADMIN
+	require.True(t, canPublish(nil, "stream/sensors/a"))
+}
+
+func TestPublishToken_JSON(t *testing.T) {
+	data, err := json.Marshal(PublishToken{OrgId: 1, Pattern: "stream/sensors/*"})
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "expires")
+
+	expires := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	data, err = json.Marshal(PublishToken{OrgId: 1, Pattern: "stream/sensors/*", Expires: &expires})
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"expires":"2021-10-01T00:00:00Z"`)
+}
+
+func TestFileStorage_PublishTokens(t *testing.T) {
+	storage := &FileStorage{DataPath: t.TempDir()}
+	ctx := context.Background()
+
+	_, _, err := storage.CreatePublishToken(ctx, 1, CreatePublishTokenCmd{Name: "test"})
+	require.Error(t, err)
+
+	token, secret, err := storage.CreatePublishToken(ctx, 1, CreatePublishTokenCmd{
+		Name:    "test",
+		Pattern: "stream/sensors/*",
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, secret)
+	require.Empty(t, token.TokenHash)
+
+	found, ok, err := storage.GetPublishTokenByHash(ctx, HashPublishToken(secret))
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, token.ID, found.ID)
+	require.Equal(t, int64(1), found.OrgId)
+
+	_, ok, err = storage.GetPublishTokenByHash(ctx, HashPublishToken("wrong"))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	tokens, err := storage.ListPublishTokens(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	require.Empty(t, tokens[0].TokenHash)
+	tokens, err = storage.ListPublishTokens(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, tokens, 0)
+
+	require.ErrorIs(t, storage.DeletePublishToken(ctx, 2, token.ID), ErrPublishTokenNotFound)
+	require.NoError(t, storage.DeletePublishToken(ctx, 1, token.ID))
+	_, ok, err = storage.GetPublishTokenByHash(ctx, HashPublishToken(secret))
+	require.NoError(t, err)
+	require.False(t, ok)
+}
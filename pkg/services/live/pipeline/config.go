@@ -3,6 +3,7 @@ package pipeline
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/live/managedstream"
@@ -57,11 +58,13 @@ type FrameOutputterConfig struct {
 	ThresholdOutputConfig   *ThresholdOutputConfig     `json:"threshold,omitempty"`
 	RemoteWriteOutputConfig *RemoteWriteOutputConfig   `json:"remoteWrite,omitempty"`
 	ChangeLogOutputConfig   *ChangeLogOutputConfig     `json:"changeLog,omitempty"`
+	LokiOutputConfig        *LokiConfig                `json:"loki,omitempty"`
 }
 
 type DataOutputterConfig struct {
-	Type                     string                    `json:"type"`
-	RedirectDataOutputConfig *RedirectDataOutputConfig `json:"redirect,omitempty"`
+	Type                        string                       `json:"type"`
+	RedirectDataOutputConfig    *RedirectDataOutputConfig    `json:"redirect,omitempty"`
+	FileArchiveDataOutputConfig *FileArchiveDataOutputConfig `json:"fileArchive,omitempty"`
 }
 
 type MultipleSubscriberConfig struct {
@@ -76,6 +79,9 @@ type SubscriberConfig struct {
 // ChannelAuthCheckConfig is used to define auth rules for a channel.
 type ChannelAuthCheckConfig struct {
 	RequireRole models.RoleType `json:"role,omitempty"`
+	// AllowTokens allows publishing with scoped publish tokens,
+	// only makes sense for publish auth.
+	AllowTokens bool `json:"allowTokens,omitempty"`
 }
 
 type ChannelAuthConfig struct {
@@ -206,6 +212,22 @@ type StorageRuleBuilder struct {
 	FrameStorage         *FrameStorage
 	RuleStorage          RuleStorage
 	ChannelHandlerGetter ChannelHandlerGetter
+	// DataPath is a root directory for outputs which persist data on disk.
+	DataPath string
+
+	mu            sync.Mutex
+	archiveWriter *FileArchiveWriter
+	// outputCancels stop background work of outputs built for an organization.
+	outputCancels map[int64]context.CancelFunc
+}
+
+func (f *StorageRuleBuilder) getArchiveWriter() *FileArchiveWriter {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.archiveWriter == nil {
+		f.archiveWriter = NewFileArchiveWriter(f.DataPath)
+	}
+	return f.archiveWriter
 }
 
 func (f *StorageRuleBuilder) extractSubscriber(config *SubscriberConfig) (Subscriber, error) {
@@ -334,7 +356,7 @@ func (f *StorageRuleBuilder) extractFrameConditionChecker(config *FrameCondition
 	}
 }
 
-func (f *StorageRuleBuilder) extractFrameOutputter(config *FrameOutputterConfig, remoteWriteBackends []RemoteWriteBackend) (FrameOutputter, error) {
+func (f *StorageRuleBuilder) extractFrameOutputter(outputCtx context.Context, config *FrameOutputterConfig, remoteWriteBackends []RemoteWriteBackend) (FrameOutputter, error) {
 	if config == nil {
 		return nil, nil
 	}
@@ -352,7 +374,7 @@ func (f *StorageRuleBuilder) extractFrameOutputter(config *FrameOutputterConfig,
 		var outputters []FrameOutputter
 		for _, outConf := range config.MultipleOutputterConfig.Outputters {
 			out := outConf
-			outputter, err := f.extractFrameOutputter(&out, remoteWriteBackends)
+			outputter, err := f.extractFrameOutputter(outputCtx, &out, remoteWriteBackends)
 			if err != nil {
 				return nil, err
 			}
@@ -371,7 +393,7 @@ func (f *StorageRuleBuilder) extractFrameOutputter(config *FrameOutputterConfig,
 		if err != nil {
 			return nil, err
 		}
-		outputter, err := f.extractFrameOutputter(config.ConditionalOutputConfig.Outputter, remoteWriteBackends)
+		outputter, err := f.extractFrameOutputter(outputCtx, config.ConditionalOutputConfig.Outputter, remoteWriteBackends)
 		if err != nil {
 			return nil, err
 		}
@@ -395,6 +417,11 @@ func (f *StorageRuleBuilder) extractFrameOutputter(config *FrameOutputterConfig,
 			return nil, missingConfiguration
 		}
 		return NewChangeLogFrameOutput(f.FrameStorage, *config.ChangeLogOutputConfig), nil
+	case FrameOutputTypeLoki:
+		if config.LokiOutputConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewLokiFrameOutput(outputCtx, *config.LokiOutputConfig), nil
 	default:
 		return nil, fmt.Errorf("unknown output type: %s", config.Type)
 	}
@@ -415,6 +442,11 @@ func (f *StorageRuleBuilder) extractDataOutputter(config *DataOutputterConfig) (
 		return NewBuiltinDataOutput(f.ChannelHandlerGetter), nil
 	case DataOutputTypeLocalSubscribers:
 		return NewLocalSubscribersDataOutput(f.Node), nil
+	case DataOutputTypeFileArchive:
+		if config.FileArchiveDataOutputConfig == nil {
+			config.FileArchiveDataOutputConfig = &FileArchiveDataOutputConfig{}
+		}
+		return NewFileArchiveDataOutput(f.getArchiveWriter(), *config.FileArchiveDataOutputConfig), nil
 	default:
 		return nil, fmt.Errorf("unknown data output type: %s", config.Type)
 	}
@@ -429,7 +461,35 @@ func (f *StorageRuleBuilder) getRemoteWriteConfig(uid string, remoteWriteBackend
 	return nil, false
 }
 
+// BuildRules builds channel rules of an organization. Outputs of the rules
+// built before are stopped and archive files are closed, since rules replace
+// them.
 func (f *StorageRuleBuilder) BuildRules(ctx context.Context, orgID int64) ([]*LiveChannelRule, error) {
+	outputCtx, cancel := context.WithCancel(context.Background())
+	rules, err := f.buildRules(ctx, outputCtx, orgID)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	f.mu.Lock()
+	if f.outputCancels == nil {
+		f.outputCancels = map[int64]context.CancelFunc{}
+	}
+	if prevCancel, ok := f.outputCancels[orgID]; ok {
+		prevCancel()
+	}
+	f.outputCancels[orgID] = cancel
+	archiveWriter := f.archiveWriter
+	f.mu.Unlock()
+
+	if archiveWriter != nil {
+		archiveWriter.CloseFiles()
+	}
+	return rules, nil
+}
+
+func (f *StorageRuleBuilder) buildRules(ctx context.Context, outputCtx context.Context, orgID int64) ([]*LiveChannelRule, error) {
 	channelRules, err := f.RuleStorage.ListChannelRules(ctx, orgID)
 	if err != nil {
 		return nil, err
@@ -453,7 +513,16 @@ func (f *StorageRuleBuilder) BuildRules(ctx context.Context, orgID int64) ([]*Li
 		}
 
 		if ruleConfig.Settings.Auth != nil && ruleConfig.Settings.Auth.Publish != nil {
-			rule.PublishAuth = NewRoleCheckAuthorizer(ruleConfig.Settings.Auth.Publish.RequireRole)
+			publishAuth := ruleConfig.Settings.Auth.Publish
+			var roleAuth PublishAuthChecker
+			if publishAuth.RequireRole != "" || !publishAuth.AllowTokens {
+				roleAuth = NewRoleCheckAuthorizer(publishAuth.RequireRole)
+			}
+			if publishAuth.AllowTokens {
+				rule.PublishAuth = NewTokenPublishAuthChecker(roleAuth)
+			} else {
+				rule.PublishAuth = roleAuth
+			}
 		}
 
 		var err error
@@ -485,7 +554,7 @@ func (f *StorageRuleBuilder) BuildRules(ctx context.Context, orgID int64) ([]*Li
 
 		var outputters []FrameOutputter
 		for _, outConfig := range ruleConfig.Settings.FrameOutputters {
-			out, err := f.extractFrameOutputter(outConfig, remoteWriteBackends)
+			out, err := f.extractFrameOutputter(outputCtx, outConfig, remoteWriteBackends)
 			if err != nil {
 				return nil, fmt.Errorf("error building frame outputter for %s: %w", rule.Pattern, err)
 			}
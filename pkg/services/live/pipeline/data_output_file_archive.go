@@ -0,0 +1,194 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultArchiveMaxFileSize = 10 * 1024 * 1024
+	defaultArchiveMaxFiles    = 5
+)
+
+// FileArchiveDataOutputConfig ...
+type FileArchiveDataOutputConfig struct {
+	// MaxFileSize is a size in bytes after which current archive file
+	// is rotated. By default 10MB.
+	MaxFileSize int64 `json:"maxFileSize,omitempty"`
+	// MaxFiles is a number of rotated files to keep per channel. By default 5.
+	MaxFiles int `json:"maxFiles,omitempty"`
+}
+
+// ArchiveRecord is a single line of archive file. Data contains raw JSON
+// payload, Text is used for non-JSON payloads (like Influx line protocol).
+type ArchiveRecord struct {
+	Time    int64           `json:"time"`
+	Channel string          `json:"channel"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	Text    string          `json:"text,omitempty"`
+}
+
+// Payload returns original data bytes of a record.
+func (r ArchiveRecord) Payload() []byte {
+	if len(r.Data) > 0 {
+		return r.Data
+	}
+	return []byte(r.Text)
+}
+
+// FileArchiveWriter appends records to archive files and rotates them. A single
+// writer is shared by all file archive outputs of a rule builder, so every
+// archive file has one open handle and is rotated under one lock even when
+// several rules archive into the same channel.
+type FileArchiveWriter struct {
+	mu       sync.Mutex
+	dataPath string
+	files    map[string]*os.File
+}
+
+func NewFileArchiveWriter(dataPath string) *FileArchiveWriter {
+	return &FileArchiveWriter{
+		dataPath: dataPath,
+		files:    map[string]*os.File{},
+	}
+}
+
+// FileArchiveDataOutput appends incoming data as NDJSON to rotating
+// files located inside Grafana data path.
+type FileArchiveDataOutput struct {
+	writer *FileArchiveWriter
+	config FileArchiveDataOutputConfig
+}
+
+func NewFileArchiveDataOutput(writer *FileArchiveWriter, config FileArchiveDataOutputConfig) *FileArchiveDataOutput {
+	if config.MaxFileSize <= 0 {
+		config.MaxFileSize = defaultArchiveMaxFileSize
+	}
+	if config.MaxFiles <= 0 {
+		config.MaxFiles = defaultArchiveMaxFiles
+	}
+	return &FileArchiveDataOutput{
+		writer: writer,
+		config: config,
+	}
+}
+
+const DataOutputTypeFileArchive = "fileArchive"
+
+func (out *FileArchiveDataOutput) Type() string {
+	return DataOutputTypeFileArchive
+}
+
+// ArchiveFilePath returns a path to the current archive file for a channel.
+// Channel is query escaped, so that every channel has its own file.
+func ArchiveFilePath(dataPath string, orgID int64, channel string) string {
+	return filepath.Join(dataPath, "live", "archive", strconv.FormatInt(orgID, 10), url.QueryEscape(channel)+".ndjson")
+}
+
+func (out *FileArchiveDataOutput) OutputData(_ context.Context, vars Vars, data []byte) ([]*ChannelData, error) {
+	record := ArchiveRecord{
+		Time:    time.Now().UnixNano() / int64(time.Millisecond),
+		Channel: vars.Channel,
+	}
+	if json.Valid(data) {
+		record.Data = data
+	} else {
+		record.Text = string(data)
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling archive record: %w", err)
+	}
+	line = append(line, '\n')
+	return nil, out.writer.write(vars.OrgID, vars.Channel, line, out.config)
+}
+
+func (w *FileArchiveWriter) write(orgID int64, channel string, line []byte, config FileArchiveDataOutputConfig) error {
+	if w.dataPath == "" {
+		return errors.New("file archive data path not configured")
+	}
+	path := ArchiveFilePath(w.dataPath, orgID, channel)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.getFile(path)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("error getting archive file info: %w", err)
+	}
+	if info.Size() > 0 && info.Size()+int64(len(line)) > config.MaxFileSize {
+		f, err = w.rotate(path, config.MaxFiles)
+		if err != nil {
+			return err
+		}
+	}
+	if _, err := f.Write(line); err != nil {
+		return fmt.Errorf("error writing to archive file: %w", err)
+	}
+	return nil
+}
+
+// CloseFiles closes all open archive files. Files are opened again on next
+// write, this allows to release files of channels which are not archived
+// anymore when channel rules are rebuilt.
+func (w *FileArchiveWriter) CloseFiles() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for path, f := range w.files {
+		if err := f.Close(); err != nil {
+			logger.Warn("Error closing archive file", "path", path, "error", err)
+		}
+		delete(w.files, path)
+	}
+}
+
+func (w *FileArchiveWriter) getFile(path string) (*os.File, error) {
+	if f, ok := w.files[path]; ok {
+		return f, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return nil, fmt.Errorf("error creating archive directory: %w", err)
+	}
+	// Safe to ignore gosec warning G304, path is built from data path.
+	// nolint:gosec
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0640)
+	if err != nil {
+		return nil, fmt.Errorf("error opening archive file: %w", err)
+	}
+	w.files[path] = f
+	return f, nil
+}
+
+// rotate shifts archive files: path.N-1 -> path.N, ..., path -> path.1.
+// The oldest file above maxFiles limit is removed.
+func (w *FileArchiveWriter) rotate(path string, maxFiles int) (*os.File, error) {
+	if f, ok := w.files[path]; ok {
+		_ = f.Close()
+		delete(w.files, path)
+	}
+	oldest := path + "." + strconv.Itoa(maxFiles)
+	if err := os.Remove(oldest); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error removing old archive file: %w", err)
+	}
+	for i := maxFiles - 1; i >= 1; i-- {
+		src := path + "." + strconv.Itoa(i)
+		if err := os.Rename(src, path+"."+strconv.Itoa(i+1)); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("error rotating archive file: %w", err)
+		}
+	}
+	if err := os.Rename(path, path+".1"); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error rotating archive file: %w", err)
+	}
+	return w.getFile(path)
+}
@@ -0,0 +1,81 @@
+package pipeline
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileArchiveDataOutput_OutputData(t *testing.T) {
+	dataPath := t.TempDir()
+	out := NewFileArchiveDataOutput(NewFileArchiveWriter(dataPath), FileArchiveDataOutputConfig{})
+	vars := Vars{OrgID: 1, Channel: "stream/test/x"}
+
+	_, err := out.OutputData(context.Background(), vars, []byte(`{"value":1}`))
+	require.NoError(t, err)
+	_, err = out.OutputData(context.Background(), vars, []byte(`cpu value=2`))
+	require.NoError(t, err)
+
+	f, err := os.Open(ArchiveFilePath(dataPath, 1, "stream/test/x"))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	var records []ArchiveRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r ArchiveRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &r))
+		records = append(records, r)
+	}
+	require.Len(t, records, 2)
+	require.Equal(t, `{"value":1}`, string(records[0].Payload()))
+	require.Equal(t, `cpu value=2`, string(records[1].Payload()))
+}
+
+func TestFileArchiveDataOutput_Rotate(t *testing.T) {
+	dataPath := t.TempDir()
+	out := NewFileArchiveDataOutput(NewFileArchiveWriter(dataPath), FileArchiveDataOutputConfig{
+		MaxFileSize: 10,
+		MaxFiles:    2,
+	})
+	vars := Vars{OrgID: 1, Channel: "stream/test/x"}
+	for i := 0; i < 5; i++ {
+		_, err := out.OutputData(context.Background(), vars, []byte(`{"value":1}`))
+		require.NoError(t, err)
+	}
+	path := ArchiveFilePath(dataPath, 1, "stream/test/x")
+	require.FileExists(t, path)
+	require.FileExists(t, path+".1")
+	require.FileExists(t, path+".2")
+	require.NoFileExists(t, path+".3")
+}
+
+func TestFileArchiveDataOutput_SharedWriter(t *testing.T) {
+	writer := NewFileArchiveWriter(t.TempDir())
+	out1 := NewFileArchiveDataOutput(writer, FileArchiveDataOutputConfig{})
+	out2 := NewFileArchiveDataOutput(writer, FileArchiveDataOutputConfig{})
+	vars := Vars{OrgID: 1, Channel: "stream/test/x"}
+
+	_, err := out1.OutputData(context.Background(), vars, []byte(`{"value":1}`))
+	require.NoError(t, err)
+	_, err = out2.OutputData(context.Background(), vars, []byte(`{"value":2}`))
+	require.NoError(t, err)
+	require.Len(t, writer.files, 1)
+
+	writer.CloseFiles()
+	require.Len(t, writer.files, 0)
+	_, err = out1.OutputData(context.Background(), vars, []byte(`{"value":3}`))
+	require.NoError(t, err)
+	require.Len(t, writer.files, 1)
+}
+
+func TestArchiveFilePath(t *testing.T) {
+	require.NotEqual(t, ArchiveFilePath("data", 1, "stream/a_b"), ArchiveFilePath("data", 1, "stream/a/b"))
+	require.NotEqual(t, ArchiveFilePath("data", 1, "stream/a*"), ArchiveFilePath("data", 1, "stream/a_"))
+	require.Equal(t, filepath.Join("data", "live", "archive", "1", "stream%2Fa%2Fb.ndjson"), ArchiveFilePath("data", 1, "stream/a/b"))
+}
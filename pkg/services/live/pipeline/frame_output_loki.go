@@ -0,0 +1,244 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+type LokiConfig struct {
+	// Endpoint to send streaming frames to, for example
+	// http://localhost:3100/loki/api/v1/push.
+	Endpoint string `json:"endpoint"`
+	// User is a user for Loki push request.
+	User string `json:"user"`
+	// Password for Loki push endpoint.
+	Password string `json:"password"`
+}
+
+// lokiStream is a single stream in Loki push API request. Values are pairs of
+// a timestamp in nanoseconds (as string) and a log line.
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+type lokiPushRequest struct {
+	Streams []lokiStream `json:"streams"`
+}
+
+// maxLokiBufferedStreams limits the number of streams kept in memory until
+// they are sent to Loki. When Loki is unavailable the oldest streams are
+// dropped over the limit.
+const maxLokiBufferedStreams = 10000
+
+// LokiFrameOutput sends frames to Loki as log streams. Every row of a frame
+// becomes a JSON-encoded log line, stream labels are taken from frame field labels.
+type LokiFrameOutput struct {
+	mu         sync.Mutex
+	config     LokiConfig
+	httpClient *http.Client
+	buffer     []lokiStream
+	// stopped is set when buffer is not flushed periodically anymore.
+	stopped bool
+}
+
+// NewLokiFrameOutput creates LokiFrameOutput which sends buffered frames to
+// Loki until ctx is done. Frames output after that are sent synchronously.
+func NewLokiFrameOutput(ctx context.Context, config LokiConfig) *LokiFrameOutput {
+	out := &LokiFrameOutput{
+		config:     config,
+		httpClient: &http.Client{Timeout: 2 * time.Second},
+	}
+	if config.Endpoint != "" {
+		go out.flushPeriodically(ctx)
+	}
+	return out
+}
+
+const FrameOutputTypeLoki = "loki"
+
+func (out *LokiFrameOutput) Type() string {
+	return FrameOutputTypeLoki
+}
+
+func (out *LokiFrameOutput) flushPeriodically(ctx context.Context) {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			out.mu.Lock()
+			out.stopped = true
+			out.mu.Unlock()
+			out.flushBuffer()
+			return
+		case <-ticker.C:
+			out.flushBuffer()
+		}
+	}
+}
+
+func (out *LokiFrameOutput) flushBuffer() {
+	out.mu.Lock()
+	if len(out.buffer) == 0 {
+		out.mu.Unlock()
+		return
+	}
+	tmpBuffer := out.buffer
+	out.buffer = nil
+	out.mu.Unlock()
+
+	err := out.flush(tmpBuffer)
+	if err != nil {
+		logger.Error("Error flush to Loki", "error", err)
+		out.mu.Lock()
+		out.buffer = append(tmpBuffer, out.buffer...)
+		out.trimBuffer()
+		out.mu.Unlock()
+	}
+}
+
+// trimBuffer must be called with mu held.
+func (out *LokiFrameOutput) trimBuffer() {
+	if dropped := len(out.buffer) - maxLokiBufferedStreams; dropped > 0 {
+		logger.Warn("Loki buffer is full, dropping oldest streams", "numStreams", dropped)
+		out.buffer = append([]lokiStream(nil), out.buffer[dropped:]...)
+	}
+}
+
+func (out *LokiFrameOutput) flush(streams []lokiStream) error {
+	logger.Debug("Loki flush", "numStreams", len(streams))
+	body, err := json.Marshal(lokiPushRequest{Streams: streams})
+	if err != nil {
+		return fmt.Errorf("error marshaling Loki push request: %w", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, out.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error constructing Loki push request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if out.config.User != "" {
+		req.SetBasicAuth(out.config.User, out.config.Password)
+	}
+
+	started := time.Now()
+	resp, err := out.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending Loki push request: %w", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		logger.Error("Unexpected response code from Loki endpoint", "code", resp.StatusCode)
+		return errors.New("unexpected response code from Loki endpoint")
+	}
+	logger.Debug("Successfully sent to Loki endpoint", "url", out.config.Endpoint, "elapsed", time.Since(started))
+	return nil
+}
+
+// frameToLokiStreams groups non-time fields of a frame by their labels. Each
+// group becomes a separate stream with one log line per frame row.
+func frameToLokiStreams(channel string, frame *data.Frame) ([]lokiStream, error) {
+	timeFieldIndex := -1
+	for i, f := range frame.Fields {
+		if f.Type() == data.FieldTypeTime || f.Type() == data.FieldTypeNullableTime {
+			timeFieldIndex = i
+			break
+		}
+	}
+
+	groups := map[string][]int{}
+	groupLabels := map[string]data.Labels{}
+	var groupKeys []string
+	for i, f := range frame.Fields {
+		if i == timeFieldIndex {
+			continue
+		}
+		key := f.Labels.String()
+		if _, ok := groups[key]; !ok {
+			groupKeys = append(groupKeys, key)
+			groupLabels[key] = f.Labels
+		}
+		groups[key] = append(groups[key], i)
+	}
+	sort.Strings(groupKeys)
+
+	numRows, err := frame.RowLen()
+	if err != nil {
+		return nil, err
+	}
+
+	streams := make([]lokiStream, 0, len(groupKeys))
+	for _, key := range groupKeys {
+		labels := map[string]string{"channel": channel}
+		for k, v := range groupLabels[key] {
+			labels[lokiLabelName(k)] = v
+		}
+		stream := lokiStream{Stream: labels, Values: make([][2]string, 0, numRows)}
+		for row := 0; row < numRows; row++ {
+			ts := time.Now()
+			if timeFieldIndex >= 0 {
+				if t, ok := frame.Fields[timeFieldIndex].ConcreteAt(row); ok {
+					ts = t.(time.Time)
+				}
+			}
+			line := make(map[string]interface{}, len(groups[key]))
+			for _, fieldIndex := range groups[key] {
+				f := frame.Fields[fieldIndex]
+				v, _ := f.ConcreteAt(row)
+				line[f.Name] = v
+			}
+			lineBytes, err := json.Marshal(line)
+			if err != nil {
+				return nil, err
+			}
+			stream.Values = append(stream.Values, [2]string{strconv.FormatInt(ts.UnixNano(), 10), string(lineBytes)})
+		}
+		streams = append(streams, stream)
+	}
+	return streams, nil
+}
+
+// lokiLabelName makes label name valid for Loki which only accepts
+// [a-zA-Z_][a-zA-Z0-9_]* label names.
+func lokiLabelName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (i > 0 && r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+func (out *LokiFrameOutput) OutputFrame(_ context.Context, vars Vars, frame *data.Frame) ([]*ChannelFrame, error) {
+	if out.config.Endpoint == "" {
+		logger.Debug("Skip sending to Loki: no url")
+		return nil, nil
+	}
+	streams, err := frameToLokiStreams(vars.Channel, frame)
+	if err != nil {
+		return nil, err
+	}
+	out.mu.Lock()
+	if out.stopped {
+		out.mu.Unlock()
+		return nil, out.flush(streams)
+	}
+	out.buffer = append(out.buffer, streams...)
+	out.trimBuffer()
+	out.mu.Unlock()
+	return nil, nil
+}
@@ -0,0 +1,60 @@
+package pipeline
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLokiFrameOutput_BufferLimit(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out := NewLokiFrameOutput(ctx, LokiConfig{Endpoint: "http://127.0.0.1:0/loki/api/v1/push"})
+
+	frame := data.NewFrame("test", data.NewField("value", nil, []float64{1}))
+	for i := 0; i < maxLokiBufferedStreams+10; i++ {
+		_, err := out.OutputFrame(context.Background(), Vars{Channel: "stream/test/x"}, frame)
+		require.NoError(t, err)
+	}
+
+	out.mu.Lock()
+	defer out.mu.Unlock()
+	require.Len(t, out.buffer, maxLokiBufferedStreams)
+}
+
+func TestLokiFrameOutput_Stop(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := NewLokiFrameOutput(ctx, LokiConfig{Endpoint: server.URL})
+	frame := data.NewFrame("test", data.NewField("value", nil, []float64{1}))
+	_, err := out.OutputFrame(context.Background(), Vars{Channel: "stream/test/x"}, frame)
+	require.NoError(t, err)
+
+	// Buffer is flushed when output stopped.
+	cancel()
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&requests) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// Frames are sent right away after that.
+	require.Eventually(t, func() bool {
+		out.mu.Lock()
+		defer out.mu.Unlock()
+		return out.stopped
+	}, time.Second, 10*time.Millisecond)
+	_, err = out.OutputFrame(context.Background(), Vars{Channel: "stream/test/x"}, frame)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
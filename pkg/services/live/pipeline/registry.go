@@ -44,6 +44,11 @@ var FrameOutputsRegistry = []EntityInfo{
 		Type:        FrameOutputTypeRemoteWrite,
 		Description: "output to remote write endpoint",
 	},
+	{
+		Type:        FrameOutputTypeLoki,
+		Description: "output frame rows as log lines to Loki",
+		Example:     LokiConfig{},
+	},
 }
 
 var ConvertersRegistry = []EntityInfo{
@@ -90,4 +95,9 @@ var DataOutputsRegistry = []EntityInfo{
 		Type:        DataOutputTypeRedirect,
 		Description: "redirect data processing to another channel rule",
 	},
+	{
+		Type:        DataOutputTypeFileArchive,
+		Description: "append data as NDJSON to rotating files under data path",
+		Example:     FileArchiveDataOutputConfig{},
+	},
 }
@@ -2,12 +2,19 @@ package pipeline
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/util"
+
+	"github.com/gobwas/glob"
 )
 
 // FileStorage can load channel rules from a file on disk.
@@ -171,3 +178,134 @@ func (f *FileStorage) DeleteChannelRule(_ context.Context, orgID int64, pattern
 
 	return f.saveChannelRules(orgID, channelRules)
 }
+
+type publishTokens struct {
+	Tokens []PublishToken `json:"tokens"`
+}
+
+func (f *FileStorage) publishTokensFilePath() string {
+	return filepath.Join(f.DataPath, "pipeline", "publish-tokens.json")
+}
+
+func (f *FileStorage) readPublishTokens() (publishTokens, error) {
+	tokenFile := f.publishTokensFilePath()
+	// Safe to ignore gosec warning G304.
+	// nolint:gosec
+	tokenBytes, err := ioutil.ReadFile(tokenFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return publishTokens{}, nil
+		}
+		return publishTokens{}, fmt.Errorf("can't read publish tokens: %s: %w", tokenFile, err)
+	}
+	var tokens publishTokens
+	err = json.Unmarshal(tokenBytes, &tokens)
+	if err != nil {
+		return publishTokens{}, fmt.Errorf("can't unmarshal publish-tokens.json data: %w", err)
+	}
+	return tokens, nil
+}
+
+func (f *FileStorage) savePublishTokens(tokens publishTokens) error {
+	tokenFile := f.publishTokensFilePath()
+	if err := os.MkdirAll(filepath.Dir(tokenFile), 0750); err != nil {
+		return fmt.Errorf("can't create pipeline directory: %w", err)
+	}
+	// Safe to ignore gosec warning G304.
+	// nolint:gosec
+	file, err := os.OpenFile(tokenFile, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("can't open publish tokens file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+	enc := json.NewEncoder(file)
+	enc.SetIndent("", "  ")
+	err = enc.Encode(tokens)
+	if err != nil {
+		return fmt.Errorf("can't save publish tokens to file: %w", err)
+	}
+	return nil
+}
+
+func (f *FileStorage) ListPublishTokens(_ context.Context, orgID int64) ([]PublishToken, error) {
+	tokens, err := f.readPublishTokens()
+	if err != nil {
+		return nil, err
+	}
+	result := []PublishToken{}
+	for _, t := range tokens.Tokens {
+		if t.OrgId == orgID {
+			t.TokenHash = ""
+			result = append(result, t)
+		}
+	}
+	return result, nil
+}
+
+func (f *FileStorage) GetPublishTokenByHash(_ context.Context, tokenHash string) (PublishToken, bool, error) {
+	tokens, err := f.readPublishTokens()
+	if err != nil {
+		return PublishToken{}, false, err
+	}
+	for _, t := range tokens.Tokens {
+		if subtle.ConstantTimeCompare([]byte(t.TokenHash), []byte(tokenHash)) == 1 {
+			return t, true, nil
+		}
+	}
+	return PublishToken{}, false, nil
+}
+
+func (f *FileStorage) CreatePublishToken(_ context.Context, orgID int64, cmd CreatePublishTokenCmd) (PublishToken, string, error) {
+	if cmd.Name == "" {
+		return PublishToken{}, "", errors.New("token name required")
+	}
+	if cmd.Pattern == "" || strings.HasPrefix(cmd.Pattern, "/") {
+		return PublishToken{}, "", errors.New("invalid token pattern")
+	}
+	if _, err := glob.Compile(cmd.Pattern, '/'); err != nil {
+		return PublishToken{}, "", fmt.Errorf("invalid token pattern: %w", err)
+	}
+	tokens, err := f.readPublishTokens()
+	if err != nil {
+		return PublishToken{}, "", err
+	}
+	secret, err := util.GetRandomString(32)
+	if err != nil {
+		return PublishToken{}, "", err
+	}
+	secret = publishTokenPrefix + secret
+	token := PublishToken{
+		OrgId:     orgID,
+		ID:        util.GenerateShortUID(),
+		Name:      cmd.Name,
+		Pattern:   cmd.Pattern,
+		TokenHash: HashPublishToken(secret),
+		Created:   time.Now(),
+		Expires:   cmd.Expires,
+	}
+	tokens.Tokens = append(tokens.Tokens, token)
+	if err := f.savePublishTokens(tokens); err != nil {
+		return PublishToken{}, "", err
+	}
+	token.TokenHash = ""
+	return token, secret, nil
+}
+
+func (f *FileStorage) DeletePublishToken(_ context.Context, orgID int64, id string) error {
+	tokens, err := f.readPublishTokens()
+	if err != nil {
+		return err
+	}
+	index := -1
+	for i, t := range tokens.Tokens {
+		if t.OrgId == orgID && t.ID == id {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return ErrPublishTokenNotFound
+	}
+	tokens.Tokens = append(tokens.Tokens[:index], tokens.Tokens[index+1:]...)
+	return f.savePublishTokens(tokens)
+}
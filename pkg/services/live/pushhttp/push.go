@@ -10,6 +10,7 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/live"
 	"github.com/grafana/grafana/pkg/services/live/convert"
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
 	"github.com/grafana/grafana/pkg/services/live/pushurl"
 	"github.com/grafana/grafana/pkg/setting"
 
@@ -83,6 +84,13 @@ func (g *Gateway) Handle(ctx *models.ReqContext) {
 		return
 	}
 
+	channels := pushurl.StreamChannels(streamID, metricFrames)
+	if ok, scope := g.GrafanaLive.PublishLimiter.AllowChannels(ctx.SignedInUser.OrgId, channels, "", len(body)); !ok {
+		logger.Debug("Push rate limit exceeded", "streamId", streamID, "scope", scope)
+		ctx.Resp.WriteHeader(http.StatusTooManyRequests)
+		return
+	}
+
 	// TODO -- make sure all packets are combined together!
 	// interval = "1s" vs flush_interval = "5s"
 
@@ -115,6 +123,12 @@ func (g *Gateway) HandlePath(ctx *models.ReqContext) {
 
 	channelID := "stream/" + streamID + "/" + path
 
+	if ok, scope := g.GrafanaLive.PublishLimiter.Allow(ctx.OrgId, channelID, "", len(body)); !ok {
+		logger.Debug("Push rate limit exceeded", "channel", channelID, "scope", scope)
+		ctx.Resp.WriteHeader(http.StatusTooManyRequests)
+		return
+	}
+
 	ruleFound, err := g.GrafanaLive.Pipeline.ProcessInput(ctx.Req.Context(), ctx.OrgId, channelID, body)
 	if err != nil {
 		logger.Error("Pipeline input processing error", "error", err, "body", string(body))
@@ -131,3 +145,73 @@ func (g *Gateway) HandlePath(ctx *models.ReqContext) {
 		return
 	}
 }
+
+// HandleTokenPath processes data pushed with a scoped publish token instead
+// of Grafana user credentials. Channel rule must allow tokens for publishing.
+func (g *Gateway) HandleTokenPath(ctx *models.ReqContext) {
+	streamID := web.Params(ctx.Req)[":streamId"]
+	path := web.Params(ctx.Req)[":path"]
+	channelID := "stream/" + streamID + "/" + path
+
+	tokenStorage := g.GrafanaLive.PublishTokenStorage()
+	if g.GrafanaLive.Pipeline == nil || tokenStorage == nil {
+		ctx.Resp.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	tokenSecret := ctx.Req.Header.Get(pipeline.PublishTokenHeader)
+	if tokenSecret == "" {
+		ctx.Resp.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	token, ok, err := tokenStorage.GetPublishTokenByHash(ctx.Req.Context(), pipeline.HashPublishToken(tokenSecret))
+	if err != nil {
+		logger.Error("Error getting publish token", "error", err)
+		ctx.Resp.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		ctx.Resp.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	reqCtx, allowed, err := g.GrafanaLive.PublishTokenContext(ctx.Req.Context(), token, channelID)
+	if err != nil {
+		logger.Error("Error checking publish token permissions", "error", err, "channel", channelID, "token", token.ID)
+		ctx.Resp.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if !allowed {
+		ctx.Resp.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	body, err := io.ReadAll(ctx.Req.Body)
+	if err != nil {
+		logger.Error("Error reading body", "error", err)
+		ctx.Resp.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	logger.Debug("Live channel token push request",
+		"protocol", "http",
+		"channel", channelID,
+		"token", token.ID,
+		"bodyLength", len(body),
+	)
+
+	if ok, scope := g.GrafanaLive.PublishLimiter.Allow(token.OrgId, channelID, "", len(body)); !ok {
+		logger.Debug("Push rate limit exceeded", "channel", channelID, "scope", scope)
+		ctx.Resp.WriteHeader(http.StatusTooManyRequests)
+		return
+	}
+
+	_, err = g.GrafanaLive.Pipeline.ProcessInput(reqCtx, token.OrgId, channelID, body)
+	if err != nil {
+		logger.Error("Pipeline input processing error", "error", err, "body", string(body))
+		if errors.Is(err, liveDto.ErrInvalidChannelID) {
+			ctx.Resp.WriteHeader(http.StatusBadRequest)
+		} else {
+			ctx.Resp.WriteHeader(http.StatusInternalServerError)
+		}
+		return
+	}
+}
@@ -0,0 +1,21 @@
+package pushurl
+
+import (
+	"github.com/grafana/grafana/pkg/services/live/telemetry"
+)
+
+// StreamChannels returns channels metric frames pushed into a stream are
+// published to, one per unique frame key.
+func StreamChannels(streamID string, metricFrames []telemetry.FrameWrapper) []string {
+	channels := make([]string, 0, len(metricFrames))
+	seen := make(map[string]struct{}, len(metricFrames))
+	for _, mf := range metricFrames {
+		channel := "stream/" + streamID + "/" + mf.Key()
+		if _, ok := seen[channel]; ok {
+			continue
+		}
+		seen[channel] = struct{}{}
+		channels = append(channels, channel)
+	}
+	return channels
+}
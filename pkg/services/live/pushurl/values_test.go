@@ -4,6 +4,9 @@ import (
 	"net/url"
 	"testing"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/services/live/telemetry"
+
 	"github.com/stretchr/testify/require"
 )
 
@@ -13,3 +16,19 @@ func TestFrameFormatFromValues(t *testing.T) {
 	values.Set(frameFormatParam, "wide")
 	require.Equal(t, "wide", FrameFormatFromValues(values))
 }
+
+type testFrame struct {
+	key string
+}
+
+func (f testFrame) Key() string        { return f.key }
+func (f testFrame) Frame() *data.Frame { return nil }
+
+func TestStreamChannels(t *testing.T) {
+	channels := StreamChannels("test", []telemetry.FrameWrapper{
+		testFrame{key: "cpu"},
+		testFrame{key: "mem"},
+		testFrame{key: "cpu"},
+	})
+	require.Equal(t, []string{"stream/test/cpu", "stream/test/mem"}, channels)
+}
@@ -1,6 +1,7 @@
 package pushws
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -11,7 +12,11 @@ import (
 	"github.com/grafana/grafana/pkg/services/live/convert"
 	"github.com/grafana/grafana/pkg/services/live/livecontext"
 	"github.com/grafana/grafana/pkg/services/live/managedstream"
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
 	"github.com/grafana/grafana/pkg/services/live/pushurl"
+	"github.com/grafana/grafana/pkg/services/live/ratelimit"
+	"github.com/grafana/grafana/pkg/services/live/telemetry"
+	"github.com/grafana/grafana/pkg/util"
 
 	"github.com/gorilla/websocket"
 	liveDto "github.com/grafana/grafana-plugin-sdk-go/live"
@@ -50,6 +55,17 @@ type Config struct {
 	// PingInterval sets interval server will send ping messages to clients.
 	// By default DefaultWebsocketPingInterval will be used.
 	PingInterval time.Duration
+
+	// PublishLimiter is used to limit the rate of pushed data. When a limit
+	// is exceeded connection closed with CloseTryAgainLater code. Nil means
+	// no limits.
+	PublishLimiter *ratelimit.Limiter
+
+	// CanPublishWithToken checks that a publish token found in request context
+	// allows publishing into a channel. Connections with a token are closed with
+	// ClosePolicyViolation code when a token does not allow publishing, nil means
+	// publishing with tokens is not allowed at all.
+	CanPublishWithToken func(ctx context.Context, token pipeline.PublishToken, channel string) (bool, error)
 }
 
 // NewHandler creates new Handler.
@@ -160,6 +176,11 @@ func (s *Handler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	token, withToken := pipeline.GetContextPublishToken(r.Context())
+
+	connID := util.GenerateShortUID()
+	defer s.config.PublishLimiter.Forget(connID)
+
 	for {
 		_, body, err := conn.ReadMessage()
 		if err != nil {
@@ -189,6 +210,28 @@ func (s *Handler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 			continue
 		}
 
+		channels := pushurl.StreamChannels(streamID, metricFrames)
+		if ok, scope := s.config.PublishLimiter.AllowChannels(user.OrgId, channels, connID, len(body)); !ok {
+			logger.Info("Push rate limit exceeded, closing connection", "streamId", streamID, "scope", scope)
+			deadline := time.Now().Add(time.Second)
+			_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "rate limit exceeded"), deadline)
+			return
+		}
+
+		if withToken {
+			allowed, err := s.canPublishWithToken(r.Context(), token, streamID, metricFrames)
+			if err != nil {
+				logger.Error("Error checking publish token permissions", "error", err, "streamId", streamID)
+				return
+			}
+			if !allowed {
+				logger.Info("Publish token does not allow push, closing connection", "streamId", streamID, "token", token.ID)
+				deadline := time.Now().Add(time.Second)
+				_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "permission denied"), deadline)
+				return
+			}
+		}
+
 		for _, mf := range metricFrames {
 			err := stream.Push(mf.Key(), mf.Frame())
 			if err != nil {
@@ -198,3 +241,19 @@ func (s *Handler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 		}
 	}
 }
+
+// canPublishWithToken checks every channel frames are pushed into since a
+// token may allow publishing into some channels of a stream only. Token is
+// checked on every message as it can expire during connection lifetime.
+func (s *Handler) canPublishWithToken(ctx context.Context, token pipeline.PublishToken, streamID string, metricFrames []telemetry.FrameWrapper) (bool, error) {
+	if s.config.CanPublishWithToken == nil {
+		return false, nil
+	}
+	for _, mf := range metricFrames {
+		allowed, err := s.config.CanPublishWithToken(ctx, token, "stream/"+streamID+"/"+mf.Key())
+		if err != nil || !allowed {
+			return false, err
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,222 @@
+// Package ratelimit contains publish rate limiting for Grafana Live.
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/time/rate"
+)
+
+var throttledPublishes = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "grafana_live",
+	Name:      "publish_throttled_total",
+	Help:      "Number of publications rejected by Live publish rate limits.",
+}, []string{"scope", "unit"})
+
+// Limits defines rate limits for a single scope. Zero value means no limit.
+// Bucket burst equals the per second limit, so a single publication larger
+// than BytesPerSecond is always rejected.
+type Limits struct {
+	MessagesPerSecond int
+	BytesPerSecond    int
+}
+
+func (l Limits) enabled() bool {
+	return l.MessagesPerSecond > 0 || l.BytesPerSecond > 0
+}
+
+// Config of Limiter.
+type Config struct {
+	Org        Limits
+	Channel    Limits
+	Connection Limits
+}
+
+// Scope of rate limit which was exceeded.
+type Scope string
+
+const (
+	ScopeOrg        Scope = "org"
+	ScopeChannel    Scope = "channel"
+	ScopeConnection Scope = "connection"
+)
+
+// idleTimeout is a time after which unused limiter state is removed.
+const idleTimeout = 5 * time.Minute
+
+type bucket struct {
+	messages *rate.Limiter
+	bytes    *rate.Limiter
+	lastUsed time.Time
+}
+
+func newBucket(limits Limits) *bucket {
+	b := &bucket{}
+	if limits.MessagesPerSecond > 0 {
+		b.messages = rate.NewLimiter(rate.Limit(limits.MessagesPerSecond), limits.MessagesPerSecond)
+	}
+	if limits.BytesPerSecond > 0 {
+		b.bytes = rate.NewLimiter(rate.Limit(limits.BytesPerSecond), limits.BytesPerSecond)
+	}
+	return b
+}
+
+// Limiter checks publications against per org, per channel and per
+// connection limits using token buckets.
+type Limiter struct {
+	mu          sync.Mutex
+	config      Config
+	orgs        map[string]*bucket
+	channels    map[string]*bucket
+	connections map[string]*bucket
+}
+
+// NewLimiter creates new Limiter.
+func NewLimiter(config Config) *Limiter {
+	return &Limiter{
+		config:      config,
+		orgs:        map[string]*bucket{},
+		channels:    map[string]*bucket{},
+		connections: map[string]*bucket{},
+	}
+}
+
+// Enabled returns true if any limit configured. Nil Limiter is
+// disabled and allows all publications.
+func (l *Limiter) Enabled() bool {
+	return l != nil && (l.config.Org.enabled() || l.config.Channel.enabled() || l.config.Connection.enabled())
+}
+
+// Allow checks whether publication of size bytes into a channel is allowed.
+// Tokens are only consumed when publication passes all limits. Empty connID
+// skips connection limit check (for example for HTTP requests). In case of
+// rejection the exceeded Scope returned.
+func (l *Limiter) Allow(orgID int64, channel string, connID string, size int) (bool, Scope) {
+	return l.AllowChannels(orgID, []string{channel}, connID, size)
+}
+
+// AllowChannels is like Allow but for a single publication which is split
+// into several channels, as it happens with pushed metrics. Org and connection
+// limits are checked once while every channel is charged for the whole
+// publication, so all ways to publish into a channel share its limit.
+func (l *Limiter) AllowChannels(orgID int64, channels []string, connID string, size int) (bool, Scope) {
+	if !l.Enabled() {
+		return true, ""
+	}
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	type check struct {
+		scope  Scope
+		bucket *bucket
+	}
+	var checks []check
+	if l.config.Org.enabled() {
+		checks = append(checks, check{ScopeOrg, getBucket(l.orgs, strconv.FormatInt(orgID, 10), l.config.Org, now)})
+	}
+	if l.config.Channel.enabled() {
+		seen := make(map[string]struct{}, len(channels))
+		for _, channel := range channels {
+			key := strconv.FormatInt(orgID, 10) + "/" + channel
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			checks = append(checks, check{ScopeChannel, getBucket(l.channels, key, l.config.Channel, now)})
+		}
+	}
+	if l.config.Connection.enabled() && connID != "" {
+		checks = append(checks, check{ScopeConnection, getBucket(l.connections, connID, l.config.Connection, now)})
+	}
+
+	var reservations []*rate.Reservation
+	cancel := func() {
+		for _, r := range reservations {
+			r.CancelAt(now)
+		}
+	}
+	for _, c := range checks {
+		if c.bucket.messages != nil {
+			r := c.bucket.messages.ReserveN(now, 1)
+			if !r.OK() || r.DelayFrom(now) > 0 {
+				r.CancelAt(now)
+				cancel()
+				throttledPublishes.WithLabelValues(string(c.scope), "messages").Inc()
+				return false, c.scope
+			}
+			reservations = append(reservations, r)
+		}
+		if c.bucket.bytes != nil {
+			r := c.bucket.bytes.ReserveN(now, size)
+			if !r.OK() || r.DelayFrom(now) > 0 {
+				r.CancelAt(now)
+				cancel()
+				throttledPublishes.WithLabelValues(string(c.scope), "bytes").Inc()
+				return false, c.scope
+			}
+			reservations = append(reservations, r)
+		}
+	}
+	return true, ""
+}
+
+func getBucket(buckets map[string]*bucket, key string, limits Limits, now time.Time) *bucket {
+	b, ok := buckets[key]
+	if !ok {
+		b = newBucket(limits)
+		buckets[key] = b
+	}
+	b.lastUsed = now
+	return b
+}
+
+// Forget removes connection state, should be called when connection closed.
+func (l *Limiter) Forget(connID string) {
+	if !l.Enabled() {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.connections, connID)
+}
+
+func (l *Limiter) removeIdle(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for k, b := range l.orgs {
+		if now.Sub(b.lastUsed) > idleTimeout {
+			delete(l.orgs, k)
+		}
+	}
+	for k, b := range l.channels {
+		if now.Sub(b.lastUsed) > idleTimeout {
+			delete(l.channels, k)
+		}
+	}
+	for k, b := range l.connections {
+		if now.Sub(b.lastUsed) > idleTimeout {
+			delete(l.connections, k)
+		}
+	}
+}
+
+// Run periodically removes state of idle orgs, channels and connections.
+func (l *Limiter) Run(ctx context.Context) error {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-ticker.C:
+			l.removeIdle(now)
+		}
+	}
+}
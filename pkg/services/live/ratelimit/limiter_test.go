@@ -0,0 +1,108 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter_Disabled(t *testing.T) {
+	l := NewLimiter(Config{})
+	for i := 0; i < 100; i++ {
+		ok, _ := l.Allow(1, "stream/test/x", "conn", 1024)
+		require.True(t, ok)
+	}
+}
+
+func TestLimiter_Nil(t *testing.T) {
+	var l *Limiter
+	require.False(t, l.Enabled())
+	ok, _ := l.Allow(1, "stream/test/x", "conn", 1024)
+	require.True(t, ok)
+	l.Forget("conn")
+}
+
+func TestLimiter_ChannelMessages(t *testing.T) {
+	l := NewLimiter(Config{Channel: Limits{MessagesPerSecond: 2}})
+	ok, _ := l.Allow(1, "stream/test/x", "", 10)
+	require.True(t, ok)
+	ok, _ = l.Allow(1, "stream/test/x", "", 10)
+	require.True(t, ok)
+	ok, scope := l.Allow(1, "stream/test/x", "", 10)
+	require.False(t, ok)
+	require.Equal(t, ScopeChannel, scope)
+
+	// Other channel and other org have separate limits.
+	ok, _ = l.Allow(1, "stream/test/y", "", 10)
+	require.True(t, ok)
+	ok, _ = l.Allow(2, "stream/test/x", "", 10)
+	require.True(t, ok)
+}
+
+func TestLimiter_SharedChannelBucket(t *testing.T) {
+	l := NewLimiter(Config{Channel: Limits{MessagesPerSecond: 2}})
+
+	// WebSocket push of metrics into two channels of a stream.
+	ok, _ := l.AllowChannels(1, []string{"stream/test/cpu", "stream/test/mem"}, "conn", 10)
+	require.True(t, ok)
+	// HTTP push into one of these channels uses the same bucket.
+	ok, _ = l.Allow(1, "stream/test/cpu", "", 10)
+	require.True(t, ok)
+	ok, scope := l.AllowChannels(1, []string{"stream/test/cpu"}, "conn", 10)
+	require.False(t, ok)
+	require.Equal(t, ScopeChannel, scope)
+	ok, scope = l.Allow(1, "stream/test/cpu", "", 10)
+	require.False(t, ok)
+	require.Equal(t, ScopeChannel, scope)
+
+	// Rejected publication does not consume tokens of other channels.
+	ok, _ = l.AllowChannels(1, []string{"stream/test/mem", "stream/test/cpu"}, "conn", 10)
+	require.False(t, ok)
+	ok, _ = l.Allow(1, "stream/test/mem", "", 10)
+	require.True(t, ok)
+}
+
+func TestLimiter_Bytes(t *testing.T) {
+	l := NewLimiter(Config{Org: Limits{BytesPerSecond: 100}})
+	ok, _ := l.Allow(1, "stream/test/x", "", 80)
+	require.True(t, ok)
+	ok, scope := l.Allow(1, "stream/test/y", "", 80)
+	require.False(t, ok)
+	require.Equal(t, ScopeOrg, scope)
+	ok, _ = l.Allow(1, "stream/test/y", "", 20)
+	require.True(t, ok)
+
+	// Publications larger than the per second limit never fit the bucket.
+	l = NewLimiter(Config{Org: Limits{BytesPerSecond: 100}})
+	ok, _ = l.Allow(1, "stream/test/x", "", 101)
+	require.False(t, ok)
+}
+
+func TestLimiter_NoTokensConsumedOnReject(t *testing.T) {
+	l := NewLimiter(Config{
+		Channel:    Limits{MessagesPerSecond: 10},
+		Connection: Limits{MessagesPerSecond: 1},
+	})
+	ok, _ := l.Allow(1, "stream/test/x", "a", 10)
+	require.True(t, ok)
+	for i := 0; i < 5; i++ {
+		ok, scope := l.Allow(1, "stream/test/x", "a", 10)
+		require.False(t, ok)
+		require.Equal(t, ScopeConnection, scope)
+	}
+	// Channel tokens were returned for rejected publications.
+	for i := 0; i < 9; i++ {
+		ok, _ := l.Allow(1, "stream/test/x", "", 10)
+		require.True(t, ok)
+	}
+}
+
+func TestLimiter_RemoveIdle(t *testing.T) {
+	l := NewLimiter(Config{Connection: Limits{MessagesPerSecond: 1}})
+	ok, _ := l.Allow(1, "stream/test/x", "a", 10)
+	require.True(t, ok)
+	require.Len(t, l.connections, 1)
+	l.removeIdle(time.Now().Add(2 * idleTimeout))
+	require.Len(t, l.connections, 0)
+}
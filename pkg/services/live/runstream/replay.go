@@ -0,0 +1,329 @@
+package runstream
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/live"
+	"github.com/grafana/grafana/pkg/tsdb/testdatasource"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// FramePusher pushes frames into managed stream channels.
+type FramePusher interface {
+	PushFrame(orgID int64, channel string, frame *data.Frame) error
+}
+
+// Supported replay data formats.
+const (
+	ReplayFormatNDJSON = "ndjson"
+	ReplayFormatCSV    = "csv"
+)
+
+// maxReplayLineSize is a maximum size of a single NDJSON line.
+const maxReplayLineSize = 10 * 1024 * 1024
+
+// minReplayLoopInterval is a minimum duration of a single iteration of a
+// looping replay, so that replays of frames without time values don't spin.
+const minReplayLoopInterval = 100 * time.Millisecond
+
+// ReplayRequest describes data to replay and replay options.
+type ReplayRequest struct {
+	// Channel is a managed stream channel to publish frames to.
+	Channel string `json:"channel"`
+	// Format of Data: ndjson (one JSON-encoded data frame per line) or csv.
+	Format string `json:"format"`
+	// Data to replay.
+	Data string `json:"data"`
+	// Speed is a replay speed multiplier, 1 by default. For example 2 replays
+	// twice faster than original data was recorded.
+	Speed float64 `json:"speed"`
+	// Loop enables starting over when all frames sent.
+	Loop bool `json:"loop"`
+}
+
+// ReplayInfo describes a running replay.
+type ReplayInfo struct {
+	ID         string    `json:"id"`
+	OrgID      int64     `json:"-"`
+	Channel    string    `json:"channel"`
+	Speed      float64   `json:"speed"`
+	Loop       bool      `json:"loop"`
+	NumFrames  int       `json:"numFrames"`
+	FramesSent int64     `json:"framesSent"`
+	Started    time.Time `json:"started"`
+}
+
+type replayFrame struct {
+	time  time.Time
+	frame *data.Frame
+}
+
+type replay struct {
+	mu       sync.Mutex
+	info     ReplayInfo
+	frames   []replayFrame
+	cancelFn func()
+}
+
+func (r *replay) getInfo() ReplayInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.info
+}
+
+func (r *replay) incSent() {
+	r.mu.Lock()
+	r.info.FramesSent++
+	r.mu.Unlock()
+}
+
+// ReplayRunner republishes recorded data into managed stream channels
+// preserving time intervals between frames.
+type ReplayRunner struct {
+	mu      sync.Mutex
+	pusher  FramePusher
+	clock   clock.Clock
+	replays map[string]*replay
+}
+
+// NewReplayRunner creates new ReplayRunner.
+func NewReplayRunner(pusher FramePusher) *ReplayRunner {
+	return &ReplayRunner{
+		pusher:  pusher,
+		clock:   clock.New(),
+		replays: map[string]*replay{},
+	}
+}
+
+// Run ReplayRunner. Stops all replays when context is done.
+func (r *ReplayRunner) Run(ctx context.Context) error {
+	<-ctx.Done()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, rp := range r.replays {
+		rp.cancelFn()
+	}
+	return ctx.Err()
+}
+
+// Start parses replay data and starts publishing it in background.
+func (r *ReplayRunner) Start(orgID int64, req ReplayRequest) (ReplayInfo, error) {
+	addr, err := live.ParseChannel(req.Channel)
+	if err != nil {
+		return ReplayInfo{}, err
+	}
+	if addr.Scope != live.ScopeStream {
+		return ReplayInfo{}, fmt.Errorf("replay only supported for %s scope channels", live.ScopeStream)
+	}
+	if req.Speed < 0 {
+		return ReplayInfo{}, errors.New("replay speed must be positive")
+	}
+	if req.Speed == 0 {
+		req.Speed = 1
+	}
+	frames, err := parseReplayFrames(req.Format, strings.NewReader(req.Data))
+	if err != nil {
+		return ReplayInfo{}, err
+	}
+	if len(frames) == 0 {
+		return ReplayInfo{}, errors.New("no frames to replay")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rp := &replay{
+		info: ReplayInfo{
+			ID:        util.GenerateShortUID(),
+			OrgID:     orgID,
+			Channel:   req.Channel,
+			Speed:     req.Speed,
+			Loop:      req.Loop,
+			NumFrames: len(frames),
+			Started:   r.clock.Now(),
+		},
+		frames:   frames,
+		cancelFn: cancel,
+	}
+
+	r.mu.Lock()
+	r.replays[rp.info.ID] = rp
+	r.mu.Unlock()
+
+	go func() {
+		defer func() {
+			r.mu.Lock()
+			delete(r.replays, rp.info.ID)
+			r.mu.Unlock()
+			cancel()
+		}()
+		err := r.runReplay(ctx, rp)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			logger.Error("Error replaying data", "channel", rp.info.Channel, "replay", rp.info.ID, "error", err)
+			return
+		}
+		logger.Debug("Replay finished", "channel", rp.info.Channel, "replay", rp.info.ID)
+	}()
+
+	return rp.getInfo(), nil
+}
+
+func (r *ReplayRunner) runReplay(ctx context.Context, rp *replay) error {
+	for {
+		started := r.clock.Now()
+		var prevTime time.Time
+		for _, f := range rp.frames {
+			if !prevTime.IsZero() && !f.time.IsZero() {
+				delay := time.Duration(float64(f.time.Sub(prevTime)) / rp.info.Speed)
+				if err := r.wait(ctx, delay); err != nil {
+					return err
+				}
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if !f.time.IsZero() {
+				prevTime = f.time
+			}
+			if err := r.pusher.PushFrame(rp.info.OrgID, rp.info.Channel, f.frame); err != nil {
+				return err
+			}
+			rp.incSent()
+		}
+		if !rp.info.Loop {
+			return nil
+		}
+		if err := r.wait(ctx, minReplayLoopInterval-r.clock.Since(started)); err != nil {
+			return err
+		}
+	}
+}
+
+// wait blocks for a delay or until context is done.
+func (r *ReplayRunner) wait(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return nil
+	}
+	timer := r.clock.Timer(delay)
+	select {
+	case <-ctx.Done():
+		timer.Stop()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Stop stops a replay by ID.
+func (r *ReplayRunner) Stop(orgID int64, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rp, ok := r.replays[id]
+	if !ok || rp.info.OrgID != orgID {
+		return ErrReplayNotFound
+	}
+	rp.cancelFn()
+	delete(r.replays, id)
+	return nil
+}
+
+// List returns running replays of an organization.
+func (r *ReplayRunner) List(orgID int64) []ReplayInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := make([]ReplayInfo, 0, len(r.replays))
+	for _, rp := range r.replays {
+		info := rp.getInfo()
+		if info.OrgID == orgID {
+			result = append(result, info)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Started.Before(result[j].Started)
+	})
+	return result
+}
+
+// ErrReplayNotFound returned when replay does not exist.
+var ErrReplayNotFound = errors.New("replay not found")
+
+func parseReplayFrames(format string, reader io.Reader) ([]replayFrame, error) {
+	switch format {
+	case ReplayFormatNDJSON:
+		return parseNDJSONFrames(reader)
+	case ReplayFormatCSV:
+		return parseCSVFrames(reader)
+	default:
+		return nil, fmt.Errorf("unsupported replay format: %q", format)
+	}
+}
+
+func parseNDJSONFrames(reader io.Reader) ([]replayFrame, error) {
+	var frames []replayFrame
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxReplayLineSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		b := scanner.Bytes()
+		if len(strings.TrimSpace(string(b))) == 0 {
+			continue
+		}
+		frame := &data.Frame{}
+		if err := json.Unmarshal(b, frame); err != nil {
+			return nil, fmt.Errorf("error decoding frame on line %d: %w", line, err)
+		}
+		frames = append(frames, replayFrame{time: frameTime(frame, 0), frame: frame})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return frames, nil
+}
+
+// parseCSVFrames splits CSV content to one frame per row so rows can
+// be sent according to values of time column.
+func parseCSVFrames(reader io.Reader) ([]replayFrame, error) {
+	frame, err := testdatasource.LoadCsvContent(reader, "")
+	if err != nil {
+		return nil, err
+	}
+	numRows, err := frame.RowLen()
+	if err != nil {
+		return nil, err
+	}
+	frames := make([]replayFrame, 0, numRows)
+	for i := 0; i < numRows; i++ {
+		rowFrame := frame.EmptyCopy()
+		rowFrame.AppendRow(frame.RowCopy(i)...)
+		frames = append(frames, replayFrame{time: frameTime(frame, i), frame: rowFrame})
+	}
+	return frames, nil
+}
+
+// frameTime returns time value of first time field at row index.
+func frameTime(frame *data.Frame, rowIdx int) time.Time {
+	for _, f := range frame.Fields {
+		if f.Type() != data.FieldTypeTime && f.Type() != data.FieldTypeNullableTime {
+			continue
+		}
+		if f.Len() <= rowIdx {
+			return time.Time{}
+		}
+		v, ok := f.ConcreteAt(rowIdx)
+		if !ok {
+			return time.Time{}
+		}
+		return v.(time.Time)
+	}
+	return time.Time{}
+}
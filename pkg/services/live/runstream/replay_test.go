@@ -0,0 +1,186 @@
+package runstream
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+type testFramePusher struct {
+	mu     sync.Mutex
+	frames []*data.Frame
+	sent   chan struct{}
+}
+
+func (p *testFramePusher) PushFrame(_ int64, _ string, frame *data.Frame) error {
+	p.mu.Lock()
+	p.frames = append(p.frames, frame)
+	p.mu.Unlock()
+	p.sent <- struct{}{}
+	return nil
+}
+
+func (p *testFramePusher) numFrames() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.frames)
+}
+
+// testClock is a mock clock reporting delays of replay timers once they are
+// set, so tests advance the clock only when a replay waits.
+type testClock struct {
+	*clock.Mock
+	timers chan time.Duration
+}
+
+func newTestClock() *testClock {
+	return &testClock{Mock: clock.NewMock(), timers: make(chan time.Duration, 1)}
+}
+
+func (c *testClock) Timer(d time.Duration) *clock.Timer {
+	timer := c.Mock.Timer(d)
+	c.timers <- d
+	return timer
+}
+
+// nextTimer waits until a replay sets a timer and returns its delay.
+func (c *testClock) nextTimer(t *testing.T) time.Duration {
+	t.Helper()
+	select {
+	case d := <-c.timers:
+		return d
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for timer")
+		return 0
+	}
+}
+
+// expectTimer waits until a replay sets a timer, checks its delay and fires it.
+func (c *testClock) expectTimer(t *testing.T, delay time.Duration) {
+	t.Helper()
+	require.Equal(t, delay, c.nextTimer(t))
+	c.Add(delay)
+}
+
+func expectFrame(t *testing.T, pusher *testFramePusher) {
+	t.Helper()
+	select {
+	case <-pusher.sent:
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for frame")
+	}
+}
+
+func newTestReplayRunner(pusher FramePusher) (*ReplayRunner, *testClock) {
+	clk := newTestClock()
+	runner := NewReplayRunner(pusher)
+	runner.clock = clk
+	return runner, clk
+}
+
+func TestReplayRunner_CSV(t *testing.T) {
+	pusher := &testFramePusher{sent: make(chan struct{}, 10)}
+	runner, clk := newTestReplayRunner(pusher)
+
+	info, err := runner.Start(1, ReplayRequest{
+		Channel: "stream/test/replay",
+		Format:  ReplayFormatCSV,
+		Data:    "time,value\n1636450000000,1\n1636450000100,2\n1636450000200,3",
+		Speed:   10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, info.NumFrames)
+
+	// Rows are 100ms apart, replayed 10 times faster.
+	expectFrame(t, pusher)
+	clk.expectTimer(t, 10*time.Millisecond)
+	expectFrame(t, pusher)
+	clk.expectTimer(t, 10*time.Millisecond)
+	expectFrame(t, pusher)
+
+	pusher.mu.Lock()
+	defer pusher.mu.Unlock()
+	require.Len(t, pusher.frames, 3)
+	v, ok := pusher.frames[2].Fields[1].ConcreteAt(0)
+	require.True(t, ok)
+	require.Equal(t, int64(3), v)
+	ts, ok := pusher.frames[0].Fields[0].ConcreteAt(0)
+	require.True(t, ok)
+	require.Equal(t, time.Unix(1636450000, 0).UTC(), ts.(time.Time).UTC())
+}
+
+func TestReplayRunner_NDJSONLoopAndStop(t *testing.T) {
+	pusher := &testFramePusher{sent: make(chan struct{}, 100)}
+	runner, clk := newTestReplayRunner(pusher)
+
+	var lines []string
+	for i := 0; i < 2; i++ {
+		frame := data.NewFrame("test",
+			data.NewField("time", nil, []time.Time{time.Unix(int64(i), 0)}),
+			data.NewField("value", nil, []float64{float64(i)}),
+		)
+		b, err := json.Marshal(frame)
+		require.NoError(t, err)
+		lines = append(lines, string(b))
+	}
+
+	info, err := runner.Start(1, ReplayRequest{
+		Channel: "stream/test/replay",
+		Format:  ReplayFormatNDJSON,
+		Data:    strings.Join(lines, "\n"),
+		Speed:   100,
+		Loop:    true,
+	})
+	require.NoError(t, err)
+
+	// Looping replay sends more frames than recorded, the loop iteration is
+	// completed to minReplayLoopInterval.
+	expectFrame(t, pusher)
+	clk.expectTimer(t, 10*time.Millisecond)
+	expectFrame(t, pusher)
+	clk.expectTimer(t, minReplayLoopInterval-10*time.Millisecond)
+	expectFrame(t, pusher)
+	require.Equal(t, 10*time.Millisecond, clk.nextTimer(t))
+
+	require.Len(t, runner.List(1), 1)
+	require.Len(t, runner.List(2), 0)
+	require.ErrorIs(t, runner.Stop(2, info.ID), ErrReplayNotFound)
+	require.NoError(t, runner.Stop(1, info.ID))
+	require.Len(t, runner.List(1), 0)
+}
+
+func TestReplayRunner_LoopWithoutTime(t *testing.T) {
+	pusher := &testFramePusher{sent: make(chan struct{}, 100)}
+	runner, clk := newTestReplayRunner(pusher)
+
+	info, err := runner.Start(1, ReplayRequest{
+		Channel: "stream/test/replay",
+		Format:  ReplayFormatCSV,
+		Data:    "value\n1",
+		Speed:   1,
+		Loop:    true,
+	})
+	require.NoError(t, err)
+
+	// Every loop iteration takes at least minReplayLoopInterval.
+	expectFrame(t, pusher)
+	clk.expectTimer(t, minReplayLoopInterval)
+	expectFrame(t, pusher)
+	require.Equal(t, minReplayLoopInterval, clk.nextTimer(t))
+	require.NoError(t, runner.Stop(1, info.ID))
+	require.Equal(t, 2, pusher.numFrames())
+}
+
+func TestReplayRunner_InvalidRequest(t *testing.T) {
+	runner := NewReplayRunner(&testFramePusher{})
+	_, err := runner.Start(1, ReplayRequest{Channel: "plugin/test/x", Format: ReplayFormatCSV, Data: "time,value\n1,1"})
+	require.Error(t, err)
+	_, err = runner.Start(1, ReplayRequest{Channel: "stream/test/x", Format: "xml", Data: "<a/>"})
+	require.Error(t, err)
+}
@@ -410,20 +410,39 @@ func (st DBstore) GetNamespaces(ctx context.Context, orgID int64, user *models.S
 		}
 
 		for _, f := range folders {
-			namespaceMap[f.Uid] = f
+			namespaceMap[f.Uid] = namespaceFolder(f)
 		}
 		page += 1
 	}
 	return namespaceMap, nil
 }
 
+// namespaceFolder returns the folder with the title set to the name of its
+// namespace. Titles of nested folders are only unique within their parent
+// folder, so namespaces of nested folders are named by the folder uid.
+func namespaceFolder(f *models.Folder) *models.Folder {
+	if f.ParentId != 0 {
+		f.Title = f.Uid
+	}
+	return f
+}
+
 // GetNamespaceByTitle is a handler for retrieving a namespace by its title. Alerting rules follow a Grafana folder-like structure which we call namespaces.
+// The namespace of a nested folder is its uid, a folder in the General folder
+// with the same title takes precedence.
 func (st DBstore) GetNamespaceByTitle(ctx context.Context, namespace string, orgID int64, user *models.SignedInUser, withCanSave bool) (*models.Folder, error) {
 	s := dashboards.NewFolderService(orgID, user, st.SQLStore)
 	folder, err := s.GetFolderByTitle(ctx, namespace)
+	if errors.Is(err, models.ErrFolderNotFound) {
+		folder, err = s.GetFolderByUID(ctx, namespace)
+		if err == nil && folder.ParentId == 0 {
+			err = models.ErrFolderNotFound
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
+	folder = namespaceFolder(folder)
 
 	if withCanSave {
 		g := guardian.New(ctx, folder.Id, orgID, user)
@@ -20,6 +20,9 @@ type DashboardProvisioner interface {
 	GetProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
 	CleanUpOrphanedDashboards(ctx context.Context)
+	CommitDashboard(ctx context.Context, provisioning *models.DashboardProvisioning, dashboard *models.Dashboard,
+		user *models.SignedInUser, message string) error
+	GetGitSyncStatus(ctx context.Context) []*GitSyncStatus
 }
 
 // DashboardProvisionerFactory creates DashboardProvisioners based on input
@@ -114,12 +117,38 @@ func (provider *Provisioner) GetProvisionerResolvedPath(name string) string {
 func (provider *Provisioner) GetAllowUIUpdatesFromConfig(name string) bool {
 	for _, config := range provider.configs {
 		if config.Name == name {
-			return config.AllowUIUpdates
+			return allowsUIUpdates(config)
 		}
 	}
 	return false
 }
 
+// CommitDashboard commits a dashboard saved in the UI to the git repository of its provisioner, if the
+// provisioner reads from a git repository and commits UI changes.
+func (provider *Provisioner) CommitDashboard(ctx context.Context, provisioning *models.DashboardProvisioning,
+	dashboard *models.Dashboard, user *models.SignedInUser, message string) error {
+	for _, reader := range provider.fileReaders {
+		if reader.Cfg.Name == provisioning.Name {
+			if reader.repo == nil || !reader.repo.commitUIChanges {
+				return nil
+			}
+			return reader.commitDashboard(ctx, provisioning, dashboard, user, message)
+		}
+	}
+	return nil
+}
+
+// GetGitSyncStatus returns the state of the provisioners reading from git repositories.
+func (provider *Provisioner) GetGitSyncStatus(ctx context.Context) []*GitSyncStatus {
+	result := []*GitSyncStatus{}
+	for _, reader := range provider.fileReaders {
+		if reader.repo != nil {
+			result = append(result, reader.gitSyncStatus(ctx))
+		}
+	}
+	return result
+}
+
 func getFileReaders(configs []*config, logger log.Logger, store dashboards.Store) ([]*FileReader, error) {
 	var readers []*FileReader
 
@@ -132,6 +161,13 @@ func getFileReaders(configs []*config, logger log.Logger, store dashboards.Store
 				return nil, errutil.Wrapf(err, "Failed to create file reader for config %v", config.Name)
 			}
 			readers = append(readers, fileReader)
+		case "git":
+			gitReader, err := NewDashboardGitReader(config, logger.New("type", config.Type, "name", config.Name),
+				store)
+			if err != nil {
+				return nil, errutil.Wrapf(err, "Failed to create git reader for config %v", config.Name)
+			}
+			readers = append(readers, gitReader)
 		default:
 			return nil, fmt.Errorf("type %s is not supported", config.Type)
 		}
@@ -38,6 +38,9 @@ type FileReader struct {
 	mux                     sync.RWMutex
 	usageTracker            *usageTracker
 	dbWriteAccessRestricted bool
+
+	// repo is set for readers importing dashboards from a git repository
+	repo *gitRepository
 }
 
 // NewDashboardFileReader returns a new filereader based on `config`
@@ -92,6 +95,17 @@ func (fr *FileReader) walkDisk(ctx context.Context) error {
 		return err
 	}
 
+	if fr.repo != nil {
+		fr.repo.syncMux.Lock()
+		defer fr.repo.syncMux.Unlock()
+
+		err := fr.repo.checkout(ctx, resolvedPath)
+		fr.repo.setError(err)
+		if err != nil {
+			return err
+		}
+	}
+
 	provisionedDashboardRefs, err := getProvisionedDashboardsByPath(fr.dashboardProvisioningService, fr.Cfg.Name)
 	if err != nil {
 		return err
@@ -104,6 +118,9 @@ func (fr *FileReader) walkDisk(ctx context.Context) error {
 	}
 
 	fr.handleMissingDashboardFiles(provisionedDashboardRefs, filesFoundOnDisk)
+	if fr.repo != nil {
+		fr.repo.removeMissingConflicts(filesFoundOnDisk)
+	}
 
 	usageTracker := newUsageTracker()
 	if fr.FoldersFromFilesStructure {
@@ -146,7 +163,7 @@ func (fr *FileReader) storeDashboardsInFolder(ctx context.Context, filesFoundOnD
 
 	// save dashboards based on json files
 	for path, fileInfo := range filesFoundOnDisk {
-		provisioningMetadata, err := fr.saveDashboard(path, folderID, fileInfo, dashboardRefs)
+		provisioningMetadata, err := fr.saveDashboard(ctx, path, folderID, fileInfo, dashboardRefs)
 		if err != nil {
 			fr.log.Error("failed to save dashboard", "error", err)
 			continue
@@ -174,7 +191,7 @@ func (fr *FileReader) storeDashboardsInFoldersFromFileStructure(ctx context.Cont
 			return fmt.Errorf("can't provision folder %q from file system structure: %w", folderName, err)
 		}
 
-		provisioningMetadata, err := fr.saveDashboard(path, folderID, fileInfo, dashboardRefs)
+		provisioningMetadata, err := fr.saveDashboard(ctx, path, folderID, fileInfo, dashboardRefs)
 		usageTracker.track(provisioningMetadata)
 		if err != nil {
 			fr.log.Error("failed to save dashboard", "error", err)
@@ -218,7 +235,7 @@ func (fr *FileReader) handleMissingDashboardFiles(provisionedDashboardRefs map[s
 }
 
 // saveDashboard saves or updates the dashboard provisioning file at path.
-func (fr *FileReader) saveDashboard(path string, folderID int64, fileInfo os.FileInfo,
+func (fr *FileReader) saveDashboard(ctx context.Context, path string, folderID int64, fileInfo os.FileInfo,
 	provisionedDashboardRefs map[string]*models.DashboardProvisioning) (provisioningMetadata, error) {
 	provisioningMetadata := provisioningMetadata{}
 	resolvedFileInfo, err := resolveSymlink(fileInfo, path)
@@ -245,9 +262,25 @@ func (fr *FileReader) saveDashboard(path string, folderID int64, fileInfo os.Fil
 	provisioningMetadata.identity = dashboardIdentity{title: dash.Dashboard.Title, folderID: dash.Dashboard.FolderId}
 
 	if upToDate {
+		if fr.repo != nil {
+			fr.repo.removeConflict(path)
+		}
 		return provisioningMetadata, nil
 	}
 
+	// dashboards changed both in the repository and in the database are not imported
+	if fr.repo != nil && alreadyProvisioned {
+		dbDashboard, changed, err := fr.repo.databaseChanged(ctx, fr.Cfg.OrgID, provisionedData)
+		if err != nil {
+			return provisioningMetadata, err
+		}
+		if changed {
+			fr.repo.addConflict(path, dbDashboard, fr.resolvedPath())
+			return provisioningMetadata, nil
+		}
+		fr.repo.removeConflict(path)
+	}
+
 	if dash.Dashboard.Id != 0 {
 		dash.Dashboard.Data.Set("id", nil)
 		dash.Dashboard.Id = 0
@@ -262,9 +295,14 @@ func (fr *FileReader) saveDashboard(path string, folderID int64, fileInfo os.Fil
 		dp := &models.DashboardProvisioning{
 			ExternalId: path,
 			Name:       fr.Cfg.Name,
-			Updated:    resolvedFileInfo.ModTime().Unix(),
 			CheckSum:   jsonFile.checkSum,
 		}
+		// git readers compare the provisioning time with the update time of the dashboard
+		// to detect changes made in the database, so the store records the update time of
+		// the saved dashboard for them instead of the file modification time
+		if fr.repo == nil {
+			dp.Updated = resolvedFileInfo.ModTime().Unix()
+		}
 		if _, err := fr.dashboardProvisioningService.SaveProvisionedDashboard(dash, dp); err != nil {
 			return provisioningMetadata, err
 		}
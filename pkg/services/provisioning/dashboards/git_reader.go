@@ -0,0 +1,340 @@
+package dashboards
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	dboards "github.com/grafana/grafana/pkg/dashboards"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	defaultGitCommitterName  = "Grafana"
+	defaultGitCommitterEmail = "grafana@localhost"
+)
+
+// GitSyncStatus is the state of a dashboard provider reading dashboards from a git repository.
+type GitSyncStatus struct {
+	Name            string         `json:"name"`
+	Path            string         `json:"path"`
+	Branch          string         `json:"branch"`
+	Commit          string         `json:"commit"`
+	CommitUIChanges bool           `json:"commitUIChanges"`
+	Error           string         `json:"error,omitempty"`
+	Conflicts       []*GitConflict `json:"conflicts"`
+}
+
+// GitConflict is a dashboard which was changed both in the repository and in the database since it
+// was last imported. The repository version isn't imported until the conflict is resolved.
+type GitConflict struct {
+	DashboardId  int64     `json:"dashboardId"`
+	DashboardUid string    `json:"dashboardUid"`
+	Title        string    `json:"title"`
+	File         string    `json:"file"`
+	Since        time.Time `json:"since"`
+}
+
+// gitRepository keeps the working tree of a git dashboard provider on the
+// configured branch, commits dashboards saved in the UI and tracks conflicts
+// between the repository and the database.
+type gitRepository struct {
+	branch          string
+	commitUIChanges bool
+	log             log.Logger
+
+	// syncMux makes sure commits don't interleave with imports.
+	syncMux sync.Mutex
+
+	mux       sync.RWMutex
+	conflicts map[string]*GitConflict
+	lastError string
+}
+
+// NewDashboardGitReader returns a new file reader importing dashboards from
+// the working tree of the git repository at the path in `config`.
+func NewDashboardGitReader(cfg *config, log log.Logger, store dboards.Store) (*FileReader, error) {
+	reader, err := NewDashboardFileReader(cfg, log, store)
+	if err != nil {
+		return nil, err
+	}
+
+	branch, _ := cfg.Options["branch"].(string)
+	commitUIChanges, _ := cfg.Options["commitUIChanges"].(bool)
+
+	reader.repo = &gitRepository{
+		branch:          branch,
+		commitUIChanges: commitUIChanges,
+		log:             log,
+		conflicts:       map[string]*GitConflict{},
+	}
+	return reader, nil
+}
+
+// allowsUIUpdates returns true if the provider for `cfg` commits dashboards saved in the UI.
+func allowsUIUpdates(cfg *config) bool {
+	if cfg.AllowUIUpdates {
+		return true
+	}
+	commitUIChanges, _ := cfg.Options["commitUIChanges"].(bool)
+	return cfg.Type == "git" && commitUIChanges
+}
+
+// checkout checks out the configured branch if the working tree is on another branch.
+func (r *gitRepository) checkout(ctx context.Context, dir string) error {
+	if r.branch == "" {
+		return nil
+	}
+
+	current, err := r.run(ctx, dir, nil, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return err
+	}
+	if current == r.branch {
+		return nil
+	}
+
+	r.log.Info("Checking out branch", "branch", r.branch, "current", current)
+	_, err = r.run(ctx, dir, nil, "checkout", r.branch)
+	return err
+}
+
+// commit writes the dashboard to the file at path and commits it, authored by user.
+// It returns the content of the file.
+func (r *gitRepository) commit(ctx context.Context, dir string, path string, dashboard *models.Dashboard,
+	user *models.SignedInUser, message string) ([]byte, error) {
+	if err := r.checkout(ctx, dir); err != nil {
+		return nil, err
+	}
+
+	data, err := dashboardFileContent(dashboard)
+	if err != nil {
+		return nil, err
+	}
+
+	mode := os.FileMode(0644)
+	if fileInfo, err := os.Stat(path); err == nil {
+		mode = fileInfo.Mode()
+	}
+	if err := ioutil.WriteFile(path, data, mode); err != nil {
+		return nil, err
+	}
+
+	if _, err := r.run(ctx, dir, nil, "add", "--", path); err != nil {
+		return nil, err
+	}
+
+	// nothing to commit if the file didn't change
+	if _, err := r.run(ctx, dir, nil, "diff", "--cached", "--quiet", "--", path); err == nil {
+		return data, nil
+	}
+
+	if message == "" {
+		message = fmt.Sprintf("Update dashboard %s", dashboard.Title)
+	}
+
+	var env []string
+	if _, err := r.run(ctx, dir, nil, "config", "user.email"); err != nil {
+		env = []string{"GIT_COMMITTER_NAME=" + defaultGitCommitterName, "GIT_COMMITTER_EMAIL=" + defaultGitCommitterEmail}
+	}
+
+	_, err = r.run(ctx, dir, env, "commit", "--author", gitAuthor(user), "--message", message, "--", path)
+	return data, err
+}
+
+// head returns the commit checked out in the working tree.
+func (r *gitRepository) head(ctx context.Context, dir string) string {
+	commit, err := r.run(ctx, dir, nil, "rev-parse", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return commit
+}
+
+func (r *gitRepository) run(ctx context.Context, dir string, env []string, args ...string) (string, error) {
+	// nolint:gosec
+	// We can ignore the gosec G204 warning on this one because the arguments come from the provisioning
+	// configuration file and the dashboards saved by Grafana.
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// databaseChanged returns true if the dashboard was saved in the database
+// since it was last imported from the repository.
+func (r *gitRepository) databaseChanged(ctx context.Context, orgID int64, provisioning *models.DashboardProvisioning) (*models.Dashboard, bool, error) {
+	query := &models.GetDashboardQuery{Id: provisioning.DashboardId, OrgId: orgID}
+	if err := bus.DispatchCtx(ctx, query); err != nil {
+		if errors.Is(err, models.ErrDashboardNotFound) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	return query.Result, query.Result.Updated.Unix() != provisioning.Updated, nil
+}
+
+func (r *gitRepository) addConflict(path string, dashboard *models.Dashboard, resolvedPath string) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if _, exists := r.conflicts[path]; exists {
+		return
+	}
+
+	file, err := filepath.Rel(resolvedPath, path)
+	if err != nil {
+		file = path
+	}
+
+	r.log.Warn("Dashboard changed in both the repository and the database, not importing it", "file", file,
+		"uid", dashboard.Uid)
+	r.conflicts[path] = &GitConflict{
+		DashboardId:  dashboard.Id,
+		DashboardUid: dashboard.Uid,
+		Title:        dashboard.Title,
+		File:         file,
+		Since:        time.Now(),
+	}
+}
+
+func (r *gitRepository) removeConflict(path string) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	delete(r.conflicts, path)
+}
+
+// removeMissingConflicts removes the conflicts of files which were deleted from the repository.
+func (r *gitRepository) removeMissingConflicts(filesFoundOnDisk map[string]os.FileInfo) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	for path := range r.conflicts {
+		if _, exists := filesFoundOnDisk[path]; !exists {
+			delete(r.conflicts, path)
+		}
+	}
+}
+
+func (r *gitRepository) setError(err error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	r.lastError = ""
+	if err != nil {
+		r.lastError = err.Error()
+	}
+}
+
+// commitDashboard commits a dashboard saved in the UI to the repository and
+// marks it as imported, so that it isn't imported again.
+func (fr *FileReader) commitDashboard(ctx context.Context, provisioning *models.DashboardProvisioning,
+	dashboard *models.Dashboard, user *models.SignedInUser, message string) error {
+	fr.repo.syncMux.Lock()
+	defer fr.repo.syncMux.Unlock()
+
+	data, err := fr.repo.commit(ctx, fr.resolvedPath(), provisioning.ExternalId, dashboard, user, message)
+	if err != nil {
+		fr.repo.setError(err)
+		return err
+	}
+
+	checkSum, err := util.Md5SumString(string(data))
+	if err != nil {
+		return err
+	}
+
+	cmd := &models.UpdateDashboardProvisioningCommand{
+		DashboardId: dashboard.Id,
+		Name:        provisioning.Name,
+		CheckSum:    checkSum,
+		Updated:     dashboard.Updated.Unix(),
+	}
+	if err := bus.DispatchCtx(ctx, cmd); err != nil {
+		return err
+	}
+
+	fr.repo.removeConflict(provisioning.ExternalId)
+	fr.repo.setError(nil)
+	return nil
+}
+
+func (fr *FileReader) gitSyncStatus(ctx context.Context) *GitSyncStatus {
+	resolvedPath := fr.resolvedPath()
+
+	status := &GitSyncStatus{
+		Name:            fr.Cfg.Name,
+		Path:            fr.Path,
+		Branch:          fr.repo.branch,
+		Commit:          fr.repo.head(ctx, resolvedPath),
+		CommitUIChanges: fr.repo.commitUIChanges,
+		Conflicts:       []*GitConflict{},
+	}
+
+	fr.repo.mux.RLock()
+	defer fr.repo.mux.RUnlock()
+
+	status.Error = fr.repo.lastError
+	for _, conflict := range fr.repo.conflicts {
+		status.Conflicts = append(status.Conflicts, conflict)
+	}
+	sort.Slice(status.Conflicts, func(i, j int) bool {
+		return status.Conflicts[i].File < status.Conflicts[j].File
+	})
+
+	return status
+}
+
+// dashboardFileContent returns the JSON model of the dashboard to store in the
+// repository, without the database id.
+func dashboardFileContent(dashboard *models.Dashboard) ([]byte, error) {
+	encoded, err := dashboard.Data.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := simplejson.NewJson(encoded)
+	if err != nil {
+		return nil, err
+	}
+	data.Del("id")
+
+	content, err := json.MarshalIndent(data.Interface(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(content, '\n'), nil
+}
+
+func gitAuthor(user *models.SignedInUser) string {
+	name := user.Name
+	if name == "" {
+		name = user.Login
+	}
+	return fmt.Sprintf("%s <%s>", name, user.Email)
+}
@@ -0,0 +1,78 @@
+//go:build integration
+// +build integration
+
+package dashboards
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegrationDashboardGitReader(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = []string{"HOME=" + dir, "GIT_CONFIG_NOSYSTEM=1"}
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	git("init", "--quiet")
+	git("config", "user.name", "Admin")
+	git("config", "user.email", "admin@example.com")
+	git("checkout", "--quiet", "-b", "main")
+	writeFile(t, filepath.Join(dir, "dashboard.json"), `{"title": "Main", "uid": "main"}`)
+	git("add", ".")
+	git("commit", "--quiet", "-m", "Add dashboard")
+	// files checked out earlier than the import
+	modTime := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "dashboard.json"), modTime, modTime))
+
+	store := sqlstore.InitTestDB(t)
+	t.Cleanup(bus.ClearBusHandlers)
+	bus.AddHandlerCtx("test", sqlstore.GetDashboard)
+
+	cfg := &config{
+		Name:  "Git",
+		Type:  "git",
+		OrgID: 1,
+		Options: map[string]interface{}{
+			"path":   dir,
+			"branch": "main",
+		},
+	}
+	reader, err := NewDashboardGitReader(cfg, log.New("test-logger"), store)
+	require.NoError(t, err)
+
+	getDashboard := func() *models.Dashboard {
+		query := &models.GetDashboardQuery{Uid: "main", OrgId: 1}
+		require.NoError(t, sqlstore.GetDashboard(context.Background(), query))
+		return query.Result
+	}
+
+	require.NoError(t, reader.walkDisk(context.Background()))
+	require.Equal(t, "Main", getDashboard().Title)
+
+	t.Run("Should import dashboards changed in the repository only", func(t *testing.T) {
+		writeFile(t, filepath.Join(dir, "dashboard.json"), `{"title": "Changed", "uid": "main"}`)
+		git("commit", "--quiet", "-am", "Change dashboard")
+
+		require.NoError(t, reader.walkDisk(context.Background()))
+		require.Equal(t, "Changed", getDashboard().Title)
+		require.Empty(t, reader.gitSyncStatus(context.Background()).Conflicts)
+	})
+}
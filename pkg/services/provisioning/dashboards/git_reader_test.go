@@ -0,0 +1,158 @@
+package dashboards
+
+import (
+	"context"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDashboardGitReader(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	setup := func(t *testing.T) (string, func(args ...string) string) {
+		t.Helper()
+
+		dir := t.TempDir()
+		git := func(args ...string) string {
+			cmd := exec.Command("git", args...)
+			cmd.Dir = dir
+			cmd.Env = []string{"HOME=" + dir, "GIT_CONFIG_NOSYSTEM=1"}
+			out, err := cmd.CombinedOutput()
+			require.NoError(t, err, string(out))
+			return string(out)
+		}
+
+		git("init", "--quiet")
+		git("config", "user.name", "Admin")
+		git("config", "user.email", "admin@example.com")
+		git("checkout", "--quiet", "-b", "main")
+		writeFile(t, filepath.Join(dir, "dashboard.json"), `{"title": "Main", "uid": "main"}`)
+		git("add", ".")
+		git("commit", "--quiet", "-m", "Add dashboard")
+		git("checkout", "--quiet", "-b", "other")
+
+		origNewDashboardProvisioningService := dashboards.NewProvisioningService
+		t.Cleanup(func() {
+			dashboards.NewProvisioningService = origNewDashboardProvisioningService
+			bus.ClearBusHandlers()
+		})
+		fakeService = mockDashboardProvisioningService()
+		bus.ClearBusHandlers()
+
+		return dir, git
+	}
+
+	newReader := func(t *testing.T, dir string, commitUIChanges bool) *FileReader {
+		t.Helper()
+
+		cfg := &config{
+			Name:  "Git",
+			Type:  "git",
+			OrgID: 1,
+			Options: map[string]interface{}{
+				"path":            dir,
+				"branch":          "main",
+				"commitUIChanges": commitUIChanges,
+			},
+		}
+		reader, err := NewDashboardGitReader(cfg, log.New("test-logger"), nil)
+		require.NoError(t, err)
+		return reader
+	}
+
+	t.Run("Should check out the branch and import dashboards from the working tree", func(t *testing.T) {
+		dir, git := setup(t)
+		reader := newReader(t, dir, false)
+
+		require.NoError(t, reader.walkDisk(context.Background()))
+		require.Equal(t, "main\n", git("rev-parse", "--abbrev-ref", "HEAD"))
+		require.Len(t, fakeService.inserted, 1)
+		require.Equal(t, "Main", fakeService.inserted[0].Dashboard.Title)
+
+		status := reader.gitSyncStatus(context.Background())
+		require.Equal(t, "main", status.Branch)
+		require.Len(t, status.Commit, 40)
+		require.Empty(t, status.Conflicts)
+	})
+
+	t.Run("Should not import dashboards changed in the repository and the database", func(t *testing.T) {
+		dir, _ := setup(t)
+		reader := newReader(t, dir, false)
+		require.NoError(t, reader.walkDisk(context.Background()))
+		provisioning := fakeService.provisioned["Git"][0]
+
+		// the dashboard was saved in the UI after the import
+		bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetDashboardQuery) error {
+			query.Result = &models.Dashboard{Id: query.Id, Uid: "main", Title: "Main", Updated: time.Unix(provisioning.Updated+60, 0)}
+			return nil
+		})
+		writeFile(t, filepath.Join(dir, "dashboard.json"), `{"title": "Changed", "uid": "main"}`)
+
+		require.NoError(t, reader.walkDisk(context.Background()))
+		require.Len(t, fakeService.inserted, 1)
+
+		status := reader.gitSyncStatus(context.Background())
+		require.Len(t, status.Conflicts, 1)
+		require.Equal(t, "dashboard.json", status.Conflicts[0].File)
+		require.Equal(t, "main", status.Conflicts[0].DashboardUid)
+	})
+
+	t.Run("Should commit dashboards saved in the UI", func(t *testing.T) {
+		dir, git := setup(t)
+		reader := newReader(t, dir, true)
+		require.NoError(t, reader.walkDisk(context.Background()))
+		provisioning := fakeService.provisioned["Git"][0]
+
+		var updated *models.UpdateDashboardProvisioningCommand
+		bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.UpdateDashboardProvisioningCommand) error {
+			updated = cmd
+			return nil
+		})
+
+		data := simplejson.NewFromAny(map[string]interface{}{"id": provisioning.DashboardId, "title": "Saved", "uid": "main"})
+		dashboard := models.NewDashboardFromJson(data)
+		dashboard.Updated = time.Now()
+		user := &models.SignedInUser{Login: "editor", Name: "Editor", Email: "editor@example.com"}
+
+		err := reader.commitDashboard(context.Background(), provisioning, dashboard, user, "Rename dashboard")
+		require.NoError(t, err)
+
+		require.Equal(t, "Editor <editor@example.com> Rename dashboard\n", git("log", "-1", "--format=%an <%ae> %s"))
+		content, err := ioutil.ReadFile(filepath.Join(dir, "dashboard.json"))
+		require.NoError(t, err)
+		require.JSONEq(t, `{"title": "Saved", "uid": "main", "version": 0}`, string(content))
+
+		require.NotNil(t, updated)
+		require.Equal(t, dashboard.Updated.Unix(), updated.Updated)
+		require.NotEqual(t, provisioning.CheckSum, updated.CheckSum)
+	})
+}
+
+func TestGetAllowUIUpdatesFromConfig(t *testing.T) {
+	provisioner := &Provisioner{configs: []*config{
+		{Name: "file", Type: "file", Options: map[string]interface{}{"commitUIChanges": true}},
+		{Name: "git", Type: "git", Options: map[string]interface{}{"commitUIChanges": true}},
+		{Name: "readonly-git", Type: "git", Options: map[string]interface{}{}},
+	}}
+
+	require.False(t, provisioner.GetAllowUIUpdatesFromConfig("file"))
+	require.True(t, provisioner.GetAllowUIUpdatesFromConfig("git"))
+	require.False(t, provisioner.GetAllowUIUpdatesFromConfig("readonly-git"))
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+}
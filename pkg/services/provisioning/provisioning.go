@@ -6,6 +6,7 @@ import (
 	"sync"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
 	plugifaces "github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/registry"
 	"github.com/grafana/grafana/pkg/services/encryption"
@@ -43,6 +44,9 @@ type ProvisioningService interface {
 	ProvisionDashboards(ctx context.Context) error
 	GetDashboardProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
+	CommitProvisionedDashboard(ctx context.Context, provisioning *models.DashboardProvisioning,
+		dashboard *models.Dashboard, user *models.SignedInUser, message string) error
+	GetDashboardGitSyncStatus(ctx context.Context) []*dashboards.GitSyncStatus
 }
 
 // Add a public constructor for overriding service to be able to instantiate OSS as fallback
@@ -184,6 +188,16 @@ func (ps *ProvisioningServiceImpl) GetAllowUIUpdatesFromConfig(name string) bool
 	return ps.dashboardProvisioner.GetAllowUIUpdatesFromConfig(name)
 }
 
+// CommitProvisionedDashboard commits a dashboard saved in the UI to the git repository it was provisioned from.
+func (ps *ProvisioningServiceImpl) CommitProvisionedDashboard(ctx context.Context, provisioning *models.DashboardProvisioning,
+	dashboard *models.Dashboard, user *models.SignedInUser, message string) error {
+	return ps.dashboardProvisioner.CommitDashboard(ctx, provisioning, dashboard, user, message)
+}
+
+func (ps *ProvisioningServiceImpl) GetDashboardGitSyncStatus(ctx context.Context) []*dashboards.GitSyncStatus {
+	return ps.dashboardProvisioner.GetGitSyncStatus(ctx)
+}
+
 func (ps *ProvisioningServiceImpl) cancelPolling() {
 	if ps.pollingCtxCancel != nil {
 		ps.log.Debug("Stop polling for dashboard changes")
@@ -1,6 +1,11 @@
 package provisioning
 
-import "context"
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
+)
 
 type Calls struct {
 	RunInitProvisioners                 []interface{}
@@ -10,6 +15,8 @@ type Calls struct {
 	ProvisionDashboards                 []interface{}
 	GetDashboardProvisionerResolvedPath []interface{}
 	GetAllowUIUpdatesFromConfig         []interface{}
+	CommitProvisionedDashboard          []interface{}
+	GetDashboardGitSyncStatus           []interface{}
 	Run                                 []interface{}
 }
 
@@ -22,6 +29,8 @@ type ProvisioningServiceMock struct {
 	ProvisionDashboardsFunc                 func() error
 	GetDashboardProvisionerResolvedPathFunc func(name string) string
 	GetAllowUIUpdatesFromConfigFunc         func(name string) bool
+	CommitProvisionedDashboardFunc          func(ctx context.Context, provisioning *models.DashboardProvisioning, dashboard *models.Dashboard, user *models.SignedInUser, message string) error
+	GetDashboardGitSyncStatusFunc           func(ctx context.Context) []*dashboards.GitSyncStatus
 	RunFunc                                 func(ctx context.Context) error
 }
 
@@ -87,6 +96,23 @@ func (mock *ProvisioningServiceMock) GetAllowUIUpdatesFromConfig(name string) bo
 	return false
 }
 
+func (mock *ProvisioningServiceMock) CommitProvisionedDashboard(ctx context.Context, provisioning *models.DashboardProvisioning,
+	dashboard *models.Dashboard, user *models.SignedInUser, message string) error {
+	mock.Calls.CommitProvisionedDashboard = append(mock.Calls.CommitProvisionedDashboard, provisioning)
+	if mock.CommitProvisionedDashboardFunc != nil {
+		return mock.CommitProvisionedDashboardFunc(ctx, provisioning, dashboard, user, message)
+	}
+	return nil
+}
+
+func (mock *ProvisioningServiceMock) GetDashboardGitSyncStatus(ctx context.Context) []*dashboards.GitSyncStatus {
+	mock.Calls.GetDashboardGitSyncStatus = append(mock.Calls.GetDashboardGitSyncStatus, nil)
+	if mock.GetDashboardGitSyncStatusFunc != nil {
+		return mock.GetDashboardGitSyncStatusFunc(ctx)
+	}
+	return []*dashboards.GitSyncStatus{}
+}
+
 func (mock *ProvisioningServiceMock) Run(ctx context.Context) error {
 	mock.Calls.Run = append(mock.Calls.Run, nil)
 	if mock.RunFunc != nil {
@@ -11,21 +11,34 @@ const (
 )
 
 type Hit struct {
-	ID           int64    `json:"id"`
-	UID          string   `json:"uid"`
-	Title        string   `json:"title"`
-	URI          string   `json:"uri"`
-	URL          string   `json:"url"`
-	Slug         string   `json:"slug"`
-	Type         HitType  `json:"type"`
-	Tags         []string `json:"tags"`
-	IsStarred    bool     `json:"isStarred"`
-	FolderID     int64    `json:"folderId,omitempty"`
-	FolderUID    string   `json:"folderUid,omitempty"`
-	FolderTitle  string   `json:"folderTitle,omitempty"`
-	FolderURL    string   `json:"folderUrl,omitempty"`
+	ID          int64    `json:"id"`
+	UID         string   `json:"uid"`
+	Title       string   `json:"title"`
+	URI         string   `json:"uri"`
+	URL         string   `json:"url"`
+	Slug        string   `json:"slug"`
+	Type        HitType  `json:"type"`
+	Tags        []string `json:"tags"`
+	IsStarred   bool     `json:"isStarred"`
+	FolderID    int64    `json:"folderId,omitempty"`
+	FolderUID   string   `json:"folderUid,omitempty"`
+	FolderTitle string   `json:"folderTitle,omitempty"`
+	FolderURL   string   `json:"folderUrl,omitempty"`
+	// FolderPath are titles of the folder and its parent folders, root first.
+	FolderPath   []string `json:"folderPath,omitempty"`
 	SortMeta     int64    `json:"sortMeta"`
 	SortMetaName string   `json:"sortMetaName,omitempty"`
+	// Matches are content of the dashboard matching the content query.
+	Matches []ContentMatch `json:"matches,omitempty"`
+}
+
+// ContentMatch is a stored text of a dashboard matching the content query,
+// shortened to the surroundings of the match.
+type ContentMatch struct {
+	Kind       string `json:"kind"`
+	PanelID    int64  `json:"panelId,omitempty"`
+	PanelTitle string `json:"panelTitle,omitempty"`
+	Text       string `json:"text"`
 }
 
 type HitList []*Hit
@@ -17,6 +17,10 @@ func ProvideService(cfg *setting.Cfg, bus bus.Bus) *SearchService {
 		sortOptions: map[string]SortOption{
 			SortAlphaAsc.Name:  SortAlphaAsc,
 			SortAlphaDesc.Name: SortAlphaDesc,
+
+			SortViewsDesc.Name:      SortViewsDesc,
+			SortLastViewedAsc.Name:  SortLastViewedAsc,
+			SortLastViewedDesc.Name: SortLastViewedDesc,
 		},
 	}
 	s.Bus.AddHandlerCtx(s.searchHandler)
@@ -36,6 +40,10 @@ type Query struct {
 	FolderIds    []int64
 	Permission   models.PermissionType
 	Sort         string
+	// Content matches dashboards with panel titles, descriptions, queries,
+	// data sources or variables containing the text.
+	Content      string
+	ContentKinds []string
 
 	Result HitList
 }
@@ -53,6 +61,8 @@ type FindPersistedDashboardsQuery struct {
 	Page         int64
 	Permission   models.PermissionType
 	Sort         SortOption
+	Content      string
+	ContentKinds []string
 
 	Filters []interface{}
 
@@ -78,6 +88,8 @@ func (s *SearchService) searchHandler(ctx context.Context, query *Query) error {
 		Limit:        query.Limit,
 		Page:         query.Page,
 		Permission:   query.Permission,
+		Content:      query.Content,
+		ContentKinds: query.ContentKinds,
 	}
 
 	if sortOpt, exists := s.sortOptions[query.Sort]; exists {
@@ -25,6 +25,34 @@ var (
 			searchstore.TitleSorter{Descending: true},
 		},
 	}
+	SortViewsDesc = SortOption{
+		Name:        "views-desc",
+		DisplayName: "조회수 (많은 순)",
+		Description: "조회수가 많은 대시보드부터 정렬합니다.",
+		Index:       1,
+		MetaName:    "views",
+		Filter: []SortOptionFilter{
+			searchstore.ViewsSorter{},
+		},
+	}
+	SortLastViewedAsc = SortOption{
+		Name:        "lastviewed-asc",
+		DisplayName: "마지막 조회 (오래된 순)",
+		Description: "가장 오랫동안 조회되지 않은 대시보드부터 정렬합니다.",
+		Index:       1,
+		Filter: []SortOptionFilter{
+			searchstore.LastViewedSorter{},
+		},
+	}
+	SortLastViewedDesc = SortOption{
+		Name:        "lastviewed-desc",
+		DisplayName: "마지막 조회 (최근 순)",
+		Description: "최근에 조회된 대시보드부터 정렬합니다.",
+		Index:       1,
+		Filter: []SortOptionFilter{
+			searchstore.LastViewedSorter{Descending: true},
+		},
+	}
 )
 
 type SortOption struct {
@@ -29,6 +29,8 @@ func init() {
 	bus.AddHandlerCtx("sql", GetDashboard)
 	bus.AddHandlerCtx("sql", GetDashboards)
 	bus.AddHandlerCtx("sql", DeleteDashboard)
+	bus.AddHandlerCtx("sql", DeleteDashboards)
+	bus.AddHandlerCtx("sql", SaveDashboards)
 	bus.AddHandlerCtx("sql", GetDashboardTags)
 	bus.AddHandlerCtx("sql", GetDashboardSlugById)
 	bus.AddHandlerCtx("sql", GetDashboardsByPluginId)
@@ -54,6 +56,18 @@ func (ss *SQLStore) SaveDashboard(cmd models.SaveDashboardCommand) (*models.Dash
 	return cmd.Result, err
 }
 
+// SaveDashboards saves the dashboards of all commands in a single transaction.
+func SaveDashboards(ctx context.Context, cmd *models.SaveDashboardsCommand) error {
+	return inTransactionCtx(ctx, func(sess *DBSession) error {
+		for _, saveCmd := range cmd.Commands {
+			if err := saveDashboard(sess, saveCmd); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func saveDashboard(sess *DBSession, cmd *models.SaveDashboardCommand) error {
 	dash := cmd.GetDashboardModel()
 
@@ -164,6 +178,10 @@ func saveDashboard(sess *DBSession, cmd *models.SaveDashboardCommand) error {
 		}
 	}
 
+	if err := updateDashboardSearchContent(sess, dash); err != nil {
+		return err
+	}
+
 	cmd.Result = dash
 
 	return nil
@@ -206,15 +224,16 @@ func (ss *SQLStore) GetDashboard(id, orgID int64, uid, slug string) (*models.Das
 }
 
 // GetDashboardByTitle gets a dashboard by its title.
+// Only folders in the General folder are found, titles of nested folders are
+// unique within their parent folder only.
 func (ss *SQLStore) GetFolderByTitle(orgID int64, title string) (*models.Dashboard, error) {
 	if title == "" {
 		return nil, models.ErrDashboardIdentifierNotSet
 	}
 
 	// there is a unique constraint on org_id, folder_id, title
-	// there are no nested folders so the parent folder id is always 0
-	dashboard := models.Dashboard{OrgId: orgID, FolderId: 0, Title: title}
-	has, err := ss.engine.Get(&dashboard)
+	dashboard := models.Dashboard{}
+	has, err := ss.engine.Where("org_id = ? AND folder_id = 0 AND title = ?", orgID, title).Get(&dashboard)
 	if err != nil {
 		return nil, err
 	} else if !has {
@@ -312,6 +331,10 @@ func (ss *SQLStore) findDashboards(ctx context.Context, query *search.FindPersis
 		filters = append(filters, searchstore.FolderFilter{IDs: query.FolderIds})
 	}
 
+	if len(query.Content) > 0 {
+		filters = append(filters, searchstore.ContentFilter{Dialect: dialect, Text: query.Content, Kinds: query.ContentKinds})
+	}
+
 	var res []DashboardSearchProjection
 	sb := &searchstore.Builder{Dialect: dialect, Filters: filters}
 
@@ -346,7 +369,15 @@ func (ss *SQLStore) SearchDashboards(ctx context.Context, query *search.FindPers
 
 	makeQueryResult(query, res)
 
-	return nil
+	orgID := query.OrgId
+	if orgID == 0 {
+		orgID = query.SignedInUser.OrgId
+	}
+	if err := ss.setFolderPaths(ctx, orgID, query.Result); err != nil {
+		return err
+	}
+
+	return ss.setContentMatches(ctx, query)
 }
 
 func getHitType(item DashboardSearchProjection) search.HitType {
@@ -420,6 +451,18 @@ func DeleteDashboard(ctx context.Context, cmd *models.DeleteDashboardCommand) er
 	})
 }
 
+// DeleteDashboards deletes the dashboards of all commands in a single transaction.
+func DeleteDashboards(ctx context.Context, cmd *models.DeleteDashboardsCommand) error {
+	return inTransactionCtx(ctx, func(sess *DBSession) error {
+		for _, deleteCmd := range cmd.Commands {
+			if err := deleteDashboard(deleteCmd, sess); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func deleteDashboard(cmd *models.DeleteDashboardCommand, sess *DBSession) error {
 	dashboard := models.Dashboard{Id: cmd.Id, OrgId: cmd.OrgId}
 	has, err := sess.Get(&dashboard)
@@ -429,8 +472,35 @@ func deleteDashboard(cmd *models.DeleteDashboardCommand, sess *DBSession) error
 		return models.ErrDashboardNotFound
 	}
 
+	if cmd.Trash {
+		if err := trashDashboard(sess, &dashboard, cmd.DeletedBy); err != nil {
+			return err
+		}
+	}
+
+	if dashboard.IsFolder {
+		// Subfolders are deleted first, together with their dashboards and alert rules.
+		var subfolders []*models.Dashboard
+		if err := sess.Where("org_id = ? AND folder_id = ? AND is_folder = ?", dashboard.OrgId, dashboard.Id,
+			dialect.BooleanStr(true)).Find(&subfolders); err != nil {
+			return err
+		}
+		for _, subfolder := range subfolders {
+			subCmd := &models.DeleteDashboardCommand{
+				Id:                     subfolder.Id,
+				OrgId:                  dashboard.OrgId,
+				ForceDeleteFolderRules: cmd.ForceDeleteFolderRules,
+			}
+			if err := deleteDashboard(subCmd, sess); err != nil {
+				return err
+			}
+		}
+	}
+
 	deletes := []string{
 		"DELETE FROM dashboard_tag WHERE dashboard_id = ? ",
+		"DELETE FROM dashboard_search_content WHERE dashboard_id = ? ",
+		"DELETE FROM dashboard_view WHERE dashboard_id = ? ",
 		"DELETE FROM star WHERE dashboard_id = ? ",
 		"DELETE FROM dashboard WHERE id = ?",
 		"DELETE FROM playlist_item WHERE type = 'dashboard_by_id' AND value = ?",
@@ -460,6 +530,8 @@ func deleteDashboard(cmd *models.DeleteDashboardCommand, sess *DBSession) error
 		if len(dashIds) > 0 {
 			childrenDeletes := []string{
 				"DELETE FROM dashboard_tag WHERE dashboard_id IN (SELECT id FROM dashboard WHERE org_id = ? AND folder_id = ?)",
+				"DELETE FROM dashboard_search_content WHERE dashboard_id IN (SELECT id FROM dashboard WHERE org_id = ? AND folder_id = ?)",
+				"DELETE FROM dashboard_view WHERE dashboard_id IN (SELECT id FROM dashboard WHERE org_id = ? AND folder_id = ?)",
 				"DELETE FROM star WHERE dashboard_id IN (SELECT id FROM dashboard WHERE org_id = ? AND folder_id = ?)",
 				"DELETE FROM dashboard_version WHERE dashboard_id IN (SELECT id FROM dashboard WHERE org_id = ? AND folder_id = ?)",
 				"DELETE FROM annotation WHERE dashboard_id IN (SELECT id FROM dashboard WHERE org_id = ? AND folder_id = ?)",
@@ -724,7 +796,7 @@ func getExistingDashboardByIdOrUidForUpdate(sess *DBSession, dash *models.Dashbo
 		return isParentFolderChanged, models.ErrDashboardTypeMismatch
 	}
 
-	if !dash.IsFolder && dash.FolderId != existing.FolderId {
+	if dash.FolderId != existing.FolderId {
 		isParentFolderChanged = true
 	}
 
@@ -748,8 +820,7 @@ func getExistingDashboardByIdOrUidForUpdate(sess *DBSession, dash *models.Dashbo
 func getExistingDashboardByTitleAndFolder(sess *DBSession, dash *models.Dashboard, overwrite,
 	isParentFolderChanged bool) (bool, error) {
 	var existing models.Dashboard
-	exists, err := sess.Where("org_id=? AND slug=? AND (is_folder=? OR folder_id=?)", dash.OrgId, dash.Slug,
-		dialect.BooleanStr(true), dash.FolderId).Get(&existing)
+	exists, err := sess.Where("org_id=? AND slug=? AND folder_id=?", dash.OrgId, dash.Slug, dash.FolderId).Get(&existing)
 	if err != nil {
 		return isParentFolderChanged, fmt.Errorf("SQL query for existing dashboard by org ID or folder ID failed: %w", err)
 	}
@@ -794,6 +865,10 @@ func (ss *SQLStore) ValidateDashboardBeforeSave(dashboard *models.Dashboard, ove
 			return err
 		}
 
+		if dashboard.IsFolder && dashboard.FolderId > 0 {
+			return validateFolderParent(sess, dashboard)
+		}
+
 		return nil
 	})
 	if err != nil {
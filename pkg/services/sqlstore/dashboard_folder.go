@@ -0,0 +1,164 @@
+package sqlstore
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/search"
+)
+
+func init() {
+	bus.AddHandlerCtx("sql", GetFolderPath)
+	bus.AddHandlerCtx("sql", GetFolderDescendants)
+}
+
+// GetFolderPath returns the folder and its parent folders, root first.
+func GetFolderPath(ctx context.Context, query *models.GetFolderPathQuery) error {
+	return withDbSession(ctx, x, func(sess *DBSession) error {
+		path, err := getFolderPath(sess, query.OrgId, query.FolderId)
+		query.Result = path
+		return err
+	})
+}
+
+func getFolderPath(sess *DBSession, orgID int64, folderID int64) ([]*models.Dashboard, error) {
+	path := make([]*models.Dashboard, 0)
+	// The depth is checked on save, the limit stops on loops in inconsistent data.
+	for id := folderID; id != 0 && len(path) <= models.MaxFolderDepth; {
+		folder := models.Dashboard{}
+		has, err := sess.Where("org_id = ? AND id = ? AND is_folder = ?", orgID, id, dialect.BooleanStr(true)).Get(&folder)
+		if err != nil {
+			return nil, err
+		}
+		if !has {
+			return nil, models.ErrFolderNotFound
+		}
+		path = append([]*models.Dashboard{&folder}, path...)
+		id = folder.FolderId
+	}
+	return path, nil
+}
+
+// GetFolderDescendants returns subfolders of the folder at any depth, parents
+// before their subfolders.
+func GetFolderDescendants(ctx context.Context, query *models.GetFolderDescendantsQuery) error {
+	return withDbSession(ctx, x, func(sess *DBSession) error {
+		descendants, err := getFolderDescendants(sess, query.OrgId, query.FolderId)
+		query.Result = descendants
+		return err
+	})
+}
+
+func getFolderDescendants(sess *DBSession, orgID int64, folderID int64) ([]*models.Dashboard, error) {
+	descendants := make([]*models.Dashboard, 0)
+	parentIDs := []int64{folderID}
+	for depth := 0; len(parentIDs) > 0 && depth < models.MaxFolderDepth; depth++ {
+		var children []*models.Dashboard
+		err := sess.Where("org_id = ? AND is_folder = ?", orgID, dialect.BooleanStr(true)).
+			In("folder_id", parentIDs).Asc("id").Find(&children)
+		if err != nil {
+			return nil, err
+		}
+
+		parentIDs = make([]int64, 0, len(children))
+		for _, child := range children {
+			parentIDs = append(parentIDs, child.Id)
+		}
+		descendants = append(descendants, children...)
+	}
+	return descendants, nil
+}
+
+// validateFolderParent validates that the folder isn't moved into its own
+// subtree, and that its subfolders stay within the maximum depth.
+func validateFolderParent(sess *DBSession, folder *models.Dashboard) error {
+	path, err := getFolderPath(sess, folder.OrgId, folder.FolderId)
+	if err != nil {
+		return err
+	}
+	for _, parent := range path {
+		if folder.Id != 0 && parent.Id == folder.Id {
+			return models.ErrDashboardFolderCircularParent
+		}
+	}
+
+	// Depth of the subtree of the folder, the folder itself is on the first level.
+	height := 1
+	if folder.Id != 0 {
+		descendants, err := getFolderDescendants(sess, folder.OrgId, folder.Id)
+		if err != nil {
+			return err
+		}
+		levels := map[int64]int{folder.Id: 1}
+		for _, descendant := range descendants {
+			level := levels[descendant.FolderId] + 1
+			levels[descendant.Id] = level
+			if level > height {
+				height = level
+			}
+		}
+	}
+
+	if len(path)+height > models.MaxFolderDepth {
+		return models.ErrDashboardFolderMaxDepthExceeded
+	}
+	return nil
+}
+
+// setFolderPaths sets the titles of the folder of the hits and its parents.
+func (ss *SQLStore) setFolderPaths(ctx context.Context, orgID int64, hits []*search.Hit) error {
+	type folder struct {
+		Id       int64
+		Title    string
+		FolderId int64
+	}
+	folders := map[int64]folder{}
+
+	missing := map[int64]struct{}{}
+	for _, hit := range hits {
+		if hit.FolderID > 0 {
+			missing[hit.FolderID] = struct{}{}
+		}
+	}
+
+	err := ss.WithDbSession(ctx, func(sess *DBSession) error {
+		for depth := 0; len(missing) > 0 && depth < models.MaxFolderDepth; depth++ {
+			ids := make([]int64, 0, len(missing))
+			for id := range missing {
+				ids = append(ids, id)
+			}
+			var res []folder
+			if err := sess.Table("dashboard").Cols("id", "title", "folder_id").
+				Where("org_id = ?", orgID).In("id", ids).Find(&res); err != nil {
+				return err
+			}
+
+			missing = map[int64]struct{}{}
+			for _, f := range res {
+				folders[f.Id] = f
+				if _, ok := folders[f.FolderId]; f.FolderId > 0 && !ok {
+					missing[f.FolderId] = struct{}{}
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, hit := range hits {
+		var path []string
+		for id := hit.FolderID; id > 0 && len(path) < models.MaxFolderDepth; {
+			f, ok := folders[id]
+			if !ok {
+				break
+			}
+			path = append([]string{f.Title}, path...)
+			id = f.FolderId
+		}
+		hit.FolderPath = path
+	}
+	return nil
+}
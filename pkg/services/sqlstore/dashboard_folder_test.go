@@ -510,6 +510,187 @@ func TestDashboardFolderDataAccess(t *testing.T) {
 	})
 }
 
+func TestNestedFolders(t *testing.T) {
+	var sqlStore *SQLStore
+	var folderA, folderB, folderC, dashInC *models.Dashboard
+	var viewer models.User
+
+	// A/B/C with a dashboard in C
+	setup := func(t *testing.T) {
+		sqlStore = InitTestDB(t)
+		folderA = insertTestDashboard(t, sqlStore, "A", 1, 0, true)
+		folderB = insertTestDashboard(t, sqlStore, "B", 1, folderA.Id, true)
+		folderC = insertTestDashboard(t, sqlStore, "C", 1, folderB.Id, true)
+		dashInC = insertTestDashboard(t, sqlStore, "dash in C", 1, folderC.Id, false)
+		viewer = createUser(t, sqlStore, "viewer", "Viewer", false)
+	}
+
+	viewerQuery := func() *search.FindPersistedDashboardsQuery {
+		return &search.FindPersistedDashboardsQuery{
+			SignedInUser: &models.SignedInUser{UserId: viewer.Id, OrgId: 1, OrgRole: models.ROLE_VIEWER},
+			OrgId:        1,
+			DashboardIds: []int64{dashInC.Id},
+		}
+	}
+
+	t.Run("Should allow folders up to the maximum depth", func(t *testing.T) {
+		setup(t)
+		folderD := models.NewDashboardFolder("D")
+		folderD.OrgId = 1
+		folderD.FolderId = folderC.Id
+		_, err := sqlStore.ValidateDashboardBeforeSave(folderD, false)
+		require.NoError(t, err)
+
+		folderD = insertTestDashboard(t, sqlStore, "D", 1, folderC.Id, true)
+		folderE := models.NewDashboardFolder("E")
+		folderE.OrgId = 1
+		folderE.FolderId = folderD.Id
+		_, err = sqlStore.ValidateDashboardBeforeSave(folderE, false)
+		require.Equal(t, models.ErrDashboardFolderMaxDepthExceeded, err)
+	})
+
+	t.Run("Should not allow moving a subtree below the maximum depth", func(t *testing.T) {
+		setup(t)
+		other := insertTestDashboard(t, sqlStore, "other", 1, 0, true)
+		otherChild := insertTestDashboard(t, sqlStore, "other child", 1, other.Id, true)
+
+		other.FolderId = folderB.Id
+		_, err := sqlStore.ValidateDashboardBeforeSave(other, true)
+		require.NoError(t, err)
+
+		other.FolderId = folderC.Id
+		_, err = sqlStore.ValidateDashboardBeforeSave(other, true)
+		require.Equal(t, models.ErrDashboardFolderMaxDepthExceeded, err)
+
+		otherChild.FolderId = folderC.Id
+		_, err = sqlStore.ValidateDashboardBeforeSave(otherChild, true)
+		require.NoError(t, err)
+	})
+
+	t.Run("Should not allow moving a folder into its subfolder", func(t *testing.T) {
+		setup(t)
+		folderA.FolderId = folderC.Id
+		_, err := sqlStore.ValidateDashboardBeforeSave(folderA, true)
+		require.Equal(t, models.ErrDashboardFolderCircularParent, err)
+	})
+
+	t.Run("Should return folder path and descendants", func(t *testing.T) {
+		setup(t)
+		pathQuery := models.GetFolderPathQuery{OrgId: 1, FolderId: folderC.Id}
+		err := GetFolderPath(context.Background(), &pathQuery)
+		require.NoError(t, err)
+		require.Len(t, pathQuery.Result, 3)
+		require.Equal(t, folderA.Id, pathQuery.Result[0].Id)
+		require.Equal(t, folderC.Id, pathQuery.Result[2].Id)
+
+		descendantsQuery := models.GetFolderDescendantsQuery{OrgId: 1, FolderId: folderA.Id}
+		err = GetFolderDescendants(context.Background(), &descendantsQuery)
+		require.NoError(t, err)
+		require.Len(t, descendantsQuery.Result, 2)
+		require.Equal(t, folderB.Id, descendantsQuery.Result[0].Id)
+		require.Equal(t, folderC.Id, descendantsQuery.Result[1].Id)
+	})
+
+	t.Run("Should return folder path of search hits", func(t *testing.T) {
+		setup(t)
+		query := viewerQuery()
+		query.DashboardIds = []int64{folderB.Id, dashInC.Id}
+		err := sqlStore.SearchDashboards(context.Background(), query)
+		require.NoError(t, err)
+		require.Len(t, query.Result, 2)
+		require.Equal(t, []string{"A"}, query.Result[0].FolderPath)
+		require.Equal(t, []string{"A", "B", "C"}, query.Result[1].FolderPath)
+	})
+
+	t.Run("Should find only folders in the General folder by title", func(t *testing.T) {
+		setup(t)
+		folder, err := sqlStore.GetFolderByTitle(1, "A")
+		require.NoError(t, err)
+		require.Equal(t, folderA.Id, folder.Id)
+
+		_, err = sqlStore.GetFolderByTitle(1, "B")
+		require.Equal(t, models.ErrDashboardNotFound, err)
+		_, err = sqlStore.GetFolderByTitle(1, "A/B")
+		require.Equal(t, models.ErrDashboardNotFound, err)
+	})
+
+	t.Run("Should inherit permissions of the nearest parent folder with permissions", func(t *testing.T) {
+		setup(t)
+		var otherUser int64 = 999
+		err := testHelperUpdateDashboardAcl(t, sqlStore, folderA.Id, models.DashboardAcl{
+			DashboardID: folderA.Id, OrgID: 1, UserID: otherUser, Permission: models.PERMISSION_EDIT,
+		})
+		require.NoError(t, err)
+
+		query := viewerQuery()
+		err = sqlStore.SearchDashboards(context.Background(), query)
+		require.NoError(t, err)
+		require.Len(t, query.Result, 0)
+
+		err = testHelperUpdateDashboardAcl(t, sqlStore, folderB.Id, models.DashboardAcl{
+			DashboardID: folderB.Id, OrgID: 1, UserID: viewer.Id, Permission: models.PERMISSION_VIEW,
+		})
+		require.NoError(t, err)
+
+		query = viewerQuery()
+		err = sqlStore.SearchDashboards(context.Background(), query)
+		require.NoError(t, err)
+		require.Len(t, query.Result, 1)
+
+		aclQuery := models.GetDashboardAclInfoListQuery{DashboardID: dashInC.Id, OrgID: 1}
+		err = sqlStore.GetDashboardAclInfoList(context.Background(), &aclQuery)
+		require.NoError(t, err)
+		require.Len(t, aclQuery.Result, 1)
+		require.Equal(t, folderB.Id, aclQuery.Result[0].DashboardId)
+		require.True(t, aclQuery.Result[0].Inherited)
+	})
+
+	t.Run("Should restrict permissions granted by parent folders in a subfolder", func(t *testing.T) {
+		setup(t)
+		var otherUser int64 = 999
+		viewerRole := models.ROLE_VIEWER
+		err := testHelperUpdateDashboardAcl(t, sqlStore, folderA.Id, models.DashboardAcl{
+			DashboardID: folderA.Id, OrgID: 1, Role: &viewerRole, Permission: models.PERMISSION_VIEW,
+		})
+		require.NoError(t, err)
+
+		query := viewerQuery()
+		err = sqlStore.SearchDashboards(context.Background(), query)
+		require.NoError(t, err)
+		require.Len(t, query.Result, 1)
+
+		err = testHelperUpdateDashboardAcl(t, sqlStore, folderB.Id, models.DashboardAcl{
+			DashboardID: folderB.Id, OrgID: 1, UserID: otherUser, Permission: models.PERMISSION_EDIT,
+		})
+		require.NoError(t, err)
+
+		query = viewerQuery()
+		query.DashboardIds = []int64{folderA.Id, folderB.Id, folderC.Id, dashInC.Id}
+		err = sqlStore.SearchDashboards(context.Background(), query)
+		require.NoError(t, err)
+		require.Len(t, query.Result, 1)
+		require.Equal(t, folderA.Id, query.Result[0].ID)
+
+		aclQuery := models.GetDashboardAclInfoListQuery{DashboardID: folderC.Id, OrgID: 1}
+		err = sqlStore.GetDashboardAclInfoList(context.Background(), &aclQuery)
+		require.NoError(t, err)
+		require.Len(t, aclQuery.Result, 1)
+		require.Equal(t, otherUser, aclQuery.Result[0].UserId)
+	})
+
+	t.Run("Should delete subfolders with their dashboards", func(t *testing.T) {
+		setup(t)
+		err := DeleteDashboard(context.Background(), &models.DeleteDashboardCommand{Id: folderA.Id, OrgId: 1})
+		require.NoError(t, err)
+
+		for _, id := range []int64{folderB.Id, folderC.Id, dashInC.Id} {
+			query := models.GetDashboardQuery{Id: id, OrgId: 1}
+			err := GetDashboard(context.Background(), &query)
+			require.Equal(t, models.ErrDashboardNotFound, err)
+		}
+	})
+}
+
 func moveDashboard(t *testing.T, sqlStore *SQLStore, orgId int64, dashboard *simplejson.Json,
 	newFolderId int64) *models.Dashboard {
 	t.Helper()
@@ -11,6 +11,7 @@ import (
 func init() {
 	bus.AddHandlerCtx("sql", UnprovisionDashboard)
 	bus.AddHandlerCtx("sql", DeleteOrphanedProvisionedDashboards)
+	bus.AddHandlerCtx("sql", UpdateDashboardProvisioning)
 }
 
 type DashboardExtras struct {
@@ -78,6 +79,16 @@ func (ss *SQLStore) GetProvisionedDashboardData(name string) ([]*models.Dashboar
 	return result, nil
 }
 
+// UpdateDashboardProvisioning updates the checksum and time of the provisioning data of a dashboard.
+func UpdateDashboardProvisioning(ctx context.Context, cmd *models.UpdateDashboardProvisioningCommand) error {
+	return withDbSession(ctx, x, func(sess *DBSession) error {
+		_, err := sess.Where("dashboard_id = ? AND name = ?", cmd.DashboardId, cmd.Name).
+			Cols("check_sum", "updated").
+			Update(&models.DashboardProvisioning{CheckSum: cmd.CheckSum, Updated: cmd.Updated})
+		return err
+	})
+}
+
 // UnprovisionDashboard removes row in dashboard_provisioning for the dashboard making it seem as if manually created.
 // The dashboard will still have `created_by = -1` to see it was not created by any particular user.
 func UnprovisionDashboard(ctx context.Context, cmd *models.UnprovisionDashboardCommand) error {
@@ -0,0 +1,146 @@
+package sqlstore
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/search"
+	"github.com/grafana/grafana/pkg/services/sqlstore/searchstore"
+)
+
+const (
+	// maxContentMatches is the maximum number of content matches of a search hit.
+	maxContentMatches = 5
+	// contentMatchContext is the number of characters around the match in content matches.
+	contentMatchContext = 40
+)
+
+// updateDashboardSearchContent replaces stored search content of the dashboard.
+func updateDashboardSearchContent(sess *DBSession, dash *models.Dashboard) error {
+	if _, err := sess.Exec("DELETE FROM dashboard_search_content WHERE dashboard_id = ?", dash.Id); err != nil {
+		return err
+	}
+	if dash.IsFolder {
+		return nil
+	}
+
+	for _, content := range searchstore.ExtractContent(dash.Data) {
+		content.DashboardId = dash.Id
+		if _, err := sess.Table("dashboard_search_content").Insert(content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RebuildDashboardSearchContent extracts content of all dashboards again and
+// returns the number of processed dashboards.
+func (ss *SQLStore) RebuildDashboardSearchContent(ctx context.Context) (int, error) {
+	var lastID int64
+	count := 0
+	for {
+		var dashboards []*models.Dashboard
+		err := ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+			err := sess.Where("id > ? AND is_folder = ?", lastID, dialect.BooleanStr(false)).
+				Asc("id").Limit(100).Find(&dashboards)
+			if err != nil {
+				return err
+			}
+			for _, dash := range dashboards {
+				if err := updateDashboardSearchContent(sess, dash); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return count, err
+		}
+		if len(dashboards) == 0 {
+			return count, nil
+		}
+		lastID = dashboards[len(dashboards)-1].Id
+		count += len(dashboards)
+	}
+}
+
+// setContentMatches sets content of the hits matching the content query.
+func (ss *SQLStore) setContentMatches(ctx context.Context, query *search.FindPersistedDashboardsQuery) error {
+	if query.Content == "" || len(query.Result) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(query.Result))
+	hits := make(map[int64]*search.Hit, len(query.Result))
+	for _, hit := range query.Result {
+		ids = append(ids, hit.ID)
+		hits[hit.ID] = hit
+	}
+
+	contains, param := searchstore.ContentContains(dialect, "text", query.Content)
+	var res []*searchstore.Content
+	err := ss.WithDbSession(ctx, func(sess *DBSession) error {
+		sess.Table("dashboard_search_content").
+			In("dashboard_id", ids).
+			Where(contains, param)
+		if len(query.ContentKinds) > 0 {
+			sess.In("kind", query.ContentKinds)
+		}
+		return sess.Asc("id").Find(&res)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, content := range res {
+		hit := hits[content.DashboardId]
+		if len(hit.Matches) >= maxContentMatches {
+			continue
+		}
+		hit.Matches = append(hit.Matches, search.ContentMatch{
+			Kind:       content.Kind,
+			PanelID:    content.PanelId,
+			PanelTitle: content.PanelTitle,
+			Text:       contentSnippet(content.Text, query.Content),
+		})
+	}
+	return nil
+}
+
+// contentSnippet returns the text around the first case-insensitive match of
+// the query in the text.
+func contentSnippet(text string, query string) string {
+	runes := []rune(text)
+	lowerText := toLowerRunes(runes)
+	lowerQuery := toLowerRunes([]rune(query))
+
+	start := strings.Index(string(lowerText), string(lowerQuery))
+	if start == -1 {
+		start = 0
+	} else {
+		// The index is in bytes, the lowercase text has the same runes
+		start = len([]rune(string(lowerText)[:start]))
+	}
+	end := start + len(lowerQuery)
+
+	from := start - contentMatchContext
+	to := end + contentMatchContext
+	prefix, suffix := "…", "…"
+	if from <= 0 {
+		from, prefix = 0, ""
+	}
+	if to >= len(runes) {
+		to, suffix = len(runes), ""
+	}
+	return prefix + string(runes[from:to]) + suffix
+}
+
+func toLowerRunes(runes []rune) []rune {
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+	return lower
+}
@@ -0,0 +1,129 @@
+//go:build integration
+// +build integration
+
+package sqlstore
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/search"
+	"github.com/grafana/grafana/pkg/services/sqlstore/searchstore"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDashboardSearchContent(t *testing.T) {
+	var sqlStore *SQLStore
+	var savedDash *models.Dashboard
+
+	setup := func() {
+		sqlStore = InitTestDB(t)
+		dash, err := sqlStore.SaveDashboard(models.SaveDashboardCommand{
+			OrgId: 1,
+			Dashboard: simplejson.NewFromAny(map[string]interface{}{
+				"title": "Shop",
+				"panels": []interface{}{
+					map[string]interface{}{
+						"id":          1,
+						"title":       "Checkout latency",
+						"description": "Latency of the checkout service",
+						"targets": []interface{}{
+							map[string]interface{}{"refId": "A", "expr": "histogram_quantile(0.99, rate(http_request_duration_seconds_bucket[5m]))"},
+						},
+					},
+				},
+			}),
+		})
+		require.NoError(t, err)
+		dash.Data.Set("id", dash.Id)
+		savedDash = dash
+		insertTestDashboard(t, sqlStore, "Other", 1, 0, false)
+	}
+
+	searchContent := func(t *testing.T, content string, kinds ...string) search.HitList {
+		t.Helper()
+		query := search.FindPersistedDashboardsQuery{
+			OrgId:        1,
+			SignedInUser: &models.SignedInUser{OrgId: 1, OrgRole: models.ROLE_EDITOR},
+			Content:      content,
+			ContentKinds: kinds,
+		}
+		require.NoError(t, sqlStore.SearchDashboards(context.Background(), &query))
+		return query.Result
+	}
+
+	t.Run("Should find dashboard by content", func(t *testing.T) {
+		setup()
+		hits := searchContent(t, "CHECKOUT")
+		require.Len(t, hits, 1)
+		require.Equal(t, savedDash.Id, hits[0].ID)
+		require.Equal(t, []search.ContentMatch{
+			{Kind: searchstore.ContentPanelTitle, PanelID: 1, PanelTitle: "Checkout latency", Text: "Checkout latency"},
+			{Kind: searchstore.ContentPanelDescription, PanelID: 1, PanelTitle: "Checkout latency", Text: "Latency of the checkout service"},
+		}, hits[0].Matches)
+	})
+
+	t.Run("Should find dashboard by kind of content", func(t *testing.T) {
+		setup()
+		hits := searchContent(t, "http_request_duration_seconds", searchstore.ContentQuery)
+		require.Len(t, hits, 1)
+		require.Len(t, hits[0].Matches, 1)
+		require.Equal(t, "histogram_quantile(0.99, rate(http_request_duration_seconds_bucket[5m]))", hits[0].Matches[0].Text)
+		require.Empty(t, searchContent(t, "checkout", searchstore.ContentQuery))
+	})
+
+	t.Run("Should match wildcards in content literally", func(t *testing.T) {
+		setup()
+		require.Empty(t, searchContent(t, "Checkout_latency"))
+		require.Empty(t, searchContent(t, "histogram%quantile"))
+		require.Len(t, searchContent(t, "histogram_quantile"), 1)
+
+		savedDash.Data.Set("panels", []interface{}{
+			map[string]interface{}{"id": 1, "title": "Errors 100% !_"},
+		})
+		_, err := sqlStore.SaveDashboard(models.SaveDashboardCommand{OrgId: 1, Dashboard: savedDash.Data, Overwrite: true})
+		require.NoError(t, err)
+		hits := searchContent(t, "100% !_")
+		require.Len(t, hits, 1)
+		require.Len(t, hits[0].Matches, 1)
+	})
+
+	t.Run("Should update content when dashboard is saved", func(t *testing.T) {
+		setup()
+		savedDash.Data.Set("panels", []interface{}{})
+		_, err := sqlStore.SaveDashboard(models.SaveDashboardCommand{OrgId: 1, Dashboard: savedDash.Data, Overwrite: true})
+		require.NoError(t, err)
+		require.Empty(t, searchContent(t, "checkout"))
+	})
+
+	t.Run("Should delete content when dashboard is deleted", func(t *testing.T) {
+		setup()
+		require.NoError(t, DeleteDashboard(context.Background(), &models.DeleteDashboardCommand{Id: savedDash.Id, OrgId: 1}))
+		count, err := x.Table("dashboard_search_content").Count()
+		require.NoError(t, err)
+		require.Zero(t, count)
+	})
+
+	t.Run("Should rebuild content", func(t *testing.T) {
+		setup()
+		_, err := x.Exec("DELETE FROM dashboard_search_content")
+		require.NoError(t, err)
+		require.Empty(t, searchContent(t, "checkout"))
+
+		count, err := sqlStore.RebuildDashboardSearchContent(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, 2, count)
+		require.Len(t, searchContent(t, "checkout"), 1)
+	})
+}
+
+func TestContentSnippet(t *testing.T) {
+	require.Equal(t, "Checkout", contentSnippet("Checkout", "check"))
+
+	text := strings.Repeat("a", 50) + "Checkout" + strings.Repeat("b", 50)
+	require.Equal(t, "…"+strings.Repeat("a", 40)+"Checkout"+strings.Repeat("b", 40)+"…", contentSnippet(text, "CHECKOUT"))
+	require.Equal(t, "Ä"+strings.Repeat("b", 40)+"…", contentSnippet("Ä"+strings.Repeat("b", 50), "ä"))
+}
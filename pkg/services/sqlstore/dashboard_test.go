@@ -447,6 +447,84 @@ func TestDashboardDataAccessGivenPluginWithImportedDashboards(t *testing.T) {
 	require.Equal(t, len(query.Result), 2)
 }
 
+func TestBulkDashboards(t *testing.T) {
+	var sqlStore *SQLStore
+	var folder, dash1, dash2 *models.Dashboard
+
+	setup := func() {
+		sqlStore = InitTestDB(t)
+		folder = insertTestDashboard(t, sqlStore, "bulk folder", 1, 0, true)
+		dash1 = insertTestDashboard(t, sqlStore, "bulk dash 1", 1, 0, false)
+		dash2 = insertTestDashboard(t, sqlStore, "bulk dash 2", 1, 0, false)
+	}
+
+	moveCommand := func(dash *models.Dashboard) *models.SaveDashboardCommand {
+		return &models.SaveDashboardCommand{
+			OrgId:     1,
+			FolderId:  folder.Id,
+			Dashboard: dash.Data,
+		}
+	}
+
+	getDashboard := func(t *testing.T, id int64) *models.Dashboard {
+		query := models.GetDashboardQuery{Id: id, OrgId: 1}
+		err := GetDashboard(context.Background(), &query)
+		require.NoError(t, err)
+		return query.Result
+	}
+
+	t.Run("Should save all dashboards", func(t *testing.T) {
+		setup()
+		cmd := &models.SaveDashboardsCommand{Commands: []*models.SaveDashboardCommand{moveCommand(dash1), moveCommand(dash2)}}
+
+		err := SaveDashboards(context.Background(), cmd)
+		require.NoError(t, err)
+		require.Equal(t, folder.Id, getDashboard(t, dash1.Id).FolderId)
+		require.Equal(t, folder.Id, getDashboard(t, dash2.Id).FolderId)
+	})
+
+	t.Run("Should not save any dashboard if one fails", func(t *testing.T) {
+		setup()
+		missing := simplejson.NewFromAny(map[string]interface{}{"id": 1000, "title": "missing"})
+		cmd := &models.SaveDashboardsCommand{Commands: []*models.SaveDashboardCommand{
+			moveCommand(dash1),
+			{OrgId: 1, Dashboard: missing},
+		}}
+
+		err := SaveDashboards(context.Background(), cmd)
+		require.ErrorIs(t, err, models.ErrDashboardNotFound)
+		require.Equal(t, int64(0), getDashboard(t, dash1.Id).FolderId)
+	})
+
+	t.Run("Should delete all dashboards", func(t *testing.T) {
+		setup()
+		cmd := &models.DeleteDashboardsCommand{Commands: []*models.DeleteDashboardCommand{
+			{Id: dash1.Id, OrgId: 1},
+			{Id: dash2.Id, OrgId: 1},
+		}}
+
+		err := DeleteDashboards(context.Background(), cmd)
+		require.NoError(t, err)
+		for _, id := range []int64{dash1.Id, dash2.Id} {
+			query := models.GetDashboardQuery{Id: id, OrgId: 1}
+			err := GetDashboard(context.Background(), &query)
+			require.ErrorIs(t, err, models.ErrDashboardNotFound)
+		}
+	})
+
+	t.Run("Should not delete any dashboard if one fails", func(t *testing.T) {
+		setup()
+		cmd := &models.DeleteDashboardsCommand{Commands: []*models.DeleteDashboardCommand{
+			{Id: dash1.Id, OrgId: 1},
+			{Id: 1000, OrgId: 1},
+		}}
+
+		err := DeleteDashboards(context.Background(), cmd)
+		require.ErrorIs(t, err, models.ErrDashboardNotFound)
+		require.Equal(t, dash1.Uid, getDashboard(t, dash1.Id).Uid)
+	})
+}
+
 func TestDashboard_SortingOptions(t *testing.T) {
 	// insertTestDashboard uses GoConvey's assertions. Workaround.
 	t.Run("test with multiple sorting options", func(t *testing.T) {
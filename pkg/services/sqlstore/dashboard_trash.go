@@ -0,0 +1,299 @@
+package sqlstore
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func init() {
+	bus.AddHandlerCtx("sql", GetTrashedDashboards)
+	bus.AddHandlerCtx("sql", RestoreTrashedDashboard)
+	bus.AddHandlerCtx("sql", DeleteTrashedDashboard)
+	bus.AddHandler("sql", DeleteExpiredTrash)
+}
+
+// GetTrashedDashboards returns dashboards and folders in the recycle bin of an org, most recently deleted first.
+func GetTrashedDashboards(ctx context.Context, query *models.GetTrashedDashboardsQuery) error {
+	query.Result = make([]*models.TrashedDashboardDTO, 0)
+	return withDbSession(ctx, x, func(sess *DBSession) error {
+		return sess.Table("dashboard_trash").
+			Select(`dashboard_trash.id,
+				dashboard_trash.dashboard_id,
+				dashboard_trash.dashboard_uid,
+				dashboard_trash.title,
+				dashboard_trash.is_folder,
+				dashboard_trash.folder_id,
+				dashboard_trash.deleted_by as deleted_by_id,
+				dashboard_trash.deleted,`+
+				dialect.Quote("user")+`.login as deleted_by`).
+			Join("LEFT", dialect.Quote("user"), `dashboard_trash.deleted_by = `+dialect.Quote("user")+`.id`).
+			Where("dashboard_trash.org_id = ?", query.OrgId).
+			OrderBy("dashboard_trash.deleted DESC, dashboard_trash.id DESC").
+			Find(&query.Result)
+	})
+}
+
+// trashDashboard adds the dashboard to the recycle bin. For folders, subfolders
+// and dashboards in the folder are added to the same entry, parents before
+// their children. Rows are not deleted here, so the caller deletes them as for
+// a permanent delete.
+func trashDashboard(sess *DBSession, dashboard *models.Dashboard, deletedBy int64) error {
+	content := models.TrashedDashboardContent{
+		Dashboards: []*models.Dashboard{dashboard},
+	}
+	if dashboard.IsFolder {
+		subfolders, err := getFolderDescendants(sess, dashboard.OrgId, dashboard.Id)
+		if err != nil {
+			return err
+		}
+		folderIDs := []int64{dashboard.Id}
+		for _, subfolder := range subfolders {
+			folderIDs = append(folderIDs, subfolder.Id)
+		}
+
+		var children []*models.Dashboard
+		if err := sess.Where("org_id = ? AND is_folder = ?", dashboard.OrgId, dialect.BooleanStr(false)).
+			In("folder_id", folderIDs).Asc("id").Find(&children); err != nil {
+			return err
+		}
+		content.Dashboards = append(content.Dashboards, subfolders...)
+		content.Dashboards = append(content.Dashboards, children...)
+	}
+
+	ids := make([]int64, 0, len(content.Dashboards))
+	for _, dash := range content.Dashboards {
+		ids = append(ids, dash.Id)
+	}
+	if err := sess.In("dashboard_id", ids).Asc("id").Find(&content.Acl); err != nil {
+		return err
+	}
+	if err := sess.In("dashboard_id", ids).Asc("id").Find(&content.Versions); err != nil {
+		return err
+	}
+	if err := sess.Table(models.LibraryElementConnectionTableName).
+		In("connection_id", ids).Asc("id").
+		Find(&content.LibraryElementConnections); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(content)
+	if err != nil {
+		return err
+	}
+	entry := &models.TrashedDashboard{
+		OrgId:        dashboard.OrgId,
+		DashboardId:  dashboard.Id,
+		DashboardUid: dashboard.Uid,
+		Title:        dashboard.Title,
+		IsFolder:     dashboard.IsFolder,
+		FolderId:     dashboard.FolderId,
+		DeletedBy:    deletedBy,
+		Deleted:      time.Now(),
+		Data:         string(data),
+	}
+	if _, err := sess.Table("dashboard_trash").Insert(entry); err != nil {
+		return err
+	}
+
+	// Connections are removed by the library element service for permanent
+	// deletes, removing them here keeps the recycle bin consistent on its own.
+	_, err = sess.In("connection_id", ids).Delete(&libraryElementConnection{})
+	return err
+}
+
+// libraryElementConnection maps library element connections for inserts and deletes.
+type libraryElementConnection struct {
+	Id           int64
+	ElementId    int64
+	Kind         int64
+	ConnectionId int64
+	Created      time.Time
+	CreatedBy    int64
+}
+
+func getTrashedDashboard(sess *DBSession, orgID int64, id int64) (*models.TrashedDashboard, error) {
+	entry := models.TrashedDashboard{}
+	has, err := sess.Table("dashboard_trash").Where("id = ? AND org_id = ?", id, orgID).Get(&entry)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, models.ErrTrashedDashboardNotFound
+	}
+	return &entry, nil
+}
+
+// RestoreTrashedDashboard restores a dashboard or folder with its permissions,
+// versions and library panel connections. Dashboards get their previous ids
+// unless they are taken, so links with ids keep working. A dashboard or folder
+// is restored to its parent folder, which must not be in the recycle bin itself.
+func RestoreTrashedDashboard(ctx context.Context, cmd *models.RestoreTrashedDashboardCommand) error {
+	return inTransactionCtx(ctx, func(sess *DBSession) error {
+		entry, err := getTrashedDashboard(sess, cmd.OrgId, cmd.Id)
+		if err != nil {
+			return err
+		}
+		var content models.TrashedDashboardContent
+		if err := json.Unmarshal([]byte(entry.Data), &content); err != nil {
+			return err
+		}
+
+		if entry.FolderId != 0 {
+			folderExists, err := sess.Table("dashboard").Where("id = ? AND org_id = ? AND is_folder = ?", entry.FolderId, entry.OrgId, dialect.BooleanStr(true)).Exist()
+			if err != nil {
+				return err
+			}
+			if !folderExists {
+				return models.ErrTrashedDashboardFolderDeleted
+			}
+		}
+
+		// Ids of restored dashboards by their ids before deletion
+		ids := map[int64]int64{}
+		for _, dash := range content.Dashboards {
+			// The folder of dashboards in a folder entry is restored first.
+			if newID, ok := ids[dash.FolderId]; ok {
+				dash.FolderId = newID
+				dash.Data.Set("folderId", newID)
+			}
+			if err := restoreDashboardRow(sess, dash, ids); err != nil {
+				return err
+			}
+		}
+
+		for _, acl := range content.Acl {
+			newID, ok := ids[acl.DashboardID]
+			if !ok {
+				continue
+			}
+			acl.Id = 0
+			acl.DashboardID = newID
+			if _, err := sess.Insert(acl); err != nil {
+				return err
+			}
+		}
+
+		for _, version := range content.Versions {
+			newID, ok := ids[version.DashboardId]
+			if !ok {
+				continue
+			}
+			version.Id = 0
+			version.DashboardId = newID
+			if version.Data != nil {
+				version.Data.Set("id", newID)
+			}
+			if _, err := sess.Insert(version); err != nil {
+				return err
+			}
+		}
+
+		for _, conn := range content.LibraryElementConnections {
+			newID, ok := ids[conn.ConnectionId]
+			if !ok {
+				continue
+			}
+			// Library elements deleted in the meantime stay disconnected.
+			elementExists, err := sess.Table("library_element").Where("id = ?", conn.ElementId).Exist()
+			if err != nil {
+				return err
+			}
+			if !elementExists {
+				continue
+			}
+			if _, err := sess.Insert(&libraryElementConnection{
+				ElementId:    conn.ElementId,
+				Kind:         conn.Kind,
+				ConnectionId: newID,
+				Created:      conn.Created,
+				CreatedBy:    conn.CreatedBy,
+			}); err != nil {
+				return err
+			}
+		}
+
+		if _, err := sess.Exec("DELETE FROM dashboard_trash WHERE id = ?", entry.Id); err != nil {
+			return err
+		}
+		cmd.Result = content.Dashboards[0]
+		return nil
+	})
+}
+
+// restoreDashboardRow inserts the dashboard, its tags and search content, and
+// records its id in ids.
+func restoreDashboardRow(sess *DBSession, dash *models.Dashboard, ids map[int64]int64) error {
+	uidExists, err := sess.Table("dashboard").Where("org_id = ? AND uid = ?", dash.OrgId, dash.Uid).Exist()
+	if err != nil {
+		return err
+	}
+	if uidExists {
+		return models.ErrDashboardWithSameUIDExists
+	}
+	titleExists, err := sess.Table("dashboard").Where("org_id = ? AND folder_id = ? AND title = ?", dash.OrgId, dash.FolderId, dash.Title).Exist()
+	if err != nil {
+		return err
+	}
+	if titleExists {
+		if dash.IsFolder {
+			return models.ErrFolderSameNameExists
+		}
+		return models.ErrDashboardWithSameNameInFolderExists
+	}
+
+	oldID := dash.Id
+	idExists, err := sess.Table("dashboard").Where("id = ?", oldID).Exist()
+	if err != nil {
+		return err
+	}
+	if idExists {
+		dash.Id = 0
+	}
+	if _, err := sess.Insert(dash); err != nil {
+		return err
+	}
+	dash.SetId(dash.Id)
+	if _, err := sess.ID(dash.Id).Cols("data").Update(dash); err != nil {
+		return err
+	}
+	ids[oldID] = dash.Id
+
+	for _, tag := range dash.GetTags() {
+		if _, err := sess.Insert(&DashboardTag{DashboardId: dash.Id, Term: tag}); err != nil {
+			return err
+		}
+	}
+	return updateDashboardSearchContent(sess, dash)
+}
+
+// DeleteTrashedDashboard deletes a dashboard or folder from the recycle bin permanently.
+func DeleteTrashedDashboard(ctx context.Context, cmd *models.DeleteTrashedDashboardCommand) error {
+	return inTransactionCtx(ctx, func(sess *DBSession) error {
+		entry, err := getTrashedDashboard(sess, cmd.OrgId, cmd.Id)
+		if err != nil {
+			return err
+		}
+		_, err = sess.Exec("DELETE FROM dashboard_trash WHERE id = ?", entry.Id)
+		return err
+	})
+}
+
+// DeleteExpiredTrash deletes recycle bin entries older than the trash retention.
+func DeleteExpiredTrash(cmd *models.DeleteExpiredTrashCommand) error {
+	if setting.DashboardTrashRetention <= 0 {
+		return nil
+	}
+	return inTransaction(func(sess *DBSession) error {
+		res, err := sess.Exec("DELETE FROM dashboard_trash WHERE deleted < ?", time.Now().Add(-setting.DashboardTrashRetention))
+		if err != nil {
+			return err
+		}
+		cmd.DeletedRows, _ = res.RowsAffected()
+		return nil
+	})
+}
@@ -0,0 +1,170 @@
+//go:build integration
+// +build integration
+
+package sqlstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDashboardTrash(t *testing.T) {
+	var sqlStore *SQLStore
+	var user models.User
+	var savedFolder, savedDash, savedDash2 *models.Dashboard
+
+	setup := func() {
+		sqlStore = InitTestDB(t)
+		user = createUser(t, sqlStore, "admin", "Admin", false)
+		savedFolder = insertTestDashboard(t, sqlStore, "trash folder", 1, 0, true)
+		savedDash = insertTestDashboard(t, sqlStore, "trash dash", 1, savedFolder.Id, false, "prod")
+		savedDash2 = insertTestDashboard(t, sqlStore, "other dash", 1, 0, false)
+		err := sqlStore.UpdateDashboardACL(savedDash.Id, []*models.DashboardAcl{{
+			OrgID:       1,
+			DashboardID: savedDash.Id,
+			UserID:      user.Id,
+			Permission:  models.PERMISSION_EDIT,
+			Created:     time.Now(),
+			Updated:     time.Now(),
+		}})
+		require.NoError(t, err)
+	}
+
+	trash := func(t *testing.T, dash *models.Dashboard) {
+		t.Helper()
+		err := DeleteDashboard(context.Background(), &models.DeleteDashboardCommand{
+			Id:        dash.Id,
+			OrgId:     1,
+			Trash:     true,
+			DeletedBy: user.Id,
+		})
+		require.NoError(t, err)
+	}
+
+	getTrash := func(t *testing.T) []*models.TrashedDashboardDTO {
+		t.Helper()
+		query := models.GetTrashedDashboardsQuery{OrgId: 1}
+		require.NoError(t, GetTrashedDashboards(context.Background(), &query))
+		return query.Result
+	}
+
+	t.Run("Deleted folder is listed in the recycle bin", func(t *testing.T) {
+		setup()
+		trash(t, savedFolder)
+
+		entries := getTrash(t)
+		require.Len(t, entries, 1)
+		require.Equal(t, savedFolder.Id, entries[0].DashboardId)
+		require.Equal(t, savedFolder.Uid, entries[0].DashboardUid)
+		require.True(t, entries[0].IsFolder)
+		require.Equal(t, "admin", entries[0].DeletedBy)
+
+		query := models.GetDashboardQuery{Id: savedDash.Id, OrgId: 1}
+		require.ErrorIs(t, GetDashboard(context.Background(), &query), models.ErrDashboardNotFound)
+	})
+
+	t.Run("Restored folder has its dashboards, permissions and versions", func(t *testing.T) {
+		setup()
+		trash(t, savedFolder)
+
+		cmd := models.RestoreTrashedDashboardCommand{Id: getTrash(t)[0].Id, OrgId: 1}
+		require.NoError(t, RestoreTrashedDashboard(context.Background(), &cmd))
+		require.Equal(t, savedFolder.Id, cmd.Result.Id)
+		require.Empty(t, getTrash(t))
+
+		query := models.GetDashboardQuery{Id: savedDash.Id, OrgId: 1}
+		require.NoError(t, GetDashboard(context.Background(), &query))
+		require.Equal(t, savedDash.Uid, query.Result.Uid)
+		require.Equal(t, savedFolder.Id, query.Result.FolderId)
+
+		aclQuery := models.GetDashboardAclInfoListQuery{DashboardID: savedDash.Id, OrgID: 1}
+		require.NoError(t, sqlStore.GetDashboardAclInfoList(context.Background(), &aclQuery))
+		found := false
+		for _, item := range aclQuery.Result {
+			if item.UserId == user.Id && item.DashboardId == savedDash.Id {
+				found = true
+			}
+		}
+		require.True(t, found)
+
+		versionsQuery := models.GetDashboardVersionsQuery{DashboardId: savedDash.Id, OrgId: 1}
+		require.NoError(t, GetDashboardVersions(&versionsQuery))
+		require.Len(t, versionsQuery.Result, 1)
+
+		tagsQuery := models.GetDashboardTagsQuery{OrgId: 1}
+		require.NoError(t, GetDashboardTags(context.Background(), &tagsQuery))
+		require.Len(t, tagsQuery.Result, 1)
+		require.Equal(t, "prod", tagsQuery.Result[0].Term)
+	})
+
+	t.Run("Dashboard can't be restored with the same uid as an existing dashboard", func(t *testing.T) {
+		setup()
+		trash(t, savedDash2)
+		insertTestDashboardWithUID(t, sqlStore, savedDash2)
+
+		cmd := models.RestoreTrashedDashboardCommand{Id: getTrash(t)[0].Id, OrgId: 1}
+		require.ErrorIs(t, RestoreTrashedDashboard(context.Background(), &cmd), models.ErrDashboardWithSameUIDExists)
+	})
+
+	t.Run("Dashboard can't be restored to a deleted folder", func(t *testing.T) {
+		setup()
+		trash(t, savedDash)
+		trash(t, savedFolder)
+
+		entries := getTrash(t)
+		require.Len(t, entries, 2)
+		for _, entry := range entries {
+			if entry.DashboardId != savedDash.Id {
+				continue
+			}
+			cmd := models.RestoreTrashedDashboardCommand{Id: entry.Id, OrgId: 1}
+			require.ErrorIs(t, RestoreTrashedDashboard(context.Background(), &cmd), models.ErrTrashedDashboardFolderDeleted)
+		}
+	})
+
+	t.Run("Dashboard is deleted from the recycle bin", func(t *testing.T) {
+		setup()
+		trash(t, savedDash2)
+
+		cmd := models.DeleteTrashedDashboardCommand{Id: getTrash(t)[0].Id, OrgId: 1}
+		require.NoError(t, DeleteTrashedDashboard(context.Background(), &cmd))
+		require.Empty(t, getTrash(t))
+		require.ErrorIs(t, DeleteTrashedDashboard(context.Background(), &cmd), models.ErrTrashedDashboardNotFound)
+	})
+
+	t.Run("Expired dashboards are deleted from the recycle bin", func(t *testing.T) {
+		setup()
+		trash(t, savedDash2)
+
+		retention := setting.DashboardTrashRetention
+		t.Cleanup(func() { setting.DashboardTrashRetention = retention })
+
+		setting.DashboardTrashRetention = time.Hour
+		cmd := models.DeleteExpiredTrashCommand{}
+		require.NoError(t, DeleteExpiredTrash(&cmd))
+		require.Len(t, getTrash(t), 1)
+
+		_, err := x.Exec("UPDATE dashboard_trash SET deleted = ?", time.Now().Add(-2*time.Hour))
+		require.NoError(t, err)
+		require.NoError(t, DeleteExpiredTrash(&cmd))
+		require.EqualValues(t, 1, cmd.DeletedRows)
+		require.Empty(t, getTrash(t))
+	})
+}
+
+func insertTestDashboardWithUID(t *testing.T, sqlStore *SQLStore, dash *models.Dashboard) {
+	t.Helper()
+	data := dash.Data
+	data.Set("id", nil)
+	_, err := sqlStore.SaveDashboard(models.SaveDashboardCommand{
+		OrgId:     dash.OrgId,
+		FolderId:  dash.FolderId,
+		Dashboard: data,
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,232 @@
+package sqlstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func init() {
+	bus.AddHandlerCtx("sql", RecordDashboardView)
+	bus.AddHandlerCtx("sql", GetDashboardViewStats)
+	bus.AddHandlerCtx("sql", GetStaleDashboards)
+	bus.AddHandler("sql", DeleteExpiredDashboardViews)
+}
+
+// RecordDashboardView counts the views of a dashboard by the user on the day of
+// the views. Views aren't recorded if the view retention is disabled.
+func RecordDashboardView(ctx context.Context, cmd *models.RecordDashboardViewCommand) error {
+	if setting.DashboardViewRetention <= 0 {
+		return nil
+	}
+
+	viewed := cmd.Time
+	if viewed.IsZero() {
+		viewed = time.Now()
+	}
+	day := startOfDay(viewed).Unix()
+	views := cmd.Views
+	if views <= 0 {
+		views = 1
+	}
+
+	record := func(sess *DBSession) error {
+		res, err := sess.Exec("UPDATE dashboard_view SET views = views + ?, last_viewed = ? WHERE dashboard_id = ? AND user_id = ? AND day = ?",
+			views, viewed.Unix(), cmd.DashboardId, cmd.UserId, day)
+		if err != nil {
+			return err
+		}
+		if affected, err := res.RowsAffected(); err != nil || affected > 0 {
+			return err
+		}
+
+		_, err = sess.Insert(&models.DashboardView{
+			OrgId:       cmd.OrgId,
+			DashboardId: cmd.DashboardId,
+			UserId:      cmd.UserId,
+			Day:         day,
+			Views:       views,
+			LastViewed:  viewed.Unix(),
+		})
+		return err
+	}
+
+	err := inTransactionCtx(ctx, record)
+	if err != nil && dialect.IsUniqueConstraintViolation(err) {
+		// the row was inserted by a concurrent view of the same user
+		err = inTransactionCtx(ctx, record)
+	}
+	return err
+}
+
+// GetDashboardViewStats returns the views of a dashboard within the number of
+// days, today included, with the views of each day.
+func GetDashboardViewStats(ctx context.Context, query *models.GetDashboardViewStatsQuery) error {
+	today := startOfDay(time.Now())
+	since := today.AddDate(0, 0, 1-query.Days)
+
+	return withDbSession(ctx, x, func(sess *DBSession) error {
+		type dailyViews struct {
+			Day           int64
+			Views         int64
+			UniqueViewers int64
+		}
+		daily := make([]*dailyViews, 0)
+		err := sess.SQL(`SELECT day, SUM(views) AS views, COUNT(DISTINCT user_id) AS unique_viewers
+			FROM dashboard_view
+			WHERE org_id = ? AND dashboard_id = ? AND day >= ?
+			GROUP BY day`, query.OrgId, query.DashboardId, since.Unix()).Find(&daily)
+		if err != nil {
+			return err
+		}
+
+		var total struct {
+			Views         int64
+			UniqueViewers int64
+		}
+		_, err = sess.SQL(`SELECT COALESCE(SUM(views), 0) AS views, COUNT(DISTINCT user_id) AS unique_viewers
+			FROM dashboard_view
+			WHERE org_id = ? AND dashboard_id = ? AND day >= ?`, query.OrgId, query.DashboardId, since.Unix()).Get(&total)
+		if err != nil {
+			return err
+		}
+
+		// last viewed regardless of the number of days
+		var last struct {
+			LastViewed int64
+		}
+		_, err = sess.SQL(`SELECT COALESCE(MAX(last_viewed), 0) AS last_viewed
+			FROM dashboard_view
+			WHERE org_id = ? AND dashboard_id = ?`, query.OrgId, query.DashboardId).Get(&last)
+		if err != nil {
+			return err
+		}
+
+		byDay := make(map[int64]*dailyViews, len(daily))
+		for _, d := range daily {
+			byDay[d.Day] = d
+		}
+
+		result := &models.DashboardViewStats{
+			DashboardId:   query.DashboardId,
+			Days:          query.Days,
+			Views:         total.Views,
+			UniqueViewers: total.UniqueViewers,
+			LastViewed:    unixToTime(last.LastViewed),
+			Daily:         make([]*models.DashboardDailyViews, 0, query.Days),
+		}
+		for day := since; !day.After(today); day = day.AddDate(0, 0, 1) {
+			views := &models.DashboardDailyViews{Date: day.Format("2006-01-02")}
+			if d, ok := byDay[day.Unix()]; ok {
+				views.Views = d.Views
+				views.UniqueViewers = d.UniqueViewers
+			}
+			result.Daily = append(result.Daily, views)
+		}
+		query.Result = result
+		return nil
+	})
+}
+
+// GetStaleDashboards returns dashboards the user can view which haven't been
+// viewed within the number of days, least recently viewed first. Dashboards
+// created within the number of days aren't stale.
+func GetStaleDashboards(ctx context.Context, query *models.GetStaleDashboardsQuery) error {
+	since := time.Now().AddDate(0, 0, -query.Days)
+
+	return withDbSession(ctx, x, func(sess *DBSession) error {
+		type staleDashboard struct {
+			Id          int64
+			Uid         string
+			Title       string
+			Slug        string
+			FolderId    int64
+			FolderUid   string
+			FolderTitle string
+			Created     time.Time
+			Updated     time.Time
+			LastViewed  int64
+		}
+
+		sb := &SQLBuilder{}
+		sb.Write(`SELECT
+				dashboard.id,
+				dashboard.uid,
+				dashboard.title,
+				dashboard.slug,
+				dashboard.folder_id,
+				folder.uid AS folder_uid,
+				folder.title AS folder_title,
+				dashboard.created,
+				dashboard.updated,
+				COALESCE(dashboard_views.last_viewed, 0) AS last_viewed
+			FROM dashboard
+			LEFT JOIN dashboard AS folder ON folder.id = dashboard.folder_id
+			LEFT JOIN (
+				SELECT dashboard_id, MAX(last_viewed) AS last_viewed
+				FROM dashboard_view
+				WHERE org_id = ?
+				GROUP BY dashboard_id
+			) AS dashboard_views ON dashboard_views.dashboard_id = dashboard.id
+			WHERE dashboard.org_id = ? AND dashboard.is_folder = ? AND dashboard.created < ? AND
+				(dashboard_views.last_viewed IS NULL OR dashboard_views.last_viewed < ?)`,
+			query.SignedInUser.OrgId, query.SignedInUser.OrgId, dialect.BooleanStr(false), since, since.Unix())
+		sb.WriteDashboardPermissionFilter(query.SignedInUser, models.PERMISSION_VIEW)
+		sb.Write(" ORDER BY last_viewed ASC, dashboard.title ASC " +
+			dialect.LimitOffset(int64(query.Limit), int64((query.Page-1)*query.Limit)))
+
+		res := make([]*staleDashboard, 0)
+		if err := sess.SQL(sb.GetSQLString(), sb.GetParams()...).Find(&res); err != nil {
+			return err
+		}
+
+		query.Result = make([]*models.StaleDashboardDTO, 0, len(res))
+		for _, d := range res {
+			query.Result = append(query.Result, &models.StaleDashboardDTO{
+				Id:          d.Id,
+				Uid:         d.Uid,
+				Title:       d.Title,
+				Url:         models.GetDashboardUrl(d.Uid, d.Slug),
+				FolderId:    d.FolderId,
+				FolderUid:   d.FolderUid,
+				FolderTitle: d.FolderTitle,
+				Created:     d.Created,
+				Updated:     d.Updated,
+				LastViewed:  unixToTime(d.LastViewed),
+			})
+		}
+		return nil
+	})
+}
+
+// DeleteExpiredDashboardViews deletes recorded views of days older than the retention.
+func DeleteExpiredDashboardViews(cmd *models.DeleteExpiredDashboardViewsCommand) error {
+	if setting.DashboardViewRetention <= 0 {
+		return nil
+	}
+	return inTransaction(func(sess *DBSession) error {
+		res, err := sess.Exec("DELETE FROM dashboard_view WHERE day < ?",
+			startOfDay(time.Now().Add(-setting.DashboardViewRetention)).Unix())
+		if err != nil {
+			return err
+		}
+		cmd.DeletedRows, _ = res.RowsAffected()
+		return nil
+	})
+}
+
+// startOfDay returns the start of the day of the time in UTC.
+func startOfDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+func unixToTime(seconds int64) *time.Time {
+	if seconds == 0 {
+		return nil
+	}
+	t := time.Unix(seconds, 0)
+	return &t
+}
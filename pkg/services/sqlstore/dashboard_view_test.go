@@ -0,0 +1,164 @@
+//go:build integration
+// +build integration
+
+package sqlstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/search"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDashboardViews(t *testing.T) {
+	var sqlStore *SQLStore
+	var viewed, stale, other *models.Dashboard
+
+	origRetention := setting.DashboardViewRetention
+	t.Cleanup(func() {
+		setting.DashboardViewRetention = origRetention
+	})
+	setting.DashboardViewRetention = 90 * 24 * time.Hour
+
+	now := time.Now()
+	daysAgo := func(days int) time.Time {
+		return now.AddDate(0, 0, -days)
+	}
+
+	setup := func(t *testing.T) {
+		sqlStore = InitTestDB(t)
+		viewed = insertTestDashboard(t, sqlStore, "viewed", 1, 0, false)
+		stale = insertTestDashboard(t, sqlStore, "stale", 1, 0, false)
+		other = insertTestDashboard(t, sqlStore, "never viewed", 1, 0, false)
+		_, err := sqlStore.engine.Exec("UPDATE dashboard SET created = ?", daysAgo(100))
+		require.NoError(t, err)
+	}
+
+	view := func(t *testing.T, dash *models.Dashboard, userID int64, viewed time.Time) {
+		t.Helper()
+		err := RecordDashboardView(context.Background(), &models.RecordDashboardViewCommand{
+			OrgId:       1,
+			DashboardId: dash.Id,
+			UserId:      userID,
+			Time:        viewed,
+		})
+		require.NoError(t, err)
+	}
+
+	t.Run("Should count views and unique viewers per day", func(t *testing.T) {
+		setup(t)
+		view(t, viewed, 1, now)
+		view(t, viewed, 1, now)
+		view(t, viewed, 2, now)
+		view(t, viewed, 1, daysAgo(2))
+		view(t, viewed, 3, daysAgo(40))
+
+		query := models.GetDashboardViewStatsQuery{OrgId: 1, DashboardId: viewed.Id, Days: 7}
+		err := GetDashboardViewStats(context.Background(), &query)
+		require.NoError(t, err)
+
+		stats := query.Result
+		require.Equal(t, int64(4), stats.Views)
+		require.Equal(t, int64(2), stats.UniqueViewers)
+		require.Equal(t, now.Unix(), stats.LastViewed.Unix())
+		require.Len(t, stats.Daily, 7)
+		require.Equal(t, now.UTC().Format("2006-01-02"), stats.Daily[6].Date)
+		require.Equal(t, int64(3), stats.Daily[6].Views)
+		require.Equal(t, int64(2), stats.Daily[6].UniqueViewers)
+		require.Equal(t, int64(1), stats.Daily[4].Views)
+		require.Equal(t, int64(0), stats.Daily[5].Views)
+	})
+
+	t.Run("Should count a number of views at once", func(t *testing.T) {
+		setup(t)
+		for i := 0; i < 2; i++ {
+			err := RecordDashboardView(context.Background(), &models.RecordDashboardViewCommand{
+				OrgId:       1,
+				DashboardId: viewed.Id,
+				UserId:      1,
+				Views:       5,
+				Time:        now,
+			})
+			require.NoError(t, err)
+		}
+
+		query := models.GetDashboardViewStatsQuery{OrgId: 1, DashboardId: viewed.Id, Days: 1}
+		err := GetDashboardViewStats(context.Background(), &query)
+		require.NoError(t, err)
+		require.Equal(t, int64(10), query.Result.Views)
+		require.Equal(t, int64(1), query.Result.UniqueViewers)
+	})
+
+	t.Run("Should return dashboards not viewed within the number of days", func(t *testing.T) {
+		setup(t)
+		view(t, viewed, 1, daysAgo(1))
+		view(t, stale, 1, daysAgo(60))
+
+		query := models.GetStaleDashboardsQuery{
+			SignedInUser: &models.SignedInUser{UserId: 1, OrgId: 1, OrgRole: models.ROLE_ADMIN},
+			Days:         30,
+			Limit:        10,
+			Page:         1,
+		}
+		err := GetStaleDashboards(context.Background(), &query)
+		require.NoError(t, err)
+		require.Len(t, query.Result, 2)
+		require.Equal(t, other.Id, query.Result[0].Id)
+		require.Nil(t, query.Result[0].LastViewed)
+		require.Equal(t, stale.Id, query.Result[1].Id)
+		require.NotNil(t, query.Result[1].LastViewed)
+	})
+
+	t.Run("Should sort search results by views and last viewed", func(t *testing.T) {
+		setup(t)
+		view(t, stale, 1, daysAgo(60))
+		view(t, viewed, 1, daysAgo(1))
+		view(t, viewed, 2, daysAgo(1))
+
+		findTitles := func(sort search.SortOption) []string {
+			query := &search.FindPersistedDashboardsQuery{
+				SignedInUser: &models.SignedInUser{UserId: 1, OrgId: 1, OrgRole: models.ROLE_ADMIN},
+				OrgId:        1,
+				Sort:         sort,
+			}
+			err := sqlStore.SearchDashboards(context.Background(), query)
+			require.NoError(t, err)
+			titles := make([]string, 0, len(query.Result))
+			for _, hit := range query.Result {
+				titles = append(titles, hit.Title)
+			}
+			return titles
+		}
+
+		require.Equal(t, []string{"viewed", "stale", "never viewed"}, findTitles(search.SortViewsDesc))
+		require.Equal(t, []string{"never viewed", "stale", "viewed"}, findTitles(search.SortLastViewedAsc))
+		require.Equal(t, []string{"viewed", "stale", "never viewed"}, findTitles(search.SortLastViewedDesc))
+	})
+
+	t.Run("Should delete views older than the retention", func(t *testing.T) {
+		setup(t)
+		view(t, viewed, 1, daysAgo(1))
+		view(t, viewed, 1, daysAgo(100))
+
+		cmd := models.DeleteExpiredDashboardViewsCommand{}
+		err := DeleteExpiredDashboardViews(&cmd)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), cmd.DeletedRows)
+	})
+
+	t.Run("Should delete views with the dashboard", func(t *testing.T) {
+		setup(t)
+		view(t, viewed, 1, now)
+
+		err := DeleteDashboard(context.Background(), &models.DeleteDashboardCommand{Id: viewed.Id, OrgId: 1})
+		require.NoError(t, err)
+
+		count, err := sqlStore.engine.Table("dashboard_view").Count()
+		require.NoError(t, err)
+		require.Equal(t, int64(0), count)
+	})
+}
@@ -0,0 +1,194 @@
+package migrations
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"xorm.io/xorm"
+)
+
+func addDashboardSearchContentMigrations(mg *Migrator) {
+	dashboardSearchContentV1 := Table{
+		Name: "dashboard_search_content",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "dashboard_id", Type: DB_BigInt, Nullable: false},
+			{Name: "kind", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "panel_id", Type: DB_BigInt, Nullable: false},
+			{Name: "panel_title", Type: DB_Text, Nullable: false},
+			{Name: "text", Type: DB_Text, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"dashboard_id"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard_search_content table v1", NewAddTableMigration(dashboardSearchContentV1))
+	addTableIndicesMigrations(mg, "v1", dashboardSearchContentV1)
+
+	mg.AddMigration("populate dashboard_search_content", &populateDashboardSearchContent{})
+}
+
+// populateDashboardSearchContent stores search content of existing dashboards.
+type populateDashboardSearchContent struct {
+	MigrationBase
+}
+
+var _ CodeMigration = &populateDashboardSearchContent{}
+
+func (m *populateDashboardSearchContent) SQL(dialect Dialect) string {
+	return "code migration"
+}
+
+func (m *populateDashboardSearchContent) Exec(sess *xorm.Session, mg *Migrator) error {
+	type dashboard struct {
+		Id   int64
+		Data string
+	}
+
+	var lastID int64
+	for {
+		var dashboards []dashboard
+		err := sess.Table("dashboard").Cols("id", "data").
+			Where("id > ? AND is_folder = "+mg.Dialect.BooleanStr(false), lastID).
+			Asc("id").Limit(100).Find(&dashboards)
+		if err != nil {
+			return err
+		}
+		if len(dashboards) == 0 {
+			return nil
+		}
+
+		for _, dash := range dashboards {
+			lastID = dash.Id
+			data, err := simplejson.NewJson([]byte(dash.Data))
+			if err != nil {
+				mg.Logger.Warn("Failed to parse dashboard, its content is not searchable", "id", dash.Id, "error", err)
+				continue
+			}
+			for _, content := range extractSearchContentV1(data) {
+				content.DashboardId = dash.Id
+				if _, err := sess.Table("dashboard_search_content").Insert(content); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// searchContentV1 is a row of the dashboard_search_content table v1.
+type searchContentV1 struct {
+	Id          int64
+	DashboardId int64
+	Kind        string
+	PanelId     int64
+	PanelTitle  string
+	Text        string
+}
+
+// searchContentQueryKeysV1 are properties of panel targets and variables
+// holding query expressions.
+var searchContentQueryKeysV1 = []string{
+	"expr",
+	"query",
+	"rawSql",
+	"target",
+	"expression",
+	"queryText",
+	"measurement",
+	"metric",
+	"metricName",
+}
+
+// extractSearchContentV1 is a copy of searchstore.ExtractContent as of the
+// migration, so that changes of the content extraction don't change what the
+// migration does.
+func extractSearchContentV1(dashboard *simplejson.Json) []*searchContentV1 {
+	e := searchContentExtractorV1{seen: map[string]struct{}{}}
+
+	for _, panel := range dashboard.Get("panels").MustArray() {
+		e.addPanel(simplejson.NewFromAny(panel))
+	}
+	for _, row := range dashboard.Get("rows").MustArray() {
+		for _, panel := range simplejson.NewFromAny(row).Get("panels").MustArray() {
+			e.addPanel(simplejson.NewFromAny(panel))
+		}
+	}
+
+	for _, v := range dashboard.Get("templating").Get("list").MustArray() {
+		variable := simplejson.NewFromAny(v)
+		e.add("variable", 0, "", variable.Get("name").MustString())
+		e.addDatasource(0, "", variable.Get("datasource"))
+		query := variable.Get("query")
+		if s, err := query.String(); err == nil {
+			e.add("query", 0, "", s)
+		} else {
+			e.addQuery(0, "", query)
+		}
+	}
+
+	return e.content
+}
+
+type searchContentExtractorV1 struct {
+	content []*searchContentV1
+	seen    map[string]struct{}
+}
+
+func (e *searchContentExtractorV1) add(kind string, panelID int64, panelTitle string, text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	if runes := []rune(text); len(runes) > 8000 {
+		text = string(runes[:8000])
+	}
+
+	key := fmt.Sprintf("%s/%d/%s", kind, panelID, text)
+	if _, ok := e.seen[key]; ok {
+		return
+	}
+	e.seen[key] = struct{}{}
+	e.content = append(e.content, &searchContentV1{
+		Kind:       kind,
+		PanelId:    panelID,
+		PanelTitle: panelTitle,
+		Text:       text,
+	})
+}
+
+func (e *searchContentExtractorV1) addPanel(panel *simplejson.Json) {
+	id := panel.Get("id").MustInt64()
+	title := panel.Get("title").MustString()
+
+	e.add("panel-title", id, title, title)
+	e.add("panel-description", id, title, panel.Get("description").MustString())
+	e.addDatasource(id, title, panel.Get("datasource"))
+	for _, t := range panel.Get("targets").MustArray() {
+		target := simplejson.NewFromAny(t)
+		e.addQuery(id, title, target)
+		e.addDatasource(id, title, target.Get("datasource"))
+	}
+
+	for _, nested := range panel.Get("panels").MustArray() {
+		e.addPanel(simplejson.NewFromAny(nested))
+	}
+}
+
+func (e *searchContentExtractorV1) addQuery(panelID int64, panelTitle string, target *simplejson.Json) {
+	for _, key := range searchContentQueryKeysV1 {
+		if s, err := target.Get(key).String(); err == nil {
+			e.add("query", panelID, panelTitle, s)
+		}
+	}
+}
+
+func (e *searchContentExtractorV1) addDatasource(panelID int64, panelTitle string, ref *simplejson.Json) {
+	if s, err := ref.String(); err == nil {
+		e.add("datasource", panelID, panelTitle, s)
+		return
+	}
+	e.add("datasource", panelID, panelTitle, ref.Get("uid").MustString())
+}
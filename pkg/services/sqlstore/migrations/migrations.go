@@ -55,6 +55,9 @@ func (*OSSMigrations) AddMigration(mg *Migrator) {
 	addSecretsMigration(mg)
 	addKVStoreMigrations(mg)
 	ualert.AddDashboardUIDPanelIDMigration(mg)
+	addDashboardTrashMigrations(mg)
+	addDashboardSearchContentMigrations(mg)
+	addDashboardViewMigrations(mg)
 }
 
 func addMigrationLogMigrations(mg *Migrator) {
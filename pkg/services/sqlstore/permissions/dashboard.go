@@ -1,6 +1,7 @@
 package permissions
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/grafana/grafana/pkg/models"
@@ -25,17 +26,13 @@ func (d DashboardPermissionFilter) Where() (string, []interface{}) {
 		okRoles = append(okRoles, models.ROLE_VIEWER)
 	}
 
-	falseStr := d.Dialect.BooleanStr(false)
-
 	sql := `(
 		dashboard.id IN (
 			SELECT distinct DashboardId from (
 				SELECT d.id AS DashboardId
-					FROM dashboard AS d
-					LEFT JOIN dashboard AS folder on folder.id = d.folder_id
+					FROM dashboard AS d` + ParentFoldersJoin("d") + `
 					LEFT JOIN dashboard_acl AS da ON
-						da.dashboard_id = d.id OR
-						da.dashboard_id = d.folder_id
+						da.dashboard_id IN (d.id, ` + ACLFolderID("d", d.Dialect) + `)
 					LEFT JOIN team_member as ugm on ugm.team_id = da.team_id
 					WHERE
 						d.org_id = ? AND
@@ -47,15 +44,11 @@ func (d DashboardPermissionFilter) Where() (string, []interface{}) {
 						)
 				UNION
 				SELECT d.id AS DashboardId
-					FROM dashboard AS d
-					LEFT JOIN dashboard AS folder on folder.id = d.folder_id
+					FROM dashboard AS d` + ParentFoldersJoin("d") + `
 					LEFT JOIN dashboard_acl AS da ON
 						(
 							-- include default permissions -->
-							da.org_id = -1 AND (
-							  (folder.id IS NOT NULL AND folder.has_acl = ` + falseStr + `) OR
-							  (folder.id IS NULL AND d.has_acl = ` + falseStr + `)
-							)
+							da.org_id = -1 AND ` + DefaultACLApplies("d", d.Dialect) + `
 						)
 					WHERE
 						d.org_id = ? AND
@@ -75,3 +68,41 @@ func (d DashboardPermissionFilter) Where() (string, []interface{}) {
 	params = append(params, okRoles...)
 	return sql, params
 }
+
+// ParentFoldersJoin joins the parent folders of the dashboard with the alias as
+// parent1, the folder of the dashboard, to parentN, where N is the maximum depth
+// of nested folders. Missing parent folders are NULL.
+func ParentFoldersJoin(alias string) string {
+	var sb strings.Builder
+	parent := alias
+	for i := 1; i <= models.MaxFolderDepth; i++ {
+		sb.WriteString(fmt.Sprintf("\n\t\t\t\t\tLEFT JOIN dashboard AS parent%d ON parent%d.id = %s.folder_id", i, i, parent))
+		parent = fmt.Sprintf("parent%d", i)
+	}
+	return sb.String()
+}
+
+// ACLFolderID returns an expression of the id of the folder whose permissions
+// apply to the dashboard with the alias besides its own ones. It's the nearest
+// parent folder with permissions set, or the dashboard itself if it's a folder
+// with permissions set, so permissions of a subfolder override permissions of
+// its parent folders. It's NULL when there is no such folder.
+func ACLFolderID(alias string, dialect migrator.Dialect) string {
+	trueStr := dialect.BooleanStr(true)
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("CASE WHEN %s.is_folder = %s AND %s.has_acl = %s THEN %s.id", alias, trueStr, alias, trueStr, alias))
+	for i := 1; i <= models.MaxFolderDepth; i++ {
+		sb.WriteString(fmt.Sprintf(" WHEN parent%d.has_acl = %s THEN parent%d.id", i, trueStr, i))
+	}
+	sb.WriteString(" END")
+	return sb.String()
+}
+
+// DefaultACLApplies returns a condition which is true when default permissions
+// apply to the dashboard with the alias, that is when no folder permissions
+// apply to it and it's not a dashboard with permissions set in the General
+// folder.
+func DefaultACLApplies(alias string, dialect migrator.Dialect) string {
+	return fmt.Sprintf("(%s) IS NULL AND (%s.folder_id <> 0 OR %s.has_acl = %s)",
+		ACLFolderID(alias, dialect), alias, alias, dialect.BooleanStr(false))
+}
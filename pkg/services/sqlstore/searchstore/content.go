@@ -0,0 +1,192 @@
+package searchstore
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// Kinds of dashboard content stored for content search.
+const (
+	ContentPanelTitle       = "panel-title"
+	ContentPanelDescription = "panel-description"
+	ContentQuery            = "query"
+	ContentDatasource       = "datasource"
+	ContentVariable         = "variable"
+)
+
+// ContentKinds lists kinds of dashboard content stored for content search.
+var ContentKinds = []string{
+	ContentPanelTitle,
+	ContentPanelDescription,
+	ContentQuery,
+	ContentDatasource,
+	ContentVariable,
+}
+
+// maxContentLength is the maximum number of characters of stored content.
+const maxContentLength = 8000
+
+// queryKeys are properties of panel targets and variables holding query
+// expressions in the data sources shipped with Grafana.
+var queryKeys = []string{
+	"expr",
+	"query",
+	"rawSql",
+	"target",
+	"expression",
+	"queryText",
+	"measurement",
+	"metric",
+	"metricName",
+}
+
+// Content is a searchable text of a dashboard, stored in the
+// dashboard_search_content table.
+type Content struct {
+	Id          int64
+	DashboardId int64
+	Kind        string
+	PanelId     int64
+	PanelTitle  string
+	Text        string
+}
+
+// ExtractContent returns searchable texts of the dashboard JSON: panel titles
+// and descriptions, query expressions, data source references and variables.
+func ExtractContent(dashboard *simplejson.Json) []*Content {
+	e := contentExtractor{seen: map[string]struct{}{}}
+
+	for _, panel := range dashboard.Get("panels").MustArray() {
+		e.addPanel(simplejson.NewFromAny(panel))
+	}
+	// Dashboards from before schema version 16 have panels in rows.
+	for _, row := range dashboard.Get("rows").MustArray() {
+		for _, panel := range simplejson.NewFromAny(row).Get("panels").MustArray() {
+			e.addPanel(simplejson.NewFromAny(panel))
+		}
+	}
+
+	for _, v := range dashboard.Get("templating").Get("list").MustArray() {
+		variable := simplejson.NewFromAny(v)
+		e.add(ContentVariable, 0, "", variable.Get("name").MustString())
+		e.addDatasource(0, "", variable.Get("datasource"))
+		// The query of query variables is a string or an object depending on
+		// the data source.
+		query := variable.Get("query")
+		if s, err := query.String(); err == nil {
+			e.add(ContentQuery, 0, "", s)
+		} else {
+			e.addQuery(0, "", query)
+		}
+	}
+
+	return e.content
+}
+
+type contentExtractor struct {
+	content []*Content
+	seen    map[string]struct{}
+}
+
+func (e *contentExtractor) add(kind string, panelID int64, panelTitle string, text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	if runes := []rune(text); len(runes) > maxContentLength {
+		text = string(runes[:maxContentLength])
+	}
+
+	key := fmt.Sprintf("%s/%d/%s", kind, panelID, text)
+	if _, ok := e.seen[key]; ok {
+		return
+	}
+	e.seen[key] = struct{}{}
+	e.content = append(e.content, &Content{
+		Kind:       kind,
+		PanelId:    panelID,
+		PanelTitle: panelTitle,
+		Text:       text,
+	})
+}
+
+func (e *contentExtractor) addPanel(panel *simplejson.Json) {
+	id := panel.Get("id").MustInt64()
+	title := panel.Get("title").MustString()
+
+	e.add(ContentPanelTitle, id, title, title)
+	e.add(ContentPanelDescription, id, title, panel.Get("description").MustString())
+	e.addDatasource(id, title, panel.Get("datasource"))
+	for _, t := range panel.Get("targets").MustArray() {
+		target := simplejson.NewFromAny(t)
+		e.addQuery(id, title, target)
+		e.addDatasource(id, title, target.Get("datasource"))
+	}
+
+	// Collapsed rows have their panels nested.
+	for _, nested := range panel.Get("panels").MustArray() {
+		e.addPanel(simplejson.NewFromAny(nested))
+	}
+}
+
+func (e *contentExtractor) addQuery(panelID int64, panelTitle string, target *simplejson.Json) {
+	for _, key := range queryKeys {
+		if s, err := target.Get(key).String(); err == nil {
+			e.add(ContentQuery, panelID, panelTitle, s)
+		}
+	}
+}
+
+// addDatasource adds a data source reference, which is a data source name or
+// an object with the uid of the data source.
+func (e *contentExtractor) addDatasource(panelID int64, panelTitle string, ref *simplejson.Json) {
+	if s, err := ref.String(); err == nil {
+		e.add(ContentDatasource, panelID, panelTitle, s)
+		return
+	}
+	e.add(ContentDatasource, panelID, panelTitle, ref.Get("uid").MustString())
+}
+
+// likeEscapeChar escapes wildcards of LIKE patterns, it isn't a backslash as
+// backslashes need escaping in SQL string literals of some databases.
+const likeEscapeChar = "!"
+
+var likeEscaper = strings.NewReplacer(
+	likeEscapeChar, likeEscapeChar+likeEscapeChar,
+	"%", likeEscapeChar+"%",
+	"_", likeEscapeChar+"_",
+)
+
+// ContentContains returns a condition and its parameter matching values of the
+// column containing the text. Wildcards in the text are matched literally.
+func ContentContains(dialect migrator.Dialect, column string, text string) (string, interface{}) {
+	return fmt.Sprintf("%s %s ? ESCAPE '%s'", column, dialect.LikeStr(), likeEscapeChar), "%" + likeEscaper.Replace(text) + "%"
+}
+
+// ContentFilter matches dashboards with stored content containing the
+// text. Kinds limits the search to some kinds of content. It's a substring
+// match scanning the content of all dashboards, there's no full-text index.
+type ContentFilter struct {
+	Dialect migrator.Dialect
+	Text    string
+	Kinds   []string
+}
+
+func (f ContentFilter) Where() (string, []interface{}) {
+	contains, param := ContentContains(f.Dialect, "dashboard_search_content.text", f.Text)
+	sql := `EXISTS (SELECT 1
+			FROM dashboard_search_content
+			WHERE dashboard_search_content.dashboard_id = dashboard.id AND ` + contains
+	params := []interface{}{param}
+
+	if len(f.Kinds) > 0 {
+		sql += ` AND dashboard_search_content.kind IN (?` + strings.Repeat(",?", len(f.Kinds)-1) + `)`
+		for _, kind := range f.Kinds {
+			params = append(params, kind)
+		}
+	}
+	return sql + ")", params
+}
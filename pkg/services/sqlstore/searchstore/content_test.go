@@ -0,0 +1,87 @@
+package searchstore
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractContent(t *testing.T) {
+	dashboard, err := simplejson.NewJson([]byte(`{
+		"panels": [
+			{
+				"id": 1,
+				"title": "Requests",
+				"description": "Checkout requests per second",
+				"datasource": "Prometheus",
+				"targets": [
+					{"refId": "A", "expr": "rate(http_requests_total[5m])"},
+					{"refId": "B", "expr": "rate(http_requests_total[5m])"}
+				]
+			},
+			{
+				"id": 2,
+				"type": "row",
+				"title": "Database",
+				"collapsed": true,
+				"panels": [
+					{
+						"id": 3,
+						"title": "Queries",
+						"datasource": {"uid": "mysql-uid", "type": "mysql"},
+						"targets": [{"refId": "A", "rawSql": "SELECT 1", "datasource": {"uid": "mysql-uid"}}]
+					}
+				]
+			}
+		],
+		"templating": {
+			"list": [
+				{"name": "instance", "datasource": "Prometheus", "query": "label_values(up, instance)"},
+				{"name": "env", "query": {"query": "SHOW TAG VALUES WITH KEY = env"}}
+			]
+		}
+	}`))
+	require.NoError(t, err)
+
+	content := ExtractContent(dashboard)
+	actual := make([]Content, 0, len(content))
+	for _, c := range content {
+		actual = append(actual, *c)
+	}
+
+	require.Equal(t, []Content{
+		{Kind: ContentPanelTitle, PanelId: 1, PanelTitle: "Requests", Text: "Requests"},
+		{Kind: ContentPanelDescription, PanelId: 1, PanelTitle: "Requests", Text: "Checkout requests per second"},
+		{Kind: ContentDatasource, PanelId: 1, PanelTitle: "Requests", Text: "Prometheus"},
+		{Kind: ContentQuery, PanelId: 1, PanelTitle: "Requests", Text: "rate(http_requests_total[5m])"},
+		{Kind: ContentPanelTitle, PanelId: 2, PanelTitle: "Database", Text: "Database"},
+		{Kind: ContentPanelTitle, PanelId: 3, PanelTitle: "Queries", Text: "Queries"},
+		{Kind: ContentDatasource, PanelId: 3, PanelTitle: "Queries", Text: "mysql-uid"},
+		{Kind: ContentQuery, PanelId: 3, PanelTitle: "Queries", Text: "SELECT 1"},
+		{Kind: ContentVariable, Text: "instance"},
+		{Kind: ContentDatasource, Text: "Prometheus"},
+		{Kind: ContentQuery, Text: "label_values(up, instance)"},
+		{Kind: ContentVariable, Text: "env"},
+		{Kind: ContentQuery, Text: "SHOW TAG VALUES WITH KEY = env"},
+	}, actual)
+}
+
+func TestExtractContent_Rows(t *testing.T) {
+	dashboard, err := simplejson.NewJson([]byte(`{
+		"rows": [{"panels": [{"id": 1, "title": "Old panel", "targets": [{"target": "stats.checkout.count"}]}]}]
+	}`))
+	require.NoError(t, err)
+
+	content := ExtractContent(dashboard)
+	require.Len(t, content, 2)
+	require.Equal(t, "stats.checkout.count", content[1].Text)
+}
+
+func TestContentContains(t *testing.T) {
+	dialect := migrator.NewSQLite3Dialect(nil)
+	sql, param := ContentContains(dialect, "text", "100% !_ done")
+	require.Equal(t, "text LIKE ? ESCAPE '!'", sql)
+	require.Equal(t, "%100!% !!!_ done%", param)
+}
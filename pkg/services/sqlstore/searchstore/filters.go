@@ -134,6 +134,35 @@ func (s TitleSorter) OrderBy() string {
 	return "dashboard.title ASC"
 }
 
+// ViewsSorter sorts dashboards by their recorded views, most viewed first.
+type ViewsSorter struct{}
+
+const dashboardViewsSQL = `(SELECT COALESCE(SUM(dashboard_view.views), 0) FROM dashboard_view WHERE dashboard_view.dashboard_id = dashboard.id)`
+
+func (s ViewsSorter) OrderBy() string {
+	return dashboardViewsSQL + " DESC, dashboard.title ASC"
+}
+
+func (s ViewsSorter) Select() string {
+	return dashboardViewsSQL + " AS sort_meta"
+}
+
+// LastViewedSorter sorts dashboards by the time they were last viewed.
+// Dashboards without recorded views are viewed least recently.
+type LastViewedSorter struct {
+	Descending bool
+}
+
+const dashboardLastViewedSQL = `(SELECT COALESCE(MAX(dashboard_view.last_viewed), 0) FROM dashboard_view WHERE dashboard_view.dashboard_id = dashboard.id)`
+
+func (s LastViewedSorter) OrderBy() string {
+	if s.Descending {
+		return dashboardLastViewedSQL + " DESC, dashboard.title ASC"
+	}
+
+	return dashboardLastViewedSQL + " ASC, dashboard.title ASC"
+}
+
 func sqlIDin(column string, ids []int64) (string, []interface{}) {
 	length := len(ids)
 	if length < 1 {
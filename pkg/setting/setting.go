@@ -95,8 +95,11 @@ var (
 	SnapShotRemoveExpired bool
 
 	// Dashboard history
-	DashboardVersionsToKeep int
-	MinRefreshInterval      string
+	DashboardVersionsToKeep    int
+	MinRefreshInterval         string
+	DashboardTrashRetention    time.Duration
+	DashboardViewRetention     time.Duration
+	LibraryPanelVersionsToKeep int
 
 	// User settings
 	AllowUserSignUp         bool
@@ -358,7 +361,9 @@ type Cfg struct {
 	Sentry Sentry
 
 	// Data sources
-	DataSourceLimit int
+	DataSourceLimit    int
+	SQLiteAllowedPaths []string
+	FileAllowedPaths   []string
 
 	// Snapshots
 	SnapshotPublicMode bool
@@ -401,9 +406,16 @@ type Cfg struct {
 	LiveHAEngine string
 	// LiveHAEngineAddress is a connection address for Live HA engine.
 	LiveHAEngineAddress string
+	// LiveHAEnginePassword is an optional password for Live HA engine.
+	LiveHAEnginePassword string
+	// LiveHAEngineDB is a database number for Live HA engine (Redis only).
+	LiveHAEngineDB int
 	// LiveAllowedOrigins is a set of origins accepted by Live. If not provided
 	// then Live uses AppURL as the only allowed origin.
 	LiveAllowedOrigins []string
+	// LivePublishLimits are rate limits applied to data published into Live
+	// channels over WebSocket and HTTP. Zero values mean no limit.
+	LivePublishLimits LivePublishLimits
 
 	// Grafana.com URL
 	GrafanaComURL string
@@ -422,6 +434,17 @@ type Cfg struct {
 	UnifiedAlerting UnifiedAlertingSettings
 }
 
+// LivePublishLimits contains Live publish rate limits in messages and bytes
+// per second for each organization, each channel and each connection.
+type LivePublishLimits struct {
+	OrgMessagesPerSecond        int
+	OrgBytesPerSecond           int
+	ChannelMessagesPerSecond    int
+	ChannelBytesPerSecond       int
+	ConnectionMessagesPerSecond int
+	ConnectionBytesPerSecond    int
+}
+
 // IsLiveConfigEnabled returns true if live should be able to save configs to SQL tables
 func (cfg Cfg) IsLiveConfigEnabled() bool {
 	return cfg.FeatureToggles["live-config"]
@@ -876,6 +899,17 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 	dashboards := iniFile.Section("dashboards")
 	DashboardVersionsToKeep = dashboards.Key("versions_to_keep").MustInt(20)
 	MinRefreshInterval = valueAsString(dashboards, "min_refresh_interval", "5s")
+	trashRetention, err := gtime.ParseDuration(valueAsString(dashboards, "trash_retention", "30d"))
+	if err != nil {
+		return err
+	}
+	DashboardTrashRetention = trashRetention
+	viewRetention, err := gtime.ParseDuration(valueAsString(dashboards, "view_retention", "90d"))
+	if err != nil {
+		return err
+	}
+	DashboardViewRetention = viewRetention
+	LibraryPanelVersionsToKeep = dashboards.Key("library_panel_versions_to_keep").MustInt(20)
 
 	cfg.DefaultHomeDashboardPath = dashboards.Key("default_home_dashboard_path").MustString("")
 
@@ -1488,6 +1522,8 @@ func (cfg *Cfg) GetContentDeliveryURL(prefix string) string {
 func (cfg *Cfg) readDataSourcesSettings() {
 	datasources := cfg.Raw.Section("datasources")
 	cfg.DataSourceLimit = datasources.Key("datasource_limit").MustInt(5000)
+	cfg.SQLiteAllowedPaths = util.SplitString(datasources.Key("sqlite_allowed_paths").MustString(""))
+	cfg.FileAllowedPaths = util.SplitString(datasources.Key("file_allowed_paths").MustString(""))
 }
 
 func GetAllowedOriginGlobs(originPatterns []string) ([]glob.Glob, error) {
@@ -1516,6 +1552,8 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 		return fmt.Errorf("unsupported live HA engine type: %s", cfg.LiveHAEngine)
 	}
 	cfg.LiveHAEngineAddress = section.Key("ha_engine_address").MustString("127.0.0.1:6379")
+	cfg.LiveHAEnginePassword = section.Key("ha_engine_password").MustString("")
+	cfg.LiveHAEngineDB = section.Key("ha_engine_db").MustInt(0)
 
 	var originPatterns []string
 	allowedOrigins := section.Key("allowed_origins").MustString("")
@@ -1531,5 +1569,14 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 		return err
 	}
 	cfg.LiveAllowedOrigins = originPatterns
+
+	cfg.LivePublishLimits = LivePublishLimits{
+		OrgMessagesPerSecond:        section.Key("publish_org_messages_per_second").MustInt(0),
+		OrgBytesPerSecond:           section.Key("publish_org_bytes_per_second").MustInt(0),
+		ChannelMessagesPerSecond:    section.Key("publish_channel_messages_per_second").MustInt(0),
+		ChannelBytesPerSecond:       section.Key("publish_channel_bytes_per_second").MustInt(0),
+		ConnectionMessagesPerSecond: section.Key("publish_connection_messages_per_second").MustInt(0),
+		ConnectionBytesPerSecond:    section.Key("publish_connection_bytes_per_second").MustInt(0),
+	}
 	return nil
 }
@@ -773,3 +773,56 @@ func TestRulerRulesFilterByDashboard(t *testing.T) {
 		require.JSONEq(t, `{"message":"panel_id must be set with dashboard_uid"}`, string(b))
 	}
 }
+
+func TestAlertRuleNestedNamespace(t *testing.T) {
+	// Setup Grafana and its Database
+	dir, path := testinfra.CreateGrafDir(t, testinfra.GrafanaOpts{
+		DisableLegacyAlerting: true,
+		EnableUnifiedAlerting: true,
+		DisableAnonymous:      true,
+	})
+
+	grafanaListedAddr, store := testinfra.StartGrafana(t, dir, path)
+	// override bus to get the GetSignedInUserQuery handler
+	store.Bus = bus.GetBus()
+
+	createUser(t, store, models.CreateUserCommand{
+		DefaultOrgRole: string(models.ROLE_EDITOR),
+		Password:       "password",
+		Login:          "grafana",
+	})
+
+	// Create parent/child and a folder in the General folder with the title of
+	// the nested folder.
+	parentUID, err := createFolder(t, store, 0, "parent")
+	require.NoError(t, err)
+	parent, err := store.GetDashboard(0, 1, parentUID, "")
+	require.NoError(t, err)
+	childUID, err := createFolder(t, store, parent.Id, "child")
+	require.NoError(t, err)
+	_, err = createFolder(t, store, 0, "child")
+	require.NoError(t, err)
+
+	// The namespace of the nested folder is its uid.
+	createRule(t, grafanaListedAddr, childUID, "grafana", "password")
+	createRule(t, grafanaListedAddr, "child", "grafana", "password")
+
+	u := fmt.Sprintf("http://grafana:password@%s/api/ruler/grafana/api/v1/rules", grafanaListedAddr)
+	resp := getRequest(t, u, http.StatusOK)
+	b, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	_, m := rulesNamespaceWithoutVariableValues(t, b)
+	require.Len(t, m, 2)
+	require.Len(t, m[childUID+",arulegroup"], 1)
+	require.Len(t, m["child,arulegroup"], 1)
+
+	t.Run("should get the rules of the nested namespace", func(t *testing.T) {
+		u := fmt.Sprintf("http://grafana:password@%s/api/ruler/grafana/api/v1/rules/%s/arulegroup", grafanaListedAddr, childUID)
+		resp := getRequest(t, u, http.StatusAccepted)
+		var group apimodels.GettableRuleGroupConfig
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&group))
+		require.Len(t, group.Rules, 1)
+		require.Equal(t, childUID, group.Rules[0].GrafanaManagedAlert.NamespaceUID)
+		require.Equal(t, m[childUID+",arulegroup"][0], group.Rules[0].GrafanaManagedAlert.UID)
+	})
+}
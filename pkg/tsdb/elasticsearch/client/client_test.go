@@ -315,6 +315,74 @@ func TestClient_ExecuteMultisearch(t *testing.T) {
 	})
 }
 
+func TestClient_ExecuteSQL(t *testing.T) {
+	version, err := semver.NewVersion("7.10.0")
+	require.NoError(t, err)
+	httpClientScenario(t, "Given a fake http client and a SQL request", &DatasourceInfo{
+		Database:  "metrics",
+		ESVersion: version,
+		TimeField: "@timestamp",
+	}, func(sc *scenarioContext) {
+		sc.responseBody = `{
+			"columns": [{ "name": "host", "type": "keyword" }, { "name": "value", "type": "double" }],
+			"rows": [["a", 1.5], ["b", null]]
+		}`
+
+		res, err := sc.client.ExecuteSQL(&SQLRequest{
+			Language:  SQLLanguageSQL,
+			Query:     "SELECT host, value FROM metrics",
+			FetchSize: 100,
+			Filter: &Query{Bool: &BoolQuery{Filters: []Filter{
+				&RangeFilter{Key: "@timestamp", Gte: "1000", Lte: "2000", Format: DateFormatEpochMS},
+			}}},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, http.MethodPost, sc.request.Method)
+		assert.Equal(t, "/_sql", sc.request.URL.Path)
+		assert.Equal(t, "format=json", sc.request.URL.RawQuery)
+		assert.Equal(t, "application/json", sc.request.Header.Get("Content-Type"))
+
+		jBody, err := simplejson.NewJson(sc.requestBody.Bytes())
+		require.NoError(t, err)
+		assert.Equal(t, "SELECT host, value FROM metrics", jBody.Get("query").MustString())
+		assert.Equal(t, 100, jBody.Get("fetch_size").MustInt())
+		assert.Equal(t, "1000", jBody.GetPath("filter", "bool", "filter", "range", "@timestamp", "gte").MustString())
+
+		assert.Equal(t, 200, res.Status)
+		require.Len(t, res.GetColumns(), 2)
+		require.Len(t, res.GetRows(), 2)
+	})
+
+	httpClientScenario(t, "Given a fake http client and a PPL request", &DatasourceInfo{
+		Database:  "metrics",
+		ESVersion: version,
+		TimeField: "@timestamp",
+	}, func(sc *scenarioContext) {
+		sc.responseBody = `{
+			"schema": [{ "name": "host", "type": "string" }],
+			"datarows": [["a"]],
+			"total": 1,
+			"size": 1
+		}`
+
+		res, err := sc.client.ExecuteSQL(&SQLRequest{
+			Language:  SQLLanguagePPL,
+			Query:     "source=metrics | fields host",
+			FetchSize: 100,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "/_plugins/_ppl", sc.request.URL.Path)
+		jBody, err := simplejson.NewJson(sc.requestBody.Bytes())
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"query": "source=metrics | fields host"}, jBody.MustMap())
+
+		assert.Equal(t, []SQLColumn{{Name: "host", Type: "string"}}, res.GetColumns())
+		assert.Equal(t, [][]interface{}{{"a"}}, res.GetRows())
+	})
+}
+
 func createMultisearchForTest(t *testing.T, c Client) (*MultiSearchRequest, error) {
 	t.Helper()
 
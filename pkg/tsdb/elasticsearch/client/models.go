@@ -74,6 +74,74 @@ type SearchResponse struct {
 	Hits         *SearchResponseHits    `json:"hits"`
 }
 
+// Query languages supported by SQLRequest
+const (
+	SQLLanguageSQL = "sql"
+	SQLLanguagePPL = "ppl"
+)
+
+// SQLRequest represents Elasticsearch SQL or OpenSearch PPL request
+type SQLRequest struct {
+	Language  string
+	Query     string
+	FetchSize int
+	Filter    *Query
+}
+
+// MarshalJSON returns the JSON encoding of the request. PPL requests support
+// the query only, filters have to be a part of the query.
+func (r *SQLRequest) MarshalJSON() ([]byte, error) {
+	root := map[string]interface{}{
+		"query": r.Query,
+	}
+	if r.Language != SQLLanguagePPL {
+		if r.FetchSize > 0 {
+			root["fetch_size"] = r.FetchSize
+		}
+		if r.Filter != nil {
+			root["filter"] = r.Filter
+		}
+		root["time_zone"] = "Z"
+	}
+	return json.Marshal(root)
+}
+
+// SQLColumn represents a column of SQL response
+type SQLColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// SQLResponse represents a response of Elasticsearch SQL, OpenSearch SQL or PPL
+// query. Elasticsearch returns columns and rows, OpenSearch returns schema and
+// datarows.
+type SQLResponse struct {
+	Status    int                    `json:"-"`
+	Error     map[string]interface{} `json:"error"`
+	Columns   []SQLColumn            `json:"columns"`
+	Rows      [][]interface{}        `json:"rows"`
+	Schema    []SQLColumn            `json:"schema"`
+	DataRows  [][]interface{}        `json:"datarows"`
+	Cursor    string                 `json:"cursor"`
+	DebugInfo *SearchDebugInfo       `json:"-"`
+}
+
+// GetColumns returns columns of the response regardless of its format.
+func (r *SQLResponse) GetColumns() []SQLColumn {
+	if len(r.Columns) > 0 {
+		return r.Columns
+	}
+	return r.Schema
+}
+
+// GetRows returns rows of the response regardless of its format.
+func (r *SQLResponse) GetRows() [][]interface{} {
+	if len(r.Columns) > 0 {
+		return r.Rows
+	}
+	return r.DataRows
+}
+
 // MultiSearchRequest represents a multi search request
 type MultiSearchRequest struct {
 	Requests []*SearchRequest
@@ -85,6 +85,20 @@ func (b *SearchRequestBuilder) SortDesc(field, unmappedType string) *SearchReque
 	return b
 }
 
+// SortBy sets an ordered list of sorts to the search request, replacing sorts added
+// with SortDesc. Use it when order of sorts matters, e.g. for search_after pagination.
+func (b *SearchRequestBuilder) SortBy(sorts ...map[string]interface{}) *SearchRequestBuilder {
+	b.sort = make(map[string]interface{})
+	b.customProps["sort"] = sorts
+	return b
+}
+
+// SearchAfter sets sort values of the last hit of a previous page to the search request
+func (b *SearchRequestBuilder) SearchAfter(values []interface{}) *SearchRequestBuilder {
+	b.customProps["search_after"] = values
+	return b
+}
+
 // AddDocValueField adds a doc value field to the search request
 func (b *SearchRequestBuilder) AddDocValueField(field string) *SearchRequestBuilder {
 	// fields field not supported on version >= 5
@@ -106,6 +106,31 @@ func TestSearchRequest(t *testing.T) {
 		})
 	})
 
+	t.Run("When adding ordered sorts and search after", func(t *testing.T) {
+		b := setup()
+		b.SortDesc(timeField, "boolean")
+		b.SortBy(
+			map[string]interface{}{timeField: map[string]string{"order": "desc"}},
+			map[string]interface{}{"_doc": map[string]string{"order": "desc"}},
+		)
+		b.SearchAfter([]interface{}{1000, 5})
+
+		sr, err := b.Build()
+		require.Nil(t, err)
+		require.Empty(t, sr.Sort)
+
+		body, err := json.Marshal(sr)
+		require.Nil(t, err)
+		json, err := simplejson.NewJson(body)
+		require.Nil(t, err)
+		sort := json.Get("sort").MustArray()
+		require.Len(t, sort, 2)
+		require.Equal(t, "desc", json.Get("sort").GetIndex(0).GetPath(timeField, "order").MustString())
+		require.Equal(t, "desc", json.Get("sort").GetIndex(1).GetPath("_doc", "order").MustString())
+		require.Equal(t, 1000, json.Get("search_after").GetIndex(0).MustInt())
+		require.Equal(t, 5, json.Get("search_after").GetIndex(1).MustInt())
+	})
+
 	t.Run("When adding doc value field", func(t *testing.T) {
 		b := setup()
 		b.AddDocValueField(timeField)
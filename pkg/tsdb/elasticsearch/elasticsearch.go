@@ -68,8 +68,29 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		return &backend.QueryDataResponse{}, err
 	}
 
-	query := newTimeSeriesQuery(client, req.Queries, s.intervalCalculator)
-	return query.execute()
+	var sqlQueries, tsQueries []backend.DataQuery
+	for _, q := range req.Queries {
+		if isSQLQuery(q) {
+			sqlQueries = append(sqlQueries, q)
+		} else {
+			tsQueries = append(tsQueries, q)
+		}
+	}
+
+	result := &backend.QueryDataResponse{Responses: backend.Responses{}}
+	if len(tsQueries) > 0 {
+		query := newTimeSeriesQuery(client, tsQueries, s.intervalCalculator)
+		result, err = query.execute()
+		if err != nil {
+			return result, err
+		}
+	}
+	if len(sqlQueries) > 0 {
+		for refID, res := range newSQLQuery(client, sqlQueries).execute().Responses {
+			result.Responses[refID] = res
+		}
+	}
+	return result, nil
 }
 
 func newInstanceSettings() datasource.InstanceFactoryFunc {
@@ -129,6 +150,16 @@ func newInstanceSettings() datasource.InstanceFactoryFunc {
 			xpack = false
 		}
 
+		logMessageField, ok := jsonData["logMessageField"].(string)
+		if !ok {
+			logMessageField = ""
+		}
+
+		logLevelField, ok := jsonData["logLevelField"].(string)
+		if !ok {
+			logLevelField = ""
+		}
+
 		model := es.DatasourceInfo{
 			ID:                         settings.ID,
 			URL:                        settings.URL,
@@ -141,6 +172,8 @@ func newInstanceSettings() datasource.InstanceFactoryFunc {
 			TimeInterval:               timeInterval,
 			IncludeFrozen:              includeFrozen,
 			XPack:                      xpack,
+			LogMessageField:            logMessageField,
+			LogLevelField:              logLevelField,
 		}
 		return model, nil
 	}
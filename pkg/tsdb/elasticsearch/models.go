@@ -15,6 +15,12 @@ type Query struct {
 	IntervalMs    int64
 	RefID         string
 	MaxDataPoints int64
+	SearchAfter   []interface{}
+}
+
+// isLogsQuery returns true for queries returning log lines instead of aggregations.
+func (q *Query) isLogsQuery() bool {
+	return len(q.Metrics) > 0 && q.Metrics[0].Type == logsType
 }
 
 // BucketAgg represents a bucket aggregation of the time series query model of the datasource
@@ -54,6 +60,7 @@ var metricAggType = map[string]string{
 	"serial_diff":    "Serial Difference",
 	"bucket_script":  "Bucket Script",
 	"raw_document":   "Raw Document",
+	"logs":           "Logs",
 	"rate":           "Rate",
 }
 
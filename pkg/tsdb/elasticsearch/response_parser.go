@@ -1,6 +1,7 @@
 package elasticsearch
 
 import (
+	"encoding/json"
 	"errors"
 	"regexp"
 	"sort"
@@ -20,6 +21,9 @@ const (
 	percentilesType   = "percentiles"
 	extendedStatsType = "extended_stats"
 	topMetricsType    = "top_metrics"
+	logsType          = "logs"
+	// defaultLogsLimit is a default number of log lines returned by logs query
+	defaultLogsLimit = 500
 	// Bucket types
 	dateHistType    = "date_histogram"
 	histogramType   = "histogram"
@@ -29,16 +33,19 @@ const (
 )
 
 type responseParser struct {
-	Responses []*es.SearchResponse
-	Targets   []*Query
-	DebugInfo *es.SearchDebugInfo
+	Responses        []*es.SearchResponse
+	Targets          []*Query
+	DebugInfo        *es.SearchDebugInfo
+	ConfiguredFields es.ConfiguredFields
 }
 
-var newResponseParser = func(responses []*es.SearchResponse, targets []*Query, debugInfo *es.SearchDebugInfo) *responseParser {
+var newResponseParser = func(responses []*es.SearchResponse, targets []*Query, debugInfo *es.SearchDebugInfo,
+	configuredFields es.ConfiguredFields) *responseParser {
 	return &responseParser{
-		Responses: responses,
-		Targets:   targets,
-		DebugInfo: debugInfo,
+		Responses:        responses,
+		Targets:          targets,
+		DebugInfo:        debugInfo,
+		ConfiguredFields: configuredFields,
 	}
 }
 
@@ -73,6 +80,11 @@ func (rp *responseParser) getTimeSeries() (*backend.QueryDataResponse, error) {
 			continue
 		}
 
+		if target.isLogsQuery() {
+			result.Responses[target.RefID] = rp.processLogs(res, target, debugInfo)
+			continue
+		}
+
 		queryRes := backend.DataResponse{}
 
 		props := make(map[string]string)
@@ -726,3 +738,197 @@ func getErrorFromElasticResponse(response *es.SearchResponse) string {
 
 	return errorString
 }
+
+// processLogs converts hits of a logs query to a logs frame. The frame has time,
+// message and level fields first, followed by flattened document fields.
+func (rp *responseParser) processLogs(res *es.SearchResponse, target *Query, debugInfo *simplejson.Json) backend.DataResponse {
+	var hits []map[string]interface{}
+	if res.Hits != nil {
+		hits = res.Hits.Hits
+	}
+
+	timeField := rp.ConfiguredFields.TimeField
+	messageField := rp.ConfiguredFields.LogMessageField
+	levelField := rp.ConfiguredFields.LogLevelField
+
+	times := make([]time.Time, 0, len(hits))
+	docs := make([]map[string]interface{}, 0, len(hits))
+	sources := make([]*string, 0, len(hits))
+	for _, hit := range hits {
+		doc := map[string]interface{}{}
+		source, _ := hit["_source"].(map[string]interface{})
+		flattenDocument(doc, "", source)
+		if fields, ok := hit["fields"].(map[string]interface{}); ok {
+			for k, v := range fields {
+				if values, ok := v.([]interface{}); ok && len(values) == 1 {
+					v = values[0]
+				}
+				doc[k] = v
+			}
+		}
+		for _, k := range []string{"_id", "_index", "_type"} {
+			if v, ok := hit[k]; ok {
+				doc[k] = v
+			}
+		}
+
+		times = append(times, getLogTime(hit, doc[timeField]))
+		delete(doc, timeField)
+		docs = append(docs, doc)
+
+		if messageField == "" {
+			sourceJSON, err := json.Marshal(source)
+			if err != nil {
+				return backend.DataResponse{Error: err}
+			}
+			s := string(sourceJSON)
+			sources = append(sources, &s)
+		}
+	}
+
+	fields := data.Fields{data.NewField(timeField, nil, times)}
+	if messageField != "" {
+		fields = append(fields, newLogField(messageField, docs, messageField, true))
+	} else {
+		fields = append(fields, data.NewField("_source", nil, sources))
+	}
+	if levelField != "" {
+		fields = append(fields, newLogField("level", docs, levelField, true))
+	}
+
+	keys := map[string]struct{}{}
+	for _, doc := range docs {
+		for k := range doc {
+			if k != messageField && k != levelField {
+				keys[k] = struct{}{}
+			}
+		}
+	}
+	sortedKeys := make([]string, 0, len(keys))
+	for k := range keys {
+		sortedKeys = append(sortedKeys, k)
+	}
+	sort.Strings(sortedKeys)
+	for _, k := range sortedKeys {
+		fields = append(fields, newLogField(k, docs, k, false))
+	}
+
+	custom := map[string]interface{}{}
+	if len(hits) > 0 {
+		if sortValues, ok := hits[len(hits)-1]["sort"].([]interface{}); ok {
+			custom["searchAfter"] = sortValues
+		}
+	}
+	if debugInfo != nil {
+		custom["debug"] = debugInfo
+	}
+
+	frame := data.NewFrame("", fields...)
+	frame.RefID = target.RefID
+	frame.Meta = &data.FrameMeta{
+		PreferredVisualization: data.VisTypeLogs,
+		Custom:                 custom,
+	}
+	return backend.DataResponse{Frames: data.Frames{frame}}
+}
+
+// flattenDocument adds nested object properties of a document to target with dot separated keys.
+func flattenDocument(target map[string]interface{}, prefix string, doc map[string]interface{}) {
+	for k, v := range doc {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if nested, ok := v.(map[string]interface{}); ok {
+			flattenDocument(target, key, nested)
+			continue
+		}
+		target[key] = v
+	}
+}
+
+// getLogTime returns time of a document. Sort value is preferred since it's always
+// epoch milliseconds, while the time field format depends on the index mapping.
+func getLogTime(hit map[string]interface{}, timeValue interface{}) time.Time {
+	if sortValues, ok := hit["sort"].([]interface{}); ok && len(sortValues) > 0 {
+		if ms, ok := sortValues[0].(float64); ok {
+			return time.Unix(0, int64(ms)*int64(time.Millisecond)).UTC()
+		}
+	}
+	switch v := timeValue.(type) {
+	case float64:
+		return time.Unix(0, int64(v)*int64(time.Millisecond)).UTC()
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t.UTC()
+		}
+		if ms, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return time.Unix(0, ms*int64(time.Millisecond)).UTC()
+		}
+	}
+	return time.Time{}
+}
+
+// newLogField creates a field with values of key of all documents. The field type
+// is number or boolean if all values have the type, otherwise values are converted
+// to strings.
+func newLogField(name string, docs []map[string]interface{}, key string, asString bool) *data.Field {
+	isFloat, isBool := !asString, !asString
+	for _, doc := range docs {
+		switch doc[key].(type) {
+		case nil:
+		case float64:
+			isBool = false
+		case bool:
+			isFloat = false
+		default:
+			isFloat, isBool = false, false
+		}
+	}
+
+	switch {
+	case isFloat && !isBool:
+		values := make([]*float64, len(docs))
+		for i, doc := range docs {
+			if v, ok := doc[key].(float64); ok {
+				values[i] = &v
+			}
+		}
+		return data.NewField(name, nil, values)
+	case isBool && !isFloat:
+		values := make([]*bool, len(docs))
+		for i, doc := range docs {
+			if v, ok := doc[key].(bool); ok {
+				values[i] = &v
+			}
+		}
+		return data.NewField(name, nil, values)
+	default:
+		values := make([]*string, len(docs))
+		for i, doc := range docs {
+			if s, ok := logValueToString(doc[key]); ok {
+				values[i] = &s
+			}
+		}
+		return data.NewField(name, nil, values)
+	}
+}
+
+func logValueToString(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case nil:
+		return "", false
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", false
+		}
+		return string(b), true
+	}
+}
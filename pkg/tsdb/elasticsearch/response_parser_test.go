@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -1061,6 +1062,88 @@ func TestResponseParser(t *testing.T) {
 	})
 }
 
+func TestResponseParser_Logs(t *testing.T) {
+	targets := map[string]string{
+		"A": `{
+			"timeField": "@timestamp",
+			"metrics": [{ "type": "logs", "id": "1" }]
+		}`,
+	}
+	response := `{
+		"responses": [
+			{
+				"hits": {
+					"hits": [
+						{
+							"_id": "doc-2",
+							"_index": "logs-2018.05.15",
+							"_source": {
+								"@timestamp": "2018-05-15T17:52:00.000Z",
+								"line": "request failed",
+								"lvl": "error",
+								"status": 500,
+								"http": { "method": "GET", "secure": true },
+								"tags": ["a", "b"]
+							},
+							"sort": [1526406720000, 2]
+						},
+						{
+							"_id": "doc-1",
+							"_index": "logs-2018.05.15",
+							"_source": {
+								"@timestamp": "2018-05-15T17:51:00.000Z",
+								"line": "request served",
+								"lvl": "info",
+								"status": "ok",
+								"http": { "method": "POST" }
+							},
+							"sort": [1526406660000, 1]
+						}
+					]
+				}
+			}
+		]
+	}`
+	rp, err := newResponseParserForTest(targets, response)
+	require.NoError(t, err)
+	result, err := rp.getTimeSeries()
+	require.NoError(t, err)
+
+	queryRes := result.Responses["A"]
+	require.NoError(t, queryRes.Error)
+	require.Len(t, queryRes.Frames, 1)
+	frame := queryRes.Frames[0]
+	require.Equal(t, "A", frame.RefID)
+	require.Equal(t, data.VisTypeLogs, string(frame.Meta.PreferredVisualization))
+	require.Equal(t, map[string]interface{}{"searchAfter": []interface{}{1526406660000., 1.}}, frame.Meta.Custom)
+	require.Equal(t, 2, frame.Rows())
+
+	names := make([]string, 0, len(frame.Fields))
+	for _, f := range frame.Fields {
+		names = append(names, f.Name)
+	}
+	require.Equal(t, []string{"@timestamp", "line", "level", "_id", "_index", "http.method", "http.secure", "status", "tags"}, names)
+
+	require.Equal(t, time.Date(2018, 5, 15, 17, 52, 0, 0, time.UTC), frame.Fields[0].At(0))
+	require.Equal(t, "request failed", *frame.Fields[1].At(0).(*string))
+	require.Equal(t, "error", *frame.Fields[2].At(0).(*string))
+	require.Equal(t, "info", *frame.Fields[2].At(1).(*string))
+
+	httpSecure := frame.Fields[6]
+	require.Equal(t, data.FieldTypeNullableBool, httpSecure.Type())
+	require.True(t, *httpSecure.At(0).(*bool))
+	require.Nil(t, httpSecure.At(1))
+
+	// Values of different types are converted to strings.
+	status := frame.Fields[7]
+	require.Equal(t, data.FieldTypeNullableString, status.Type())
+	require.Equal(t, "500", *status.At(0).(*string))
+	require.Equal(t, "ok", *status.At(1).(*string))
+
+	tags := frame.Fields[8]
+	require.Equal(t, `["a","b"]`, *tags.At(0).(*string))
+}
+
 func newResponseParserForTest(tsdbQueries map[string]string, responseBody string) (*responseParser, error) {
 	from := time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC)
 	to := time.Date(2018, 5, 15, 17, 55, 0, 0, time.UTC)
@@ -1092,5 +1175,9 @@ func newResponseParserForTest(tsdbQueries map[string]string, responseBody string
 		return nil, err
 	}
 
-	return newResponseParser(response.Responses, queries, nil), nil
+	return newResponseParser(response.Responses, queries, nil, es.ConfiguredFields{
+		TimeField:       "@timestamp",
+		LogMessageField: "line",
+		LogLevelField:   "lvl",
+	}), nil
 }
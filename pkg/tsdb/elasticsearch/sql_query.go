@@ -0,0 +1,270 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+const (
+	// Query types of raw SQL and PPL queries
+	sqlQueryType = "sql"
+	pplQueryType = "ppl"
+
+	defaultSQLLimit = 1000
+	maxSQLLimit     = 10000
+)
+
+// sqlTimeLayouts are formats of date and time values returned by Elasticsearch
+// SQL and OpenSearch SQL and PPL.
+var sqlTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+func isSQLQuery(q backend.DataQuery) bool {
+	return q.QueryType == sqlQueryType || q.QueryType == pplQueryType
+}
+
+type sqlQuery struct {
+	client      es.Client
+	dataQueries []backend.DataQuery
+}
+
+var newSQLQuery = func(client es.Client, dataQueries []backend.DataQuery) *sqlQuery {
+	return &sqlQuery{
+		client:      client,
+		dataQueries: dataQueries,
+	}
+}
+
+// execute runs raw SQL and PPL queries one by one. Errors are returned
+// per query, so a failing query doesn't fail other queries of the request.
+func (e *sqlQuery) execute() *backend.QueryDataResponse {
+	result := &backend.QueryDataResponse{
+		Responses: backend.Responses{},
+	}
+	for _, q := range e.dataQueries {
+		result.Responses[q.RefID] = e.executeQuery(q)
+	}
+	return result
+}
+
+func (e *sqlQuery) executeQuery(q backend.DataQuery) backend.DataResponse {
+	model, err := simplejson.NewJson(q.JSON)
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+	rawSQL := strings.TrimSpace(model.Get("rawSql").MustString())
+	if rawSQL == "" {
+		return backend.DataResponse{Error: errors.New("query is empty")}
+	}
+	limit := model.Get("limit").MustInt(defaultSQLLimit)
+	if limit <= 0 || limit > maxSQLLimit {
+		limit = maxSQLLimit
+	}
+
+	req := &es.SQLRequest{
+		Language:  es.SQLLanguageSQL,
+		Query:     interpolateSQLMacros(rawSQL, q.TimeRange, q.QueryType),
+		FetchSize: limit,
+	}
+	if q.QueryType == pplQueryType {
+		req.Language = es.SQLLanguagePPL
+	} else {
+		// Elasticsearch SQL accepts Query DSL filter, so the time range doesn't
+		// have to be a part of the query.
+		from := strconv.FormatInt(q.TimeRange.From.UnixNano()/int64(time.Millisecond), 10)
+		to := strconv.FormatInt(q.TimeRange.To.UnixNano()/int64(time.Millisecond), 10)
+		filter := &es.Query{Bool: &es.BoolQuery{}}
+		filter.Bool.Filters = append(filter.Bool.Filters, &es.RangeFilter{
+			Key:    e.client.GetTimeField(),
+			Gte:    from,
+			Lte:    to,
+			Format: es.DateFormatEpochMS,
+		})
+		req.Filter = filter
+	}
+
+	res, err := e.client.ExecuteSQL(req)
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+	if res.Error != nil {
+		return backend.DataResponse{Error: errors.New(getSQLErrorMessage(res.Error))}
+	}
+
+	frame, err := sqlResponseToFrame(res, limit)
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+	frame.RefID = q.RefID
+	frame.Meta = &data.FrameMeta{ExecutedQueryString: req.Query}
+	if res.Cursor != "" || frame.Rows() >= limit {
+		frame.AppendNotices(data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text:     fmt.Sprintf("Results have been limited to %d rows", limit),
+		})
+	}
+	if res.DebugInfo != nil {
+		frame.Meta.Custom = res.DebugInfo
+	}
+	return backend.DataResponse{Frames: data.Frames{frame}}
+}
+
+// interpolateSQLMacros replaces $__timeFrom() and $__timeTo() macros with time
+// literals, PPL doesn't accept RFC3339 times.
+func interpolateSQLMacros(rawSQL string, timeRange backend.TimeRange, queryType string) string {
+	layout := "'2006-01-02T15:04:05Z'"
+	if queryType == pplQueryType {
+		layout = "'2006-01-02 15:04:05'"
+	}
+	return strings.NewReplacer(
+		"$__timeFrom()", timeRange.From.UTC().Format(layout),
+		"$__timeTo()", timeRange.To.UTC().Format(layout),
+	).Replace(rawSQL)
+}
+
+func getSQLErrorMessage(esErr map[string]interface{}) string {
+	if rootCauses, ok := esErr["root_cause"].([]interface{}); ok && len(rootCauses) > 0 {
+		if rootCause, ok := rootCauses[0].(map[string]interface{}); ok {
+			if reason, ok := rootCause["reason"].(string); ok && reason != "" {
+				return reason
+			}
+		}
+	}
+	reason, _ := esErr["reason"].(string)
+	details, _ := esErr["details"].(string)
+	switch {
+	case reason != "" && details != "":
+		return reason + ": " + details
+	case reason != "":
+		return reason
+	case details != "":
+		return details
+	}
+	return "Unknown elasticsearch error response"
+}
+
+// sqlResponseToFrame converts columnar SQL response to a table frame with a
+// field per column. Field types are based on column types.
+func sqlResponseToFrame(res *es.SQLResponse, limit int) (*data.Frame, error) {
+	columns := res.GetColumns()
+	rows := res.GetRows()
+	if len(rows) > limit {
+		rows = rows[:limit]
+	}
+
+	frame := data.NewFrame("")
+	for i, col := range columns {
+		field, err := newSQLField(col, rows, i)
+		if err != nil {
+			return nil, err
+		}
+		frame.Fields = append(frame.Fields, field)
+	}
+	return frame, nil
+}
+
+func newSQLField(col es.SQLColumn, rows [][]interface{}, idx int) (*data.Field, error) {
+	value := func(row []interface{}) interface{} {
+		if idx < len(row) {
+			return row[idx]
+		}
+		return nil
+	}
+
+	switch strings.ToLower(col.Type) {
+	case "byte", "short", "integer", "long", "unsigned_long":
+		values := make([]*int64, len(rows))
+		for i, row := range rows {
+			switch v := value(row).(type) {
+			case nil:
+			case float64:
+				n := int64(v)
+				values[i] = &n
+			default:
+				return nil, fmt.Errorf("unexpected value %v of column %q with type %s", v, col.Name, col.Type)
+			}
+		}
+		return data.NewField(col.Name, nil, values), nil
+	case "double", "float", "half_float", "scaled_float":
+		values := make([]*float64, len(rows))
+		for i, row := range rows {
+			switch v := value(row).(type) {
+			case nil:
+			case float64:
+				values[i] = &v
+			default:
+				return nil, fmt.Errorf("unexpected value %v of column %q with type %s", v, col.Name, col.Type)
+			}
+		}
+		return data.NewField(col.Name, nil, values), nil
+	case "boolean":
+		values := make([]*bool, len(rows))
+		for i, row := range rows {
+			switch v := value(row).(type) {
+			case nil:
+			case bool:
+				values[i] = &v
+			default:
+				return nil, fmt.Errorf("unexpected value %v of column %q with type %s", v, col.Name, col.Type)
+			}
+		}
+		return data.NewField(col.Name, nil, values), nil
+	case "datetime", "date", "timestamp":
+		values := make([]*time.Time, len(rows))
+		for i, row := range rows {
+			v := value(row)
+			if v == nil {
+				continue
+			}
+			t, err := parseSQLTime(v)
+			if err != nil {
+				return nil, fmt.Errorf("column %q: %w", col.Name, err)
+			}
+			values[i] = &t
+		}
+		return data.NewField(col.Name, nil, values), nil
+	default:
+		values := make([]*string, len(rows))
+		for i, row := range rows {
+			switch v := value(row).(type) {
+			case nil:
+			case string:
+				values[i] = &v
+			default:
+				b, err := json.Marshal(v)
+				if err != nil {
+					return nil, err
+				}
+				s := string(b)
+				values[i] = &s
+			}
+		}
+		return data.NewField(col.Name, nil, values), nil
+	}
+}
+
+func parseSQLTime(v interface{}) (time.Time, error) {
+	switch v := v.(type) {
+	case float64:
+		return time.Unix(0, int64(v)*int64(time.Millisecond)).UTC(), nil
+	case string:
+		for _, layout := range sqlTimeLayouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t.UTC(), nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot parse time value %v", v)
+}
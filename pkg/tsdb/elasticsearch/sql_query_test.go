@@ -0,0 +1,148 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteSQLQuery(t *testing.T) {
+	from := time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC)
+	to := time.Date(2018, 5, 15, 17, 55, 0, 0, time.UTC)
+
+	executeSQLQuery := func(c es.Client, queryType, body string) backend.DataResponse {
+		t.Helper()
+		res := newSQLQuery(c, []backend.DataQuery{
+			{
+				RefID:     "A",
+				QueryType: queryType,
+				JSON:      json.RawMessage(body),
+				TimeRange: backend.TimeRange{From: from, To: to},
+			},
+		}).execute()
+		return res.Responses["A"]
+	}
+
+	t.Run("SQL query", func(t *testing.T) {
+		c := newFakeClient("7.10.0")
+		c.sqlResponse = &es.SQLResponse{
+			Columns: []es.SQLColumn{
+				{Name: "@timestamp", Type: "datetime"},
+				{Name: "host", Type: "keyword"},
+				{Name: "count", Type: "long"},
+				{Name: "avg", Type: "double"},
+				{Name: "up", Type: "boolean"},
+				{Name: "location", Type: "geo_point"},
+			},
+			Rows: [][]interface{}{
+				{"2018-05-15T17:51:00.000Z", "a", 10.0, 1.5, true, map[string]interface{}{"lat": 1.0}},
+				{"2018-05-15T17:52:00.000Z", nil, nil, nil, nil, nil},
+			},
+		}
+
+		res := executeSQLQuery(c, sqlQueryType, `{"rawSql": "SELECT * FROM logs WHERE \"@timestamp\" > $__timeFrom()"}`)
+		require.NoError(t, res.Error)
+
+		require.Len(t, c.sqlRequests, 1)
+		req := c.sqlRequests[0]
+		require.Equal(t, es.SQLLanguageSQL, req.Language)
+		require.Equal(t, `SELECT * FROM logs WHERE "@timestamp" > '2018-05-15T17:50:00Z'`, req.Query)
+		require.Equal(t, defaultSQLLimit, req.FetchSize)
+		rangeFilter := req.Filter.Bool.Filters[0].(*es.RangeFilter)
+		require.Equal(t, "@timestamp", rangeFilter.Key)
+		require.Equal(t, "1526406600000", rangeFilter.Gte)
+		require.Equal(t, "1526406900000", rangeFilter.Lte)
+
+		require.Len(t, res.Frames, 1)
+		frame := res.Frames[0]
+		require.Equal(t, "A", frame.RefID)
+		require.Equal(t, req.Query, frame.Meta.ExecutedQueryString)
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, data.FieldTypeNullableTime, frame.Fields[0].Type())
+		require.Equal(t, time.Date(2018, 5, 15, 17, 51, 0, 0, time.UTC), *frame.Fields[0].At(0).(*time.Time))
+		require.Equal(t, data.FieldTypeNullableString, frame.Fields[1].Type())
+		require.Equal(t, data.FieldTypeNullableInt64, frame.Fields[2].Type())
+		require.Equal(t, int64(10), *frame.Fields[2].At(0).(*int64))
+		require.Equal(t, data.FieldTypeNullableFloat64, frame.Fields[3].Type())
+		require.Equal(t, data.FieldTypeNullableBool, frame.Fields[4].Type())
+		require.Equal(t, `{"lat":1}`, *frame.Fields[5].At(0).(*string))
+		for _, f := range frame.Fields[1:] {
+			require.Nil(t, f.At(1))
+		}
+	})
+
+	t.Run("PPL query", func(t *testing.T) {
+		c := newFakeClient("7.10.0")
+		c.sqlResponse = &es.SQLResponse{
+			Schema: []es.SQLColumn{
+				{Name: "@timestamp", Type: "timestamp"},
+				{Name: "count()", Type: "integer"},
+			},
+			DataRows: [][]interface{}{
+				{"2018-05-15 17:51:00", 3.0},
+			},
+		}
+
+		res := executeSQLQuery(c, pplQueryType, `{"rawSql": "source=logs | where @timestamp >= $__timeFrom() | stats count()", "limit": 50}`)
+		require.NoError(t, res.Error)
+
+		req := c.sqlRequests[0]
+		require.Equal(t, es.SQLLanguagePPL, req.Language)
+		require.Equal(t, "source=logs | where @timestamp >= '2018-05-15 17:50:00' | stats count()", req.Query)
+		require.Nil(t, req.Filter)
+
+		frame := res.Frames[0]
+		require.Equal(t, time.Date(2018, 5, 15, 17, 51, 0, 0, time.UTC), *frame.Fields[0].At(0).(*time.Time))
+		require.Equal(t, int64(3), *frame.Fields[1].At(0).(*int64))
+	})
+
+	t.Run("Truncated results", func(t *testing.T) {
+		c := newFakeClient("7.10.0")
+		c.sqlResponse = &es.SQLResponse{
+			Columns: []es.SQLColumn{{Name: "host", Type: "keyword"}},
+			Rows:    [][]interface{}{{"a"}, {"b"}},
+			Cursor:  "abc",
+		}
+
+		res := executeSQLQuery(c, sqlQueryType, `{"rawSql": "SELECT host FROM logs", "limit": 2}`)
+		require.NoError(t, res.Error)
+		require.Equal(t, 2, c.sqlRequests[0].FetchSize)
+		require.Len(t, res.Frames[0].Meta.Notices, 1)
+	})
+
+	t.Run("Error response", func(t *testing.T) {
+		c := newFakeClient("7.10.0")
+		c.sqlResponse = &es.SQLResponse{
+			Error: map[string]interface{}{
+				"root_cause": []interface{}{
+					map[string]interface{}{"reason": "Unknown index [missing]"},
+				},
+				"reason": "Found 1 problem",
+			},
+		}
+
+		res := executeSQLQuery(c, sqlQueryType, `{"rawSql": "SELECT * FROM missing"}`)
+		require.EqualError(t, res.Error, "Unknown index [missing]")
+	})
+
+	t.Run("Request error", func(t *testing.T) {
+		c := newFakeClient("7.10.0")
+		c.sqlError = errors.New("connection refused")
+
+		res := executeSQLQuery(c, sqlQueryType, `{"rawSql": "SELECT * FROM logs"}`)
+		require.EqualError(t, res.Error, "connection refused")
+	})
+
+	t.Run("Empty query", func(t *testing.T) {
+		c := newFakeClient("7.10.0")
+		res := executeSQLQuery(c, sqlQueryType, `{"rawSql": " "}`)
+		require.Error(t, res.Error)
+		require.Empty(t, c.sqlRequests)
+	})
+}
@@ -59,10 +59,36 @@ func (e *timeSeriesQuery) execute() (*backend.QueryDataResponse, error) {
 		return &backend.QueryDataResponse{}, err
 	}
 
-	rp := newResponseParser(res.Responses, queries, res.DebugInfo)
+	rp := newResponseParser(res.Responses, queries, res.DebugInfo, e.client.GetConfiguredFields())
 	return rp.getTimeSeries()
 }
 
+// processLogsQuery requests the newest documents, bucket aggregations are ignored.
+// Documents are sorted by time and index order, so the next page can be requested
+// using search_after with sort values of the last document.
+func processLogsQuery(q *Query, b *es.SearchRequestBuilder, timeField string) {
+	b.Size(getLogsLimit(q.Metrics[0]))
+	b.SortBy(
+		map[string]interface{}{timeField: map[string]string{"order": "desc", "unmapped_type": "boolean"}},
+		map[string]interface{}{"_doc": map[string]string{"order": "desc"}},
+	)
+	if len(q.SearchAfter) > 0 {
+		b.SearchAfter(q.SearchAfter)
+	}
+}
+
+// getLogsLimit returns limit of log lines, the frontend stores it as a string.
+func getLogsLimit(m *MetricAgg) int {
+	limit := m.Settings.Get("limit")
+	if v, err := limit.Int(); err == nil && v > 0 {
+		return v
+	}
+	if v, err := strconv.Atoi(limit.MustString()); err == nil && v > 0 {
+		return v
+	}
+	return defaultLogsLimit
+}
+
 // nolint:staticcheck
 func (e *timeSeriesQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilder, from, to string,
 	result backend.QueryDataResponse) error {
@@ -81,6 +107,11 @@ func (e *timeSeriesQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilde
 		filters.AddQueryStringFilter(q.RawQuery, true)
 	}
 
+	if q.isLogsQuery() {
+		processLogsQuery(q, b, e.client.GetTimeField())
+		return nil
+	}
+
 	if len(q.BucketAggs) == 0 {
 		if len(q.Metrics) == 0 || q.Metrics[0].Type != "raw_document" {
 			result.Responses[q.RefID] = backend.DataResponse{
@@ -398,6 +429,7 @@ func (p *timeSeriesQueryParser) parse(tsdbQuery []backend.DataQuery) ([]*Query,
 		}
 		alias := model.Get("alias").MustString("")
 		interval := model.Get("interval").MustString("")
+		searchAfter := model.Get("searchAfter").MustArray()
 
 		queries = append(queries, &Query{
 			TimeField:     timeField,
@@ -408,6 +440,7 @@ func (p *timeSeriesQueryParser) parse(tsdbQuery []backend.DataQuery) ([]*Query,
 			Interval:      interval,
 			RefID:         q.RefID,
 			MaxDataPoints: q.MaxDataPoints,
+			SearchAfter:   searchAfter,
 		})
 	}
 
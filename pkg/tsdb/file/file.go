@@ -0,0 +1,273 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/infra/fs"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin/coreplugin"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var logger = log.New("tsdb.file")
+
+const (
+	sourceLocal = "local"
+	sourceURL   = "url"
+
+	// maxFileSize is the size limit of files, the data source is meant for
+	// small reference datasets.
+	maxFileSize = 20 * 1024 * 1024
+)
+
+var (
+	errNoAllowedPaths = errors.New("no allowed paths configured for File data source, see file_allowed_paths setting")
+	errURLNotAllowed  = errors.New("URL is outside of the data source URL")
+)
+
+type Service struct {
+	im  instancemgmt.InstanceManager
+	cfg *setting.Cfg
+}
+
+type datasourceInfo struct {
+	Source string `json:"source"`
+
+	baseURL    *url.URL
+	httpClient *http.Client
+}
+
+type queryModel struct {
+	// Path is a file path relative to an allowed directory, or a URL relative
+	// to the data source URL.
+	Path   string `json:"path"`
+	Format string `json:"format"`
+	// RootSelector is a JSONPath selecting records of JSON documents.
+	RootSelector string       `json:"rootSelector"`
+	Fields       []fieldQuery `json:"fields"`
+	TimeField    string       `json:"timeField"`
+	// IgnoreTimeRange returns all rows instead of rows in the query time range.
+	IgnoreTimeRange bool `json:"ignoreTimeRange"`
+}
+
+func ProvideService(cfg *setting.Cfg, httpClientProvider httpclient.Provider, manager backendplugin.Manager) (*Service, error) {
+	s := &Service{
+		im:  datasource.NewInstanceManager(newInstanceSettings(httpClientProvider)),
+		cfg: cfg,
+	}
+	factory := coreplugin.New(backend.ServeOpts{
+		QueryDataHandler: s,
+	})
+
+	if err := manager.Register("file", factory); err != nil {
+		logger.Error("Failed to register plugin", "error", err)
+	}
+	return s, nil
+}
+
+func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.InstanceFactoryFunc {
+	return func(settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+		model := &datasourceInfo{Source: sourceLocal}
+		if len(settings.JSONData) > 0 {
+			if err := json.Unmarshal(settings.JSONData, model); err != nil {
+				return nil, fmt.Errorf("error reading settings: %w", err)
+			}
+		}
+
+		switch model.Source {
+		case sourceLocal:
+		case sourceURL:
+			baseURL, err := url.Parse(settings.URL)
+			if err != nil || baseURL.Host == "" || (baseURL.Scheme != "http" && baseURL.Scheme != "https") {
+				return nil, fmt.Errorf("invalid data source URL %q", settings.URL)
+			}
+			model.baseURL = baseURL
+
+			opts, err := settings.HTTPClientOptions()
+			if err != nil {
+				return nil, err
+			}
+			if model.httpClient, err = httpClientProvider.New(opts); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unsupported source %q", model.Source)
+		}
+		return model, nil
+	}
+}
+
+func (s *Service) getDSInfo(pluginCtx backend.PluginContext) (*datasourceInfo, error) {
+	i, err := s.im.Get(pluginCtx)
+	if err != nil {
+		return nil, err
+	}
+	instance := i.(*datasourceInfo)
+	return instance, nil
+}
+
+func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	dsInfo, err := s.getDSInfo(req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := backend.NewQueryDataResponse()
+	for _, q := range req.Queries {
+		resp.Responses[q.RefID] = s.query(ctx, dsInfo, q)
+	}
+	return resp, nil
+}
+
+func (s *Service) query(ctx context.Context, dsInfo *datasourceInfo, q backend.DataQuery) backend.DataResponse {
+	model := queryModel{}
+	if err := json.Unmarshal(q.JSON, &model); err != nil {
+		return backend.DataResponse{Error: fmt.Errorf("failed to parse query json: %w", err)}
+	}
+
+	format, err := detectFormat(model.Format, model.Path)
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+	content, err := s.readFile(ctx, dsInfo, model.Path)
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+	t, err := readTable(content, format, model.RootSelector)
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+
+	frame, timeIdx, err := tableToFrame(t, model.Fields, model.TimeField)
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+	if timeIdx != -1 {
+		if !model.IgnoreTimeRange {
+			frame, err = filterTimeRange(frame, timeIdx, q.TimeRange.From, q.TimeRange.To)
+			if err != nil {
+				return backend.DataResponse{Error: err}
+			}
+		}
+		frame = sortByTime(frame, timeIdx)
+	}
+
+	frame.Name = q.RefID
+	frame.RefID = q.RefID
+	frame.Meta = &data.FrameMeta{ExecutedQueryString: model.Path}
+	return backend.DataResponse{Frames: data.Frames{frame}}
+}
+
+// readFile returns content of the file at the path, which is a local file or a
+// URL depending on the source of the data source.
+func (s *Service) readFile(ctx context.Context, dsInfo *datasourceInfo, path string) ([]byte, error) {
+	if dsInfo.Source == sourceURL {
+		u, err := resolveURL(dsInfo.baseURL, path)
+		if err != nil {
+			return nil, err
+		}
+		return readURL(ctx, dsInfo.httpClient, u)
+	}
+
+	filePath, err := resolvePath(s.cfg.FileAllowedPaths, path)
+	if err != nil {
+		return nil, err
+	}
+	// The path is checked to be inside of allowed directories
+	// nolint:gosec
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			logger.Warn("Failed to close file", "err", err, "path", filePath)
+		}
+	}()
+	return readLimited(f)
+}
+
+func readURL(ctx context.Context, client *http.Client, u *url.URL) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("request failed, status: %s", res.Status)
+	}
+	return readLimited(res.Body)
+}
+
+func readLimited(r io.Reader) ([]byte, error) {
+	content, err := io.ReadAll(io.LimitReader(r, maxFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if len(content) > maxFileSize {
+		return nil, fmt.Errorf("file is larger than %d bytes", maxFileSize)
+	}
+	return content, nil
+}
+
+// resolveURL resolves the path against the data source URL. The result must be
+// the data source URL or under its path, so queries can't read other URLs.
+func resolveURL(baseURL *url.URL, path string) (*url.URL, error) {
+	ref, err := url.Parse(strings.TrimSpace(path))
+	if err != nil {
+		return nil, fmt.Errorf("invalid path %q: %w", path, err)
+	}
+	if ref.Scheme != "" || ref.Host != "" {
+		return nil, errURLNotAllowed
+	}
+	if ref.Path == "" && ref.RawQuery == "" {
+		return baseURL, nil
+	}
+
+	base := *baseURL
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+	}
+	// Paths of queries are relative to the data source URL.
+	ref.Path = strings.TrimPrefix(ref.Path, "/")
+	u := base.ResolveReference(ref)
+	if !strings.HasPrefix(u.Path, base.Path) {
+		return nil, errURLNotAllowed
+	}
+	return u, nil
+}
+
+// resolvePath returns absolute path to a file located inside one of
+// allowed directories.
+func resolvePath(allowedPaths []string, path string) (string, error) {
+	if len(allowedPaths) == 0 {
+		return "", errNoAllowedPaths
+	}
+	if strings.TrimSpace(path) == "" {
+		return "", errors.New("file path is not set")
+	}
+	return fs.ResolveAllowedPath(allowedPaths, path)
+}
@@ -0,0 +1,119 @@
+package file
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana/pkg/infra/fs"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryData(t *testing.T) {
+	dir := t.TempDir()
+	content, err := os.ReadFile(filepath.Join("testdata", "hosts.csv"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "hosts.csv"), content, 0600))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/files/hosts.csv" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(content)
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := setting.NewCfg()
+	cfg.FileAllowedPaths = []string{dir}
+	s := &Service{
+		im:  datasource.NewInstanceManager(newInstanceSettings(httpclient.NewProvider())),
+		cfg: cfg,
+	}
+	from := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	query := func(t *testing.T, settings backend.DataSourceInstanceSettings, queryJSON string) backend.DataResponse {
+		t.Helper()
+		resp, err := s.QueryData(context.Background(), &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{DataSourceInstanceSettings: &settings},
+			Queries: []backend.DataQuery{
+				{
+					RefID:     "A",
+					JSON:      []byte(queryJSON),
+					TimeRange: backend.TimeRange{From: from, To: from.Add(5 * time.Minute)},
+				},
+			},
+		})
+		require.NoError(t, err)
+		return resp.Responses["A"]
+	}
+	local := backend.DataSourceInstanceSettings{ID: 1, JSONData: []byte(`{"source": "local"}`)}
+	remote := backend.DataSourceInstanceSettings{ID: 2, URL: server.URL + "/files", JSONData: []byte(`{"source": "url"}`)}
+
+	t.Run("local file", func(t *testing.T) {
+		res := query(t, local, `{"path": "hosts.csv"}`)
+		require.NoError(t, res.Error)
+		frame := res.Frames[0]
+		require.Equal(t, "A", frame.RefID)
+		require.Equal(t, 3, frame.Rows())
+		require.Equal(t, from, *frame.Fields[0].At(0).(*time.Time))
+	})
+
+	t.Run("time range is ignored", func(t *testing.T) {
+		res := query(t, local, `{"path": "hosts.csv", "ignoreTimeRange": true}`)
+		require.NoError(t, res.Error)
+		require.Equal(t, 4, res.Frames[0].Rows())
+	})
+
+	t.Run("file outside of allowed paths", func(t *testing.T) {
+		res := query(t, local, `{"path": "../hosts.csv"}`)
+		require.ErrorIs(t, res.Error, fs.ErrPathNotAllowed)
+	})
+
+	t.Run("URL", func(t *testing.T) {
+		res := query(t, remote, `{"path": "hosts.csv"}`)
+		require.NoError(t, res.Error)
+		require.Equal(t, 3, res.Frames[0].Rows())
+
+		res = query(t, remote, `{"path": "missing.csv"}`)
+		require.Error(t, res.Error)
+	})
+
+	t.Run("URL outside of data source URL", func(t *testing.T) {
+		res := query(t, remote, `{"path": "../hosts.csv"}`)
+		require.ErrorIs(t, res.Error, errURLNotAllowed)
+		res = query(t, remote, `{"path": "http://example.com/hosts.csv"}`)
+		require.ErrorIs(t, res.Error, errURLNotAllowed)
+	})
+}
+
+func TestResolveURL(t *testing.T) {
+	base, err := url.Parse("https://example.com/data")
+	require.NoError(t, err)
+
+	for path, expected := range map[string]string{
+		"":                  "https://example.com/data",
+		"hosts.csv":         "https://example.com/data/hosts.csv",
+		"/dir/hosts.csv":    "https://example.com/data/dir/hosts.csv",
+		"hosts.csv?v=1":     "https://example.com/data/hosts.csv?v=1",
+		"dir/../hosts.json": "https://example.com/data/hosts.json",
+	} {
+		u, err := resolveURL(base, path)
+		require.NoError(t, err, path)
+		require.Equal(t, expected, u.String(), path)
+	}
+
+	for _, path := range []string{"../other.csv", "//other.com/hosts.csv", "file:///etc/passwd"} {
+		_, err := resolveURL(base, path)
+		require.ErrorIs(t, err, errURLNotAllowed, path)
+	}
+}
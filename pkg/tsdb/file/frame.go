@@ -0,0 +1,486 @@
+package file
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// Types of fields which can be set in queries to override type inference.
+const (
+	fieldTypeString  = "string"
+	fieldTypeNumber  = "number"
+	fieldTypeBoolean = "boolean"
+	fieldTypeTime    = "time"
+)
+
+// Layouts of time values in files. Values without a time zone are in UTC.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// Names of fields which are checked for time values when the query doesn't set
+// the time field.
+var timeFieldNames = []string{"time", "timestamp", "ts", "date", "datetime"}
+
+// Numeric times below this value are in seconds, greater values are in
+// milliseconds since the epoch.
+const maxEpochSeconds = 1e11
+
+// fieldQuery selects a field of records.
+type fieldQuery struct {
+	// Selector is a JSONPath relative to a record, or a field name.
+	Selector string `json:"selector"`
+	// Name is the name of the frame field, the selector by default.
+	Name string `json:"name,omitempty"`
+	// Type overrides the inferred type of the field.
+	Type string `json:"type,omitempty"`
+}
+
+// column holds values of a selected field of all records.
+type column struct {
+	name      string
+	fieldType string
+	values    []interface{}
+}
+
+// selectColumns returns columns of fields selected by the query, or columns of
+// all fields of the table when the query doesn't select fields.
+func selectColumns(t *table, fields []fieldQuery) ([]*column, error) {
+	if len(fields) == 0 {
+		for _, name := range t.fields {
+			fields = append(fields, fieldQuery{Selector: name})
+		}
+	}
+
+	columns := make([]*column, 0, len(fields))
+	for _, f := range fields {
+		name := f.Name
+		if name == "" {
+			name = f.Selector
+		}
+		col := &column{name: name, fieldType: f.Type, values: make([]interface{}, len(t.records))}
+
+		// Plain names select fields of records directly, as CSV headers may
+		// contain characters which have a meaning in JSONPath.
+		if _, isField := indexOf(t.fields, f.Selector); isField {
+			for i, record := range t.records {
+				col.values[i] = record.values[f.Selector]
+			}
+			columns = append(columns, col)
+			continue
+		}
+
+		steps, err := parseJSONPath(f.Selector)
+		if err != nil {
+			return nil, err
+		}
+		for i, record := range t.records {
+			matches := evalJSONPath(steps, record)
+			switch len(matches) {
+			case 0:
+			case 1:
+				col.values[i] = matches[0]
+			default:
+				col.values[i] = matches
+			}
+		}
+		columns = append(columns, col)
+	}
+	return columns, nil
+}
+
+// tableToFrame converts the table to a frame. Time field is the name of the
+// field with times, when it's empty the time field is detected. The index of
+// the time field is -1 if the frame has no time field.
+func tableToFrame(t *table, fields []fieldQuery, timeField string) (*data.Frame, int, error) {
+	columns, err := selectColumns(t, fields)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	timeIdx := -1
+	if timeField != "" {
+		idx, ok := indexOfColumn(columns, timeField)
+		if !ok {
+			return nil, -1, fmt.Errorf("time field %q not found", timeField)
+		}
+		if columns[idx].fieldType != "" && columns[idx].fieldType != fieldTypeTime {
+			return nil, -1, fmt.Errorf("time field %q has type %q", timeField, columns[idx].fieldType)
+		}
+		columns[idx].fieldType = fieldTypeTime
+		timeIdx = idx
+	} else {
+		timeIdx = detectTimeColumn(columns, t.text)
+	}
+
+	frameFields := make([]*data.Field, 0, len(columns))
+	for _, col := range columns {
+		field, err := columnToField(col, t.text)
+		if err != nil {
+			return nil, -1, err
+		}
+		frameFields = append(frameFields, field)
+	}
+	return data.NewFrame("", frameFields...), timeIdx, nil
+}
+
+// detectTimeColumn returns the index of the first column with times. Columns
+// with a time like name may hold epoch numbers or strings, other columns are
+// only used when all values are time strings.
+func detectTimeColumn(columns []*column, text bool) int {
+	for i, col := range columns {
+		if col.fieldType == fieldTypeTime {
+			return i
+		}
+	}
+	for i, col := range columns {
+		if col.fieldType == "" && isTimeFieldName(col.name) && allTimes(col.values, text, true) {
+			col.fieldType = fieldTypeTime
+			return i
+		}
+	}
+	for i, col := range columns {
+		if col.fieldType == "" && allTimes(col.values, text, false) {
+			col.fieldType = fieldTypeTime
+			return i
+		}
+	}
+	return -1
+}
+
+func isTimeFieldName(name string) bool {
+	name = strings.ToLower(name)
+	for _, n := range timeFieldNames {
+		if name == n {
+			return true
+		}
+	}
+	return strings.Contains(name, "time")
+}
+
+// allTimes returns true if all non-null values are times, and there is at
+// least one value.
+func allTimes(values []interface{}, text bool, allowNumbers bool) bool {
+	found := false
+	for _, v := range values {
+		c := toCell(v, text)
+		if c.value == nil {
+			continue
+		}
+		if _, isString := c.value.(string); !isString && !allowNumbers {
+			return false
+		}
+		if _, ok := c.time(); !ok {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+// cell is a value converted to one of nil, bool, int64, float64, string or a
+// JSON encoded array or object. Raw is the value as text.
+type cell struct {
+	value interface{}
+	raw   string
+}
+
+// toCell converts a value of a record. Text values are parsed, so values of
+// CSV files have the same types as equal JSON values.
+func toCell(v interface{}, text bool) cell {
+	switch v := v.(type) {
+	case nil:
+		return cell{}
+	case string:
+		if !text {
+			return cell{value: v, raw: v}
+		}
+		if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return cell{value: i, raw: v}
+		}
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return cell{value: f, raw: v}
+		}
+		if b, err := strconv.ParseBool(v); err == nil && len(v) > 1 {
+			return cell{value: b, raw: v}
+		}
+		return cell{value: v, raw: v}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return cell{value: i, raw: v.String()}
+		}
+		if f, err := v.Float64(); err == nil {
+			return cell{value: f, raw: v.String()}
+		}
+		return cell{value: v.String(), raw: v.String()}
+	case bool:
+		return cell{value: v, raw: strconv.FormatBool(v)}
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return cell{value: fmt.Sprint(v), raw: fmt.Sprint(v)}
+		}
+		return cell{value: string(b), raw: string(b)}
+	}
+}
+
+func (c cell) time() (time.Time, bool) {
+	switch v := c.value.(type) {
+	case string:
+		for _, layout := range timeLayouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t.UTC(), true
+			}
+		}
+	case int64:
+		return epochToTime(float64(v)), true
+	case float64:
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			return epochToTime(v), true
+		}
+	}
+	return time.Time{}, false
+}
+
+func epochToTime(v float64) time.Time {
+	if math.Abs(v) < maxEpochSeconds {
+		return time.Unix(0, int64(v*float64(time.Second))).UTC()
+	}
+	return time.Unix(0, int64(v*float64(time.Millisecond))).UTC()
+}
+
+func (c cell) float() (float64, bool) {
+	switch v := c.value.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func (c cell) bool() (bool, bool) {
+	switch v := c.value.(type) {
+	case bool:
+		return v, true
+	case int64:
+		return v != 0, true
+	case float64:
+		return v != 0, true
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+		return b, err == nil
+	}
+	return false, false
+}
+
+// columnToField converts values of the column to a field. Without a type set,
+// the type is inferred from values: fields of booleans, integers or numbers
+// have that type, any other value makes it a string field.
+func columnToField(col *column, text bool) (*data.Field, error) {
+	cells := make([]cell, len(col.values))
+	for i, v := range col.values {
+		cells[i] = toCell(v, text)
+	}
+
+	fieldType := col.fieldType
+	if fieldType == "" {
+		fieldType = inferFieldType(cells)
+	}
+
+	var field *data.Field
+	switch fieldType {
+	case fieldTypeTime:
+		field = data.NewFieldFromFieldType(data.FieldTypeNullableTime, len(cells))
+		for i, c := range cells {
+			if c.value == nil {
+				continue
+			}
+			t, ok := c.time()
+			if !ok {
+				return nil, fmt.Errorf("value %q of field %q is not a time", c.raw, col.name)
+			}
+			field.SetConcrete(i, t)
+		}
+	case fieldTypeNumber:
+		if isIntegerColumn(cells) {
+			field = data.NewFieldFromFieldType(data.FieldTypeNullableInt64, len(cells))
+			for i, c := range cells {
+				if c.value != nil {
+					field.SetConcrete(i, c.value.(int64))
+				}
+			}
+			break
+		}
+		field = data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, len(cells))
+		for i, c := range cells {
+			if c.value == nil {
+				continue
+			}
+			f, ok := c.float()
+			if !ok {
+				return nil, fmt.Errorf("value %q of field %q is not a number", c.raw, col.name)
+			}
+			field.SetConcrete(i, f)
+		}
+	case fieldTypeBoolean:
+		field = data.NewFieldFromFieldType(data.FieldTypeNullableBool, len(cells))
+		for i, c := range cells {
+			if c.value == nil {
+				continue
+			}
+			b, ok := c.bool()
+			if !ok {
+				return nil, fmt.Errorf("value %q of field %q is not a boolean", c.raw, col.name)
+			}
+			field.SetConcrete(i, b)
+		}
+	case fieldTypeString:
+		field = data.NewFieldFromFieldType(data.FieldTypeNullableString, len(cells))
+		for i, c := range cells {
+			if c.value != nil {
+				field.SetConcrete(i, c.raw)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported type %q of field %q", fieldType, col.name)
+	}
+
+	field.Name = col.name
+	return field, nil
+}
+
+func inferFieldType(cells []cell) string {
+	bools, ints, floats, others := 0, 0, 0, 0
+	for _, c := range cells {
+		switch c.value.(type) {
+		case nil:
+		case bool:
+			bools++
+		case int64:
+			ints++
+		case float64:
+			floats++
+		default:
+			others++
+		}
+	}
+	switch {
+	case others > 0:
+		return fieldTypeString
+	case bools > 0 && ints+floats > 0:
+		return fieldTypeString
+	case bools > 0:
+		return fieldTypeBoolean
+	case ints+floats > 0:
+		return fieldTypeNumber
+	}
+	// Fields without values are strings.
+	return fieldTypeString
+}
+
+func isIntegerColumn(cells []cell) bool {
+	for _, c := range cells {
+		if c.value == nil {
+			continue
+		}
+		if _, ok := c.value.(int64); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// filterTimeRange returns rows of the frame with times in the range, rows
+// without a time are dropped.
+func filterTimeRange(frame *data.Frame, timeIdx int, from, to time.Time) (*data.Frame, error) {
+	filtered, err := frame.FilterRowsByField(timeIdx, func(v interface{}) (bool, error) {
+		t, ok := v.(*time.Time)
+		if !ok || t == nil {
+			return false, nil
+		}
+		return !t.Before(from) && !t.After(to), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return filtered, nil
+}
+
+// sortByTime returns the frame with rows sorted by the time field, as rows of
+// files are often not sorted. Null times are last.
+func sortByTime(frame *data.Frame, timeIdx int) *data.Frame {
+	rows := frame.Rows()
+	timeField := frame.Fields[timeIdx]
+	less := func(i, j int) bool {
+		a, _ := timeField.At(i).(*time.Time)
+		b, _ := timeField.At(j).(*time.Time)
+		if a == nil || b == nil {
+			return a != nil
+		}
+		return a.Before(*b)
+	}
+
+	order := make([]int, rows)
+	for i := range order {
+		order[i] = i
+	}
+	sorted := sort.SliceIsSorted(order, func(i, j int) bool { return less(order[i], order[j]) })
+	if sorted {
+		return frame
+	}
+	sort.SliceStable(order, func(i, j int) bool { return less(order[i], order[j]) })
+
+	fields := make([]*data.Field, len(frame.Fields))
+	for i, field := range frame.Fields {
+		sortedField := data.NewFieldFromFieldType(field.Type(), rows)
+		sortedField.Name = field.Name
+		sortedField.Labels = field.Labels
+		sortedField.Config = field.Config
+		for row, idx := range order {
+			sortedField.Set(row, field.CopyAt(idx))
+		}
+		fields[i] = sortedField
+	}
+	sortedFrame := data.NewFrame(frame.Name, fields...)
+	sortedFrame.RefID = frame.RefID
+	sortedFrame.Meta = frame.Meta
+	return sortedFrame
+}
+
+func indexOf(values []string, value string) (int, bool) {
+	for i, v := range values {
+		if v == value {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+func indexOfColumn(columns []*column, name string) (int, bool) {
+	for i, col := range columns {
+		if col.name == name {
+			return i, true
+		}
+	}
+	return -1, false
+}
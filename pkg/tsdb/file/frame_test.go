@@ -0,0 +1,135 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func readTestTable(t *testing.T, name string, rootSelector string) *table {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	format, err := detectFormat("", name)
+	require.NoError(t, err)
+	tbl, err := readTable(content, format, rootSelector)
+	require.NoError(t, err)
+	return tbl
+}
+
+func TestTableToFrame(t *testing.T) {
+	t.Run("CSV types are inferred", func(t *testing.T) {
+		frame, timeIdx, err := tableToFrame(readTestTable(t, "hosts.csv", ""), nil, "")
+		require.NoError(t, err)
+		require.Equal(t, 0, timeIdx)
+		require.Equal(t, 4, frame.Rows())
+
+		require.Equal(t, "time", frame.Fields[0].Name)
+		require.Equal(t, data.FieldTypeNullableTime, frame.Fields[0].Type())
+		require.Equal(t, time.Date(2021, 6, 1, 12, 2, 0, 0, time.UTC), *frame.Fields[0].At(0).(*time.Time))
+		require.Equal(t, data.FieldTypeNullableString, frame.Fields[1].Type())
+		require.Equal(t, data.FieldTypeNullableFloat64, frame.Fields[2].Type())
+		require.Nil(t, frame.Fields[2].At(2))
+		require.Equal(t, 1.0, *frame.Fields[2].At(3).(*float64))
+		require.Equal(t, data.FieldTypeNullableBool, frame.Fields[3].Type())
+		require.False(t, *frame.Fields[3].At(2).(*bool))
+	})
+
+	t.Run("JSON fields are selected", func(t *testing.T) {
+		tbl := readTestTable(t, "hosts.json", "$.data.hosts")
+		require.Equal(t, []string{"name", "timestamp", "stats"}, tbl.fields)
+
+		frame, timeIdx, err := tableToFrame(tbl, []fieldQuery{
+			{Selector: "name", Name: "host"},
+			{Selector: "$.stats.cpu", Name: "cpu"},
+			{Selector: "stats.disks"},
+			{Selector: "timestamp", Type: fieldTypeString},
+		}, "")
+		require.NoError(t, err)
+		require.Equal(t, -1, timeIdx)
+
+		require.Equal(t, "host", frame.Fields[0].Name)
+		require.Equal(t, "web", *frame.Fields[0].At(0).(*string))
+		require.Equal(t, data.FieldTypeNullableFloat64, frame.Fields[1].Type())
+		require.Equal(t, 0.5, *frame.Fields[1].At(0).(*float64))
+		require.Equal(t, "stats.disks", frame.Fields[2].Name)
+		require.Equal(t, `["sda","sdb"]`, *frame.Fields[2].At(0).(*string))
+		require.Nil(t, frame.Fields[2].At(1))
+		require.Equal(t, "1622548800000", *frame.Fields[3].At(0).(*string))
+	})
+
+	t.Run("epoch milliseconds in time named field", func(t *testing.T) {
+		frame, timeIdx, err := tableToFrame(readTestTable(t, "hosts.json", "$.data.hosts"), nil, "")
+		require.NoError(t, err)
+		require.Equal(t, 1, timeIdx)
+		require.Equal(t, time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC), *frame.Fields[1].At(0).(*time.Time))
+		require.Equal(t, `{"cpu":0.5,"disks":["sda","sdb"]}`, *frame.Fields[2].At(0).(*string))
+	})
+
+	t.Run("NDJSON with time field set", func(t *testing.T) {
+		frame, timeIdx, err := tableToFrame(readTestTable(t, "events.ndjson", ""), nil, "ts")
+		require.NoError(t, err)
+		require.Equal(t, 0, timeIdx)
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, time.Date(2021, 6, 1, 12, 1, 0, 0, time.UTC), *frame.Fields[0].At(1).(*time.Time))
+		require.Equal(t, data.FieldTypeNullableInt64, frame.Fields[2].Type())
+	})
+
+	t.Run("invalid time field", func(t *testing.T) {
+		_, _, err := tableToFrame(readTestTable(t, "events.ndjson", ""), nil, "level")
+		require.Error(t, err)
+		_, _, err = tableToFrame(readTestTable(t, "events.ndjson", ""), nil, "missing")
+		require.Error(t, err)
+	})
+
+	t.Run("scalar values are records", func(t *testing.T) {
+		tbl, err := readTable([]byte(`[1, 2.5, null]`), formatJSON, "")
+		require.NoError(t, err)
+		frame, _, err := tableToFrame(tbl, nil, "")
+		require.NoError(t, err)
+		require.Equal(t, "value", frame.Fields[0].Name)
+		require.Equal(t, 2.5, *frame.Fields[0].At(1).(*float64))
+	})
+}
+
+func TestFilterTimeRange(t *testing.T) {
+	frame, timeIdx, err := tableToFrame(readTestTable(t, "hosts.csv", ""), nil, "")
+	require.NoError(t, err)
+
+	from := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	filtered, err := filterTimeRange(frame, timeIdx, from, from.Add(5*time.Minute))
+	require.NoError(t, err)
+	sorted := sortByTime(filtered, timeIdx)
+	require.Equal(t, 3, sorted.Rows())
+	for i, minute := range []int{0, 1, 2} {
+		require.Equal(t, from.Add(time.Duration(minute)*time.Minute), *sorted.Fields[0].At(i).(*time.Time))
+	}
+	require.Equal(t, "db", *sorted.Fields[1].At(1).(*string))
+}
+
+func TestDetectFormat(t *testing.T) {
+	for path, expected := range map[string]string{
+		"data.csv":                      formatCSV,
+		"data.JSON":                     formatJSON,
+		"logs.jsonl":                    formatNDJSON,
+		"api/data.ndjson?token=1#frag":  formatNDJSON,
+		"dir/data.json?download=result": formatJSON,
+	} {
+		format, err := detectFormat("", path)
+		require.NoError(t, err, path)
+		require.Equal(t, expected, format, path)
+	}
+
+	format, err := detectFormat("CSV", "data.txt")
+	require.NoError(t, err)
+	require.Equal(t, formatCSV, format)
+
+	_, err = detectFormat("", "data.txt")
+	require.Error(t, err)
+	_, err = detectFormat("xml", "data.xml")
+	require.Error(t, err)
+}
@@ -0,0 +1,109 @@
+package file
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// pathStep is a step of a parsed JSONPath. Wildcard steps select all elements
+// of an array or all values of an object.
+type pathStep struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// parseJSONPath parses a subset of JSONPath: member access with dots or
+// brackets, array indexes and wildcards, for example $.data.items[*].name or
+// $['data'][0]. The leading $ is optional, so field names can be used as paths.
+func parseJSONPath(path string) ([]pathStep, error) {
+	p := strings.TrimSpace(path)
+	hasRoot := strings.HasPrefix(p, "$")
+	p = strings.TrimPrefix(p, "$")
+
+	steps := []pathStep{}
+	for len(p) > 0 {
+		switch p[0] {
+		case '.':
+			p = p[1:]
+			end := strings.IndexAny(p, ".[")
+			if end == -1 {
+				end = len(p)
+			}
+			name := p[:end]
+			if name == "" {
+				return nil, fmt.Errorf("invalid JSONPath %q: empty member name", path)
+			}
+			if name == "*" {
+				steps = append(steps, pathStep{wildcard: true})
+			} else {
+				steps = append(steps, pathStep{key: name})
+			}
+			p = p[end:]
+		case '[':
+			end := strings.Index(p, "]")
+			if end == -1 {
+				return nil, fmt.Errorf("invalid JSONPath %q: missing ]", path)
+			}
+			inner := strings.TrimSpace(p[1:end])
+			p = p[end+1:]
+			switch {
+			case inner == "*":
+				steps = append(steps, pathStep{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				steps = append(steps, pathStep{key: inner[1 : len(inner)-1]})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("invalid JSONPath %q: invalid index %q", path, inner)
+				}
+				steps = append(steps, pathStep{index: index, isIndex: true})
+			}
+		default:
+			if hasRoot || len(steps) > 0 {
+				return nil, fmt.Errorf("invalid JSONPath %q: unexpected %q", path, p[0])
+			}
+			// A path without the leading $ starts with a member name.
+			p = "." + p
+		}
+	}
+	return steps, nil
+}
+
+// evalJSONPath returns values matching the path in a decoded JSON document.
+// Negative indexes count from the end of an array.
+func evalJSONPath(steps []pathStep, doc interface{}) []interface{} {
+	current := []interface{}{doc}
+	for _, step := range steps {
+		next := []interface{}{}
+		for _, v := range current {
+			switch v := v.(type) {
+			case *object:
+				if step.wildcard {
+					for _, key := range v.keys {
+						next = append(next, v.values[key])
+					}
+				} else if child, ok := v.values[step.key]; ok && !step.isIndex {
+					next = append(next, child)
+				}
+			case []interface{}:
+				switch {
+				case step.wildcard:
+					next = append(next, v...)
+				case step.isIndex:
+					index := step.index
+					if index < 0 {
+						index += len(v)
+					}
+					if index >= 0 && index < len(v) {
+						next = append(next, v[index])
+					}
+				}
+			}
+		}
+		current = next
+	}
+	return current
+}
@@ -0,0 +1,48 @@
+package file
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONPath(t *testing.T) {
+	doc, err := decodeJSON([]byte(`{"data": {"items": [{"name": "a", "n": 1}, {"name": "b", "n": 2}], "the key": "v"}}`))
+	require.NoError(t, err)
+
+	eval := func(path string) []interface{} {
+		t.Helper()
+		steps, err := parseJSONPath(path)
+		require.NoError(t, err)
+		return evalJSONPath(steps, doc)
+	}
+
+	t.Run("member access", func(t *testing.T) {
+		require.Equal(t, []interface{}{"a"}, eval("$.data.items[0].name"))
+		require.Equal(t, []interface{}{"b"}, eval("data.items[-1].name"))
+		require.Equal(t, []interface{}{"v"}, eval("$['data']['the key']"))
+	})
+
+	t.Run("wildcards", func(t *testing.T) {
+		require.Equal(t, []interface{}{"a", "b"}, eval("$.data.items[*].name"))
+		require.Equal(t, []interface{}{json.Number("1"), json.Number("2")}, eval("$.data.items.*.n"))
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		require.Empty(t, eval("$.data.missing"))
+		require.Empty(t, eval("$.data.items[5]"))
+		require.Empty(t, eval("$.data[0]"))
+	})
+
+	t.Run("root", func(t *testing.T) {
+		require.Equal(t, []interface{}{doc}, eval("$"))
+	})
+
+	t.Run("invalid paths", func(t *testing.T) {
+		for _, path := range []string{"$.data[0", "$.data[x]", "$.data..items", "$x"} {
+			_, err := parseJSONPath(path)
+			require.Error(t, err, path)
+		}
+	})
+}
@@ -0,0 +1,297 @@
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+const (
+	formatCSV    = "csv"
+	formatJSON   = "json"
+	formatNDJSON = "ndjson"
+)
+
+// object is a decoded JSON object which keeps the order of its keys, so
+// fields of a frame are in the same order as in the file.
+type object struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newObject() *object {
+	return &object{values: map[string]interface{}{}}
+}
+
+func (o *object) set(key string, value interface{}) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+func (o *object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// table is the content of a file as records. Field names are in order of
+// their first appearance in records.
+type table struct {
+	fields  []string
+	records []*object
+	// text is true when values are unparsed strings, as in CSV files.
+	text bool
+}
+
+func (t *table) add(record *object) {
+	t.records = append(t.records, record)
+}
+
+// index adds names of fields of records to fields of the table.
+func (t *table) index() {
+	seen := map[string]bool{}
+	for _, record := range t.records {
+		for _, key := range record.keys {
+			if !seen[key] {
+				seen[key] = true
+				t.fields = append(t.fields, key)
+			}
+		}
+	}
+}
+
+// detectFormat returns the format of the query, or the format matching the
+// extension of the file when it's not set.
+func detectFormat(format string, filePath string) (string, error) {
+	switch strings.ToLower(format) {
+	case formatCSV, formatJSON, formatNDJSON:
+		return strings.ToLower(format), nil
+	case "":
+	default:
+		return "", fmt.Errorf("unsupported format %q", format)
+	}
+
+	// Query strings of URLs are not part of the extension.
+	if i := strings.IndexAny(filePath, "?#"); i != -1 {
+		filePath = filePath[:i]
+	}
+	switch strings.ToLower(path.Ext(filePath)) {
+	case ".csv":
+		return formatCSV, nil
+	case ".json":
+		return formatJSON, nil
+	case ".ndjson", ".jsonl":
+		return formatNDJSON, nil
+	}
+	return "", fmt.Errorf("format of %q can't be detected from the file extension, set the format of the query", filePath)
+}
+
+// readTable parses the content in the format. For JSON formats, the root
+// selector selects records in a document, by default the document is an array
+// of records or a single record.
+func readTable(content []byte, format string, rootSelector string) (*table, error) {
+	var root []pathStep
+	if rootSelector != "" && format != formatCSV {
+		var err error
+		if root, err = parseJSONPath(rootSelector); err != nil {
+			return nil, err
+		}
+	}
+
+	var t *table
+	var err error
+	switch format {
+	case formatCSV:
+		t, err = readCSV(content)
+	case formatJSON:
+		t = &table{}
+		err = readJSONRecords(t, content, root)
+	case formatNDJSON:
+		t, err = readNDJSON(content, root)
+	default:
+		err = fmt.Errorf("unsupported format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	t.index()
+	return t, nil
+}
+
+func readCSV(content []byte) (*table, error) {
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return &table{text: true}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header line: %w", err)
+	}
+	for i, name := range header {
+		header[i] = strings.TrimSpace(name)
+	}
+
+	t := &table{text: true}
+	for {
+		line, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read line: %w", err)
+		}
+
+		record := newObject()
+		for i, name := range header {
+			// Missing and empty values are null.
+			var value interface{}
+			if i < len(line) && strings.TrimSpace(line[i]) != "" {
+				value = strings.TrimSpace(line[i])
+			}
+			record.set(name, value)
+		}
+		t.add(record)
+	}
+	return t, nil
+}
+
+func readNDJSON(content []byte, root []pathStep) (*table, error) {
+	t := &table{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), maxFileSize)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if err := readJSONRecords(t, line, root); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// readJSONRecords adds records of a JSON document to the table. Values which
+// aren't objects are records with a single value field.
+func readJSONRecords(t *table, content []byte, root []pathStep) error {
+	doc, err := decodeJSON(content)
+	if err != nil {
+		return err
+	}
+
+	values := []interface{}{doc}
+	if len(root) > 0 {
+		values = evalJSONPath(root, doc)
+	}
+	// A single array is a list of records.
+	if len(values) == 1 {
+		if list, ok := values[0].([]interface{}); ok {
+			values = list
+		}
+	}
+
+	for _, v := range values {
+		record, ok := v.(*object)
+		if !ok {
+			record = newObject()
+			record.set("value", v)
+		}
+		t.add(record)
+	}
+	return nil
+}
+
+// decodeJSON decodes a JSON document with objects decoded as *object and
+// numbers as json.Number.
+func decodeJSON(content []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(content))
+	dec.UseNumber()
+	v, err := decodeJSONValue(dec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse JSON: unexpected data after the document")
+	}
+	return v, nil
+}
+
+func decodeJSONValue(dec *json.Decoder) (interface{}, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		o := newObject()
+		for dec.More() {
+			keyToken, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, ok := keyToken.(string)
+			if !ok {
+				return nil, fmt.Errorf("unexpected object key %v", keyToken)
+			}
+			value, err := decodeJSONValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			o.set(key, value)
+		}
+		// Closing }
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return o, nil
+	case json.Delim('['):
+		list := []interface{}{}
+		for dec.More() {
+			value, err := decodeJSONValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		// Closing ]
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return list, nil
+	}
+	return token, nil
+}
@@ -0,0 +1,285 @@
+package graphite
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+	"golang.org/x/net/context/ctxhttp"
+)
+
+const (
+	// resourceCacheTTL is how long successful find and tags responses are cached.
+	resourceCacheTTL = time.Minute
+	// functionsCacheTTL is how long the function index is cached, it only
+	// changes when Graphite is upgraded.
+	functionsCacheTTL = 10 * time.Minute
+)
+
+// infinityDefaultRe matches function parameter defaults Graphite 1.1.7 returns
+// as Infinity, which is not valid JSON. See https://github.com/graphite-project/graphite-web/issues/2609
+var infinityDefaultRe = regexp.MustCompile(`"default": ?(-?)Infinity`)
+
+func (s *Service) registerRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/metrics/find", s.resourceHandler(metricsFindResource, resourceCacheTTL))
+	mux.HandleFunc("/tags/autoComplete/tags", s.resourceHandler(tagsAutoCompleteResource, resourceCacheTTL))
+	mux.HandleFunc("/tags/autoComplete/values", s.resourceHandler(tagValuesAutoCompleteResource, resourceCacheTTL))
+	mux.HandleFunc("/functions", s.resourceHandler(functionsResource, functionsCacheTTL))
+}
+
+// resourceRequest is a request to Graphite API made by a resource handler.
+type resourceRequest struct {
+	Method string
+	Path   string
+	Params url.Values
+}
+
+// resource reads request parameters, and normalizes Graphite response to the
+// response returned to the frontend.
+type resource struct {
+	request  func(form url.Values) (resourceRequest, error)
+	response func(body []byte) (interface{}, error)
+}
+
+// MetricFindResult is a node of the metric tree returned by /metrics/find.
+type MetricFindResult struct {
+	Text       string `json:"text"`
+	ID         string `json:"id"`
+	Expandable bool   `json:"expandable"`
+	Leaf       bool   `json:"leaf"`
+}
+
+type resourceError struct {
+	Message string `json:"message"`
+}
+
+var metricsFindResource = resource{
+	request: func(form url.Values) (resourceRequest, error) {
+		if form.Get("query") == "" {
+			return resourceRequest{}, errors.New("missing query parameter")
+		}
+		return resourceRequest{
+			// Queries may be too long to be sent in the URL, Graphite accepts them in the form.
+			Method: http.MethodPost,
+			Path:   "metrics/find",
+			Params: pickParams(form, "query", "from", "until"),
+		}, nil
+	},
+	response: func(body []byte) (interface{}, error) {
+		// Graphite and graphite-api return flags as numbers or booleans
+		var nodes []struct {
+			Text       string      `json:"text"`
+			ID         string      `json:"id"`
+			Expandable interface{} `json:"expandable"`
+			Leaf       interface{} `json:"leaf"`
+		}
+		if err := json.Unmarshal(body, &nodes); err != nil {
+			return nil, err
+		}
+		result := make([]MetricFindResult, 0, len(nodes))
+		for _, node := range nodes {
+			result = append(result, MetricFindResult{
+				Text:       node.Text,
+				ID:         node.ID,
+				Expandable: isTrue(node.Expandable),
+				Leaf:       isTrue(node.Leaf),
+			})
+		}
+		return result, nil
+	},
+}
+
+var tagsAutoCompleteResource = resource{
+	request: func(form url.Values) (resourceRequest, error) {
+		return resourceRequest{
+			Method: http.MethodGet,
+			Path:   "tags/autoComplete/tags",
+			Params: pickParams(form, "expr", "tagPrefix", "limit", "from", "until"),
+		}, nil
+	},
+	response: parseTagsResponse,
+}
+
+var tagValuesAutoCompleteResource = resource{
+	request: func(form url.Values) (resourceRequest, error) {
+		if form.Get("tag") == "" {
+			return resourceRequest{}, errors.New("missing tag parameter")
+		}
+		return resourceRequest{
+			Method: http.MethodGet,
+			Path:   "tags/autoComplete/values",
+			Params: pickParams(form, "expr", "tag", "valuePrefix", "limit", "from", "until"),
+		}, nil
+	},
+	response: parseTagsResponse,
+}
+
+var functionsResource = resource{
+	request: func(form url.Values) (resourceRequest, error) {
+		return resourceRequest{
+			Method: http.MethodGet,
+			Path:   "functions",
+			Params: url.Values{},
+		}, nil
+	},
+	response: func(body []byte) (interface{}, error) {
+		// 1e9999 is parsed as Infinity by the frontend, as in Graphite versions before 1.1.7.
+		fixed := infinityDefaultRe.ReplaceAll(body, []byte(`"default": ${1}1e9999`))
+		if !json.Valid(fixed) {
+			return nil, errors.New("invalid functions response")
+		}
+		return json.RawMessage(fixed), nil
+	},
+}
+
+func parseTagsResponse(body []byte) (interface{}, error) {
+	tags := []string{}
+	if err := json.Unmarshal(body, &tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (s *Service) resourceHandler(res resource, ttl time.Duration) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		s.logger.Debug("Received resource call", "url", req.URL.String(), "method", req.Method)
+
+		if req.Method != http.MethodGet && req.Method != http.MethodPost {
+			writeResourceError(rw, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		if err := req.ParseForm(); err != nil {
+			writeResourceError(rw, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		dsInfo, err := s.getDSInfo(httpadapter.PluginConfigFromContext(req.Context()))
+		if err != nil {
+			writeResourceError(rw, http.StatusInternalServerError, fmt.Sprintf("unexpected error %v", err))
+			return
+		}
+
+		graphiteReq, err := res.request(req.Form)
+		if err != nil {
+			writeResourceError(rw, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		cacheKey := graphiteReq.Path + "?" + graphiteReq.Params.Encode()
+		if dsInfo.resourceCache != nil {
+			if cached, ok := dsInfo.resourceCache.Get(cacheKey); ok {
+				writeResourceResponse(rw, http.StatusOK, cached.([]byte))
+				return
+			}
+		}
+
+		body, err := s.doResourceRequest(req.Context(), dsInfo, graphiteReq)
+		if err != nil {
+			writeResourceError(rw, http.StatusBadGateway, err.Error())
+			return
+		}
+		result, err := res.response(body)
+		if err != nil {
+			s.logger.Info("Failed to parse graphite response", "path", graphiteReq.Path, "error", err)
+			writeResourceError(rw, http.StatusBadGateway, fmt.Sprintf("invalid graphite response: %v", err))
+			return
+		}
+		resBody, err := json.Marshal(result)
+		if err != nil {
+			writeResourceError(rw, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if dsInfo.resourceCache != nil {
+			dsInfo.resourceCache.Set(cacheKey, resBody, ttl)
+		}
+		writeResourceResponse(rw, http.StatusOK, resBody)
+	}
+}
+
+func (s *Service) doResourceRequest(ctx context.Context, dsInfo *datasourceInfo, r resourceRequest) ([]byte, error) {
+	u, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, r.Path)
+
+	var req *http.Request
+	if r.Method == http.MethodPost {
+		req, err = http.NewRequest(http.MethodPost, u.String(), strings.NewReader(r.Params.Encode()))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	} else {
+		u.RawQuery = r.Params.Encode()
+		req, err = http.NewRequest(http.MethodGet, u.String(), nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	res, err := ctxhttp.Do(ctx, dsInfo.HTTPClient, req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			s.logger.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode/100 != 2 {
+		s.logger.Info("Request failed", "status", res.Status, "body", string(body))
+		return nil, fmt.Errorf("request failed, status: %s", res.Status)
+	}
+	return body, nil
+}
+
+func pickParams(form url.Values, keys ...string) url.Values {
+	params := url.Values{}
+	for _, key := range keys {
+		if values, ok := form[key]; ok {
+			params[key] = values
+		}
+	}
+	return params
+}
+
+func isTrue(v interface{}) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v == "1" || v == "true"
+	}
+	return false
+}
+
+func writeResourceError(rw http.ResponseWriter, code int, msg string) {
+	body, err := json.Marshal(resourceError{Message: msg})
+	if err != nil {
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	writeResourceResponse(rw, code, body)
+}
+
+func writeResourceResponse(rw http.ResponseWriter, code int, body []byte) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(code)
+	_, _ = rw.Write(body)
+}
@@ -0,0 +1,153 @@
+package graphite
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceHandler(t *testing.T) {
+	var mu sync.Mutex
+	var requests []*http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		require.NoError(t, req.ParseForm())
+		mu.Lock()
+		requests = append(requests, req)
+		mu.Unlock()
+
+		rw.Header().Set("Content-Type", "application/json")
+		switch req.URL.Path {
+		case "/metrics/find":
+			if req.Form.Get("query") == "broken.*" {
+				rw.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			_, _ = rw.Write([]byte(`[{"text":"001","id":"prod.servers.001","expandable":1,"leaf":0,"allowChildren":1},{"text":"cpu","id":"prod.servers.cpu","expandable":false,"leaf":true}]`))
+		case "/tags/autoComplete/tags":
+			_, _ = rw.Write([]byte(`["dc","host"]`))
+		case "/tags/autoComplete/values":
+			_, _ = rw.Write([]byte(`["eu-1","us-1"]`))
+		case "/functions":
+			_, _ = rw.Write([]byte(`{"removeAboveValue":{"name":"removeAboveValue","params":[{"name":"n","type":"integer","default": Infinity}]}}`))
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	s := &Service{
+		logger: log.New("graphite-test"),
+		im:     datasource.NewInstanceManager(newInstanceSettings(httpclient.NewProvider())),
+	}
+	mux := http.NewServeMux()
+	s.registerRoutes(mux)
+	handler := httpadapter.New(mux)
+	pluginCtx := backend.PluginContext{
+		DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{ID: 1, URL: srv.URL},
+	}
+
+	call := func(t *testing.T, method, path string, query url.Values) (int, string) {
+		t.Helper()
+		sender := &fakeResourceSender{}
+		err := handler.CallResource(context.Background(), &backend.CallResourceRequest{
+			PluginContext: pluginCtx,
+			Path:          path,
+			Method:        method,
+			URL:           path + "?" + query.Encode(),
+		}, sender)
+		require.NoError(t, err)
+		require.Len(t, sender.responses, 1)
+		return sender.responses[0].Status, string(sender.responses[0].Body)
+	}
+	lastRequest := func() *http.Request {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests[len(requests)-1]
+	}
+	requestCount := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(requests)
+	}
+
+	t.Run("metrics find", func(t *testing.T) {
+		status, body := call(t, http.MethodGet, "metrics/find", url.Values{"query": {"prod.servers.*"}, "from": {"-1h"}, "until": {"now"}})
+		require.Equal(t, http.StatusOK, status)
+		require.JSONEq(t, `[
+			{"text":"001","id":"prod.servers.001","expandable":true,"leaf":false},
+			{"text":"cpu","id":"prod.servers.cpu","expandable":false,"leaf":true}
+		]`, body)
+
+		req := lastRequest()
+		require.Equal(t, http.MethodPost, req.Method)
+		require.Equal(t, "prod.servers.*", req.PostForm.Get("query"))
+		require.Equal(t, "-1h", req.PostForm.Get("from"))
+		require.Equal(t, "now", req.PostForm.Get("until"))
+	})
+
+	t.Run("responses are cached", func(t *testing.T) {
+		before := requestCount()
+		status, _ := call(t, http.MethodGet, "metrics/find", url.Values{"query": {"prod.servers.*"}, "from": {"-1h"}, "until": {"now"}})
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, before, requestCount())
+	})
+
+	t.Run("metrics find without query", func(t *testing.T) {
+		status, body := call(t, http.MethodGet, "metrics/find", url.Values{})
+		require.Equal(t, http.StatusBadRequest, status)
+		require.JSONEq(t, `{"message":"missing query parameter"}`, body)
+	})
+
+	t.Run("tags autocomplete", func(t *testing.T) {
+		status, body := call(t, http.MethodGet, "tags/autoComplete/tags", url.Values{"expr": {"name=cpu", "dc=eu-1"}, "tagPrefix": {"h"}, "unknown": {"1"}})
+		require.Equal(t, http.StatusOK, status)
+		require.JSONEq(t, `["dc","host"]`, body)
+
+		req := lastRequest()
+		require.Equal(t, http.MethodGet, req.Method)
+		require.Equal(t, []string{"name=cpu", "dc=eu-1"}, req.Form["expr"])
+		require.Equal(t, "h", req.Form.Get("tagPrefix"))
+		require.Empty(t, req.Form.Get("unknown"))
+	})
+
+	t.Run("tag values autocomplete", func(t *testing.T) {
+		status, body := call(t, http.MethodGet, "tags/autoComplete/values", url.Values{"tag": {"dc"}, "limit": {"10"}})
+		require.Equal(t, http.StatusOK, status)
+		require.JSONEq(t, `["eu-1","us-1"]`, body)
+		require.Equal(t, "10", lastRequest().Form.Get("limit"))
+
+		status, _ = call(t, http.MethodGet, "tags/autoComplete/values", url.Values{})
+		require.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("functions with infinity defaults", func(t *testing.T) {
+		status, body := call(t, http.MethodGet, "functions", url.Values{})
+		require.Equal(t, http.StatusOK, status)
+		require.Contains(t, body, `"default":1e9999`)
+	})
+
+	t.Run("graphite error", func(t *testing.T) {
+		status, body := call(t, http.MethodGet, "metrics/find", url.Values{"query": {"broken.*"}})
+		require.Equal(t, http.StatusBadGateway, status)
+		require.Contains(t, body, "request failed")
+	})
+}
+
+type fakeResourceSender struct {
+	responses []*backend.CallResourceResponse
+}
+
+func (s *fakeResourceSender) Send(res *backend.CallResourceResponse) error {
+	s.responses = append(s.responses, res)
+	return nil
+}
@@ -37,6 +37,11 @@ func Query(ctx context.Context, dsInfo *models.DatasourceInfo, tsdbQuery backend
 		// If the default changes also update labels/placeholder in config page.
 		maxSeries := dsInfo.MaxSeries
 		res := executeQuery(ctx, *qm, r, maxSeries)
+		if qm.Stream && res.Error == nil {
+			if err := registerStream(dsInfo, tsdbQuery.PluginContext, *qm, &res); err != nil {
+				res.Error = err
+			}
+		}
 
 		tRes.Responses[query.RefID] = res
 	}
@@ -21,6 +21,10 @@ type queryModel struct {
 	RawQuery string       `json:"query"`
 	Options  queryOptions `json:"options"`
 
+	// Stream enables streaming of new rows over Grafana Live
+	Stream         bool   `json:"stream,omitempty"`
+	StreamInterval string `json:"streamInterval,omitempty"`
+
 	// Not from JSON
 	TimeRange     backend.TimeRange `json:"-"`
 	MaxDataPoints int64             `json:"-"`
@@ -0,0 +1,269 @@
+package flux
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/live"
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
+)
+
+const (
+	// streamPathPrefix is the prefix of Live channel paths of Flux query streams.
+	streamPathPrefix = "flux/"
+
+	defaultStreamInterval = 5 * time.Second
+	minStreamInterval     = time.Second
+	// StreamQueryTTL is how long a stream query is kept after the last panel query registering it.
+	StreamQueryTTL = time.Hour
+)
+
+// streamQuery is a Flux query registered for a Live stream. Channel paths can't
+// hold the query, so queries are kept in the data source instance and looked up
+// by the path when a stream is started.
+type streamQuery struct {
+	OrgID    int64
+	Query    queryModel
+	Interval time.Duration
+	// Since is the time of the last row sent, only newer rows are streamed.
+	Since time.Time
+}
+
+// streamPath returns a Live channel path of the query. The path is the same for
+// equal queries, so panels showing the same query share the stream.
+func streamPath(orgID int64, query queryModel, interval time.Duration) (string, error) {
+	b, err := json.Marshal(query)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "%d\n%s\n%s\n", orgID, interval, b)
+	return streamPathPrefix + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// getStreamInterval returns interval of stream query re-execution.
+func getStreamInterval(query queryModel) (time.Duration, error) {
+	if query.StreamInterval == "" {
+		return defaultStreamInterval, nil
+	}
+	interval, err := time.ParseDuration(query.StreamInterval)
+	if err != nil {
+		return 0, fmt.Errorf("invalid stream interval %q: %w", query.StreamInterval, err)
+	}
+	if interval < minStreamInterval {
+		interval = minStreamInterval
+	}
+	return interval, nil
+}
+
+// registerStream registers the query for streaming and sets the Live channel of
+// the stream to the first frame of the response.
+func registerStream(dsInfo *models.DatasourceInfo, pluginCtx backend.PluginContext, query queryModel, res *backend.DataResponse) error {
+	if dsInfo.StreamQueries == nil || pluginCtx.DataSourceInstanceSettings == nil {
+		return fmt.Errorf("streaming is not supported by the data source")
+	}
+	interval, err := getStreamInterval(query)
+	if err != nil {
+		return err
+	}
+	path, err := streamPath(pluginCtx.OrgID, query, interval)
+	if err != nil {
+		return err
+	}
+
+	// A stream continues from the end of the last query registering it.
+	sq := &streamQuery{
+		OrgID:    pluginCtx.OrgID,
+		Query:    query,
+		Interval: interval,
+		Since:    query.TimeRange.To,
+	}
+	dsInfo.StreamQueries.Set(path, sq, StreamQueryTTL)
+
+	channel := live.Channel{
+		Scope:     live.ScopeDatasource,
+		Namespace: pluginCtx.DataSourceInstanceSettings.UID,
+		Path:      path,
+	}
+	if len(res.Frames) == 0 {
+		// The panel needs a frame to learn the channel even when there's no data yet.
+		res.Frames = append(res.Frames, data.NewFrame(""))
+	}
+	if res.Frames[0].Meta == nil {
+		res.Frames[0].Meta = &data.FrameMeta{}
+	}
+	res.Frames[0].Meta.Channel = channel.String()
+	return nil
+}
+
+func getStreamQuery(dsInfo *models.DatasourceInfo, orgID int64, path string) (*streamQuery, bool) {
+	if dsInfo.StreamQueries == nil || !strings.HasPrefix(path, streamPathPrefix) {
+		return nil, false
+	}
+	v, ok := dsInfo.StreamQueries.Get(path)
+	if !ok {
+		return nil, false
+	}
+	sq := v.(*streamQuery)
+	if sq.OrgID != orgID {
+		return nil, false
+	}
+	return sq, true
+}
+
+// SubscribeStream allows subscriptions to streams of registered queries.
+func SubscribeStream(dsInfo *models.DatasourceInfo, req *backend.SubscribeStreamRequest) *backend.SubscribeStreamResponse {
+	if _, ok := getStreamQuery(dsInfo, req.PluginContext.OrgID, req.Path); !ok {
+		return &backend.SubscribeStreamResponse{Status: backend.SubscribeStreamStatusNotFound}
+	}
+	return &backend.SubscribeStreamResponse{Status: backend.SubscribeStreamStatusOK}
+}
+
+// RunStream re-executes the registered query of the stream at the stream
+// interval, and sends rows newer than the last sent row.
+func RunStream(ctx context.Context, dsInfo *models.DatasourceInfo, req *backend.RunStreamRequest, sender *backend.StreamSender) error {
+	sq, ok := getStreamQuery(dsInfo, req.PluginContext.OrgID, req.Path)
+	if !ok {
+		return fmt.Errorf("unknown stream %q", req.Path)
+	}
+
+	r, err := runnerFromDataSource(dsInfo)
+	if err != nil {
+		return err
+	}
+	defer r.client.Close()
+
+	glog.Debug("Starting Flux stream", "path", req.Path, "interval", sq.Interval)
+	send := func(frame *data.Frame) error {
+		// Frames of a stream may differ in labels and fields, so schema is sent with data.
+		return sender.SendFrame(frame, data.IncludeAll)
+	}
+	return runStream(ctx, *sq, r, dsInfo.MaxSeries, send, time.Now)
+}
+
+// runStream executes the query at every interval and sends rows which are new
+// since the previous execution. New rows are tracked per series, as series can
+// lag behind each other, and the query starts at the latest row of the series
+// lagging the most.
+func runStream(ctx context.Context, sq streamQuery, r queryRunner, maxSeries int, send func(*data.Frame) error, now func() time.Time) error {
+	ticker := time.NewTicker(sq.Interval)
+	defer ticker.Stop()
+
+	from := sq.Since
+	latest := map[string]time.Time{}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		query := sq.Query
+		query.TimeRange = backend.TimeRange{From: from, To: now()}
+		if !query.TimeRange.To.After(from) {
+			continue
+		}
+
+		res := executeQuery(ctx, query, r, maxSeries)
+		if res.Error != nil {
+			// The query is retried at the next tick, errors are often transient.
+			glog.Warn("Flux stream query failed", "err", res.Error)
+			continue
+		}
+
+		// Series missing in the result have no rows since their latest row,
+		// so they are forgotten.
+		seriesLatest := make(map[string]time.Time, len(res.Frames))
+		for _, frame := range res.Frames {
+			key := seriesKey(frame)
+			since, ok := latest[key]
+			if !ok {
+				since = from
+			}
+			newFrame, last, err := rowsAfter(frame, since)
+			if err != nil {
+				return err
+			}
+			if prev, ok := seriesLatest[key]; !ok || last.After(prev) {
+				seriesLatest[key] = last
+			}
+			if newFrame == nil || newFrame.Rows() == 0 {
+				continue
+			}
+			if err := send(newFrame); err != nil {
+				return err
+			}
+		}
+		if len(seriesLatest) > 0 {
+			latest = seriesLatest
+			from = time.Time{}
+			for _, t := range latest {
+				if from.IsZero() || t.Before(from) {
+					from = t
+				}
+			}
+		}
+	}
+}
+
+// seriesKey identifies a series of the frame by the frame name and names and
+// labels of its fields.
+func seriesKey(frame *data.Frame) string {
+	var sb strings.Builder
+	sb.WriteString(frame.Name)
+	for _, field := range frame.Fields {
+		sb.WriteString("\n")
+		sb.WriteString(field.Name)
+		sb.WriteString(field.Labels.String())
+	}
+	return sb.String()
+}
+
+// rowsAfter returns rows of the frame with time after since, and the time of
+// the latest row. Frames without a time field are skipped.
+func rowsAfter(frame *data.Frame, since time.Time) (*data.Frame, time.Time, error) {
+	timeIdx := -1
+	for i, field := range frame.Fields {
+		if t := field.Type(); t == data.FieldTypeTime || t == data.FieldTypeNullableTime {
+			timeIdx = i
+			break
+		}
+	}
+	if timeIdx == -1 {
+		return nil, since, nil
+	}
+
+	latest := since
+	filtered, err := frame.FilterRowsByField(timeIdx, func(v interface{}) (bool, error) {
+		var t time.Time
+		switch v := v.(type) {
+		case time.Time:
+			t = v
+		case *time.Time:
+			if v == nil {
+				return false, nil
+			}
+			t = *v
+		}
+		if !t.After(since) {
+			return false, nil
+		}
+		if t.After(latest) {
+			latest = t
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, since, err
+	}
+	// Metadata of the executed query is not relevant to stream updates.
+	filtered.Meta = nil
+	return filtered, latest, nil
+}
@@ -0,0 +1,185 @@
+package flux
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
+	"github.com/influxdata/influxdb-client-go/v2/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterStream(t *testing.T) {
+	dsInfo := &models.DatasourceInfo{StreamQueries: localcache.New(StreamQueryTTL, time.Minute)}
+	pluginCtx := backend.PluginContext{
+		OrgID:                      1,
+		DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{UID: "influx"},
+	}
+	to := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	query := queryModel{
+		RawQuery:  `from(bucket: "test") |> range(start: v.timeRangeStart, stop: v.timeRangeStop)`,
+		Stream:    true,
+		TimeRange: backend.TimeRange{From: to.Add(-time.Hour), To: to},
+	}
+	newResponse := func() *backend.DataResponse {
+		return &backend.DataResponse{Frames: data.Frames{data.NewFrame("").SetMeta(&data.FrameMeta{})}}
+	}
+
+	res := newResponse()
+	require.NoError(t, registerStream(dsInfo, pluginCtx, query, res))
+	channel := res.Frames[0].Meta.Channel
+	require.True(t, strings.HasPrefix(channel, "ds/influx/flux/"))
+	path := strings.TrimPrefix(channel, "ds/influx/")
+
+	t.Run("same query shares the stream", func(t *testing.T) {
+		res := newResponse()
+		require.NoError(t, registerStream(dsInfo, pluginCtx, query, res))
+		require.Equal(t, channel, res.Frames[0].Meta.Channel)
+
+		other := query
+		other.RawQuery = `from(bucket: "other")`
+		res = newResponse()
+		require.NoError(t, registerStream(dsInfo, pluginCtx, other, res))
+		require.NotEqual(t, channel, res.Frames[0].Meta.Channel)
+	})
+
+	t.Run("registered query", func(t *testing.T) {
+		sq, ok := getStreamQuery(dsInfo, 1, path)
+		require.True(t, ok)
+		require.Equal(t, defaultStreamInterval, sq.Interval)
+		require.Equal(t, to, sq.Since)
+		require.Equal(t, query.RawQuery, sq.Query.RawQuery)
+	})
+
+	t.Run("subscribe", func(t *testing.T) {
+		res := SubscribeStream(dsInfo, &backend.SubscribeStreamRequest{PluginContext: pluginCtx, Path: path})
+		require.Equal(t, backend.SubscribeStreamStatusOK, res.Status)
+
+		res = SubscribeStream(dsInfo, &backend.SubscribeStreamRequest{PluginContext: backend.PluginContext{OrgID: 2}, Path: path})
+		require.Equal(t, backend.SubscribeStreamStatusNotFound, res.Status)
+
+		res = SubscribeStream(dsInfo, &backend.SubscribeStreamRequest{PluginContext: pluginCtx, Path: "flux/unknown"})
+		require.Equal(t, backend.SubscribeStreamStatusNotFound, res.Status)
+	})
+
+	t.Run("stream interval", func(t *testing.T) {
+		interval, err := getStreamInterval(queryModel{StreamInterval: "10s"})
+		require.NoError(t, err)
+		require.Equal(t, 10*time.Second, interval)
+
+		interval, err = getStreamInterval(queryModel{StreamInterval: "10ms"})
+		require.NoError(t, err)
+		require.Equal(t, minStreamInterval, interval)
+
+		_, err = getStreamInterval(queryModel{StreamInterval: "often"})
+		require.Error(t, err)
+	})
+}
+
+func TestRunStream(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runner := &countingRunner{
+		runner: &MockRunner{testDataPath: "simple.csv"},
+		onRun: func(calls int) {
+			if calls == 3 {
+				cancel()
+			}
+		},
+	}
+	var frames []*data.Frame
+	send := func(frame *data.Frame) error {
+		frames = append(frames, frame)
+		return nil
+	}
+	now := time.Date(2020, 2, 19, 0, 0, 0, 0, time.UTC)
+	sq := streamQuery{
+		Query:    queryModel{RawQuery: "v.timeRangeStart v.timeRangeStop", MaxDataPoints: 100},
+		Interval: 10 * time.Millisecond,
+		// Between the two rows of simple.csv
+		Since: time.Date(2020, 2, 18, 12, 0, 0, 0, time.UTC),
+	}
+
+	err := runStream(ctx, sq, runner, 50, send, func() time.Time { return now })
+	require.ErrorIs(t, err, context.Canceled)
+
+	require.Equal(t, sq.Since, runner.queries[0].From)
+	require.Equal(t, now, runner.queries[0].To)
+	require.Equal(t, time.Date(2020, 2, 18, 22, 8, 44, 850214724, time.UTC), runner.queries[1].From)
+
+	// Only the new row is sent, later executions have no new rows.
+	require.Len(t, frames, 1)
+	require.Equal(t, 1, frames[0].Rows())
+	require.Equal(t, 6.6, *frames[0].Fields[1].At(0).(*float64))
+}
+
+func TestRunStream_LaggingSeries(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mock := &MockRunner{testDataPath: "stream_lagging_1.csv"}
+	runner := &countingRunner{
+		runner: mock,
+		onRun: func(calls int) {
+			// The series a=2 gets a row older than the latest row of a=1.
+			mock.testDataPath = "stream_lagging_2.csv"
+			if calls == 3 {
+				cancel()
+			}
+		},
+	}
+	var frames []*data.Frame
+	send := func(frame *data.Frame) error {
+		frames = append(frames, frame)
+		return nil
+	}
+	now := time.Date(2020, 2, 19, 0, 0, 0, 0, time.UTC)
+	sq := streamQuery{
+		Query:    queryModel{RawQuery: "v.timeRangeStart v.timeRangeStop", MaxDataPoints: 100},
+		Interval: 10 * time.Millisecond,
+		Since:    time.Date(2020, 2, 18, 9, 0, 0, 0, time.UTC),
+	}
+
+	err := runStream(ctx, sq, runner, 50, send, func() time.Time { return now })
+	require.ErrorIs(t, err, context.Canceled)
+
+	// Queries start at the latest row of the lagging series.
+	require.Equal(t, sq.Since, runner.queries[0].From)
+	require.Equal(t, time.Date(2020, 2, 18, 11, 0, 0, 0, time.UTC), runner.queries[1].From)
+	require.Equal(t, time.Date(2020, 2, 18, 11, 30, 0, 0, time.UTC), runner.queries[2].From)
+
+	require.Len(t, frames, 3)
+	require.Equal(t, 2, frames[0].Rows())
+	require.Equal(t, 1, frames[1].Rows())
+	require.Equal(t, 1, frames[2].Rows())
+	require.Equal(t, "2", frames[2].Fields[1].Labels["a"])
+	require.Equal(t, 4.0, *frames[2].Fields[1].At(0).(*float64))
+}
+
+// countingRunner records time ranges of executed queries.
+type countingRunner struct {
+	runner  queryRunner
+	onRun   func(calls int)
+	queries []backend.TimeRange
+}
+
+func (r *countingRunner) runQuery(ctx context.Context, q string) (*api.QueryTableResult, error) {
+	r.queries = append(r.queries, parseTimeRange(q))
+	res, err := r.runner.runQuery(ctx, q)
+	r.onRun(len(r.queries))
+	return res, err
+}
+
+// parseTimeRange reads the time range of the interpolated stream test query.
+func parseTimeRange(q string) backend.TimeRange {
+	parts := strings.Split(q, " ")
+	from, _ := time.Parse(time.RFC3339Nano, parts[0])
+	to, _ := time.Parse(time.RFC3339Nano, parts[1])
+	return backend.TimeRange{From: from, To: to}
+}
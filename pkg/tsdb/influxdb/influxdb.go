@@ -9,11 +9,13 @@ import (
 	"net/url"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
 	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin/coreplugin"
@@ -43,6 +45,7 @@ func ProvideService(httpClient httpclient.Provider, backendPluginManager backend
 
 	factory := coreplugin.New(backend.ServeOpts{
 		QueryDataHandler: s,
+		StreamHandler:    s,
 	})
 
 	if err := backendPluginManager.Register("influxdb", factory); err != nil {
@@ -89,6 +92,7 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			Organization:  jsonData.Organization,
 			MaxSeries:     maxSeries,
 			Token:         settings.DecryptedSecureJSONData["token"],
+			StreamQueries: localcache.New(flux.StreamQueryTTL, 10*time.Minute),
 		}
 		return model, nil
 	}
@@ -2,6 +2,8 @@ package models
 
 import (
 	"net/http"
+
+	"github.com/grafana/grafana/pkg/infra/localcache"
 )
 
 type DatasourceInfo struct {
@@ -16,4 +18,9 @@ type DatasourceInfo struct {
 	DefaultBucket string `json:"defaultBucket"`
 	Organization  string `json:"organization"`
 	MaxSeries     int    `json:"maxSeries"`
+
+	// StreamQueries holds Flux queries of Live streams by channel path. The
+	// queries are kept in memory of this Grafana server only, so in a HA setup
+	// a stream can only be started on the server which ran the initial query.
+	StreamQueries *localcache.CacheService `json:"-"`
 }
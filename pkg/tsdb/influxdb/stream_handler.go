@@ -0,0 +1,38 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/flux"
+)
+
+// SubscribeStream allows subscriptions to streams of Flux queries, streams
+// are registered by panel queries with streaming enabled.
+func (s *Service) SubscribeStream(_ context.Context, req *backend.SubscribeStreamRequest) (*backend.SubscribeStreamResponse, error) {
+	dsInfo, err := s.getDSInfo(req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+	if dsInfo.Version != "Flux" {
+		return &backend.SubscribeStreamResponse{Status: backend.SubscribeStreamStatusNotFound}, nil
+	}
+	return flux.SubscribeStream(dsInfo, req), nil
+}
+
+// PublishStream denies publishing, streams only carry query results.
+func (s *Service) PublishStream(_ context.Context, _ *backend.PublishStreamRequest) (*backend.PublishStreamResponse, error) {
+	return &backend.PublishStreamResponse{Status: backend.PublishStreamStatusPermissionDenied}, nil
+}
+
+func (s *Service) RunStream(ctx context.Context, req *backend.RunStreamRequest, sender *backend.StreamSender) error {
+	dsInfo, err := s.getDSInfo(req.PluginContext)
+	if err != nil {
+		return err
+	}
+	if dsInfo.Version != "Flux" {
+		return fmt.Errorf("streaming is only supported for Flux queries")
+	}
+	return flux.RunStream(ctx, dsInfo, req, sender)
+}
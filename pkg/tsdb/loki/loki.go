@@ -5,8 +5,10 @@ import (
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
 	"net/http"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -58,6 +60,10 @@ var (
 	legendFormat = regexp.MustCompile(`\{\{\s*(.+?)\s*\}\}`)
 )
 
+// defaultMaxLines is a line limit for log queries used when neither
+// query nor datasource settings specify one.
+const defaultMaxLines = 1000
+
 type datasourceInfo struct {
 	HTTPClient        *http.Client
 	URL               string
@@ -65,6 +71,7 @@ type datasourceInfo struct {
 	BasicAuthUser     string
 	BasicAuthPassword string
 	TimeInterval      string `json:"timeInterval"`
+	MaxLines          string `json:"maxLines"`
 }
 
 type ResponseModel struct {
@@ -73,6 +80,8 @@ type ResponseModel struct {
 	Interval     string `json:"interval"`
 	IntervalMS   int    `json:"intervalMS"`
 	Resolution   int64  `json:"resolution"`
+	MaxLines     int    `json:"maxLines"`
+	Instant      bool   `json:"instant"`
 }
 
 func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.InstanceFactoryFunc {
@@ -103,6 +112,7 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			URL:               settings.URL,
 			TLSClientConfig:   tlsClientConfig,
 			TimeInterval:      jsonData.TimeInterval,
+			MaxLines:          jsonData.MaxLines,
 			BasicAuthUser:     settings.BasicAuthUser,
 			BasicAuthPassword: settings.DecryptedSecureJSONData["basicAuthPassword"],
 		}
@@ -144,12 +154,17 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		span.SetTag("stop_unixnano", query.End.UnixNano())
 		defer span.Finish()
 
-		//Currently hard coded as not used - applies to log queries
-		limit := 1000
-		//Currently hard coded as not used - applies to queries which produce a stream response
-		interval := time.Second * 1
-
-		value, err := client.QueryRange(query.Expr, limit, query.Start, query.End, logproto.BACKWARD, query.Step, interval, false)
+		// One line over the limit is requested to find out whether the result
+		// was actually limited, the extra line is cut in parseStreams.
+		limit := query.MaxLines + 1
+		var value *loghttp.QueryResponse
+		if query.Instant {
+			value, err = client.Query(query.Expr, limit, query.End, logproto.BACKWARD, false)
+		} else {
+			//Currently hard coded as not used - applies to queries which produce a stream response
+			interval := time.Second * 1
+			value, err = client.QueryRange(query.Expr, limit, query.Start, query.End, logproto.BACKWARD, query.Step, interval, false)
+		}
 		if err != nil {
 			return result, err
 		}
@@ -209,6 +224,11 @@ func (s *Service) parseQuery(dsInfo *datasourceInfo, queryContext *backend.Query
 
 		step := time.Duration(int64(interval.Value) * resolution)
 
+		maxLines, err := getMaxLines(dsInfo, model)
+		if err != nil {
+			return nil, err
+		}
+
 		qs = append(qs, &lokiQuery{
 			Expr:         model.Expr,
 			Step:         step,
@@ -216,20 +236,47 @@ func (s *Service) parseQuery(dsInfo *datasourceInfo, queryContext *backend.Query
 			Start:        start,
 			End:          end,
 			RefID:        query.RefID,
+			MaxLines:     maxLines,
+			Instant:      model.Instant,
 		})
 	}
 
 	return qs, nil
 }
 
-func parseResponse(value *loghttp.QueryResponse, query *lokiQuery) (data.Frames, error) {
-	frames := data.Frames{}
+// getMaxLines returns a line limit for a query. Limit set in query has
+// precedence over the one configured in datasource settings.
+func getMaxLines(dsInfo *datasourceInfo, model *ResponseModel) (int, error) {
+	if model.MaxLines > 0 {
+		return model.MaxLines, nil
+	}
+	if dsInfo.MaxLines != "" {
+		maxLines, err := strconv.Atoi(dsInfo.MaxLines)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse maxLines: %w", err)
+		}
+		if maxLines > 0 {
+			return maxLines, nil
+		}
+	}
+	return defaultMaxLines, nil
+}
 
-	//We are currently processing only matrix results (for alerting)
-	matrix, ok := value.Data.Result.(loghttp.Matrix)
-	if !ok {
-		return frames, fmt.Errorf("unsupported result format: %q", value.Data.ResultType)
+func parseResponse(value *loghttp.QueryResponse, query *lokiQuery) (data.Frames, error) {
+	switch result := value.Data.Result.(type) {
+	case loghttp.Matrix:
+		return parseMatrix(result, query), nil
+	case loghttp.Vector:
+		return parseVector(result, query), nil
+	case loghttp.Streams:
+		return parseStreams(result, query), nil
+	default:
+		return data.Frames{}, fmt.Errorf("unsupported result format: %q", value.Data.ResultType)
 	}
+}
+
+func parseMatrix(matrix loghttp.Matrix, query *lokiQuery) data.Frames {
+	frames := data.Frames{}
 
 	for _, v := range matrix {
 		name := formatLegend(v.Metric, query)
@@ -251,7 +298,94 @@ func parseResponse(value *loghttp.QueryResponse, query *lokiQuery) (data.Frames,
 			data.NewField("value", tags, values).SetConfig(&data.FieldConfig{DisplayNameFromDS: name})))
 	}
 
-	return frames, nil
+	return frames
+}
+
+func parseVector(vector loghttp.Vector, query *lokiQuery) data.Frames {
+	frames := data.Frames{}
+
+	for _, v := range vector {
+		name := formatLegend(v.Metric, query)
+		tags := make(map[string]string, len(v.Metric))
+		for k, v := range v.Metric {
+			tags[string(k)] = string(v)
+		}
+
+		frames = append(frames, data.NewFrame(name,
+			data.NewField("time", nil, []time.Time{v.Timestamp.Time().UTC()}),
+			data.NewField("value", tags, []float64{float64(v.Value)}).SetConfig(&data.FieldConfig{DisplayNameFromDS: name})))
+	}
+
+	return frames
+}
+
+// parseStreams converts log streams to log frames, one frame per stream.
+// Total number of lines in result is cut at query line limit, a notice is
+// added when some lines were dropped.
+func parseStreams(streams loghttp.Streams, query *lokiQuery) data.Frames {
+	frames := data.Frames{}
+	remaining := query.MaxLines
+	limited := false
+
+	for _, stream := range streams {
+		if remaining <= 0 {
+			limited = limited || len(stream.Entries) > 0
+			continue
+		}
+		entries := stream.Entries
+		if len(entries) > remaining {
+			entries = entries[:remaining]
+			limited = true
+		}
+		remaining -= len(entries)
+
+		labels := data.Labels(stream.Labels.Map())
+		labelsString := stream.Labels.String()
+		timeVector := make([]time.Time, 0, len(entries))
+		lines := make([]string, 0, len(entries))
+		ids := make([]string, 0, len(entries))
+		usedIDs := make(map[string]int, len(entries))
+
+		for _, entry := range entries {
+			timeVector = append(timeVector, entry.Timestamp.UTC())
+			lines = append(lines, entry.Line)
+			ids = append(ids, entryID(entry, labelsString, usedIDs))
+		}
+
+		frame := data.NewFrame(labelsString,
+			data.NewField("ts", nil, timeVector),
+			data.NewField("line", labels, lines),
+			data.NewField("id", nil, ids),
+		)
+		frame.RefID = query.RefID
+		frame.Meta = &data.FrameMeta{
+			PreferredVisualization: data.VisTypeLogs,
+		}
+		frames = append(frames, frame)
+	}
+
+	if limited && len(frames) > 0 {
+		frames[len(frames)-1].AppendNotices(data.Notice{
+			Severity: data.NoticeSeverityInfo,
+			Text:     fmt.Sprintf("Result was limited to %d lines", query.MaxLines),
+		})
+	}
+
+	return frames
+}
+
+// entryID generates an ID of a log entry from its timestamp, stream labels and
+// line. Duplicate IDs within a stream get a numeric suffix.
+func entryID(entry loghttp.Entry, labelsString string, usedIDs map[string]int) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(fmt.Sprintf("%d_%s_%s", entry.Timestamp.UnixNano(), labelsString, entry.Line)))
+	id := strconv.FormatUint(h.Sum64(), 16)
+	n := usedIDs[id]
+	usedIDs[id] = n + 1
+	if n > 0 {
+		return id + "_" + strconv.Itoa(n)
+	}
+	return id
 }
 
 func (s *Service) getDSInfo(pluginCtx backend.PluginContext) (*datasourceInfo, error) {
@@ -118,11 +118,12 @@ func TestLoki(t *testing.T) {
 }
 
 func TestParseResponse(t *testing.T) {
-	t.Run("value is not of type matrix", func(t *testing.T) {
+	t.Run("value is of unsupported type", func(t *testing.T) {
 		queryRes := data.Frames{}
 		value := loghttp.QueryResponse{
 			Data: loghttp.QueryResponseData{
-				Result: loghttp.Vector{},
+				ResultType: loghttp.ResultTypeScalar,
+				Result:     loghttp.Scalar{},
 			},
 		}
 		res, err := parseResponse(&value, nil)
@@ -174,6 +175,125 @@ func TestParseResponse(t *testing.T) {
 	})
 }
 
+func TestParseResponse_Vector(t *testing.T) {
+	value := loghttp.QueryResponse{
+		Data: loghttp.QueryResponseData{
+			Result: loghttp.Vector{
+				p.Sample{
+					Metric:    p.Metric{"app": "Application"},
+					Value:     42,
+					Timestamp: 1500,
+				},
+			},
+		},
+	}
+
+	frames, err := parseResponse(&value, &lokiQuery{LegendFormat: "{{app}}"})
+	require.NoError(t, err)
+	require.Len(t, frames, 1)
+
+	field1 := data.NewField("time", nil, []time.Time{time.Date(1970, 1, 1, 0, 0, 1, 500*int(time.Millisecond), time.UTC)})
+	field2 := data.NewField("value", data.Labels{"app": "Application"}, []float64{42})
+	field2.SetConfig(&data.FieldConfig{DisplayNameFromDS: "Application"})
+	testFrame := data.NewFrame("Application", field1, field2)
+
+	if diff := cmp.Diff(testFrame, frames[0], data.FrameTestCompareOptions()...); diff != "" {
+		t.Errorf("Result mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseResponse_Streams(t *testing.T) {
+	streams := loghttp.Streams{
+		{
+			Labels: loghttp.LabelSet{"app": "backend"},
+			Entries: []loghttp.Entry{
+				{Timestamp: time.Unix(0, 3000000000), Line: "line 3"},
+				{Timestamp: time.Unix(0, 2000000000), Line: "line 2"},
+				{Timestamp: time.Unix(0, 2000000000), Line: "line 2"},
+			},
+		},
+		{
+			Labels: loghttp.LabelSet{"app": "frontend"},
+			Entries: []loghttp.Entry{
+				{Timestamp: time.Unix(0, 1000000000), Line: "line 1"},
+			},
+		},
+	}
+	value := loghttp.QueryResponse{
+		Data: loghttp.QueryResponseData{
+			ResultType: loghttp.ResultTypeStream,
+			Result:     streams,
+		},
+	}
+
+	t.Run("streams are converted to log frames", func(t *testing.T) {
+		frames, err := parseResponse(&value, &lokiQuery{RefID: "A", MaxLines: 1000})
+		require.NoError(t, err)
+		require.Len(t, frames, 2)
+
+		frame := frames[0]
+		require.Equal(t, "A", frame.RefID)
+		require.Equal(t, data.VisTypeLogs, string(frame.Meta.PreferredVisualization))
+		require.Len(t, frame.Fields, 3)
+		require.Equal(t, "ts", frame.Fields[0].Name)
+		require.Equal(t, time.Unix(3, 0).UTC(), frame.Fields[0].At(0))
+		require.Equal(t, "line", frame.Fields[1].Name)
+		require.Equal(t, data.Labels{"app": "backend"}, frame.Fields[1].Labels)
+		require.Equal(t, "line 3", frame.Fields[1].At(0))
+		require.Equal(t, "id", frame.Fields[2].Name)
+
+		// Duplicate entries get unique ids.
+		id1 := frame.Fields[2].At(1).(string)
+		id2 := frame.Fields[2].At(2).(string)
+		require.NotEqual(t, id1, id2)
+		require.Equal(t, id1+"_1", id2)
+		require.Empty(t, frame.Meta.Notices)
+	})
+
+	t.Run("lines are cut at line limit", func(t *testing.T) {
+		frames, err := parseResponse(&value, &lokiQuery{RefID: "A", MaxLines: 2})
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		n, err := frames[0].RowLen()
+		require.NoError(t, err)
+		require.Equal(t, 2, n)
+		require.Len(t, frames[0].Meta.Notices, 1)
+	})
+
+	t.Run("no notice when lines count equals line limit", func(t *testing.T) {
+		frames, err := parseResponse(&value, &lokiQuery{RefID: "A", MaxLines: 4})
+		require.NoError(t, err)
+		require.Len(t, frames, 2)
+		for _, frame := range frames {
+			require.Empty(t, frame.Meta.Notices)
+		}
+	})
+
+	t.Run("notice when whole streams are cut", func(t *testing.T) {
+		frames, err := parseResponse(&value, &lokiQuery{RefID: "A", MaxLines: 3})
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		require.Len(t, frames[0].Meta.Notices, 1)
+	})
+}
+
+func TestGetMaxLines(t *testing.T) {
+	maxLines, err := getMaxLines(&datasourceInfo{}, &ResponseModel{})
+	require.NoError(t, err)
+	require.Equal(t, defaultMaxLines, maxLines)
+
+	maxLines, err = getMaxLines(&datasourceInfo{MaxLines: "500"}, &ResponseModel{})
+	require.NoError(t, err)
+	require.Equal(t, 500, maxLines)
+
+	maxLines, err = getMaxLines(&datasourceInfo{MaxLines: "500"}, &ResponseModel{MaxLines: 20})
+	require.NoError(t, err)
+	require.Equal(t, 20, maxLines)
+
+	_, err = getMaxLines(&datasourceInfo{MaxLines: "many"}, &ResponseModel{})
+	require.Error(t, err)
+}
+
 type mockCalculator struct {
 	interval intervalv2.Interval
 }
@@ -5,13 +5,16 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"regexp"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
 	sdkhttpclient "github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
 	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin/coreplugin"
@@ -40,8 +43,11 @@ func ProvideService(httpClientProvider httpclient.Provider, backendPluginManager
 		im:                 im,
 	}
 
+	resourceMux := http.NewServeMux()
+	s.registerRoutes(resourceMux)
 	factory := coreplugin.New(backend.ServeOpts{
-		QueryDataHandler: s,
+		QueryDataHandler:    s,
+		CallResourceHandler: httpadapter.New(resourceMux),
 	})
 	if err := backendPluginManager.Register("prometheus", factory); err != nil {
 		plog.Error("Failed to register plugin", "error", err)
@@ -63,6 +69,11 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			return nil, fmt.Errorf("error getting http options: %w", err)
 		}
 
+		// Custom query parameters are passed to the middleware in HTTP client options.
+		if customQueryParams, ok := jsonData[customQueryParametersKey].(string); ok {
+			httpCliOpts.CustomOptions[customQueryParametersKey] = customQueryParams
+		}
+
 		// Set SigV4 service namespace
 		if httpCliOpts.SigV4 != nil {
 			httpCliOpts.SigV4.Service = "aps"
@@ -88,10 +99,11 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 		}
 
 		mdl := DatasourceInfo{
-			ID:           settings.ID,
-			URL:          settings.URL,
-			TimeInterval: timeInterval,
-			promClient:   client,
+			ID:            settings.ID,
+			URL:           settings.URL,
+			TimeInterval:  timeInterval,
+			promClient:    client,
+			resourceCache: localcache.New(resourceCacheTTL, 2*resourceCacheTTL),
 		}
 
 		return mdl, nil
@@ -0,0 +1,302 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+	apiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+)
+
+const (
+	// resourceCacheTTL is how long successful metadata responses are cached.
+	resourceCacheTTL = time.Minute
+	// resourceDefaultRange is used when a request has no start time.
+	resourceDefaultRange = time.Hour
+	// resourceMaxRange bounds time range of requests, older start times are moved forward.
+	resourceMaxRange = 7 * 24 * time.Hour
+	// resourceTimeRounding is used to round time range, so requests made a few
+	// moments apart share cached responses.
+	resourceTimeRounding = time.Minute
+	// resourceMaxLimit is the maximum number of items returned by a request.
+	resourceMaxLimit = 50000
+)
+
+// Route paths match Prometheus HTTP API, so the frontend can switch from the
+// data source proxy without changing response handling.
+func (s *Service) registerRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/api/v1/labels", s.resourceHandler(labelsResource))
+	mux.HandleFunc("/api/v1/label/", s.resourceHandler(labelValuesResource))
+	mux.HandleFunc("/api/v1/series", s.resourceHandler(seriesResource))
+	mux.HandleFunc("/api/v1/metadata", s.resourceHandler(metadataResource))
+}
+
+type resourceParams struct {
+	Label   string
+	Matches []string
+	Metric  string
+	Start   time.Time
+	End     time.Time
+	Limit   int
+}
+
+// resourceResult is a result of a resource call, Data is truncated to the limit.
+type resourceResult struct {
+	Data      interface{}
+	Warnings  []string
+	Truncated bool
+}
+
+type resourceFunc func(ctx context.Context, client apiv1.API, params resourceParams) (resourceResult, error)
+
+type resourceResponse struct {
+	Status    string      `json:"status"`
+	Data      interface{} `json:"data,omitempty"`
+	Warnings  []string    `json:"warnings,omitempty"`
+	ErrorType string      `json:"errorType,omitempty"`
+	Error     string      `json:"error,omitempty"`
+}
+
+func labelsResource(ctx context.Context, client apiv1.API, params resourceParams) (resourceResult, error) {
+	names, warnings, err := client.LabelNames(ctx, params.Matches, params.Start, params.End)
+	if err != nil {
+		return resourceResult{}, err
+	}
+	truncated := len(names) > params.Limit
+	if truncated {
+		names = names[:params.Limit]
+	}
+	return resourceResult{Data: names, Warnings: warnings, Truncated: truncated}, nil
+}
+
+func labelValuesResource(ctx context.Context, client apiv1.API, params resourceParams) (resourceResult, error) {
+	values, warnings, err := client.LabelValues(ctx, params.Label, params.Matches, params.Start, params.End)
+	if err != nil {
+		return resourceResult{}, err
+	}
+	truncated := len(values) > params.Limit
+	if truncated {
+		values = values[:params.Limit]
+	}
+	return resourceResult{Data: values, Warnings: warnings, Truncated: truncated}, nil
+}
+
+func seriesResource(ctx context.Context, client apiv1.API, params resourceParams) (resourceResult, error) {
+	series, warnings, err := client.Series(ctx, params.Matches, params.Start, params.End)
+	if err != nil {
+		return resourceResult{}, err
+	}
+	truncated := len(series) > params.Limit
+	if truncated {
+		series = series[:params.Limit]
+	}
+	return resourceResult{Data: series, Warnings: warnings, Truncated: truncated}, nil
+}
+
+func metadataResource(ctx context.Context, client apiv1.API, params resourceParams) (resourceResult, error) {
+	// Prometheus limits number of metrics in metadata response itself, one more
+	// is requested to find out whether the result was truncated.
+	metadata, err := client.Metadata(ctx, params.Metric, strconv.Itoa(params.Limit+1))
+	if err != nil {
+		return resourceResult{}, err
+	}
+	truncated := len(metadata) > params.Limit
+	if truncated {
+		metrics := make([]string, 0, len(metadata))
+		for metric := range metadata {
+			metrics = append(metrics, metric)
+		}
+		sort.Strings(metrics)
+		for _, metric := range metrics[params.Limit:] {
+			delete(metadata, metric)
+		}
+	}
+	return resourceResult{Data: metadata, Truncated: truncated}, nil
+}
+
+func (s *Service) resourceHandler(fn resourceFunc) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		plog.Debug("Received resource call", "url", req.URL.String(), "method", req.Method)
+
+		if req.Method != http.MethodGet && req.Method != http.MethodPost {
+			writeResourceError(rw, http.StatusMethodNotAllowed, "bad_data", "method not allowed")
+			return
+		}
+
+		dsInfo, err := s.getDSInfo(httpadapter.PluginConfigFromContext(req.Context()))
+		if err != nil {
+			writeResourceError(rw, http.StatusInternalServerError, "internal", fmt.Sprintf("unexpected error %v", err))
+			return
+		}
+
+		params, err := parseResourceParams(req, time.Now())
+		if err != nil {
+			writeResourceError(rw, http.StatusBadRequest, "bad_data", err.Error())
+			return
+		}
+
+		cacheKey := resourceCacheKey(req.URL.Path, params)
+		if dsInfo.resourceCache != nil {
+			if cached, ok := dsInfo.resourceCache.Get(cacheKey); ok {
+				writeResourceResponse(rw, http.StatusOK, cached.([]byte))
+				return
+			}
+		}
+
+		result, err := fn(req.Context(), dsInfo.promClient, params)
+		if err != nil {
+			var apiErr *apiv1.Error
+			if errors.As(err, &apiErr) {
+				writeResourceError(rw, http.StatusBadGateway, string(apiErr.Type), apiErr.Msg)
+				return
+			}
+			writeResourceError(rw, http.StatusBadGateway, "unavailable", err.Error())
+			return
+		}
+
+		warnings := result.Warnings
+		if result.Truncated {
+			warnings = append(warnings, fmt.Sprintf("Result was truncated to %d items", params.Limit))
+		}
+		body, err := json.Marshal(resourceResponse{Status: "success", Data: result.Data, Warnings: warnings})
+		if err != nil {
+			writeResourceError(rw, http.StatusInternalServerError, "internal", err.Error())
+			return
+		}
+		if dsInfo.resourceCache != nil {
+			dsInfo.resourceCache.Set(cacheKey, body, resourceCacheTTL)
+		}
+		writeResourceResponse(rw, http.StatusOK, body)
+	}
+}
+
+// parseResourceParams reads request parameters the same way Prometheus does. Time
+// range is bounded to resourceMaxRange and limit to resourceMaxLimit.
+func parseResourceParams(req *http.Request, now time.Time) (resourceParams, error) {
+	if err := req.ParseForm(); err != nil {
+		return resourceParams{}, err
+	}
+	form := req.Form
+
+	params := resourceParams{
+		Matches: form["match[]"],
+		Metric:  form.Get("metric"),
+		Limit:   resourceMaxLimit,
+	}
+
+	if strings.HasPrefix(req.URL.Path, "/api/v1/label/") {
+		label, err := parseLabelValuesPath(req.URL.Path)
+		if err != nil {
+			return resourceParams{}, err
+		}
+		params.Label = label
+	}
+	if strings.HasSuffix(req.URL.Path, "/series") && len(params.Matches) == 0 {
+		return resourceParams{}, errors.New("no match[] parameter provided")
+	}
+
+	var err error
+	params.End = now
+	if v := form.Get("end"); v != "" {
+		if params.End, err = parseTime(v); err != nil {
+			return resourceParams{}, fmt.Errorf("invalid parameter \"end\": %w", err)
+		}
+	}
+	params.Start = params.End.Add(-resourceDefaultRange)
+	if v := form.Get("start"); v != "" {
+		if params.Start, err = parseTime(v); err != nil {
+			return resourceParams{}, fmt.Errorf("invalid parameter \"start\": %w", err)
+		}
+	}
+	if params.End.Before(params.Start) {
+		return resourceParams{}, errors.New("end timestamp must not be before start time")
+	}
+	if params.End.Sub(params.Start) > resourceMaxRange {
+		params.Start = params.End.Add(-resourceMaxRange)
+	}
+	params.Start = params.Start.Truncate(resourceTimeRounding)
+	if rounded := params.End.Truncate(resourceTimeRounding); !rounded.Equal(params.End) {
+		params.End = rounded.Add(resourceTimeRounding)
+	}
+
+	if v := form.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 0 {
+			return resourceParams{}, fmt.Errorf("invalid parameter \"limit\": %q", v)
+		}
+		if limit > 0 && limit < resourceMaxLimit {
+			params.Limit = limit
+		}
+	}
+
+	return params, nil
+}
+
+// parseLabelValuesPath returns label name from /api/v1/label/<name>/values path.
+func parseLabelValuesPath(path string) (string, error) {
+	name := strings.TrimPrefix(path, "/api/v1/label/")
+	if !strings.HasSuffix(name, "/values") {
+		return "", fmt.Errorf("unknown resource path %q", path)
+	}
+	name = strings.TrimSuffix(name, "/values")
+	if !model.LabelName(name).IsValid() {
+		return "", fmt.Errorf("invalid label name: %q", name)
+	}
+	return name, nil
+}
+
+// parseTime parses Unix timestamp with optional decimal places or RFC3339 time.
+func parseTime(s string) (time.Time, error) {
+	if t, err := strconv.ParseFloat(s, 64); err == nil {
+		if math.IsNaN(t) || math.IsInf(t, 0) {
+			return time.Time{}, fmt.Errorf("cannot parse %q to a valid timestamp", s)
+		}
+		sec, frac := math.Modf(t)
+		return time.Unix(int64(sec), int64(math.Round(frac*1000))*int64(time.Millisecond)).UTC(), nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t.UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("cannot parse %q to a valid timestamp", s)
+}
+
+func resourceCacheKey(path string, params resourceParams) string {
+	matches := append([]string{}, params.Matches...)
+	sort.Strings(matches)
+	values := url.Values{
+		"match[]": matches,
+		"metric":  {params.Metric},
+		"start":   {strconv.FormatInt(params.Start.Unix(), 10)},
+		"end":     {strconv.FormatInt(params.End.Unix(), 10)},
+		"limit":   {strconv.Itoa(params.Limit)},
+	}
+	return path + "?" + values.Encode()
+}
+
+func writeResourceError(rw http.ResponseWriter, code int, errorType string, msg string) {
+	body, err := json.Marshal(resourceResponse{Status: "error", ErrorType: errorType, Error: msg})
+	if err != nil {
+		plog.Error("Failed to marshal resource error", "error", err)
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	writeResourceResponse(rw, code, body)
+}
+
+func writeResourceResponse(rw http.ResponseWriter, code int, body []byte) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(code)
+	if _, err := rw.Write(body); err != nil {
+		plog.Error("Failed to write resource response", "error", err)
+	}
+}
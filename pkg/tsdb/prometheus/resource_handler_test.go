@@ -0,0 +1,212 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseResourceParams(t *testing.T) {
+	reqTime := time.Date(2021, 6, 1, 12, 0, 30, 0, time.UTC)
+
+	parse := func(t *testing.T, path string, query url.Values) (resourceParams, error) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, path+"?"+query.Encode(), nil)
+		return parseResourceParams(req, reqTime)
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		params, err := parse(t, "/api/v1/labels", url.Values{})
+		require.NoError(t, err)
+		require.Equal(t, time.Date(2021, 6, 1, 11, 0, 0, 0, time.UTC), params.Start)
+		require.Equal(t, time.Date(2021, 6, 1, 12, 1, 0, 0, time.UTC), params.End)
+		require.Equal(t, resourceMaxLimit, params.Limit)
+	})
+
+	t.Run("time range is bounded", func(t *testing.T) {
+		params, err := parse(t, "/api/v1/labels", url.Values{
+			"start": {"0"},
+			"end":   {"2021-06-01T12:00:00Z"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, time.Date(2021, 5, 25, 12, 0, 0, 0, time.UTC), params.Start)
+		require.Equal(t, time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC), params.End)
+	})
+
+	t.Run("end before start", func(t *testing.T) {
+		_, err := parse(t, "/api/v1/labels", url.Values{"start": {"1622548800"}, "end": {"1622545200"}})
+		require.Error(t, err)
+	})
+
+	t.Run("invalid time", func(t *testing.T) {
+		_, err := parse(t, "/api/v1/labels", url.Values{"start": {"yesterday"}})
+		require.Error(t, err)
+	})
+
+	t.Run("limit is bounded", func(t *testing.T) {
+		params, err := parse(t, "/api/v1/labels", url.Values{"limit": {"10"}})
+		require.NoError(t, err)
+		require.Equal(t, 10, params.Limit)
+		params, err = parse(t, "/api/v1/labels", url.Values{"limit": {"1000000"}})
+		require.NoError(t, err)
+		require.Equal(t, resourceMaxLimit, params.Limit)
+		_, err = parse(t, "/api/v1/labels", url.Values{"limit": {"-1"}})
+		require.Error(t, err)
+	})
+
+	t.Run("label values", func(t *testing.T) {
+		params, err := parse(t, "/api/v1/label/job/values", url.Values{"match[]": {"up"}})
+		require.NoError(t, err)
+		require.Equal(t, "job", params.Label)
+		require.Equal(t, []string{"up"}, params.Matches)
+		_, err = parse(t, "/api/v1/label/not-valid/values", url.Values{})
+		require.Error(t, err)
+		_, err = parse(t, "/api/v1/label/job", url.Values{})
+		require.Error(t, err)
+	})
+
+	t.Run("series require match", func(t *testing.T) {
+		_, err := parse(t, "/api/v1/series", url.Values{})
+		require.Error(t, err)
+	})
+}
+
+func TestResourceHandler(t *testing.T) {
+	var mu sync.Mutex
+	var requests []*http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		require.NoError(t, req.ParseForm())
+		mu.Lock()
+		requests = append(requests, req)
+		mu.Unlock()
+
+		rw.Header().Set("Content-Type", "application/json")
+		switch req.URL.Path {
+		case "/api/v1/labels":
+			_, _ = rw.Write([]byte(`{"status":"success","data":["__name__","instance","job"]}`))
+		case "/api/v1/label/job/values":
+			_, _ = rw.Write([]byte(`{"status":"success","data":["grafana","prometheus"],"warnings":["partial"]}`))
+		case "/api/v1/series":
+			_, _ = rw.Write([]byte(`{"status":"success","data":[{"__name__":"up","job":"grafana"},{"__name__":"up","job":"prometheus"}]}`))
+		case "/api/v1/metadata":
+			_, _ = rw.Write([]byte(`{"status":"success","data":{"up":[{"type":"gauge","help":"Up","unit":""}],"scrape_duration_seconds":[{"type":"gauge","help":"Duration","unit":"seconds"}]}}`))
+		default:
+			rw.WriteHeader(http.StatusBadRequest)
+			_, _ = rw.Write([]byte(`{"status":"error","errorType":"bad_data","error":"unknown"}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	s := &Service{im: datasource.NewInstanceManager(newInstanceSettings(httpclient.NewProvider()))}
+	mux := http.NewServeMux()
+	s.registerRoutes(mux)
+	handler := httpadapter.New(mux)
+	pluginCtx := backend.PluginContext{
+		DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
+			ID:       1,
+			URL:      srv.URL,
+			JSONData: []byte(`{"customQueryParameters": "tenant=team-a"}`),
+		},
+	}
+
+	call := func(t *testing.T, path string, query url.Values) (int, resourceResponse) {
+		t.Helper()
+		sender := &fakeResourceSender{}
+		err := handler.CallResource(context.Background(), &backend.CallResourceRequest{
+			PluginContext: pluginCtx,
+			Path:          path,
+			Method:        http.MethodGet,
+			URL:           path + "?" + query.Encode(),
+		}, sender)
+		require.NoError(t, err)
+		require.Len(t, sender.responses, 1)
+		var res resourceResponse
+		require.NoError(t, json.Unmarshal(sender.responses[0].Body, &res))
+		return sender.responses[0].Status, res
+	}
+	lastRequest := func() *http.Request {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests[len(requests)-1]
+	}
+
+	t.Run("labels", func(t *testing.T) {
+		status, res := call(t, "api/v1/labels", url.Values{"start": {"1622541600"}, "end": {"1622545200"}})
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, "success", res.Status)
+		require.Equal(t, []interface{}{"__name__", "instance", "job"}, res.Data)
+
+		req := lastRequest()
+		require.Equal(t, "1622541600", req.Form.Get("start"))
+		require.Equal(t, "1622545200", req.Form.Get("end"))
+		require.Equal(t, "team-a", req.Form.Get("tenant"))
+	})
+
+	t.Run("responses are cached", func(t *testing.T) {
+		mu.Lock()
+		before := len(requests)
+		mu.Unlock()
+		status, _ := call(t, "api/v1/labels", url.Values{"start": {"1622541610"}, "end": {"1622545190"}})
+		require.Equal(t, http.StatusOK, status)
+		mu.Lock()
+		require.Equal(t, before, len(requests))
+		mu.Unlock()
+	})
+
+	t.Run("label values are truncated to limit", func(t *testing.T) {
+		status, res := call(t, "api/v1/label/job/values", url.Values{"limit": {"1"}, "match[]": {"up"}})
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, []interface{}{"grafana"}, res.Data)
+		require.Equal(t, []string{"partial", "Result was truncated to 1 items"}, res.Warnings)
+		require.Equal(t, []string{"up"}, lastRequest().Form["match[]"])
+	})
+
+	t.Run("series", func(t *testing.T) {
+		status, res := call(t, "api/v1/series", url.Values{"match[]": {"up"}})
+		require.Equal(t, http.StatusOK, status)
+		require.Len(t, res.Data, 2)
+	})
+
+	t.Run("series without match", func(t *testing.T) {
+		status, res := call(t, "api/v1/series", url.Values{})
+		require.Equal(t, http.StatusBadRequest, status)
+		require.Equal(t, "error", res.Status)
+	})
+
+	t.Run("metadata", func(t *testing.T) {
+		status, res := call(t, "api/v1/metadata", url.Values{"limit": {"1"}})
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, "2", lastRequest().Form.Get("limit"))
+		require.Equal(t, map[string]interface{}{
+			"scrape_duration_seconds": []interface{}{map[string]interface{}{"type": "gauge", "help": "Duration", "unit": "seconds"}},
+		}, res.Data)
+		require.Len(t, res.Warnings, 1)
+	})
+
+	t.Run("upstream error", func(t *testing.T) {
+		status, res := call(t, "api/v1/label/unknown/values", url.Values{})
+		require.Equal(t, http.StatusBadGateway, status)
+		require.Equal(t, "error", res.Status)
+		require.Equal(t, "bad_data", res.ErrorType)
+	})
+}
+
+type fakeResourceSender struct {
+	responses []*backend.CallResourceResponse
+}
+
+func (s *fakeResourceSender) Send(res *backend.CallResourceResponse) error {
+	s.responses = append(s.responses, res)
+	return nil
+}
@@ -3,6 +3,7 @@ package prometheus
 import (
 	"time"
 
+	"github.com/grafana/grafana/pkg/infra/localcache"
 	apiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
 )
 
@@ -11,7 +12,8 @@ type DatasourceInfo struct {
 	URL          string
 	TimeInterval string
 
-	promClient apiv1.API
+	promClient    apiv1.API
+	resourceCache *localcache.CacheService
 }
 
 type PrometheusQuery struct {
@@ -0,0 +1,272 @@
+package tsdb
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// QueryCacheSkipHeader is a request header which makes a query bypass the query cache.
+const QueryCacheSkipHeader = "X-Cache-Skip"
+
+const (
+	// defaultQueryCacheTTL is used for data sources with enabled caching and without TTL set.
+	defaultQueryCacheTTL = time.Minute
+	// minQueryCacheInterval is a minimal interval used to round query time range.
+	minQueryCacheInterval = time.Second
+)
+
+var queryCacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "grafana",
+	Subsystem: "query_cache",
+	Name:      "requests_total",
+	Help:      "Number of data source query requests served using query cache, by result (hit or miss).",
+}, []string{"datasource_type", "result"})
+
+func init() {
+	remotecache.Register(cachedQueryResponse{})
+}
+
+// cachedQueryResponse is a query response as stored in the remote cache.
+type cachedQueryResponse struct {
+	Results map[string]cachedQueryResult
+}
+
+type cachedQueryResult struct {
+	RefID  string
+	Frames [][]byte
+}
+
+// queryCacheSettings are read from data source JSON data:
+// queryCacheEnabled enables caching and queryCacheTTL sets TTL of cached responses.
+type queryCacheSettings struct {
+	Enabled bool
+	TTL     time.Duration
+}
+
+// getQueryCacheSettings returns the cache settings of the data source. An
+// invalid TTL is returned as an error together with settings using the default
+// TTL, so a bad setting doesn't fail the queries of the data source.
+func getQueryCacheSettings(ds *models.DataSource) (queryCacheSettings, error) {
+	if ds.JsonData == nil || !ds.JsonData.Get("queryCacheEnabled").MustBool(false) {
+		return queryCacheSettings{}, nil
+	}
+	settings := queryCacheSettings{Enabled: true, TTL: defaultQueryCacheTTL}
+	if ttl := ds.JsonData.Get("queryCacheTTL").MustString(""); ttl != "" {
+		d, err := time.ParseDuration(ttl)
+		if err != nil {
+			return settings, fmt.Errorf("invalid queryCacheTTL: %w", err)
+		}
+		if d > 0 {
+			settings.TTL = d
+		}
+	}
+	return settings, nil
+}
+
+type queryCache struct {
+	storage remotecache.CacheStorage
+	log     log.Logger
+	now     func() time.Time
+}
+
+func newQueryCache(storage remotecache.CacheStorage) *queryCache {
+	return &queryCache{
+		storage: storage,
+		log:     log.New("tsdb.querycache"),
+		now:     time.Now,
+	}
+}
+
+// nolint: staticcheck // plugins.DataPlugin deprecated
+type dataQueryFunc func(ctx context.Context, ds *models.DataSource, query plugins.DataQuery) (plugins.DataResponse, error)
+
+// handle serves query from the cache if possible, otherwise it runs the query
+// and caches successful response.
+// nolint: staticcheck // plugins.DataPlugin deprecated
+func (c *queryCache) handle(ctx context.Context, ds *models.DataSource, query plugins.DataQuery, settings queryCacheSettings,
+	fn dataQueryFunc) (plugins.DataResponse, error) {
+	key, ttl, err := c.cacheKey(ds, query, settings.TTL)
+	if err != nil {
+		c.log.Warn("Failed to build query cache key", "datasource", ds.Uid, "error", err)
+		return fn(ctx, ds, query)
+	}
+
+	if resp, ok := c.get(key); ok {
+		queryCacheRequests.WithLabelValues(ds.Type, "hit").Inc()
+		return resp, nil
+	}
+	queryCacheRequests.WithLabelValues(ds.Type, "miss").Inc()
+
+	resp, err := fn(ctx, ds, query)
+	if err != nil {
+		return resp, err
+	}
+	c.set(key, resp, ttl)
+	return resp, nil
+}
+
+// nolint: staticcheck // plugins.DataPlugin deprecated
+func (c *queryCache) get(key string) (plugins.DataResponse, bool) {
+	item, err := c.storage.Get(key)
+	if err != nil {
+		if !errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			c.log.Warn("Failed to read query cache", "error", err)
+		}
+		return plugins.DataResponse{}, false
+	}
+	cached, ok := item.(cachedQueryResponse)
+	if !ok {
+		return plugins.DataResponse{}, false
+	}
+	resp := plugins.DataResponse{
+		Results: make(map[string]plugins.DataQueryResult, len(cached.Results)),
+	}
+	for refID, r := range cached.Results {
+		resp.Results[refID] = plugins.DataQueryResult{
+			RefID:      r.RefID,
+			Dataframes: plugins.NewEncodedDataFrames(r.Frames),
+		}
+	}
+	return resp, true
+}
+
+// set stores response in the cache. Responses with errors are not cached.
+// nolint: staticcheck // plugins.DataPlugin deprecated
+func (c *queryCache) set(key string, resp plugins.DataResponse, ttl time.Duration) {
+	cached := cachedQueryResponse{
+		Results: make(map[string]cachedQueryResult, len(resp.Results)),
+	}
+	for refID, r := range resp.Results {
+		if r.Error != nil || r.ErrorString != "" {
+			return
+		}
+		// Only data frames can be cached, other result formats are legacy.
+		if len(r.Series) > 0 || len(r.Tables) > 0 {
+			return
+		}
+		var frames [][]byte
+		if r.Dataframes != nil {
+			encoded, err := r.Dataframes.Encoded()
+			if err != nil {
+				c.log.Warn("Failed to encode frames for query cache", "error", err)
+				return
+			}
+			frames = encoded
+		}
+		cached.Results[refID] = cachedQueryResult{RefID: r.RefID, Frames: frames}
+	}
+	if err := c.storage.Set(key, cached, ttl); err != nil {
+		c.log.Warn("Failed to write query cache", "error", err)
+	}
+}
+
+// cacheKey returns cache key and TTL for a query. Time range is rounded to the
+// query interval, so requests made a few moments apart share the same key. For
+// ranges ending at "now" the last interval is not complete yet, so TTL is limited
+// to the end of the current interval.
+// nolint: staticcheck // plugins.DataPlugin deprecated
+func (c *queryCache) cacheKey(ds *models.DataSource, query plugins.DataQuery, ttl time.Duration) (string, time.Duration, error) {
+	if query.TimeRange == nil {
+		return "", 0, errors.New("query has no time range")
+	}
+
+	interval := minQueryCacheInterval
+	for _, q := range query.Queries {
+		if d := time.Duration(q.IntervalMS) * time.Millisecond; d > interval {
+			interval = d
+		}
+	}
+
+	now := c.now()
+	if query.TimeRange.Now.IsZero() {
+		timeRange := *query.TimeRange
+		timeRange.Now = now
+		query.TimeRange = &timeRange
+	}
+	from := query.TimeRange.GetFromAsTimeUTC().Truncate(interval)
+	to := query.TimeRange.GetToAsTimeUTC()
+	if !to.Before(now.Add(-interval)) {
+		untilNextInterval := to.Truncate(interval).Add(interval).Sub(now)
+		if untilNextInterval > 0 && untilNextInterval < ttl {
+			ttl = untilNextInterval
+		}
+	}
+	to = to.Truncate(interval)
+
+	type keyQuery struct {
+		RefID         string          `json:"refId"`
+		QueryType     string          `json:"queryType"`
+		IntervalMS    int64           `json:"intervalMs"`
+		MaxDataPoints int64           `json:"maxDataPoints"`
+		Model         json.RawMessage `json:"model"`
+	}
+	keyData := struct {
+		OrgID    int64      `json:"orgId"`
+		UID      string     `json:"uid"`
+		Updated  int64      `json:"updated"`
+		From     int64      `json:"from"`
+		To       int64      `json:"to"`
+		Interval int64      `json:"interval"`
+		Queries  []keyQuery `json:"queries"`
+	}{
+		OrgID:    ds.OrgId,
+		UID:      ds.Uid,
+		Updated:  ds.Updated.UnixNano(),
+		From:     from.UnixNano(),
+		To:       to.UnixNano(),
+		Interval: int64(interval),
+	}
+	for _, q := range query.Queries {
+		model, err := normalizeQueryModel(q)
+		if err != nil {
+			return "", 0, err
+		}
+		keyData.Queries = append(keyData.Queries, keyQuery{
+			RefID:         q.RefID,
+			QueryType:     q.QueryType,
+			IntervalMS:    q.IntervalMS,
+			MaxDataPoints: q.MaxDataPoints,
+			Model:         model,
+		})
+	}
+
+	b, err := json.Marshal(keyData)
+	if err != nil {
+		return "", 0, err
+	}
+	sum := sha256.Sum256(b)
+	return "query-cache-" + hex.EncodeToString(sum[:]), ttl, nil
+}
+
+// volatileQueryModelKeys are query model properties which differ between
+// requests of the same query and do not change the result.
+var volatileQueryModelKeys = []string{"requestId", "utcOffsetSec"}
+
+// normalizeQueryModel returns query model JSON with sorted keys and without volatile properties.
+// nolint: staticcheck // plugins.DataPlugin deprecated
+func normalizeQueryModel(q plugins.DataSubQuery) (json.RawMessage, error) {
+	if q.Model == nil {
+		return json.RawMessage("null"), nil
+	}
+	model, err := q.Model.Map()
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range volatileQueryModelKeys {
+		delete(model, k)
+	}
+	// encoding/json sorts map keys.
+	return json.Marshal(model)
+}
@@ -0,0 +1,276 @@
+package tsdb
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetQueryCacheSettings(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		settings, err := getQueryCacheSettings(&models.DataSource{JsonData: simplejson.New()})
+		require.NoError(t, err)
+		require.False(t, settings.Enabled)
+	})
+
+	t.Run("enabled with default TTL", func(t *testing.T) {
+		settings, err := getQueryCacheSettings(&models.DataSource{
+			JsonData: simplejson.NewFromAny(map[string]interface{}{"queryCacheEnabled": true}),
+		})
+		require.NoError(t, err)
+		require.True(t, settings.Enabled)
+		require.Equal(t, defaultQueryCacheTTL, settings.TTL)
+	})
+
+	t.Run("enabled with TTL", func(t *testing.T) {
+		settings, err := getQueryCacheSettings(&models.DataSource{
+			JsonData: simplejson.NewFromAny(map[string]interface{}{"queryCacheEnabled": true, "queryCacheTTL": "5m"}),
+		})
+		require.NoError(t, err)
+		require.Equal(t, 5*time.Minute, settings.TTL)
+	})
+
+	t.Run("invalid TTL", func(t *testing.T) {
+		settings, err := getQueryCacheSettings(&models.DataSource{
+			JsonData: simplejson.NewFromAny(map[string]interface{}{"queryCacheEnabled": true, "queryCacheTTL": "often"}),
+		})
+		require.Error(t, err)
+		require.True(t, settings.Enabled)
+		require.Equal(t, defaultQueryCacheTTL, settings.TTL)
+	})
+}
+
+func TestQueryCacheKey(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 30, 0, time.UTC)
+	cache := newQueryCache(newFakeCacheStorage())
+	cache.now = func() time.Time { return now }
+	ds := &models.DataSource{OrgId: 1, Uid: "abc"}
+
+	t.Run("time range is rounded to the interval", func(t *testing.T) {
+		key1, ttl, err := cache.cacheKey(ds, newCacheTestQuery("1622541600000", "1622545200000", 60000, `{"expr": "up"}`), time.Hour)
+		require.NoError(t, err)
+		require.Equal(t, time.Hour, ttl)
+		key2, _, err := cache.cacheKey(ds, newCacheTestQuery("1622541610000", "1622545210000", 60000, `{"expr": "up"}`), time.Hour)
+		require.NoError(t, err)
+		require.Equal(t, key1, key2)
+		key3, _, err := cache.cacheKey(ds, newCacheTestQuery("1622541660000", "1622545260000", 60000, `{"expr": "up"}`), time.Hour)
+		require.NoError(t, err)
+		require.NotEqual(t, key1, key3)
+	})
+
+	t.Run("query model is normalized", func(t *testing.T) {
+		key1, _, err := cache.cacheKey(ds, newCacheTestQuery("1622541600000", "1622545200000", 60000, `{"expr": "up", "format": "time_series", "requestId": "Q100"}`), time.Hour)
+		require.NoError(t, err)
+		key2, _, err := cache.cacheKey(ds, newCacheTestQuery("1622541600000", "1622545200000", 60000, `{"format": "time_series", "expr": "up", "requestId": "Q101"}`), time.Hour)
+		require.NoError(t, err)
+		require.Equal(t, key1, key2)
+		key3, _, err := cache.cacheKey(ds, newCacheTestQuery("1622541600000", "1622545200000", 60000, `{"expr": "down", "format": "time_series"}`), time.Hour)
+		require.NoError(t, err)
+		require.NotEqual(t, key1, key3)
+	})
+
+	t.Run("key depends on data source", func(t *testing.T) {
+		query := newCacheTestQuery("1622541600000", "1622545200000", 60000, `{"expr": "up"}`)
+		key1, _, err := cache.cacheKey(ds, query, time.Hour)
+		require.NoError(t, err)
+		key2, _, err := cache.cacheKey(&models.DataSource{OrgId: 1, Uid: "def"}, query, time.Hour)
+		require.NoError(t, err)
+		require.NotEqual(t, key1, key2)
+		key3, _, err := cache.cacheKey(&models.DataSource{OrgId: 1, Uid: "abc", Updated: now}, query, time.Hour)
+		require.NoError(t, err)
+		require.NotEqual(t, key1, key3)
+	})
+
+	t.Run("TTL of range ending now is limited to the end of the current interval", func(t *testing.T) {
+		_, ttl, err := cache.cacheKey(ds, newCacheTestQuery("now-1h", "now", 60000, `{"expr": "up"}`), time.Hour)
+		require.NoError(t, err)
+		require.Equal(t, 30*time.Second, ttl)
+
+		_, ttl, err = cache.cacheKey(ds, newCacheTestQuery("now-1h", "now", 60000, `{"expr": "up"}`), 10*time.Second)
+		require.NoError(t, err)
+		require.Equal(t, 10*time.Second, ttl)
+	})
+
+	t.Run("range ending now and a few moments later share the key", func(t *testing.T) {
+		key1, _, err := cache.cacheKey(ds, newCacheTestQuery("now-1h", "now", 60000, `{"expr": "up"}`), time.Hour)
+		require.NoError(t, err)
+		cache.now = func() time.Time { return now.Add(10 * time.Second) }
+		defer func() { cache.now = func() time.Time { return now } }()
+		key2, ttl, err := cache.cacheKey(ds, newCacheTestQuery("now-1h", "now", 60000, `{"expr": "up"}`), time.Hour)
+		require.NoError(t, err)
+		require.Equal(t, key1, key2)
+		require.Equal(t, 20*time.Second, ttl)
+	})
+}
+
+func TestQueryCacheHandle(t *testing.T) {
+	ds := &models.DataSource{OrgId: 1, Uid: "abc", Type: "test"}
+	settings := queryCacheSettings{Enabled: true, TTL: time.Minute}
+	query := newCacheTestQuery("1622541600000", "1622545200000", 60000, `{"expr": "up"}`)
+
+	newQueryFn := func(result plugins.DataQueryResult, err error) (dataQueryFunc, *int) {
+		calls := 0
+		return func(ctx context.Context, ds *models.DataSource, query plugins.DataQuery) (plugins.DataResponse, error) {
+			calls++
+			return plugins.DataResponse{Results: map[string]plugins.DataQueryResult{"A": result}}, err
+		}, &calls
+	}
+
+	t.Run("second request is served from the cache", func(t *testing.T) {
+		storage := newFakeCacheStorage()
+		cache := newQueryCache(storage)
+		frame := data.NewFrame("up", data.NewField("value", nil, []float64{1, 2}))
+		fn, calls := newQueryFn(plugins.DataQueryResult{
+			RefID:      "A",
+			Dataframes: plugins.NewDecodedDataFrames(data.Frames{frame}),
+		}, nil)
+
+		_, err := cache.handle(context.Background(), ds, query, settings, fn)
+		require.NoError(t, err)
+		resp, err := cache.handle(context.Background(), ds, query, settings, fn)
+		require.NoError(t, err)
+		require.Equal(t, 1, *calls)
+		require.Len(t, storage.items, 1)
+		for _, item := range storage.items {
+			require.Equal(t, time.Minute, item.ttl)
+		}
+
+		frames, err := resp.Results["A"].Dataframes.Decoded()
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		require.Equal(t, "up", frames[0].Name)
+		require.Equal(t, 2.0, frames[0].Fields[0].At(1))
+	})
+
+	t.Run("responses with errors are not cached", func(t *testing.T) {
+		storage := newFakeCacheStorage()
+		cache := newQueryCache(storage)
+		fn, calls := newQueryFn(plugins.DataQueryResult{RefID: "A", Error: errors.New("boom")}, nil)
+
+		_, err := cache.handle(context.Background(), ds, query, settings, fn)
+		require.NoError(t, err)
+		_, err = cache.handle(context.Background(), ds, query, settings, fn)
+		require.NoError(t, err)
+		require.Equal(t, 2, *calls)
+		require.Empty(t, storage.items)
+	})
+
+	t.Run("failed requests are not cached", func(t *testing.T) {
+		storage := newFakeCacheStorage()
+		cache := newQueryCache(storage)
+		fn, calls := newQueryFn(plugins.DataQueryResult{RefID: "A"}, errors.New("boom"))
+
+		_, err := cache.handle(context.Background(), ds, query, settings, fn)
+		require.Error(t, err)
+		require.Equal(t, 1, *calls)
+		require.Empty(t, storage.items)
+	})
+}
+
+func TestHandleRequest_QueryCache(t *testing.T) {
+	query := newCacheTestQuery("1622541600000", "1622545200000", 60000, `{"expr": "up"}`)
+
+	run := func(t *testing.T, jsonData map[string]interface{}, headers map[string]string, useCache bool) int {
+		t.Helper()
+		svc, _, pm := createService()
+		calls := 0
+		pm.QueryDataHandlerFunc = func(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+			calls++
+			return backend.NewQueryDataResponse(), nil
+		}
+		ds := &models.DataSource{Id: 1, Uid: "abc", Type: "test", JsonData: simplejson.NewFromAny(jsonData)}
+		q := query
+		q.Headers = headers
+		q.UseQueryCache = useCache
+		for i := 0; i < 2; i++ {
+			_, err := svc.HandleRequest(context.Background(), ds, q)
+			require.NoError(t, err)
+		}
+		return calls
+	}
+
+	t.Run("cache is disabled by default", func(t *testing.T) {
+		require.Equal(t, 2, run(t, map[string]interface{}{}, nil, true))
+	})
+
+	t.Run("cache is used when enabled", func(t *testing.T) {
+		require.Equal(t, 1, run(t, map[string]interface{}{"queryCacheEnabled": true}, nil, true))
+	})
+
+	t.Run("cache is used with default TTL when TTL is invalid", func(t *testing.T) {
+		require.Equal(t, 1, run(t, map[string]interface{}{"queryCacheEnabled": true, "queryCacheTTL": "often"}, nil, true))
+	})
+
+	t.Run("cache is skipped with header", func(t *testing.T) {
+		require.Equal(t, 2, run(t, map[string]interface{}{"queryCacheEnabled": true}, map[string]string{QueryCacheSkipHeader: "true"}, true))
+	})
+
+	t.Run("cache is skipped for requests not using it", func(t *testing.T) {
+		// e.g. alert rule evaluations
+		require.Equal(t, 2, run(t, map[string]interface{}{"queryCacheEnabled": true}, nil, false))
+	})
+}
+
+// nolint: staticcheck // plugins.DataPlugin deprecated
+func newCacheTestQuery(from, to string, intervalMS int64, model string) plugins.DataQuery {
+	timeRange := plugins.NewDataTimeRange(from, to)
+	timeRange.Now = time.Date(2021, 6, 1, 12, 0, 30, 0, time.UTC)
+	m, err := simplejson.NewJson([]byte(model))
+	if err != nil {
+		panic(err)
+	}
+	return plugins.DataQuery{
+		TimeRange: &timeRange,
+		Queries: []plugins.DataSubQuery{
+			{RefID: "A", IntervalMS: intervalMS, MaxDataPoints: 1000, Model: m},
+		},
+	}
+}
+
+type fakeCacheItem struct {
+	value interface{}
+	ttl   time.Duration
+}
+
+type fakeCacheStorage struct {
+	mu    sync.Mutex
+	items map[string]fakeCacheItem
+}
+
+func newFakeCacheStorage() *fakeCacheStorage {
+	return &fakeCacheStorage{items: map[string]fakeCacheItem{}}
+}
+
+func (s *fakeCacheStorage) Get(key string) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item, ok := s.items[key]
+	if !ok {
+		return nil, remotecache.ErrCacheItemNotFound
+	}
+	return item.value, nil
+}
+
+func (s *fakeCacheStorage) Set(key string, value interface{}, expire time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items[key] = fakeCacheItem{value: value, ttl: expire}
+	return nil
+}
+
+func (s *fakeCacheStorage) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.items, key)
+	return nil
+}
@@ -3,6 +3,7 @@ package tsdb
 import (
 	"context"
 
+	"github.com/grafana/grafana/pkg/infra/remotecache"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin"
@@ -15,17 +16,20 @@ import (
 // NewService returns a new Service.
 func NewService(
 	cfg *setting.Cfg, backendPluginManager backendplugin.Manager,
-	oauthTokenService *oauthtoken.Service, dataSourcesService *datasources.Service) *Service {
-	return newService(cfg, backendPluginManager, oauthTokenService, dataSourcesService)
+	oauthTokenService *oauthtoken.Service, dataSourcesService *datasources.Service,
+	remoteCache *remotecache.RemoteCache) *Service {
+	return newService(cfg, backendPluginManager, oauthTokenService, dataSourcesService, remoteCache)
 }
 
 func newService(cfg *setting.Cfg, backendPluginManager backendplugin.Manager,
-	oauthTokenService oauthtoken.OAuthTokenService, dataSourcesService *datasources.Service) *Service {
+	oauthTokenService oauthtoken.OAuthTokenService, dataSourcesService *datasources.Service,
+	cacheStorage remotecache.CacheStorage) *Service {
 	return &Service{
 		Cfg:                  cfg,
 		BackendPluginManager: backendPluginManager,
 		OAuthTokenService:    oauthTokenService,
 		DataSourcesService:   dataSourcesService,
+		queryCache:           newQueryCache(cacheStorage),
 	}
 }
 
@@ -35,9 +39,25 @@ type Service struct {
 	BackendPluginManager backendplugin.Manager
 	OAuthTokenService    oauthtoken.OAuthTokenService
 	DataSourcesService   *datasources.Service
+
+	queryCache *queryCache
 }
 
 //nolint: staticcheck // plugins.DataPlugin deprecated
 func (s *Service) HandleRequest(ctx context.Context, ds *models.DataSource, query plugins.DataQuery) (plugins.DataResponse, error) {
-	return dataPluginQueryAdapter(ds.Type, s.BackendPluginManager, s.OAuthTokenService, s.DataSourcesService).DataQuery(ctx, ds, query)
+	fn := dataPluginQueryAdapter(ds.Type, s.BackendPluginManager, s.OAuthTokenService, s.DataSourcesService).DataQuery
+
+	if !query.UseQueryCache {
+		return fn(ctx, ds, query)
+	}
+
+	settings, err := getQueryCacheSettings(ds)
+	if err != nil && s.queryCache != nil {
+		s.queryCache.log.Warn("Invalid query cache settings, using the default TTL", "datasource", ds.Uid, "error", err)
+	}
+	if !settings.Enabled || s.queryCache == nil || query.Headers[QueryCacheSkipHeader] == "true" ||
+		s.OAuthTokenService.IsOAuthPassThruEnabled(ds) {
+		return fn(ctx, ds, query)
+	}
+	return s.queryCache.handle(ctx, ds, query, settings, fn)
 }
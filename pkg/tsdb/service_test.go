@@ -108,6 +108,7 @@ func createService() (*Service, *fakeExecutor, *fakeBackendPM) {
 		fakeBackendPM,
 		&fakeOAuthTokenService{},
 		dsService,
+		newFakeCacheStorage(),
 	)
 	e := &fakeExecutor{
 		//nolint: staticcheck // plugins.DataPlugin deprecated
@@ -100,6 +100,11 @@ type QueryJson struct {
 	FillMode     string  `json:"fillMode"`
 	FillValue    float64 `json:"fillValue"`
 	Format       string  `json:"format"`
+
+	// Properties of template variable queries
+	SearchFilter string `json:"searchFilter"`
+	Sort         int    `json:"sort"`
+	Limit        int64  `json:"limit"`
 }
 
 func (e *DataSourceHandler) transformQueryError(err error) error {
@@ -202,6 +207,28 @@ func (e *DataSourceHandler) QueryData(ctx context.Context, req *backend.QueryDat
 	return result, nil
 }
 
+// interpolateQuery substitutes global variables and data source specific
+// macros in the raw SQL of a query. The search filter of variable queries is
+// substituted last, so that it is never expanded as a macro.
+func (e *DataSourceHandler) interpolateQuery(query backend.DataQuery, queryJson QueryJson) (string, error) {
+	// global substitutions
+	interpolatedQuery, err := Interpolate(query, query.TimeRange, e.dsInfo.JsonData.TimeInterval, queryJson.RawSql)
+	if err != nil {
+		return interpolatedQuery, err
+	}
+
+	// data source specific substitutions
+	interpolatedQuery, err = e.macroEngine.Interpolate(&query, query.TimeRange, interpolatedQuery)
+	if err != nil {
+		return interpolatedQuery, err
+	}
+
+	if query.QueryType == VariableQueryType {
+		interpolatedQuery = interpolateSearchFilter(interpolatedQuery, queryJson.SearchFilter)
+	}
+	return interpolatedQuery, nil
+}
+
 func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitGroup, queryContext context.Context,
 	ch chan DBDataResponse, queryJson QueryJson) {
 	defer wg.Done()
@@ -228,8 +255,6 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 		panic("Query model property rawSql should not be empty at this point")
 	}
 
-	timeRange := query.TimeRange
-
 	errAppendDebug := func(frameErr string, err error, query string) {
 		var emptyFrame data.Frame
 		emptyFrame.SetMeta(&data.FrameMeta{
@@ -240,15 +265,7 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 		ch <- queryResult
 	}
 
-	// global substitutions
-	interpolatedQuery, err := Interpolate(query, timeRange, e.dsInfo.JsonData.TimeInterval, queryJson.RawSql)
-	if err != nil {
-		errAppendDebug("interpolation failed", e.transformQueryError(err), interpolatedQuery)
-		return
-	}
-
-	// data source specific substitutions
-	interpolatedQuery, err = e.macroEngine.Interpolate(&query, timeRange, interpolatedQuery)
+	interpolatedQuery, err := e.interpolateQuery(query, queryJson)
 	if err != nil {
 		errAppendDebug("interpolation failed", e.transformQueryError(err), interpolatedQuery)
 		return
@@ -269,6 +286,18 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 		}
 	}()
 
+	if query.QueryType == VariableQueryType {
+		frame, err := e.executeVariableQuery(rows, queryJson)
+		if err != nil {
+			errAppendDebug("variable query failed", err, interpolatedQuery)
+			return
+		}
+		frame.Meta = &data.FrameMeta{ExecutedQueryString: interpolatedQuery}
+		queryResult.dataResponse.Frames = data.Frames{frame}
+		ch <- queryResult
+		return
+	}
+
 	qm, err := e.newProcessCfg(query, queryContext, rows, interpolatedQuery)
 	if err != nil {
 		errAppendDebug("failed to get configurations", err, interpolatedQuery)
@@ -0,0 +1,245 @@
+package sqleng
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+	"xorm.io/core"
+)
+
+// VariableQueryType is the query type of template variable queries. Variable
+// queries return a frame with __text and __value fields.
+const VariableQueryType = "variable"
+
+const (
+	// Names of variable frame fields, a query may select them to set text and
+	// value of variable options separately.
+	variableTextField  = "__text"
+	variableValueField = "__value"
+
+	searchFilterMacro = "$__searchFilter"
+)
+
+// variableSort is the sort order of variable options, values match sort of
+// query variables in dashboards.
+type variableSort int
+
+const (
+	variableSortDisabled variableSort = iota
+	variableSortAlphabeticalAsc
+	variableSortAlphabeticalDesc
+	variableSortNumericalAsc
+	variableSortNumericalDesc
+	variableSortAlphabeticalCaseInsensitiveAsc
+	variableSortAlphabeticalCaseInsensitiveDesc
+)
+
+var numericalSortRe = regexp.MustCompile(`.*?(\d+).*`)
+
+type variableOption struct {
+	text  string
+	value string
+}
+
+// interpolateSearchFilter replaces $__searchFilter with a pattern matching values
+// starting with the search filter, or any value when there's no filter. Like in
+// the frontend, queries surround the macro with quotes, so quotes of the filter
+// are escaped. Backslashes are removed, as MySQL treats them as escape characters.
+func interpolateSearchFilter(sql string, searchFilter string) string {
+	if !strings.Contains(sql, searchFilterMacro) {
+		return sql
+	}
+	filter := strings.ReplaceAll(searchFilter, `\`, "")
+	filter = strings.ReplaceAll(filter, "'", "''")
+	return strings.ReplaceAll(sql, searchFilterMacro, filter+"%")
+}
+
+// executeVariableQuery reads variable options from rows. All rows up to the
+// data source row limit are read, so options are de-duplicated before the query
+// limit is applied.
+func (e *DataSourceHandler) executeVariableQuery(rows *core.Rows, queryJson QueryJson) (*data.Frame, error) {
+	stringConverters := e.queryResultTransformer.GetConverterList()
+	frame, err := sqlutil.FrameFromRows(rows.Rows, e.rowLimit, sqlutil.ToConverters(stringConverters...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := e.rowLimit
+	if queryJson.Limit > 0 && (limit <= 0 || queryJson.Limit < limit) {
+		limit = queryJson.Limit
+	}
+	return toVariableFrame(frame, queryJson, limit)
+}
+
+// toVariableFrame converts result of a variable query to a frame of unique
+// variable options. If the result has __text and __value fields, they are used
+// as text and value of options, otherwise values of all fields are used as both
+// text and value. Options exceeding the limit are dropped.
+func toVariableFrame(frame *data.Frame, queryJson QueryJson, limit int64) (*data.Frame, error) {
+	options, err := variableOptions(frame)
+	if err != nil {
+		return nil, err
+	}
+	sortVariableOptions(options, variableSort(queryJson.Sort))
+
+	truncated := false
+	if limit > 0 && int64(len(options)) > limit {
+		options = options[:limit]
+		truncated = true
+	}
+
+	texts := make([]string, len(options))
+	values := make([]string, len(options))
+	for i, option := range options {
+		texts[i] = option.text
+		values[i] = option.value
+	}
+
+	result := data.NewFrame(frame.Name,
+		data.NewField(variableTextField, nil, texts),
+		data.NewField(variableValueField, nil, values),
+	)
+	if truncated {
+		result.AppendNotices(data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text:     fmt.Sprintf("Variable options have been limited to %d", limit),
+		})
+	}
+	return result, nil
+}
+
+func variableOptions(frame *data.Frame) ([]variableOption, error) {
+	textIdx, valueIdx := -1, -1
+	for i, field := range frame.Fields {
+		switch field.Name {
+		case variableTextField:
+			textIdx = i
+		case variableValueField:
+			valueIdx = i
+		}
+	}
+
+	seen := map[string]bool{}
+	options := []variableOption{}
+	add := func(text, value string) {
+		// The first option with a text wins, as in the dashboard variable editor.
+		if seen[text] {
+			return
+		}
+		seen[text] = true
+		options = append(options, variableOption{text: text, value: value})
+	}
+
+	if textIdx != -1 && valueIdx != -1 {
+		for i := 0; i < frame.Rows(); i++ {
+			text, ok, err := variableValueToString(frame.Fields[textIdx].At(i))
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			value, _, err := variableValueToString(frame.Fields[valueIdx].At(i))
+			if err != nil {
+				return nil, err
+			}
+			add(text, value)
+		}
+		return options, nil
+	}
+
+	for _, field := range frame.Fields {
+		for i := 0; i < field.Len(); i++ {
+			text, ok, err := variableValueToString(field.At(i))
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				add(text, text)
+			}
+		}
+	}
+	return options, nil
+}
+
+// variableValueToString converts a field value to a string, ok is false for null values.
+func variableValueToString(v interface{}) (string, bool, error) {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return "", false, nil
+	}
+	// Values of nullable fields are pointers
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return "", false, nil
+		}
+		rv = rv.Elem()
+	}
+
+	switch value := rv.Interface().(type) {
+	case string:
+		return value, true, nil
+	case time.Time:
+		return value.UTC().Format(time.RFC3339Nano), true, nil
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), true, nil
+	case float32:
+		return strconv.FormatFloat(float64(value), 'f', -1, 32), true, nil
+	case bool:
+		return strconv.FormatBool(value), true, nil
+	}
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), true, nil
+	}
+	return "", false, fmt.Errorf("unsupported variable value type %T", v)
+}
+
+func sortVariableOptions(options []variableOption, order variableSort) {
+	if order == variableSortDisabled {
+		return
+	}
+
+	var less func(a, b variableOption) bool
+	switch order {
+	case variableSortAlphabeticalAsc:
+		less = func(a, b variableOption) bool { return a.text < b.text }
+	case variableSortAlphabeticalDesc:
+		less = func(a, b variableOption) bool { return a.text > b.text }
+	case variableSortNumericalAsc:
+		less = func(a, b variableOption) bool { return numericalSortKey(a.text) < numericalSortKey(b.text) }
+	case variableSortNumericalDesc:
+		less = func(a, b variableOption) bool { return numericalSortKey(a.text) > numericalSortKey(b.text) }
+	case variableSortAlphabeticalCaseInsensitiveAsc:
+		less = func(a, b variableOption) bool { return strings.ToLower(a.text) < strings.ToLower(b.text) }
+	case variableSortAlphabeticalCaseInsensitiveDesc:
+		less = func(a, b variableOption) bool { return strings.ToLower(a.text) > strings.ToLower(b.text) }
+	default:
+		return
+	}
+	sort.SliceStable(options, func(i, j int) bool {
+		return less(options[i], options[j])
+	})
+}
+
+// numericalSortKey returns the first number in the text, or -1 if there's none.
+func numericalSortKey(text string) int64 {
+	match := numericalSortRe.FindStringSubmatch(text)
+	if match == nil {
+		return -1
+	}
+	n, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil {
+		return -1
+	}
+	return n
+}
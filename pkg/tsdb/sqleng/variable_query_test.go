@@ -0,0 +1,142 @@
+package sqleng
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+	"github.com/xorcare/pointer"
+)
+
+func TestInterpolateSearchFilter(t *testing.T) {
+	t.Run("no search filter", func(t *testing.T) {
+		sql := interpolateSearchFilter("SELECT host FROM hosts WHERE host LIKE '$__searchFilter'", "")
+		require.Equal(t, "SELECT host FROM hosts WHERE host LIKE '%'", sql)
+	})
+
+	t.Run("search filter", func(t *testing.T) {
+		sql := interpolateSearchFilter("SELECT host FROM hosts WHERE host LIKE '$__searchFilter'", "web")
+		require.Equal(t, "SELECT host FROM hosts WHERE host LIKE 'web%'", sql)
+	})
+
+	t.Run("search filter is escaped", func(t *testing.T) {
+		sql := interpolateSearchFilter("SELECT host FROM hosts WHERE host LIKE '$__searchFilter'", `o'\'; DROP TABLE hosts; --`)
+		require.Equal(t, "SELECT host FROM hosts WHERE host LIKE 'o''''; DROP TABLE hosts; --%'", sql)
+	})
+
+	t.Run("query without macro", func(t *testing.T) {
+		sql := interpolateSearchFilter("SELECT host FROM hosts", "web")
+		require.Equal(t, "SELECT host FROM hosts", sql)
+	})
+}
+
+type testMacroEngine struct{}
+
+func (m *testMacroEngine) Interpolate(query *backend.DataQuery, timeRange backend.TimeRange, sql string) (string, error) {
+	return strings.ReplaceAll(sql, "$__timeFilter(time)", "time > 0"), nil
+}
+
+func TestInterpolateQuery(t *testing.T) {
+	handler := &DataSourceHandler{macroEngine: &testMacroEngine{}}
+	query := backend.DataQuery{
+		QueryType: VariableQueryType,
+		TimeRange: backend.TimeRange{From: time.Now().Add(-time.Hour), To: time.Now()},
+	}
+
+	t.Run("macros in search filter are not expanded", func(t *testing.T) {
+		sql, err := handler.interpolateQuery(query, QueryJson{
+			RawSql:       "SELECT host FROM hosts WHERE $__timeFilter(time) AND host LIKE '$__searchFilter'",
+			SearchFilter: "$__timeFilter(time)",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "SELECT host FROM hosts WHERE time > 0 AND host LIKE '$__timeFilter(time)%'", sql)
+	})
+
+	t.Run("search filter is not substituted in data queries", func(t *testing.T) {
+		sql, err := handler.interpolateQuery(backend.DataQuery{TimeRange: query.TimeRange}, QueryJson{
+			RawSql:       "SELECT host FROM hosts WHERE host LIKE '$__searchFilter'",
+			SearchFilter: "web",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "SELECT host FROM hosts WHERE host LIKE '$__searchFilter'", sql)
+	})
+}
+
+func TestToVariableFrame(t *testing.T) {
+	fieldValues := func(frame *data.Frame, idx int) []string {
+		values := make([]string, frame.Rows())
+		for i := range values {
+			values[i] = frame.Fields[idx].At(i).(string)
+		}
+		return values
+	}
+
+	t.Run("values of all fields are used as options", func(t *testing.T) {
+		frame := data.NewFrame("",
+			data.NewField("host", nil, []*string{pointer.String("b"), nil, pointer.String("a"), pointer.String("b")}),
+			data.NewField("port", nil, []*int64{pointer.Int64(80), pointer.Int64(443), nil, nil}),
+		)
+		res, err := toVariableFrame(frame, QueryJson{}, 0)
+		require.NoError(t, err)
+		require.Equal(t, variableTextField, res.Fields[0].Name)
+		require.Equal(t, variableValueField, res.Fields[1].Name)
+		require.Equal(t, []string{"b", "a", "80", "443"}, fieldValues(res, 0))
+		require.Equal(t, []string{"b", "a", "80", "443"}, fieldValues(res, 1))
+	})
+
+	t.Run("__text and __value fields", func(t *testing.T) {
+		frame := data.NewFrame("",
+			data.NewField("__value", nil, []*int64{pointer.Int64(1), pointer.Int64(2), pointer.Int64(3), pointer.Int64(4)}),
+			data.NewField("__text", nil, []*string{pointer.String("web"), pointer.String("db"), pointer.String("web"), nil}),
+		)
+		res, err := toVariableFrame(frame, QueryJson{}, 0)
+		require.NoError(t, err)
+		require.Equal(t, []string{"web", "db"}, fieldValues(res, 0))
+		require.Equal(t, []string{"1", "2"}, fieldValues(res, 1))
+	})
+
+	t.Run("time values", func(t *testing.T) {
+		ts := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+		frame := data.NewFrame("", data.NewField("time", nil, []*time.Time{&ts}))
+		res, err := toVariableFrame(frame, QueryJson{}, 0)
+		require.NoError(t, err)
+		require.Equal(t, []string{"2021-06-01T12:00:00Z"}, fieldValues(res, 0))
+	})
+
+	t.Run("sort", func(t *testing.T) {
+		frame := data.NewFrame("", data.NewField("host", nil, []string{"host10", "Host2", "host1", "other"}))
+		testCases := []struct {
+			sort     variableSort
+			expected []string
+		}{
+			{variableSortDisabled, []string{"host10", "Host2", "host1", "other"}},
+			{variableSortAlphabeticalAsc, []string{"Host2", "host1", "host10", "other"}},
+			{variableSortAlphabeticalDesc, []string{"other", "host10", "host1", "Host2"}},
+			{variableSortNumericalAsc, []string{"other", "host1", "Host2", "host10"}},
+			{variableSortNumericalDesc, []string{"host10", "Host2", "host1", "other"}},
+			{variableSortAlphabeticalCaseInsensitiveAsc, []string{"host1", "host10", "Host2", "other"}},
+			{variableSortAlphabeticalCaseInsensitiveDesc, []string{"other", "Host2", "host10", "host1"}},
+		}
+		for _, tc := range testCases {
+			res, err := toVariableFrame(frame, QueryJson{Sort: int(tc.sort)}, 0)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, fieldValues(res, 0), "sort %d", tc.sort)
+		}
+	})
+
+	t.Run("limit is applied after de-duplication", func(t *testing.T) {
+		frame := data.NewFrame("", data.NewField("host", nil, []string{"c", "c", "b", "a"}))
+		res, err := toVariableFrame(frame, QueryJson{Sort: int(variableSortAlphabeticalAsc)}, 2)
+		require.NoError(t, err)
+		require.Equal(t, []string{"a", "b"}, fieldValues(res, 0))
+		require.Len(t, res.Meta.Notices, 1)
+
+		res, err = toVariableFrame(frame, QueryJson{}, 3)
+		require.NoError(t, err)
+		require.Equal(t, 3, res.Rows())
+		require.Nil(t, res.Meta)
+	})
+}
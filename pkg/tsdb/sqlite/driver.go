@@ -0,0 +1,152 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"xorm.io/core"
+)
+
+// driverName is a name of database/sql driver used by SQLite data source. It wraps
+// sqlite3 driver to deny attaching other database files and to report column scan
+// types based on values of the first row, since SQLite columns have no strict types.
+const driverName = "grafana-sqlite3"
+
+var registerDriverOnce sync.Once
+
+func registerDriver() {
+	registerDriverOnce.Do(func() {
+		sql.Register(driverName, &sqliteDriver{
+			SQLiteDriver: sqlite3.SQLiteDriver{
+				ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+					conn.RegisterAuthorizer(authorize)
+					return nil
+				},
+			},
+		})
+		core.RegisterDriver(driverName, core.QueryDriver("sqlite3"))
+	})
+}
+
+// authorize denies statements which may open database files other than
+// the configured one.
+func authorize(op int, _, _, _ string) int {
+	switch op {
+	case sqlite3.SQLITE_ATTACH, sqlite3.SQLITE_DETACH:
+		return sqlite3.SQLITE_DENY
+	default:
+		return sqlite3.SQLITE_OK
+	}
+}
+
+type sqliteDriver struct {
+	sqlite3.SQLiteDriver
+}
+
+func (d *sqliteDriver) Open(dsn string) (driver.Conn, error) {
+	conn, err := d.SQLiteDriver.Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteConn{SQLiteConn: conn.(*sqlite3.SQLiteConn)}, nil
+}
+
+type sqliteConn struct {
+	*sqlite3.SQLiteConn
+}
+
+func (c *sqliteConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	rows, err := c.SQLiteConn.QueryContext(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	r := &sqliteRows{SQLiteRows: rows.(*sqlite3.SQLiteRows)}
+	// Statement is executed on first step, so fetching first row here also
+	// makes execution errors returned from query instead of being lost
+	// during rows iteration.
+	r.first = make([]driver.Value, len(r.Columns()))
+	r.firstErr = r.SQLiteRows.Next(r.first)
+	if r.firstErr != nil && !errors.Is(r.firstErr, io.EOF) {
+		_ = r.Close()
+		return nil, r.firstErr
+	}
+	return r, nil
+}
+
+// sqliteRows prefetches first row to find out column scan types.
+type sqliteRows struct {
+	*sqlite3.SQLiteRows
+	consumed bool
+	first    []driver.Value
+	firstErr error
+}
+
+func (r *sqliteRows) Next(dest []driver.Value) error {
+	if !r.consumed {
+		r.consumed = true
+		if r.firstErr != nil {
+			return r.firstErr
+		}
+		copy(dest, r.first)
+		return nil
+	}
+	return r.SQLiteRows.Next(dest)
+}
+
+// ColumnTypeDatabaseTypeName returns declared column type in upper case and without
+// size arguments, for example VARCHAR for varchar(255). It is empty for expressions.
+func (r *sqliteRows) ColumnTypeDatabaseTypeName(i int) string {
+	name := strings.ToUpper(r.SQLiteRows.ColumnTypeDatabaseTypeName(i))
+	if idx := strings.Index(name, "("); idx >= 0 {
+		name = name[:idx]
+	}
+	return strings.TrimSpace(name)
+}
+
+func (r *sqliteRows) ColumnTypeScanType(i int) reflect.Type {
+	declType := r.ColumnTypeDatabaseTypeName(i)
+	if r.firstErr == nil && i < len(r.first) && r.first[i] != nil {
+		switch r.first[i].(type) {
+		case int64:
+			// Expressions like avg() may return integers and floats in different rows.
+			if !strings.Contains(declType, "INT") {
+				return reflect.TypeOf(float64(0))
+			}
+			return reflect.TypeOf(int64(0))
+		case float64:
+			return reflect.TypeOf(float64(0))
+		case bool:
+			return reflect.TypeOf(false)
+		case time.Time:
+			return reflect.TypeOf(time.Time{})
+		case []byte:
+			return reflect.TypeOf([]byte{})
+		default:
+			return reflect.TypeOf("")
+		}
+	}
+	return scanTypeByAffinity(declType)
+}
+
+// scanTypeByAffinity returns scan type for a declared column type according to
+// SQLite type affinity rules, see https://www.sqlite.org/datatype3.html.
+func scanTypeByAffinity(declType string) reflect.Type {
+	switch {
+	case strings.Contains(declType, "INT"):
+		return reflect.TypeOf(int64(0))
+	case strings.Contains(declType, "REAL"), strings.Contains(declType, "FLOA"), strings.Contains(declType, "DOUB"):
+		return reflect.TypeOf(float64(0))
+	case declType == "DATE", declType == "DATETIME", declType == "TIMESTAMP":
+		return reflect.TypeOf(time.Time{})
+	default:
+		return reflect.TypeOf("")
+	}
+}
@@ -0,0 +1,162 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+	"github.com/grafana/grafana/pkg/infra/fs"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin/coreplugin"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
+	"github.com/mattn/go-sqlite3"
+)
+
+var logger = log.New("tsdb.sqlite")
+
+var (
+	errNoAllowedPaths = errors.New("no allowed paths configured for SQLite data source, see sqlite_allowed_paths setting")
+	errQueryFailed    = errors.New("query failed - please inspect Grafana server log for details")
+)
+
+type Service struct {
+	im instancemgmt.InstanceManager
+}
+
+func ProvideService(cfg *setting.Cfg, manager backendplugin.Manager) (*Service, error) {
+	registerDriver()
+	s := &Service{
+		im: datasource.NewInstanceManager(newInstanceSettings(cfg)),
+	}
+	factory := coreplugin.New(backend.ServeOpts{
+		QueryDataHandler: s,
+	})
+
+	if err := manager.Register("sqlite", factory); err != nil {
+		logger.Error("Failed to register plugin", "error", err)
+	}
+	return s, nil
+}
+
+func newInstanceSettings(cfg *setting.Cfg) datasource.InstanceFactoryFunc {
+	return func(settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+		jsonData := sqleng.JsonData{
+			MaxOpenConns:    0,
+			MaxIdleConns:    2,
+			ConnMaxLifetime: 14400,
+		}
+
+		err := json.Unmarshal(settings.JSONData, &jsonData)
+		if err != nil {
+			return nil, fmt.Errorf("error reading settings: %w", err)
+		}
+		dsInfo := sqleng.DataSourceInfo{
+			JsonData:                jsonData,
+			URL:                     settings.URL,
+			Database:                settings.Database,
+			ID:                      settings.ID,
+			Updated:                 settings.Updated,
+			UID:                     settings.UID,
+			DecryptedSecureJSONData: settings.DecryptedSecureJSONData,
+		}
+
+		path, err := resolvePath(cfg.SQLiteAllowedPaths, dsInfo.Database)
+		if err != nil {
+			return nil, err
+		}
+
+		cnnstr := connectionString(path)
+		if cfg.Env == setting.Dev {
+			logger.Debug("getEngine", "connection", cnnstr)
+		}
+
+		config := sqleng.DataPluginConfiguration{
+			DriverName:        driverName,
+			ConnectionString:  cnnstr,
+			DSInfo:            dsInfo,
+			TimeColumnNames:   []string{"time", "time_sec"},
+			MetricColumnTypes: []string{"TEXT", "CHAR", "VARCHAR", "NCHAR", "NVARCHAR", "CLOB"},
+			RowLimit:          cfg.DataProxyRowLimit,
+		}
+
+		rowTransformer := sqliteQueryResultTransformer{
+			log: logger,
+		}
+
+		return sqleng.NewQueryDataHandler(config, &rowTransformer, newSQLiteMacroEngine(logger), logger)
+	}
+}
+
+// connectionString returns URI to open database file in read-only mode.
+func connectionString(path string) string {
+	u := url.URL{
+		Scheme:   "file",
+		Path:     filepath.ToSlash(path),
+		RawQuery: "mode=ro&_query_only=true",
+	}
+	return u.String()
+}
+
+// resolvePath returns absolute path to a database file located inside one of
+// allowed directories.
+func resolvePath(allowedPaths []string, path string) (string, error) {
+	if len(allowedPaths) == 0 {
+		return "", errNoAllowedPaths
+	}
+	if strings.TrimSpace(path) == "" {
+		return "", errors.New("database file path is not set")
+	}
+	return fs.ResolveAllowedPath(allowedPaths, path)
+}
+
+func (s *Service) getDataSourceHandler(pluginCtx backend.PluginContext) (*sqleng.DataSourceHandler, error) {
+	i, err := s.im.Get(pluginCtx)
+	if err != nil {
+		return nil, err
+	}
+	instance := i.(*sqleng.DataSourceHandler)
+	return instance, nil
+}
+
+func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	dsHandler, err := s.getDataSourceHandler(req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+	return dsHandler.QueryData(ctx, req)
+}
+
+type sqliteQueryResultTransformer struct {
+	log log.Logger
+}
+
+func (t *sqliteQueryResultTransformer) TransformQueryError(err error) error {
+	var driverErr sqlite3.Error
+	if errors.As(err, &driverErr) {
+		// Syntax errors, missing tables or columns and denied statements are
+		// useful for users, other errors may reveal server details.
+		if driverErr.Code != sqlite3.ErrError && driverErr.Code != sqlite3.ErrAuth &&
+			driverErr.Code != sqlite3.ErrReadonly {
+			t.log.Error("query error", "err", err)
+			return errQueryFailed
+		}
+	}
+
+	return err
+}
+
+// GetConverterList returns no converters since column scan types are
+// reported by the driver wrapper.
+func (t *sqliteQueryResultTransformer) GetConverterList() []sqlutil.StringConverter {
+	return nil
+}
@@ -0,0 +1,163 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolvePath(t *testing.T) {
+	allowedDir := t.TempDir()
+	dbPath := filepath.Join(allowedDir, "metrics.db")
+	require.NoError(t, os.WriteFile(dbPath, nil, 0600))
+	allowedDir, err := filepath.EvalSymlinks(allowedDir)
+	require.NoError(t, err)
+
+	t.Run("no allowed paths", func(t *testing.T) {
+		_, err := resolvePath(nil, dbPath)
+		require.ErrorIs(t, err, errNoAllowedPaths)
+	})
+
+	t.Run("path not set", func(t *testing.T) {
+		_, err := resolvePath([]string{allowedDir}, " ")
+		require.EqualError(t, err, "database file path is not set")
+	})
+
+	t.Run("relative path", func(t *testing.T) {
+		path, err := resolvePath([]string{allowedDir}, "metrics.db")
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(allowedDir, "metrics.db"), path)
+	})
+}
+
+func TestSQLite(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "metrics.db")
+
+	db, err := sql.Open("sqlite3", dbPath)
+	require.NoError(t, err)
+	_, err = db.Exec(`CREATE TABLE metrics (time DATETIME, epoch INTEGER, host VARCHAR(100), value REAL)`)
+	require.NoError(t, err)
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	// Two rows at the end are out of the five minutes time range of queries.
+	for i := 0; i < 12; i++ {
+		rowTime := start.Add(time.Duration(i) * 30 * time.Second)
+		ts, epoch := rowTime.Format("2006-01-02 15:04:05"), rowTime.Unix()
+		_, err = db.Exec(`INSERT INTO metrics VALUES (?, ?, 'a', ?), (?, ?, 'b', ?)`, ts, epoch, i, ts, epoch, i*10)
+		require.NoError(t, err)
+	}
+	require.NoError(t, db.Close())
+
+	registerDriver()
+	cfg := setting.NewCfg()
+	cfg.SQLiteAllowedPaths = []string{dir}
+	cfg.DataProxyRowLimit = 1000
+	instance, err := newInstanceSettings(cfg)(backend.DataSourceInstanceSettings{
+		JSONData: []byte(`{}`),
+		Database: "metrics.db",
+	})
+	require.NoError(t, err)
+	handler := instance.(*sqleng.DataSourceHandler)
+	t.Cleanup(handler.Dispose)
+
+	query := func(rawSQL, format string) backend.DataResponse {
+		t.Helper()
+		resp, err := handler.QueryData(context.Background(), &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{
+				{
+					RefID: "A",
+					JSON:  []byte(`{"rawSql": "` + rawSQL + `", "format": "` + format + `"}`),
+					TimeRange: backend.TimeRange{
+						From: start,
+						To:   start.Add(5 * time.Minute),
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+		return resp.Responses["A"]
+	}
+
+	t.Run("time series query with macros", func(t *testing.T) {
+		res := query(`SELECT $__timeGroupAlias(time, '1m'), host AS metric, avg(value) AS value FROM metrics WHERE $__timeFilter(time) GROUP BY 1, 2 ORDER BY 1`, "time_series")
+		require.NoError(t, res.Error)
+		require.Len(t, res.Frames, 1)
+		frame := res.Frames[0]
+		require.Len(t, frame.Fields, 3)
+		require.Equal(t, 6, frame.Rows())
+		require.Equal(t, "a", frame.Fields[1].Name)
+		require.Equal(t, "b", frame.Fields[2].Name)
+		require.Equal(t, start, frame.Fields[0].At(0).(time.Time).UTC())
+		require.Equal(t, 0.5, *frame.Fields[1].At(0).(*float64))
+		require.Equal(t, 5.0, *frame.Fields[2].At(0).(*float64))
+	})
+
+	t.Run("time series query with unix epoch macros", func(t *testing.T) {
+		res := query(`SELECT $__unixEpochGroupAlias(epoch, '1m'), host AS metric, avg(value) AS value FROM metrics WHERE $__unixEpochFilter(epoch) GROUP BY 1, 2 ORDER BY 1`, "time_series")
+		require.NoError(t, res.Error)
+		require.Len(t, res.Frames, 1)
+		frame := res.Frames[0]
+		require.Equal(t, 6, frame.Rows())
+		require.Equal(t, start, frame.Fields[0].At(0).(time.Time).UTC())
+		require.Equal(t, 0.5, *frame.Fields[1].At(0).(*float64))
+	})
+
+	t.Run("time filters on text and unix epoch columns", func(t *testing.T) {
+		for _, filter := range []string{"$__timeFilter(time)", "$__unixEpochFilter(epoch)"} {
+			res := query(`SELECT host, value FROM metrics WHERE `+filter+` AND host = 'a'`, "table")
+			require.NoError(t, res.Error)
+			require.Len(t, res.Frames, 1)
+			// Rows at 0s, 30s, ..., 300s fit the time range.
+			require.Equal(t, 11, res.Frames[0].Rows(), filter)
+		}
+	})
+
+	t.Run("table query", func(t *testing.T) {
+		res := query(`SELECT time, host, value FROM metrics WHERE host = 'b' ORDER BY time LIMIT 2`, "table")
+		require.NoError(t, res.Error)
+		frame := res.Frames[0]
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, data.FieldTypeNullableTime, frame.Fields[0].Type())
+		require.Equal(t, data.FieldTypeNullableString, frame.Fields[1].Type())
+		require.Equal(t, data.FieldTypeNullableFloat64, frame.Fields[2].Type())
+		require.Equal(t, 10.0, *frame.Fields[2].At(1).(*float64))
+	})
+
+	t.Run("variable query", func(t *testing.T) {
+		resp, err := handler.QueryData(context.Background(), &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{
+				{
+					RefID:     "A",
+					QueryType: sqleng.VariableQueryType,
+					JSON:      []byte(`{"rawSql": "SELECT host FROM metrics WHERE host LIKE '$__searchFilter'", "searchFilter": "b", "sort": 1}`),
+				},
+			},
+		})
+		require.NoError(t, err)
+		res := resp.Responses["A"]
+		require.NoError(t, res.Error)
+		frame := res.Frames[0]
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, "b", frame.Fields[0].At(0))
+		require.Equal(t, "SELECT host FROM metrics WHERE host LIKE 'b%'", frame.Meta.ExecutedQueryString)
+	})
+
+	t.Run("database is read-only", func(t *testing.T) {
+		res := query(`DELETE FROM metrics`, "table")
+		require.Error(t, res.Error)
+	})
+
+	t.Run("attaching other databases is denied", func(t *testing.T) {
+		res := query(`ATTACH DATABASE '/tmp/other.db' AS other`, "table")
+		require.Error(t, res.Error)
+	})
+}
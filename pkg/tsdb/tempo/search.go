@@ -0,0 +1,223 @@
+package tempo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const (
+	// searchQueryType is the query type of Tempo search queries, queries
+	// without a query type are trace ID queries.
+	searchQueryType  = "nativeSearch"
+	traceIDQueryType = "traceId"
+
+	defaultSearchLimit = 20
+)
+
+// traceIDLinkPlaceholder is replaced by the trace ID of the table row when the link is clicked.
+const traceIDLinkPlaceholder = "${__value.raw}"
+
+type SearchResponse struct {
+	Traces []*TraceSearchMetadata `json:"traces"`
+}
+
+type TraceSearchMetadata struct {
+	TraceID           string `json:"traceID"`
+	RootServiceName   string `json:"rootServiceName"`
+	RootTraceName     string `json:"rootTraceName"`
+	StartTimeUnixNano string `json:"startTimeUnixNano"`
+	DurationMs        uint32 `json:"durationMs"`
+}
+
+func isSearchQuery(query backend.DataQuery, model *QueryModel) bool {
+	return query.QueryType == searchQueryType || model.QueryType == searchQueryType
+}
+
+func (s *Service) search(ctx context.Context, dsInfo *datasourceInfo, query backend.DataQuery, model *QueryModel) backend.DataResponse {
+	request, err := s.createSearchRequest(ctx, dsInfo, query.TimeRange, model)
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+
+	resp, err := dsInfo.HTTPClient.Do(request)
+	if err != nil {
+		return backend.DataResponse{Error: fmt.Errorf("failed get to tempo: %w", err)}
+	}
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			s.tlog.Warn("failed to close response body", "err", err)
+		}
+	}()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return backend.DataResponse{Error: fmt.Errorf("failed to search traces Status: %s Body: %s", resp.Status, string(body))}
+	}
+
+	searchResponse := &SearchResponse{}
+	if err := json.Unmarshal(body, searchResponse); err != nil {
+		return backend.DataResponse{Error: fmt.Errorf("failed to parse tempo search response: %w", err)}
+	}
+
+	frame, err := searchResponseToFrame(searchResponse, traceIDLink(dsInfo, query.TimeRange))
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+	frame.RefID = query.RefID
+	return backend.DataResponse{Frames: data.Frames{frame}}
+}
+
+// createSearchRequest creates a request to Tempo search API. Service and span
+// names are added to tags of the search query.
+func (s *Service) createSearchRequest(ctx context.Context, dsInfo *datasourceInfo, timeRange backend.TimeRange, model *QueryModel) (*http.Request, error) {
+	params := url.Values{}
+
+	tags := strings.TrimSpace(model.Search)
+	if model.ServiceName != "" {
+		tags = strings.TrimSpace(tags + " " + formatTag("service.name", model.ServiceName))
+	}
+	if model.SpanName != "" {
+		tags = strings.TrimSpace(tags + " " + formatTag("name", model.SpanName))
+	}
+	if tags != "" {
+		params.Set("tags", tags)
+	}
+
+	if model.MinDuration != "" {
+		if _, err := time.ParseDuration(model.MinDuration); err != nil {
+			return nil, fmt.Errorf("invalid min duration %q", model.MinDuration)
+		}
+		params.Set("minDuration", model.MinDuration)
+	}
+	if model.MaxDuration != "" {
+		if _, err := time.ParseDuration(model.MaxDuration); err != nil {
+			return nil, fmt.Errorf("invalid max duration %q", model.MaxDuration)
+		}
+		params.Set("maxDuration", model.MaxDuration)
+	}
+
+	limit := model.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	params.Set("limit", strconv.Itoa(limit))
+
+	if !timeRange.From.IsZero() && !timeRange.To.IsZero() {
+		params.Set("start", strconv.FormatInt(timeRange.From.Unix(), 10))
+		params.Set("end", strconv.FormatInt(timeRange.To.Unix(), 10))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", dsInfo.URL+"/api/search?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	s.tlog.Debug("Tempo search request", "url", req.URL.String(), "headers", req.Header)
+	return req, nil
+}
+
+// formatTag formats a tag in logfmt, values with spaces or quotes are quoted.
+func formatTag(key, value string) string {
+	if strings.ContainsAny(value, " \"=") {
+		value = strconv.Quote(value)
+	}
+	return key + "=" + value
+}
+
+// searchResponseToFrame converts found traces to a table frame, the most recent traces first.
+func searchResponseToFrame(searchResponse *SearchResponse, link data.DataLink) (*data.Frame, error) {
+	traces := make([]*TraceSearchMetadata, 0, len(searchResponse.Traces))
+	startTimes := make(map[*TraceSearchMetadata]time.Time, len(searchResponse.Traces))
+	for _, trace := range searchResponse.Traces {
+		if trace == nil {
+			continue
+		}
+		startTime := time.Time{}
+		if trace.StartTimeUnixNano != "" {
+			nanos, err := strconv.ParseInt(trace.StartTimeUnixNano, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse start time of trace %s: %w", trace.TraceID, err)
+			}
+			startTime = time.Unix(0, nanos).UTC()
+		}
+		startTimes[trace] = startTime
+		traces = append(traces, trace)
+	}
+	sort.SliceStable(traces, func(i, j int) bool {
+		return startTimes[traces[i]].After(startTimes[traces[j]])
+	})
+
+	traceIDs := make([]string, len(traces))
+	traceNames := make([]string, len(traces))
+	rootServiceNames := make([]string, len(traces))
+	starts := make([]time.Time, len(traces))
+	durations := make([]float64, len(traces))
+	for i, trace := range traces {
+		traceIDs[i] = trace.TraceID
+		traceNames[i] = trace.RootTraceName
+		rootServiceNames[i] = trace.RootServiceName
+		starts[i] = startTimes[trace]
+		durations[i] = float64(trace.DurationMs)
+	}
+
+	traceIDField := data.NewField("traceID", nil, traceIDs).SetConfig(&data.FieldConfig{
+		DisplayNameFromDS: "Trace ID",
+		Links:             []data.DataLink{link},
+	})
+
+	frame := data.NewFrame("Traces",
+		traceIDField,
+		data.NewField("traceName", nil, traceNames).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Trace name"}),
+		data.NewField("rootServiceName", nil, rootServiceNames).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Root service"}),
+		data.NewField("startTime", nil, starts).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Start time"}),
+		data.NewField("duration", nil, durations).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Duration", Unit: "ms"}),
+	)
+	frame.Meta = &data.FrameMeta{
+		PreferredVisualization: data.VisTypeTable,
+	}
+	return frame, nil
+}
+
+// traceIDLink returns a link opening the trace of the clicked row in Explore
+// with a trace ID query of the same data source.
+func traceIDLink(dsInfo *datasourceInfo, timeRange backend.TimeRange) data.DataLink {
+	state := map[string]interface{}{
+		"datasource": dsInfo.Name,
+		"queries": []map[string]string{
+			{"query": traceIDLinkPlaceholder, "queryType": traceIDQueryType},
+		},
+	}
+	if !timeRange.From.IsZero() && !timeRange.To.IsZero() {
+		state["range"] = map[string]string{
+			"from": strconv.FormatInt(timeRange.From.UnixNano()/int64(time.Millisecond), 10),
+			"to":   strconv.FormatInt(timeRange.To.UnixNano()/int64(time.Millisecond), 10),
+		}
+	}
+	// Marshaling a map of strings can't fail
+	b, _ := json.Marshal(state)
+	// The placeholder is interpolated by the frontend, so it's kept unescaped
+	left := strings.ReplaceAll(url.QueryEscape(string(b)), url.QueryEscape(traceIDLinkPlaceholder), traceIDLinkPlaceholder)
+
+	return data.DataLink{
+		Title: "Trace: " + traceIDLinkPlaceholder,
+		URL:   "/explore?left=" + left,
+	}
+}
@@ -0,0 +1,126 @@
+package tempo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearch(t *testing.T) {
+	var lastQuery url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/api/search" {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+		lastQuery = req.URL.Query()
+		if lastQuery.Get("tags") == "error=true" {
+			rw.WriteHeader(http.StatusBadRequest)
+			_, _ = rw.Write([]byte("invalid tags"))
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		_, _ = rw.Write([]byte(`{"traces": [
+			{"traceID": "2f3e0cee77ae5dc9", "rootServiceName": "app", "rootTraceName": "HTTP GET", "startTimeUnixNano": "1622541600000000000", "durationMs": 120},
+			{"traceID": "65a2cbbdd1e1e5d9", "rootServiceName": "db", "rootTraceName": "query", "startTimeUnixNano": "1622541660000000000"}
+		]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	s := &Service{
+		tlog: log.New("tempo-test"),
+		im:   datasource.NewInstanceManager(newInstanceSettings(httpclient.NewProvider())),
+	}
+	from := time.Date(2021, 6, 1, 9, 0, 0, 0, time.UTC)
+	to := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	query := func(t *testing.T, model string) backend.DataResponse {
+		t.Helper()
+		res, err := s.QueryData(context.Background(), &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{ID: 1, Name: "Tempo", URL: srv.URL},
+			},
+			Queries: []backend.DataQuery{
+				{RefID: "A", JSON: json.RawMessage(model), TimeRange: backend.TimeRange{From: from, To: to}},
+			},
+		})
+		require.NoError(t, err)
+		return res.Responses["A"]
+	}
+
+	t.Run("search request", func(t *testing.T) {
+		res := query(t, `{"queryType": "nativeSearch", "serviceName": "app", "spanName": "HTTP GET", "search": "http.status_code=500", "minDuration": "100ms", "maxDuration": "5s", "limit": 10}`)
+		require.NoError(t, res.Error)
+
+		require.Equal(t, `http.status_code=500 service.name=app name="HTTP GET"`, lastQuery.Get("tags"))
+		require.Equal(t, "100ms", lastQuery.Get("minDuration"))
+		require.Equal(t, "5s", lastQuery.Get("maxDuration"))
+		require.Equal(t, "10", lastQuery.Get("limit"))
+		require.Equal(t, "1622538000", lastQuery.Get("start"))
+		require.Equal(t, "1622541600", lastQuery.Get("end"))
+	})
+
+	t.Run("search defaults", func(t *testing.T) {
+		res := query(t, `{"queryType": "nativeSearch"}`)
+		require.NoError(t, res.Error)
+		require.Empty(t, lastQuery.Get("tags"))
+		require.Empty(t, lastQuery.Get("minDuration"))
+		require.Equal(t, "20", lastQuery.Get("limit"))
+	})
+
+	t.Run("search response is converted to a table frame", func(t *testing.T) {
+		res := query(t, `{"queryType": "nativeSearch"}`)
+		require.NoError(t, res.Error)
+		require.Len(t, res.Frames, 1)
+
+		frame := res.Frames[0]
+		require.Equal(t, "A", frame.RefID)
+		require.Equal(t, data.VisTypeTable, string(frame.Meta.PreferredVisualization))
+		require.Equal(t, []string{"traceID", "traceName", "rootServiceName", "startTime", "duration"}, fieldNames(frame))
+		require.Equal(t, 2, frame.Rows())
+
+		// The most recent trace goes first
+		require.Equal(t, "65a2cbbdd1e1e5d9", frame.Fields[0].At(0))
+		require.Equal(t, "query", frame.Fields[1].At(0))
+		require.Equal(t, "db", frame.Fields[2].At(0))
+		require.Equal(t, time.Date(2021, 6, 1, 10, 1, 0, 0, time.UTC), frame.Fields[3].At(0))
+		require.Equal(t, 0.0, frame.Fields[4].At(0))
+		require.Equal(t, "2f3e0cee77ae5dc9", frame.Fields[0].At(1))
+		require.Equal(t, 120.0, frame.Fields[4].At(1))
+		require.Equal(t, "ms", frame.Fields[4].Config.Unit)
+
+		links := frame.Fields[0].Config.Links
+		require.Len(t, links, 1)
+		require.True(t, strings.HasPrefix(links[0].URL, "/explore?left="))
+		left, err := url.QueryUnescape(strings.TrimPrefix(links[0].URL, "/explore?left="))
+		require.NoError(t, err)
+		require.JSONEq(t, `{
+			"datasource": "Tempo",
+			"queries": [{"query": "${__value.raw}", "queryType": "traceId"}],
+			"range": {"from": "1622538000000", "to": "1622541600000"}
+		}`, left)
+	})
+
+	t.Run("invalid duration", func(t *testing.T) {
+		res := query(t, `{"queryType": "nativeSearch", "minDuration": "soon"}`)
+		require.Error(t, res.Error)
+	})
+
+	t.Run("error response", func(t *testing.T) {
+		res := query(t, `{"queryType": "nativeSearch", "search": "error=true"}`)
+		require.Error(t, res.Error)
+		require.Contains(t, res.Error.Error(), "invalid tags")
+	})
+}
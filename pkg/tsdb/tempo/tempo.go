@@ -47,10 +47,20 @@ func ProvideService(httpClientProvider httpclient.Provider, manager backendplugi
 type datasourceInfo struct {
 	HTTPClient *http.Client
 	URL        string
+	Name       string
 }
 
 type QueryModel struct {
-	TraceID string `json:"query"`
+	TraceID   string `json:"query"`
+	QueryType string `json:"queryType"`
+
+	// Search query properties
+	Search      string `json:"search"`
+	ServiceName string `json:"serviceName"`
+	SpanName    string `json:"spanName"`
+	MinDuration string `json:"minDuration"`
+	MaxDuration string `json:"maxDuration"`
+	Limit       int    `json:"limit"`
 }
 
 func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.InstanceFactoryFunc {
@@ -68,6 +78,7 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 		model := &datasourceInfo{
 			HTTPClient: client,
 			URL:        settings.URL,
+			Name:       settings.Name,
 		}
 		return model, nil
 	}
@@ -89,6 +100,11 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		return nil, err
 	}
 
+	if isSearchQuery(req.Queries[0], model) {
+		result.Responses[refID] = s.search(ctx, dsInfo, req.Queries[0], model)
+		return result, nil
+	}
+
 	request, err := s.createRequest(ctx, dsInfo, model.TraceID)
 	if err != nil {
 		return result, err
@@ -0,0 +1,38 @@
+import React from 'react';
+import { DataSourcePluginOptionsEditorProps, SelectableValue } from '@grafana/data';
+import { DataSourceHttpSettings, InlineField, Select } from '@grafana/ui';
+import { FileOptions, FileSource } from '../types';
+
+export type Props = DataSourcePluginOptionsEditorProps<FileOptions>;
+
+const sources: Array<SelectableValue<FileSource>> = [
+  { label: 'Local file', value: 'local', description: 'Files in directories listed in file_allowed_paths setting' },
+  { label: 'URL', value: 'url', description: 'Files under the data source URL' },
+];
+
+export const ConfigEditor = ({ options, onOptionsChange }: Props) => {
+  const source = options.jsonData.source ?? 'local';
+
+  const onSourceChange = (value: SelectableValue<FileSource>) => {
+    onOptionsChange({ ...options, jsonData: { ...options.jsonData, source: value.value } });
+  };
+
+  return (
+    <>
+      <h3 className="page-heading">Source</h3>
+      <div className="gf-form-group">
+        <InlineField label="Source" labelWidth={16} tooltip="Where files of queries are read from.">
+          <Select width={30} menuShouldPortal options={sources} value={source} onChange={onSourceChange} />
+        </InlineField>
+      </div>
+      {source === 'url' && (
+        <DataSourceHttpSettings
+          defaultUrl="https://example.com/data"
+          dataSourceConfig={options}
+          onChange={onOptionsChange}
+          showAccessOptions={false}
+        />
+      )}
+    </>
+  );
+};
@@ -0,0 +1,133 @@
+import React, { ChangeEvent } from 'react';
+import { QueryEditorProps, SelectableValue } from '@grafana/data';
+import { Button, InlineField, InlineFieldRow, InlineSwitch, Input, Select } from '@grafana/ui';
+import { FileDatasource } from '../datasource';
+import { FileField, FileFieldType, FileFormat, FileOptions, FileQuery } from '../types';
+
+export type Props = QueryEditorProps<FileDatasource, FileQuery, FileOptions>;
+
+const formats: Array<SelectableValue<FileFormat>> = [
+  { label: 'Auto', value: undefined, description: 'Detect from the file extension' },
+  { label: 'CSV', value: 'csv' },
+  { label: 'JSON', value: 'json' },
+  { label: 'NDJSON', value: 'ndjson', description: 'A JSON document per line' },
+];
+
+const fieldTypes: Array<SelectableValue<FileFieldType>> = [
+  { label: 'Auto', value: undefined },
+  { label: 'String', value: 'string' },
+  { label: 'Number', value: 'number' },
+  { label: 'Boolean', value: 'boolean' },
+  { label: 'Time', value: 'time' },
+];
+
+export const QueryEditor = ({ query, onChange, onRunQuery }: Props) => {
+  const fields = query.fields ?? [];
+
+  const onInputChange = (key: 'path' | 'rootSelector' | 'timeField') => (event: ChangeEvent<HTMLInputElement>) => {
+    onChange({ ...query, [key]: event.target.value });
+  };
+
+  const onFormatChange = (value: SelectableValue<FileFormat>) => {
+    onChange({ ...query, format: value.value });
+    onRunQuery();
+  };
+
+  const onIgnoreTimeRangeChange = (event: React.FormEvent<HTMLInputElement>) => {
+    onChange({ ...query, ignoreTimeRange: event.currentTarget.checked });
+    onRunQuery();
+  };
+
+  const onFieldsChange = (next: FileField[]) => {
+    onChange({ ...query, fields: next });
+    onRunQuery();
+  };
+
+  const updateField = (index: number, field: Partial<FileField>) => {
+    onChange({ ...query, fields: fields.map((f, i) => (i === index ? { ...f, ...field } : f)) });
+  };
+
+  const onFieldTypeChange = (index: number) => (value: SelectableValue<FileFieldType>) => {
+    onFieldsChange(fields.map((f, i) => (i === index ? { ...f, type: value.value } : f)));
+  };
+
+  return (
+    <>
+      <InlineFieldRow>
+        <InlineField
+          label="Path"
+          labelWidth={14}
+          grow
+          tooltip="File path relative to an allowed directory, or URL path relative to the data source URL."
+        >
+          <Input value={query.path ?? ''} placeholder="data.csv" onChange={onInputChange('path')} onBlur={onRunQuery} />
+        </InlineField>
+        <InlineField label="Format">
+          <Select width={16} menuShouldPortal options={formats} value={query.format} onChange={onFormatChange} />
+        </InlineField>
+      </InlineFieldRow>
+      <InlineFieldRow>
+        <InlineField label="Root selector" labelWidth={14} tooltip="JSONPath selecting records of JSON documents.">
+          <Input
+            width={30}
+            value={query.rootSelector ?? ''}
+            placeholder="$.data.items"
+            onChange={onInputChange('rootSelector')}
+            onBlur={onRunQuery}
+          />
+        </InlineField>
+        <InlineField label="Time field" tooltip="Field with times, detected when empty.">
+          <Input
+            width={20}
+            value={query.timeField ?? ''}
+            placeholder="auto"
+            onChange={onInputChange('timeField')}
+            onBlur={onRunQuery}
+          />
+        </InlineField>
+        <InlineField label="Ignore time range" tooltip="Return all rows instead of rows in the time range.">
+          <InlineSwitch value={query.ignoreTimeRange ?? false} onChange={onIgnoreTimeRangeChange} />
+        </InlineField>
+      </InlineFieldRow>
+      {fields.map((field, index) => (
+        <InlineFieldRow key={index}>
+          <InlineField label="Field" labelWidth={14} tooltip="JSONPath relative to a record, or a field name.">
+            <Input
+              width={30}
+              value={field.selector}
+              placeholder="$.stats.cpu"
+              onChange={(e: ChangeEvent<HTMLInputElement>) => updateField(index, { selector: e.target.value })}
+              onBlur={onRunQuery}
+            />
+          </InlineField>
+          <InlineField label="Alias">
+            <Input
+              width={20}
+              value={field.name ?? ''}
+              onChange={(e: ChangeEvent<HTMLInputElement>) => updateField(index, { name: e.target.value })}
+              onBlur={onRunQuery}
+            />
+          </InlineField>
+          <InlineField label="Type">
+            <Select
+              width={14}
+              menuShouldPortal
+              options={fieldTypes}
+              value={field.type}
+              onChange={onFieldTypeChange(index)}
+            />
+          </InlineField>
+          <Button
+            variant="secondary"
+            icon="trash-alt"
+            aria-label="Remove field"
+            onClick={() => onFieldsChange(fields.filter((_, i) => i !== index))}
+          />
+        </InlineFieldRow>
+      ))}
+      <Button variant="secondary" icon="plus" onClick={() => onChange({ ...query, fields: [...fields, { selector: '' }] })}>
+        Field
+      </Button>
+    </>
+  );
+};
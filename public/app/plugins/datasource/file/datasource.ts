@@ -0,0 +1,22 @@
+import { DataSourceInstanceSettings, ScopedVars } from '@grafana/data';
+import { DataSourceWithBackend, getTemplateSrv } from '@grafana/runtime';
+import { FileOptions, FileQuery } from './types';
+
+export class FileDatasource extends DataSourceWithBackend<FileQuery, FileOptions> {
+  constructor(instanceSettings: DataSourceInstanceSettings<FileOptions>) {
+    super(instanceSettings);
+  }
+
+  filterQuery(query: FileQuery): boolean {
+    return !query.hide && !!query.path;
+  }
+
+  applyTemplateVariables(query: FileQuery, scopedVars: ScopedVars): Record<string, any> {
+    const templateSrv = getTemplateSrv();
+    return {
+      ...query,
+      path: templateSrv.replace(query.path ?? '', scopedVars),
+      rootSelector: templateSrv.replace(query.rootSelector ?? '', scopedVars),
+    };
+  }
+}
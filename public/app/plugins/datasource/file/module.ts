@@ -0,0 +1,6 @@
+import { DataSourcePlugin } from '@grafana/data';
+import { ConfigEditor } from './components/ConfigEditor';
+import { QueryEditor } from './components/QueryEditor';
+import { FileDatasource } from './datasource';
+
+export const plugin = new DataSourcePlugin(FileDatasource).setConfigEditor(ConfigEditor).setQueryEditor(QueryEditor);
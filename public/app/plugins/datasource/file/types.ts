@@ -0,0 +1,26 @@
+import { DataQuery, DataSourceJsonData } from '@grafana/data';
+
+export type FileFormat = 'csv' | 'json' | 'ndjson';
+
+export type FileFieldType = 'string' | 'number' | 'boolean' | 'time';
+
+export interface FileField {
+  selector: string;
+  name?: string;
+  type?: FileFieldType;
+}
+
+export interface FileQuery extends DataQuery {
+  path?: string;
+  format?: FileFormat;
+  rootSelector?: string;
+  fields?: FileField[];
+  timeField?: string;
+  ignoreTimeRange?: boolean;
+}
+
+export type FileSource = 'local' | 'url';
+
+export interface FileOptions extends DataSourceJsonData {
+  source?: FileSource;
+}
@@ -0,0 +1,38 @@
+import React, { ChangeEvent } from 'react';
+import { DataSourcePluginOptionsEditorProps } from '@grafana/data';
+import { InlineField, Input } from '@grafana/ui';
+import { SQLiteOptions } from '../types';
+
+export type Props = DataSourcePluginOptionsEditorProps<SQLiteOptions>;
+
+export const ConfigEditor = ({ options, onOptionsChange }: Props) => {
+  const onPathChange = (event: ChangeEvent<HTMLInputElement>) => {
+    onOptionsChange({ ...options, database: event.target.value });
+  };
+
+  const onTimeIntervalChange = (event: ChangeEvent<HTMLInputElement>) => {
+    onOptionsChange({ ...options, jsonData: { ...options.jsonData, timeInterval: event.target.value } });
+  };
+
+  return (
+    <>
+      <h3 className="page-heading">Database file</h3>
+      <div className="gf-form-group">
+        <InlineField
+          label="Path"
+          labelWidth={16}
+          tooltip="Path to a database file, relative to or inside one of the directories listed in sqlite_allowed_paths setting. The file is opened in read-only mode."
+        >
+          <Input width={50} value={options.database ?? ''} placeholder="metrics.db" onChange={onPathChange} />
+        </InlineField>
+        <InlineField
+          label="Min time interval"
+          labelWidth={16}
+          tooltip="A lower limit for the auto group by time interval, for example 1m or 1h."
+        >
+          <Input width={20} value={options.jsonData.timeInterval ?? ''} placeholder="1m" onChange={onTimeIntervalChange} />
+        </InlineField>
+      </div>
+    </>
+  );
+};
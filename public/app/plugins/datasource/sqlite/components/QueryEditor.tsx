@@ -0,0 +1,52 @@
+import React, { ChangeEvent } from 'react';
+import { QueryEditorProps, SelectableValue } from '@grafana/data';
+import { InlineField, Select, TextArea } from '@grafana/ui';
+import { SQLiteDatasource } from '../datasource';
+import { SQLiteOptions, SQLiteQuery, SQLiteQueryFormat } from '../types';
+
+export type Props = QueryEditorProps<SQLiteDatasource, SQLiteQuery, SQLiteOptions>;
+
+const formats: Array<SelectableValue<SQLiteQueryFormat>> = [
+  { label: 'Time series', value: 'time_series' },
+  { label: 'Table', value: 'table' },
+];
+
+const defaultQuery = `SELECT
+  $__timeGroupAlias(time_column, $__interval),
+  avg(value_column) AS value
+FROM metric_table
+WHERE $__timeFilter(time_column)
+GROUP BY 1
+ORDER BY 1`;
+
+export const QueryEditor = ({ query, onChange, onRunQuery }: Props) => {
+  const onRawSqlChange = (event: ChangeEvent<HTMLTextAreaElement>) => {
+    onChange({ ...query, rawSql: event.target.value });
+  };
+
+  const onFormatChange = (value: SelectableValue<SQLiteQueryFormat>) => {
+    onChange({ ...query, format: value.value });
+    onRunQuery();
+  };
+
+  return (
+    <>
+      <TextArea
+        rows={8}
+        value={query.rawSql ?? ''}
+        placeholder={defaultQuery}
+        onChange={onRawSqlChange}
+        onBlur={onRunQuery}
+      />
+      <InlineField label="Format as" labelWidth={12}>
+        <Select
+          width={20}
+          menuShouldPortal
+          options={formats}
+          value={query.format ?? 'time_series'}
+          onChange={onFormatChange}
+        />
+      </InlineField>
+    </>
+  );
+};
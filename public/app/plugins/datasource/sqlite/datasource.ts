@@ -0,0 +1,28 @@
+import { DataSourceInstanceSettings, ScopedVars } from '@grafana/data';
+import { DataSourceWithBackend, getTemplateSrv } from '@grafana/runtime';
+import { SQLiteOptions, SQLiteQuery } from './types';
+
+export class SQLiteDatasource extends DataSourceWithBackend<SQLiteQuery, SQLiteOptions> {
+  constructor(instanceSettings: DataSourceInstanceSettings<SQLiteOptions>) {
+    super(instanceSettings);
+  }
+
+  filterQuery(query: SQLiteQuery): boolean {
+    return !query.hide && !!query.rawSql;
+  }
+
+  applyTemplateVariables(query: SQLiteQuery, scopedVars: ScopedVars): Record<string, any> {
+    return {
+      ...query,
+      format: query.format ?? 'time_series',
+      rawSql: getTemplateSrv().replace(query.rawSql ?? '', scopedVars, this.interpolateVariable),
+    };
+  }
+
+  interpolateVariable = (value: string | string[]) => {
+    if (typeof value === 'string') {
+      return value;
+    }
+    return value.map((v) => `'${v.replace(/'/g, `''`)}'`).join(',');
+  };
+}
@@ -0,0 +1,6 @@
+import { DataSourcePlugin } from '@grafana/data';
+import { ConfigEditor } from './components/ConfigEditor';
+import { QueryEditor } from './components/QueryEditor';
+import { SQLiteDatasource } from './datasource';
+
+export const plugin = new DataSourcePlugin(SQLiteDatasource).setConfigEditor(ConfigEditor).setQueryEditor(QueryEditor);
@@ -0,0 +1,12 @@
+import { DataQuery, DataSourceJsonData } from '@grafana/data';
+
+export type SQLiteQueryFormat = 'time_series' | 'table';
+
+export interface SQLiteQuery extends DataQuery {
+  rawSql?: string;
+  format?: SQLiteQueryFormat;
+}
+
+export interface SQLiteOptions extends DataSourceJsonData {
+  timeInterval?: string;
+}